@@ -0,0 +1,164 @@
+package regexptable
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SetAdaptive enables or disables adaptive mode, in which each recompilation tunes
+// the table to the traffic it has seen, as counted by the hit statistics. It
+// suits long-lived tables whose inputs are dominated by a few exact values, such
+// as the common commands or status words of a log format. Two adaptations are
+// made, both only for tables anchored at both ends and engines that implement
+// LiteralAnalyzer, where they cannot change which pattern wins:
+//
+//   - Up to promote of the most often matched patterns that are plain literals,
+//     without capture groups or exclusions, are promoted to a map that the string
+//     lookups consult before matching the union. A literal is only promoted if
+//     the union itself gives it to that pattern.
+//   - Runs of adjacent literal patterns with equal priorities are reordered by
+//     descending hit count, so that engines which try the branches of the union
+//     in turn, such as backtracking ones, reach the common ones first.
+//
+// Adaptation is driven by hit statistics, so SetAdaptive enables them, keeping no
+// examples, if they are not already enabled. Stats reports the decisions made
+// by the last recompilation.
+func (rt *RegexpTable[T]) SetAdaptive(enabled bool, promote int) {
+	if enabled && !rt.hitStats {
+		rt.SetHitStats(true, 0)
+	}
+	rt.adaptive = enabled
+	rt.adaptivePromote = max(promote, 0)
+	rt.needsRecompile = true
+}
+
+// Adaptive reports whether adaptive mode is enabled, see SetAdaptive.
+func (rt *RegexpTable[T]) Adaptive() bool {
+	return rt.adaptive
+}
+
+// adaptable reports whether the table's adaptations can apply, returning the
+// engine's LiteralAnalyzer if so.
+func (rt *RegexpTable[T]) adaptable() (LiteralAnalyzer, bool) {
+	analyzer, ok := rt.engine.(LiteralAnalyzer)
+	return analyzer, ok && rt.adaptive && rt.Anchoring() == AnchorBoth
+}
+
+// literalText returns the text an entry matches if its pattern, as compiled, is a
+// plain literal.
+func (rt *RegexpTable[T]) literalText(analyzer LiteralAnalyzer, entry *ValueAndPattern[T]) (string, bool) {
+	if entry.exclusion != nil {
+		return "", false
+	}
+	prefix, rest, ok := analyzer.LiteralPrefix(rt.effectivePattern(entry))
+	return prefix, ok && rest == ""
+}
+
+// reorderByHits reorders runs of adjacent literal entries with equal priorities
+// by descending hit count, see SetAdaptive. Anchored at both ends, distinct
+// literals never match the same input, so their order cannot change the winner.
+// The caller orders the entries first.
+func (rt *RegexpTable[T]) reorderByHits() {
+	rt.reordered = nil
+	analyzer, ok := rt.adaptable()
+	if !ok {
+		return
+	}
+	for start := 0; start < len(rt.maplets); {
+		end := start
+		for end < len(rt.maplets) && rt.maplets[end].hits != nil && rt.maplets[end].priority == rt.maplets[start].priority {
+			if _, ok := rt.literalText(analyzer, rt.maplets[end]); !ok {
+				break
+			}
+			end++
+		}
+		if end-start < 2 {
+			start++
+			continue
+		}
+		run := rt.maplets[start:end]
+		moved := slices.Clone(run)
+		slices.SortStableFunc(run, func(a, b *ValueAndPattern[T]) int {
+			return cmp.Compare(b.hits.count.Load(), a.hits.count.Load())
+		})
+		if !slices.Equal(moved, run) {
+			for _, entry := range run {
+				rt.reordered = append(rt.reordered, entry.insertionIndex())
+			}
+		}
+		start = end
+	}
+}
+
+// promoteHotLiterals rebuilds the map of promoted literals, see SetAdaptive. The
+// caller must have compiled the union.
+func (rt *RegexpTable[T]) promoteHotLiterals() {
+	rt.hotLiterals = nil
+	analyzer, ok := rt.adaptable()
+	if !ok || rt.adaptivePromote == 0 {
+		return
+	}
+	type candidate struct {
+		entry   *ValueAndPattern[T]
+		literal string
+		hits    uint64
+	}
+	var candidates []candidate
+	for _, entry := range rt.maplets {
+		if entry.hits == nil || entry.groupCount > 0 {
+			continue
+		}
+		if hits := entry.hits.count.Load(); hits > 0 {
+			if literal, ok := rt.literalText(analyzer, entry); ok {
+				candidates = append(candidates, candidate{entry, literal, hits})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.hits, a.hits)
+	})
+	for _, c := range candidates {
+		if len(rt.hotLiterals) == rt.adaptivePromote {
+			break
+		}
+		// An earlier pattern may match the literal too, and would then win it.
+		winner, err := rt.locateUnion(c.literal, rt.compiled, rt.individualRegexp)
+		if err != nil || winner.entry != c.entry {
+			continue
+		}
+		if rt.hotLiterals == nil {
+			rt.hotLiterals = make(map[string]*ValueAndPattern[T])
+		}
+		rt.hotLiterals[c.literal] = c.entry
+	}
+}
+
+// forgetRemovedLiterals drops the promoted literals of entries that are no longer
+// in the table.
+func (rt *RegexpTable[T]) forgetRemovedLiterals() {
+	live := make(map[*ValueAndPattern[T]]bool, len(rt.maplets))
+	for _, entry := range rt.maplets {
+		live[entry] = true
+	}
+	for literal, entry := range rt.hotLiterals {
+		if !live[entry] {
+			delete(rt.hotLiterals, literal)
+		}
+	}
+}
+
+// promotedIndexes returns the insertion indexes of the promoted patterns in table
+// order.
+func (rt *RegexpTable[T]) promotedIndexes() []int {
+	promoted := make(map[*ValueAndPattern[T]]bool, len(rt.hotLiterals))
+	for _, entry := range rt.hotLiterals {
+		promoted[entry] = true
+	}
+	var indexes []int
+	for _, entry := range rt.maplets {
+		if promoted[entry] {
+			indexes = append(indexes, entry.insertionIndex())
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,93 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetAdaptive(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		WithAdaptive(true, 2).
+		AddPattern(`get`, "get").
+		AddPattern(`put`, "put").
+		AddPattern(`post`, "post").
+		AddPattern(`[a-z]+`, "word").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+	if !table.Adaptive() {
+		t.Error("Expected adaptive mode to be enabled")
+	}
+	for _, input := range []string{"post", "post", "post", "put", "put", "get", "other"} {
+		if _, _, err := table.Lookup(input); err != nil {
+			t.Fatalf("Lookup(%q) failed: %v", input, err)
+		}
+	}
+	if err := table.Compact(); err != nil {
+		t.Fatalf("Failed to recompile: %v", err)
+	}
+	stats := table.Stats()
+	if !slices.Equal(stats.Reordered, []int{2, 1, 0}) {
+		t.Errorf("Expected [2 1 0] reordered, got %v", stats.Reordered)
+	}
+	if !slices.Equal(stats.Promoted, []int{2, 1}) {
+		t.Errorf("Expected [2 1] promoted, got %v", stats.Promoted)
+	}
+
+	for input, expected := range map[string]string{"post": "post", "put": "put", "get": "get", "postal": "word"} {
+		result, err := table.LookupResult(input)
+		if err != nil || result.Value != expected {
+			t.Errorf("Expected %q for %q, got %v, %v", expected, input, result, err)
+			continue
+		}
+		if expected != "word" && result.Pattern != input {
+			t.Errorf("Expected pattern %q, got %q", input, result.Pattern)
+		}
+	}
+	if got := table.HitCount(2); got != 4 {
+		t.Errorf("Expected promoted lookups to count as hits, got %d", got)
+	}
+
+	table.RemovePattern(`post`)
+	if value, _, err := table.Lookup("post"); err != nil || value != "word" {
+		t.Errorf("Expected a removed literal to fall through, got %q, %v", value, err)
+	}
+	if stats := table.Stats(); !slices.Equal(stats.Promoted, []int{1}) {
+		t.Errorf("Expected [1] promoted, got %v", stats.Promoted)
+	}
+}
+
+func TestSetAdaptive_Shadowed(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.SetAdaptive(true, 10)
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`get`, "get")
+	for range 3 {
+		if value, _, _ := table.Lookup("get"); value != "word" {
+			t.Fatalf("Expected word, got %q", value)
+		}
+	}
+	if err := table.Compact(); err != nil {
+		t.Fatalf("Failed to recompile: %v", err)
+	}
+	if stats := table.Stats(); stats.Promoted != nil || stats.Reordered != nil {
+		t.Errorf("Expected no adaptations, got %v and %v", stats.Promoted, stats.Reordered)
+	}
+}
+
+func TestSetAdaptive_Unanchored(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	table.SetAdaptive(true, 10)
+	table.AddPattern(`ab`, "ab")
+	table.AddPattern(`abc`, "abc")
+	for range 3 {
+		table.Lookup("abc")
+	}
+	if err := table.Compact(); err != nil {
+		t.Fatalf("Failed to recompile: %v", err)
+	}
+	if stats := table.Stats(); stats.Promoted != nil || stats.Reordered != nil {
+		t.Errorf("Expected no adaptations without anchoring at both ends, got %v and %v", stats.Promoted, stats.Reordered)
+	}
+}
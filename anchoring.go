@@ -0,0 +1,250 @@
+package regexptable
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Anchoring selects which ends of the input a lookup is anchored to.
+type Anchoring int
+
+const (
+	AnchorNone  Anchoring = iota // Search for a match anywhere in the input
+	AnchorStart                  // The match must start at the beginning of the input
+	AnchorEnd                    // The match must finish at the end of the input
+	AnchorBoth                   // The match must span the whole input
+)
+
+// NewAnchoring returns the Anchoring corresponding to a pair of anchor flags, as
+// passed to NewRegexpTable and Build.
+func NewAnchoring(anchorStart, anchorEnd bool) Anchoring {
+	a := AnchorNone
+	if anchorStart {
+		a |= AnchorStart
+	}
+	if anchorEnd {
+		a |= AnchorEnd
+	}
+	return a
+}
+
+// AnchorsStart reports whether matches must start at the beginning of the input.
+func (a Anchoring) AnchorsStart() bool {
+	return a&AnchorStart != 0
+}
+
+// AnchorsEnd reports whether matches must finish at the end of the input.
+func (a Anchoring) AnchorsEnd() bool {
+	return a&AnchorEnd != 0
+}
+
+// String returns the name of the anchoring.
+func (a Anchoring) String() string {
+	switch a {
+	case AnchorNone:
+		return "AnchorNone"
+	case AnchorStart:
+		return "AnchorStart"
+	case AnchorEnd:
+		return "AnchorEnd"
+	case AnchorBoth:
+		return "AnchorBoth"
+	default:
+		return fmt.Sprintf("Anchoring(%d)", int(a))
+	}
+}
+
+// AnchorPattern wraps a pattern in a non-capturing group and anchors it at the
+// requested ends, e.g. ^(?:pattern)$ for AnchorBoth. This is exactly how tables
+// anchor their patterns, unless SetOmitRedundantWrapper is enabled (see
+// AnchorPatternMinimal), so tooling outside a table can reproduce the patterns it
+// compiles byte for byte.
+func AnchorPattern(pattern string, anchoring Anchoring) string {
+	result := WrapNonCapturing(pattern)
+	if anchoring.AnchorsStart() {
+		result = "^" + result
+	}
+	if anchoring.AnchorsEnd() {
+		result += "$"
+	}
+	return result
+}
+
+// AnchorPatternMinimal is like AnchorPattern but leaves out the non-capturing
+// group when it provably makes no difference: when the pattern is not anchored
+// at all, or when it is already a single group, such as (a|b) or (?P<name>x).
+// Tables with SetOmitRedundantWrapper enabled anchor their patterns this way.
+func AnchorPatternMinimal(pattern string, anchoring Anchoring) string {
+	if anchoring != AnchorNone && !isSingleGroup(pattern) {
+		pattern = WrapNonCapturing(pattern)
+	}
+	if anchoring.AnchorsStart() {
+		pattern = "^" + pattern
+	}
+	if anchoring.AnchorsEnd() {
+		pattern += "$"
+	}
+	return pattern
+}
+
+// SetOmitRedundantWrapper controls whether the table leaves out the non-capturing
+// group it normally wraps patterns in before anchoring them, in the cases where
+// AnchorPatternMinimal can tell that the group makes no difference, e.g. a table
+// with a single pattern or an unanchored table. Some engines number groups
+// differently or match more slowly with the extra group.
+func (rt *RegexpTable[T]) SetOmitRedundantWrapper(enabled bool) {
+	if rt.omitWrapper != enabled {
+		rt.omitWrapper = enabled
+		rt.needsRecompile = true
+		for _, entry := range rt.maplets {
+			entry.compiledPattern = nil
+		}
+	}
+}
+
+// anchorPatternAs anchors a pattern the way the table does, with the given
+// anchoring.
+func (rt *RegexpTable[T]) anchorPatternAs(pattern string, anchoring Anchoring) string {
+	if rt.omitWrapper {
+		return AnchorPatternMinimal(pattern, anchoring)
+	}
+	return AnchorPattern(pattern, anchoring)
+}
+
+// WrapNonCapturing wraps a pattern in a non-capturing group, (?:pattern), so that
+// it can be combined with other patterns without its alternations or trailing
+// operators leaking out.
+func WrapNonCapturing(pattern string) string {
+	return "(?:" + pattern + ")"
+}
+
+// anchoredVariants holds the unions compiled for anchorings other than the
+// table's own, see LookupAnchored. Lookups compile them on first use and may run
+// concurrently, so the map is guarded by a mutex; Recompile replaces the whole
+// set rather than clearing it.
+type anchoredVariants[T any] struct {
+	mu     sync.Mutex
+	unions map[Anchoring]*anchoredUnion[T]
+}
+
+// anchoredUnion holds the table's union compiled with a non-default anchoring,
+// together with the matching stand-alone regexps used for disambiguation.
+type anchoredUnion[T any] struct {
+	anchoring   Anchoring
+	compiled    CompiledRegexp
+	mu          sync.Mutex // Guards individuals, which lookups fill concurrently
+	individuals map[*ValueAndPattern[T]]CompiledRegexp
+}
+
+// individualRegexp returns the entry's pattern compiled on its own with this
+// variant's anchoring, compiling and caching it on first use.
+func (u *anchoredUnion[T]) individualRegexp(rt *RegexpTable[T], entry *ValueAndPattern[T]) (CompiledRegexp, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if compiled, ok := u.individuals[entry]; ok {
+		return compiled, nil
+	}
+	compiled, err := rt.engine.Compile(rt.anchorPatternAs(rt.effectivePattern(entry), u.anchoring))
+	if err != nil {
+		return nil, err
+	}
+	u.individuals[entry] = compiled
+	return compiled, nil
+}
+
+// Anchoring returns the anchoring the table was created with, which is the one
+// used by Lookup.
+func (rt *RegexpTable[T]) Anchoring() Anchoring {
+	return NewAnchoring(rt.anchorStart, rt.anchorEnd)
+}
+
+// LookupAnchored is like Lookup but overrides the table's anchoring for this call,
+// so that one table can serve both as a classifier of whole tokens and as a search
+// within longer text. The union compiled for each anchoring is kept alongside the
+// table's own and reused until the table next recompiles. Lookups with the table's
+// own anchoring are exactly equivalent to Lookup; other anchorings bypass the
+// memoization cache.
+func (rt *RegexpTable[T]) LookupAnchored(input string, anchoring Anchoring) (T, []string, error) {
+	var zero T
+
+	if anchoring == rt.Anchoring() {
+		return rt.Lookup(input)
+	}
+
+	err := rt.ensureCompiled()
+	if err != nil {
+		return zero, nil, err
+	}
+	variant, err := rt.anchoredVariant(anchoring)
+	if err != nil {
+		return zero, nil, err
+	}
+	input, err = rt.limitInput(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	normalized := input
+	if rt.normalizers != nil {
+		normalized, _ = rt.normalize(input)
+	}
+	found, err := rt.locateUnion(normalized, variant.compiled, func(entry *ValueAndPattern[T]) (CompiledRegexp, error) {
+		return variant.individualRegexp(rt, entry)
+	})
+	if err != nil {
+		return zero, nil, err
+	}
+	matches := found.submatches(nil)
+	rt.recordHit(found.entry, input, matches)
+	return found.entry.Value, matches, nil
+}
+
+// TryLookupAnchored is like LookupAnchored but reports failure with a boolean.
+func (rt *RegexpTable[T]) TryLookupAnchored(input string, anchoring Anchoring) (T, []string, bool) {
+	value, matches, err := rt.LookupAnchored(input, anchoring)
+	return value, matches, err == nil
+}
+
+// anchoredVariant returns the union compiled with the given anchoring, compiling
+// it on first use. The caller must ensure the table has been compiled.
+func (rt *RegexpTable[T]) anchoredVariant(anchoring Anchoring) (*anchoredUnion[T], error) {
+	if anchoring < AnchorNone || anchoring > AnchorBoth {
+		return nil, codeErrorf(CodeInvalidArgument, "invalid anchoring: %v", anchoring)
+	}
+	variants := rt.variants
+	if variants == nil {
+		// A table that has never been compiled has nowhere to keep the variant.
+		return rt.newAnchoredVariant(anchoring)
+	}
+	variants.mu.Lock()
+	defer variants.mu.Unlock()
+	if variant, ok := variants.unions[anchoring]; ok {
+		return variant, nil
+	}
+	variant, err := rt.newAnchoredVariant(anchoring)
+	if err != nil {
+		return nil, err
+	}
+	if variants.unions == nil {
+		variants.unions = make(map[Anchoring]*anchoredUnion[T])
+	}
+	variants.unions[anchoring] = variant
+	return variant, nil
+}
+
+// newAnchoredVariant compiles the union with the given anchoring.
+func (rt *RegexpTable[T]) newAnchoredVariant(anchoring Anchoring) (*anchoredUnion[T], error) {
+	variant := &anchoredUnion[T]{
+		anchoring:   anchoring,
+		individuals: make(map[*ValueAndPattern[T]]CompiledRegexp),
+	}
+	if rt.compiled != nil {
+		// Anchoring adds no capture groups, so the group bookkeeping recorded by
+		// Recompile applies to this union unchanged.
+		compiled, err := rt.engine.Compile(rt.anchorPatternAs(rt.unionPattern, anchoring))
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to compile %v union regexp: %w", anchoring, err)
+		}
+		variant.compiled = compiled
+	}
+	return variant, nil
+}
@@ -0,0 +1,216 @@
+package regexptable
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRegexpTable_LookupAnchored(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testCases := []struct {
+		input     string
+		anchoring Anchoring
+		expected  string
+		match     string
+		ok        bool
+	}{
+		{"hello", AnchorBoth, "word", "hello", true},
+		{"say 42 now", AnchorBoth, "", "", false},
+		{"say 42 now", AnchorNone, "word", "say", true},
+		{"!! 42 now", AnchorNone, "number", "42", true},
+		{"42 now", AnchorStart, "number", "42", true},
+		{"!! 42", AnchorStart, "", "", false},
+		{"!! 42", AnchorEnd, "number", "42", true},
+		{"42 !!", AnchorEnd, "", "", false},
+	}
+	for _, tc := range testCases {
+		value, matches, ok := table.TryLookupAnchored(tc.input, tc.anchoring)
+		if ok != tc.ok {
+			t.Errorf("LookupAnchored(%q, %v): expected ok=%v, got %v", tc.input, tc.anchoring, tc.ok, ok)
+			continue
+		}
+		if ok && (value != tc.expected || matches[0] != tc.match) {
+			t.Errorf("LookupAnchored(%q, %v): expected (%q, %q), got (%q, %q)", tc.input, tc.anchoring, tc.expected, tc.match, value, matches[0])
+		}
+	}
+
+	// The table's own anchoring is unaffected by the overrides.
+	if _, _, ok := table.TryLookup("say 42 now"); ok {
+		t.Error("Expected the table's own lookup to stay anchored")
+	}
+}
+
+func TestRegexpTable_LookupAnchoredAfterAddPattern(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	if err := table.AddPattern(`cat`, "cat"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if _, _, ok := table.TryLookupAnchored("dog", AnchorBoth); ok {
+		t.Fatal("Expected no match before the pattern is added")
+	}
+
+	// Adding a pattern must invalidate the cached secondary union.
+	if err := table.AddPattern(`(d)og`, "dog"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	value, matches, err := table.LookupAnchored("dog", AnchorBoth)
+	if err != nil {
+		t.Fatalf("LookupAnchored failed: %v", err)
+	}
+	if value != "dog" || !slices.Equal(matches, []string{"dog", "d"}) {
+		t.Errorf("Expected (dog, [dog d]), got (%q, %v)", value, matches)
+	}
+}
+
+func TestRegexpTable_LookupAnchoredDisambiguation(t *testing.T) {
+	// Both patterns can match the empty string, forcing the disambiguation path.
+	table := NewRegexpTable[string](false, false)
+	for _, p := range []struct{ pattern, value string }{{`x*`, "xs"}, {`y*`, "ys"}} {
+		if err := table.AddPattern(p.pattern, p.value); err != nil {
+			t.Fatalf("Failed to add pattern: %v", err)
+		}
+	}
+	value, _, err := table.LookupAnchored("", AnchorBoth)
+	if err != nil {
+		t.Fatalf("LookupAnchored failed: %v", err)
+	}
+	if value != "xs" {
+		t.Errorf("Expected xs, got %q", value)
+	}
+	if _, _, err := table.LookupAnchored("", Anchoring(7)); err == nil {
+		t.Error("Expected an error for an invalid anchoring")
+	}
+}
+
+// TestRegexpTable_LookupAnchoredConcurrent compiles variants and their individual
+// regexps from several goroutines at once, which go test -race checks.
+func TestRegexpTable_LookupAnchoredConcurrent(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`x*`, "xs").
+		AddPattern(`y*`, "ys").
+		WithPrecompileIndividuals(true).
+		Build(false, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			anchoring := []Anchoring{AnchorStart, AnchorEnd, AnchorBoth}[i%3]
+			if value, _, err := table.LookupAnchored("", anchoring); err != nil || value != "xs" {
+				t.Errorf("Expected xs with %v, got %q, %v", anchoring, value, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewAnchoring(t *testing.T) {
+	testCases := []struct {
+		start, end bool
+		expected   Anchoring
+	}{
+		{false, false, AnchorNone},
+		{true, false, AnchorStart},
+		{false, true, AnchorEnd},
+		{true, true, AnchorBoth},
+	}
+	for _, tc := range testCases {
+		a := NewAnchoring(tc.start, tc.end)
+		if a != tc.expected || a.AnchorsStart() != tc.start || a.AnchorsEnd() != tc.end {
+			t.Errorf("NewAnchoring(%v, %v): expected %v, got %v", tc.start, tc.end, tc.expected, a)
+		}
+	}
+}
+
+func TestAnchorPattern(t *testing.T) {
+	testCases := []struct {
+		anchoring Anchoring
+		expected  string
+	}{
+		{AnchorNone, `(?:a|b)`},
+		{AnchorStart, `^(?:a|b)`},
+		{AnchorEnd, `(?:a|b)$`},
+		{AnchorBoth, `^(?:a|b)$`},
+	}
+	for _, tc := range testCases {
+		if got := AnchorPattern(`a|b`, tc.anchoring); got != tc.expected {
+			t.Errorf("AnchorPattern(%v): expected %q, got %q", tc.anchoring, tc.expected, got)
+		}
+	}
+	if got := WrapNonCapturing(`x+`); got != `(?:x+)` {
+		t.Errorf("WrapNonCapturing: expected (?:x+), got %q", got)
+	}
+}
+
+func TestAnchorPattern_MatchesTableComposition(t *testing.T) {
+	for _, anchoring := range []Anchoring{AnchorNone, AnchorStart, AnchorEnd, AnchorBoth} {
+		table := NewRegexpTable[string](anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+		if err := table.AddAndCheckPattern(`a|b`, "ab"); err != nil {
+			t.Fatalf("AddAndCheckPattern failed: %v", err)
+		}
+		if expected := AnchorPattern(table.unionPattern, anchoring); table.compiledUnion != expected {
+			t.Errorf("%v: expected the table to compile %q, got %q", anchoring, expected, table.compiledUnion)
+		}
+	}
+}
+
+func TestAnchorPatternMinimal(t *testing.T) {
+	testCases := []struct {
+		pattern   string
+		anchoring Anchoring
+		expected  string
+	}{
+		{`a|b`, AnchorNone, `a|b`},
+		{`a|b`, AnchorBoth, `^(?:a|b)$`},
+		{`(a|b)`, AnchorStart, `^(a|b)`},
+		{`(?P<x>a)`, AnchorEnd, `(?P<x>a)$`},
+		{`(?m)a`, AnchorEnd, `(?:(?m)a)$`},
+	}
+	for _, tc := range testCases {
+		if got := AnchorPatternMinimal(tc.pattern, tc.anchoring); got != tc.expected {
+			t.Errorf("AnchorPatternMinimal(%q, %v): expected %q, got %q", tc.pattern, tc.anchoring, tc.expected, got)
+		}
+	}
+}
+
+func TestRegexpTable_SetOmitRedundantWrapper(t *testing.T) {
+	single := NewRegexpTableBuilder[string]().WithOmitRedundantWrapper(true).AddPattern(`a|b`, "ab").MustBuild(true, true)
+	if single.compiledUnion != `^(?P<__REGEXPTABLE_1__>(?:a|b))$` {
+		t.Errorf("Expected the single pattern's union to be unwrapped, got %s", single.compiledUnion)
+	}
+	if AnchorPatternMinimal(single.unionPattern, single.Anchoring()) != single.compiledUnion {
+		t.Error("Expected AnchorPatternMinimal to reproduce the compiled union")
+	}
+
+	// Tables with and without the wrapper classify every input the same way.
+	patterns := []string{`(a|b)`, `(?i:x)y`, `\d+`, `(?m)^z$`, `(c)(d)`, `(?P<w>[a-z]+)`}
+	inputs := []string{"a", "b", "Xy", "42", "z", "cd", "word", "a\nz", "", "ab"}
+	for _, anchoring := range []Anchoring{AnchorNone, AnchorStart, AnchorEnd, AnchorBoth} {
+		for _, pattern := range patterns {
+			wrapped := NewRegexpTable[string](anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+			minimal := NewRegexpTable[string](anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+			minimal.SetOmitRedundantWrapper(true)
+			for _, table := range []*RegexpTable[string]{wrapped, minimal} {
+				table.AddPattern(pattern, pattern)
+			}
+			for _, input := range inputs {
+				want, err := wrapped.LookupResult(input)
+				got, err2 := minimal.LookupResult(input)
+				if (err == nil) != (err2 == nil) || err == nil && (!slices.Equal(want.Groups, got.Groups) || want.Start != got.Start) {
+					t.Errorf("%v %s %q: expected %v, %v, got %v, %v", anchoring, pattern, input, want, err, got, err2)
+				}
+			}
+		}
+	}
+}
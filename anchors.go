@@ -0,0 +1,137 @@
+package regexptable
+
+import "strings"
+
+// findAnchors returns the byte offsets of explicit anchors in a pattern: unescaped
+// ^ and $ outside character classes, and the \A, \z and \Z escapes. The scan is
+// purely textual so that it works for any engine's syntax.
+func findAnchors(pattern string) []int {
+	var offsets []int
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				switch pattern[i+1] {
+				case 'A', 'z', 'Z':
+					offsets = append(offsets, i)
+				}
+			}
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '^', '$':
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// hasTopLevelAlternation reports whether a pattern contains a | outside any group
+// or character class, as in a|b. Such a pattern must be grouped before it is
+// combined with other text. Like findAnchors, the scan is purely textual.
+func hasTopLevelAlternation(pattern string) bool {
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSingleGroup reports whether a pattern is one group from start to end, as in
+// (a|b) or (?P<name>x), so that text added around it cannot bind to part of it.
+// Groups that only set flags, such as (?m), do not count, since their flags
+// carry on beyond them. Like findAnchors, the scan is purely textual, and
+// patterns with quoted text (\Q...\E) are never reported as a single group.
+func isSingleGroup(pattern string) bool {
+	if !strings.HasPrefix(pattern, "(") || strings.Contains(pattern, `\Q`) || isFlagGroup(pattern) {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(pattern)-1
+			}
+		}
+	}
+	return false
+}
+
+// isFlagGroup reports whether a pattern starts with a group that only sets
+// flags, such as (?i) or (?-m).
+func isFlagGroup(pattern string) bool {
+	if !strings.HasPrefix(pattern, "(?") {
+		return false
+	}
+	for i := 2; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == ')':
+			return true
+		case c != '-' && !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'):
+			return false
+		}
+	}
+	return false
+}
+
+// skipClass returns the offset of the ] that closes the character class opened at
+// pattern[start], or the last offset of the pattern if the class is not closed.
+func skipClass(pattern string, start int) int {
+	i := start + 1
+	// A ] straight after [ or [^ is a literal member of the class.
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++ // Skip the escaped character
+		case pattern[i] == '[' && i+1 < len(pattern) && pattern[i+1] == ':':
+			// Skip a POSIX class such as [:alpha:], whose ] does not close the class.
+			for j := i + 2; j+1 < len(pattern); j++ {
+				if pattern[j] == ':' && pattern[j+1] == ']' {
+					i = j + 1
+					break
+				}
+			}
+		case pattern[i] == ']':
+			return i
+		}
+	}
+	return len(pattern) - 1
+}
+
+// formatBranch formats the named group that represents a pattern in a union,
+// grouping the pattern first if it has a top-level alternation. Engines wrap the
+// pattern in parentheses when they name it, but an alternation must not depend on
+// that: each pattern is one branch of the union, whatever the engine.
+func formatBranch(engine RegexpEngine, groupName, pattern string) string {
+	if hasTopLevelAlternation(pattern) {
+		pattern = WrapNonCapturing(pattern)
+	}
+	return engine.FormatNamedGroup(groupName, pattern)
+}
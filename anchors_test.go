@@ -0,0 +1,106 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindAnchors(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected []int
+	}{
+		{`abc`, nil},
+		{`^abc`, []int{0}},
+		{`abc$`, []int{3}},
+		{`a|^b|c$`, []int{2, 6}},
+		{`[^abc]`, nil},
+		{`[$^]`, nil},
+		{`[]^]x`, nil},
+		{`[^]$]`, nil},
+		{`\^\$`, nil},
+		{`\Aabc\z`, []int{0, 5}},
+		{`[[:alpha:]]$`, []int{11}},
+		{`[\]^]`, nil},
+	}
+	for _, tc := range testCases {
+		if got := findAnchors(tc.pattern); !slices.Equal(got, tc.expected) {
+			t.Errorf("findAnchors(%q) = %v, expected %v", tc.pattern, got, tc.expected)
+		}
+	}
+}
+
+func TestHasTopLevelAlternation(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected bool
+	}{
+		{`abc`, false},
+		{`a|b`, true},
+		{`(a|b)c`, false},
+		{`(?:a|b)|c`, true},
+		{`[|]`, false},
+		{`\|`, false},
+		{`[\]|]`, false},
+		{`[[:alpha:]|]`, false},
+		{`(a)|(b)`, true},
+		{`|`, true},
+	}
+	for _, tc := range testCases {
+		if got := hasTopLevelAlternation(tc.pattern); got != tc.expected {
+			t.Errorf("hasTopLevelAlternation(%q) = %v, expected %v", tc.pattern, got, tc.expected)
+		}
+	}
+}
+
+func TestRegexpTable_TopLevelAlternation(t *testing.T) {
+	// A pattern with a top-level | is a single entry: every alternative takes the
+	// entry's precedence and maps to its value, and its capture groups are
+	// numbered as in the pattern on its own.
+	for _, factoring := range []bool{false, true} {
+		table := NewRegexpTable[string](true, true)
+		table.SetPrefixFactoring(factoring)
+		table.AddPattern(`x|do(g)`, "first")
+		table.AddPattern(`dog`, "second")
+		table.AddPattern(`do(t)|cat`, "third")
+
+		if table.maplets[0].namedPattern != "(?P<__REGEXPTABLE_1__>(?:x|do(g)))" {
+			t.Errorf("Expected the alternation to be grouped, got %q", table.maplets[0].namedPattern)
+		}
+		for input, want := range map[string]string{"x": "first", "dog": "first", "dot": "third", "cat": "third"} {
+			if value, _, ok := table.TryLookup(input); !ok || value != want {
+				t.Errorf("Expected %q for %q (factoring %v), got %q", want, input, factoring, value)
+			}
+		}
+		result, err := table.LookupResult("dog")
+		if err != nil || len(result.Groups) != 2 || result.Groups[1] != "g" {
+			t.Errorf("Expected groups [dog g] (factoring %v), got %v, %v", factoring, result, err)
+		}
+	}
+}
+
+func TestIsSingleGroup(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected bool
+	}{
+		{`(a|b)`, true},
+		{`(?P<name>x)`, true},
+		{`(?i:abc)`, true},
+		{`(a[)]b)`, true},
+		{`(a\)b)`, true},
+		{`(a)(b)`, false},
+		{`(a)*`, false},
+		{`a|b`, false},
+		{`(?m)a`, false},
+		{`(?i)`, false},
+		{`(\Q)\E)`, false},
+		{`(a`, false},
+		{``, false},
+	}
+	for _, tc := range testCases {
+		if got := isSingleGroup(tc.pattern); got != tc.expected {
+			t.Errorf("isSingleGroup(%q) = %v, expected %v", tc.pattern, got, tc.expected)
+		}
+	}
+}
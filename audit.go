@@ -0,0 +1,106 @@
+package regexptable
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Audit is a snapshot of the rules active in a table, as written by ExportAudit.
+// Its Digest is a SHA-256 hash of everything else in it, so that two snapshots
+// can be compared by digest alone, and the exported bytes are deterministic, so
+// that they can be signed or archived as evidence of exactly which rules were
+// active at a given time.
+type Audit struct {
+	Engine      string      `json:"engine"`
+	Anchoring   string      `json:"anchoring"`
+	Fingerprint string      `json:"fingerprint"` // See RegexpTable.Fingerprint
+	Rules       []AuditRule `json:"rules"`
+	Digest      string      `json:"digest"`
+}
+
+// AuditRule describes one of the rules in an Audit.
+type AuditRule struct {
+	Index    int      `json:"index"` // The rule's insertion index, as in Result.Index
+	Pattern  string   `json:"pattern"`
+	Values   []string `json:"values"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Doc      string   `json:"doc,omitempty"` // See Entry.Doc
+}
+
+// ExportAudit writes an Audit of the table as indented JSON, listing every rule
+// that has not expired in insertion order with its values, priority, tags and
+// doc, see Entry.Doc. Values are rendered by formatValue, or with %v if it is
+// nil; choose a rendering that is stable over time, since it is part of the
+// digest. Tags are sorted.
+func (rt *RegexpTable[T]) ExportAudit(w io.Writer, formatValue func(T) string) error {
+	if formatValue == nil {
+		formatValue = func(value T) string { return fmt.Sprint(value) }
+	}
+	audit := &Audit{
+		Fingerprint: rt.Fingerprint(), // Sweeps expired entries
+		Engine:      engineName(rt.engine),
+		Anchoring:   rt.Anchoring().String(),
+		Rules:       make([]AuditRule, 0, len(rt.maplets)),
+	}
+	for _, entry := range rt.maplets {
+		rule := AuditRule{
+			Index:    entry.insertionIndex(),
+			Pattern:  entry.Pattern,
+			Priority: entry.priority,
+			Tags:     slices.Sorted(slices.Values(entry.tags)),
+			Doc:      entry.doc,
+		}
+		if rule.Tags == nil {
+			rule.Tags = []string{}
+		}
+		for _, value := range entry.values() {
+			rule.Values = append(rule.Values, formatValue(value))
+		}
+		audit.Rules = append(audit.Rules, rule)
+	}
+	slices.SortFunc(audit.Rules, func(x, y AuditRule) int { return x.Index - y.Index })
+
+	digest, err := audit.computeDigest()
+	if err != nil {
+		return err
+	}
+	audit.Digest = digest
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(audit)
+}
+
+// ReadAudit reads an Audit written by ExportAudit and checks its digest,
+// reporting an error if the audit has been altered.
+func ReadAudit(r io.Reader) (*Audit, error) {
+	var audit Audit
+	if err := json.NewDecoder(r).Decode(&audit); err != nil {
+		return nil, fmt.Errorf("invalid audit: %w", err)
+	}
+	digest, err := audit.computeDigest()
+	if err != nil {
+		return nil, err
+	}
+	if digest != audit.Digest {
+		return nil, codeErrorf(CodeAuditMismatch, "audit digest mismatch: recorded %s, computed %s", audit.Digest, digest)
+	}
+	return &audit, nil
+}
+
+// computeDigest returns the digest of the audit, ignoring its Digest field.
+func (a *Audit) computeDigest() (string, error) {
+	unsigned := *a
+	unsigned.Digest = ""
+	var buffer bytes.Buffer
+	if err := json.NewEncoder(&buffer).Encode(&unsigned); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(buffer.Bytes())
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,80 @@
+package regexptable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegexpTable_ExportAudit(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	table := NewRegexpTableBuilder[int]().
+		AddPatternWithTags(`a.*`, 1, "team-b", "team-a").
+		AddPatternWithPriority(`abc.*`, 2, 5).
+		AddPatternValues(`x`, 3, 4).
+		WithLiteralOrdering(true).
+		MustBuild(true, true)
+	table.now = func() time.Time { return now }
+	table.AddPatternWithTTL(`tmp`, 5, time.Minute)
+
+	var before bytes.Buffer
+	if err := table.ExportAudit(&before, nil); err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	audit, err := ReadAudit(bytes.NewReader(before.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadAudit failed: %v", err)
+	}
+	if audit.Engine != "go-regexp" || audit.Anchoring != "AnchorBoth" || audit.Fingerprint != table.Fingerprint() {
+		t.Errorf("Unexpected audit header %+v", audit)
+	}
+	if len(audit.Rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(audit.Rules))
+	}
+	// Priority ordering puts abc.* first in the table, but the audit lists rules by
+	// their position in the builder.
+	first, second, third := audit.Rules[0], audit.Rules[1], audit.Rules[2]
+	if first.Index != 0 || first.Pattern != `a.*` || strings.Join(first.Tags, ",") != "team-a,team-b" {
+		t.Errorf("Expected sorted tags on the first rule, got %+v", first)
+	}
+	if second.Index != 1 || second.Pattern != `abc.*` || second.Priority != 5 || second.Values[0] != "2" {
+		t.Errorf("Unexpected second rule %+v", second)
+	}
+	if strings.Join(third.Values, ",") != "3,4" || third.Tags == nil {
+		t.Errorf("Expected both values and empty tags on the third rule, got %+v", third)
+	}
+
+	// Exports are deterministic, and expired rules drop out.
+	var again bytes.Buffer
+	table.ExportAudit(&again, nil)
+	if again.String() != before.String() {
+		t.Error("Expected identical exports of an unchanged table")
+	}
+	now = now.Add(time.Hour)
+	var after bytes.Buffer
+	table.ExportAudit(&after, func(v int) string { return strings.Repeat("*", v) })
+	expired, err := ReadAudit(&after)
+	if err != nil {
+		t.Fatalf("ReadAudit failed: %v", err)
+	}
+	if len(expired.Rules) != 3 || expired.Digest == audit.Digest || expired.Rules[1].Values[0] != "**" {
+		t.Errorf("Expected the expired rule gone and a new digest, got %+v", expired)
+	}
+}
+
+func TestReadAudit_Tampered(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`allow`, "allow")
+	var buffer bytes.Buffer
+	if err := table.ExportAudit(&buffer, nil); err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	tampered := strings.Replace(buffer.String(), `"allow"`, `"deny"`, 1)
+	if _, err := ReadAudit(strings.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("Expected a digest mismatch, got %v", err)
+	}
+	if _, err := ReadAudit(strings.NewReader("{")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
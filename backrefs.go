@@ -0,0 +1,204 @@
+package regexptable
+
+import (
+	"errors"
+	"strings"
+)
+
+// backreference is a reference to a capture group found in a pattern.
+type backreference struct {
+	start, end int    // Byte offsets of the reference in the pattern
+	group      int    // The group referred to by number, 0 if referred to by name
+	name       string // The group referred to by name, "" if referred to by number
+}
+
+// resolve returns the number of the group that the reference refers to, counting
+// from 1, given the names of the pattern's capture groups in order. ok is false if
+// the pattern has no such group.
+func (ref backreference) resolve(groups []string) (group int, ok bool) {
+	if ref.name == "" {
+		return ref.group, ref.group <= len(groups)
+	}
+	for i, name := range groups {
+		if name == ref.name {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// scanGroups returns the backreferences in a pattern and the names of its capture
+// groups in the order they open, "" for unnamed groups. It understands the syntax
+// shared by Go, PCRE and their relatives rather than any one engine's: \1 to \9,
+// \k<name>, \k'name' and (?P=name) are backreferences, while longer numbers such
+// as \12 are left alone, since Go reads them as octal escapes. Nothing inside a
+// bracket expression or between \Q and \E counts.
+func scanGroups(pattern string) (refs []backreference, groups []string) {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return refs, groups
+			}
+			rest := pattern[i+1:]
+			switch {
+			case rest[0] >= '1' && rest[0] <= '9' && (len(rest) == 1 || rest[1] < '0' || rest[1] > '9'):
+				refs = append(refs, backreference{start: i, end: i + 2, group: int(rest[0] - '0')})
+			case strings.HasPrefix(rest, "k<"), strings.HasPrefix(rest, "k'"):
+				closing := ">"
+				if rest[1] == '\'' {
+					closing = "'"
+				}
+				if n := strings.Index(rest[2:], closing); n > 0 {
+					refs = append(refs, backreference{start: i, end: i + 4 + n, name: rest[2 : 2+n]})
+					i += 3 + n
+					continue
+				}
+			case rest[0] == 'Q':
+				n := strings.Index(rest, `\E`)
+				if n < 0 {
+					return refs, groups
+				}
+				i += n + 2
+				continue
+			}
+			i++
+		case '[':
+			i = bracketEnd(pattern, i)
+		case '(':
+			rest := pattern[i+1:]
+			switch {
+			case !strings.HasPrefix(rest, "?"):
+				groups = append(groups, "")
+			case strings.HasPrefix(rest, "?P="):
+				if n := strings.IndexByte(rest, ')'); n > 0 {
+					refs = append(refs, backreference{start: i, end: i + 2 + n, name: rest[3:n]})
+					i += 1 + n
+				}
+			case strings.HasPrefix(rest, "?P<"), strings.HasPrefix(rest, "?<") && !strings.HasPrefix(rest, "?<=") && !strings.HasPrefix(rest, "?<!"):
+				start := strings.IndexByte(rest, '<') + 1
+				if n := strings.IndexByte(rest[start:], '>'); n >= 0 {
+					groups = append(groups, rest[start:start+n])
+				}
+			case strings.HasPrefix(rest, "?'"):
+				if n := strings.IndexByte(rest[2:], '\''); n >= 0 {
+					groups = append(groups, rest[2:2+n])
+				}
+			}
+		}
+	}
+	return refs, groups
+}
+
+// bracketEnd returns the offset of the ] that closes the bracket expression
+// opening at pattern[start], or the offset of the last byte if it is not closed.
+func bracketEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++ // A leading ] is a member, not the end
+	}
+	for ; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++
+		case strings.HasPrefix(pattern[i:], "[:"):
+			if n := strings.Index(pattern[i+2:], ":]"); n >= 0 {
+				i += n + 3
+			}
+		case pattern[i] == ']':
+			return i
+		}
+	}
+	return len(pattern) - 1
+}
+
+// checkBackreferences rejects patterns with backreferences that the engine cannot
+// match (see Backreferencer) or that refer to groups the pattern does not have.
+func (rt *RegexpTable[T]) checkBackreferences() error {
+	var errs []error
+	for _, entry := range rt.maplets {
+		errs = append(errs, backreferenceErrors(rt.engine, entry.Pattern, rt.capturePattern(entry))...)
+	}
+	return errors.Join(errs...)
+}
+
+// backreferenceErrors reports the problems with the backreferences in pattern as
+// checkBackreferences describes, scanning the form of the pattern that is
+// compiled, which differs from pattern in non-capturing mode.
+func backreferenceErrors(engine RegexpEngine, pattern, compiled string) []error {
+	var errs []error
+	refs, groups := scanGroups(compiled)
+	for _, ref := range refs {
+		if _, ok := engine.(Backreferencer); !ok {
+			errs = append(errs, codeErrorf(CodeEngineUnsupported, "pattern '%s' uses the backreference %s, which the %s engine does not support",
+				pattern, compiled[ref.start:ref.end], engineName(engine)))
+		} else if _, ok := ref.resolve(groups); !ok {
+			errs = append(errs, codeErrorf(CodeCompileFailed, "pattern '%s' uses the backreference %s to a group it does not have",
+				pattern, compiled[ref.start:ref.end]))
+		}
+	}
+	return errs
+}
+
+// numberUnionGroups records, for every entry whose pattern has backreferences,
+// the number its own group will have in the union, so that unionBranchPattern can
+// renumber the references. The union wraps each pattern in one capture group of
+// the table's, followed by the pattern's own groups.
+func (rt *RegexpTable[T]) numberUnionGroups() {
+	_, supported := rt.engine.(Backreferencer)
+	next := 1 // Skip the union's full match
+	for _, entry := range rt.maplets {
+		entry.unionGroup = 0
+		if !supported {
+			continue
+		}
+		refs, groups := scanGroups(rt.effectivePattern(entry))
+		if len(refs) > 0 {
+			entry.unionGroup = next
+		}
+		next += 1 + len(groups)
+	}
+}
+
+// unionBranchPattern returns the pattern that an entry contributes to the union:
+// its effective pattern, with any backreferences rewritten to refer to their
+// groups by their numbers in the union. Named references are resolved within the
+// entry's own pattern, so patterns may reuse each other's group names.
+func (rt *RegexpTable[T]) unionBranchPattern(entry *ValueAndPattern[T]) string {
+	pattern := rt.effectivePattern(entry)
+	formatter, ok := rt.engine.(Backreferencer)
+	if entry.unionGroup == 0 || !ok {
+		return pattern
+	}
+	refs, groups := scanGroups(pattern)
+	var b strings.Builder
+	last := 0
+	for _, ref := range refs {
+		group, ok := ref.resolve(groups)
+		if !ok {
+			continue // Reported by checkBackreferences
+		}
+		b.WriteString(pattern[last:ref.start])
+		b.WriteString(formatter.FormatBackreference(entry.unionGroup + group))
+		last = ref.end
+	}
+	b.WriteString(pattern[last:])
+	return b.String()
+}
+
+// checkUnionGroups confirms that the groups of entries with renumbered
+// backreferences ended up where numberUnionGroups expected, which fails only if
+// the engine counts groups differently from scanGroups, for instance by numbering
+// named groups after unnamed ones.
+func (rt *RegexpTable[T]) checkUnionGroups() error {
+	for _, entry := range rt.maplets {
+		if entry.unionGroup != 0 && entry.unionGroup != entry.firstGroup {
+			return codeErrorf(CodeEngineUnsupported, "cannot renumber the backreferences of pattern '%s': expected its group to be number %d in the union, found %d",
+				entry.Pattern, entry.unionGroup, entry.firstGroup)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package regexptable
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// backrefEngine is the standard engine posing as an engine with backreferences,
+// written \g{N}, that also accepts \k<name> and \1 to \9. It records the patterns
+// it compiles and, since Go cannot match backreferences, compiles them with every
+// backreference matching any one character.
+type backrefEngine struct {
+	StandardRegexpEngine
+	compiled []string
+}
+
+var backrefSyntax = regexp.MustCompile(`\\g\{\d+\}|\\k<\w+>|\\[1-9]`)
+
+func (e *backrefEngine) Compile(pattern string) (CompiledRegexp, error) {
+	e.compiled = append(e.compiled, pattern)
+	return e.StandardRegexpEngine.Compile(backrefSyntax.ReplaceAllString(pattern, "."))
+}
+
+func (e *backrefEngine) FormatBackreference(i int) string {
+	return fmt.Sprintf(`\g{%d}`, i)
+}
+
+func TestScanGroups(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		refs    []string
+		groups  []string
+	}{
+		{`(a)\1`, []string{`\1`}, []string{""}},
+		{`(?P<q>["'])\w+\k<q>`, []string{`\k<q>`}, []string{"q"}},
+		{`(?<q>x)\k'q'(?P=q)`, []string{`\k'q'`, `(?P=q)`}, []string{"q"}},
+		{`(?'q'x)(?:y)(?<=z)(?<!w)(z)`, nil, []string{"q", ""}},
+		{`\\1\12\0`, nil, nil},                 // An escaped backslash, an octal escape and a NUL
+		{`[\1(]\Q\1(\E(x)`, nil, []string{""}}, // Nothing in brackets or quotes counts
+		{`[]\1][[:alpha:]\1]\1`, []string{`\1`}, nil},
+		{`\k{digit}`, nil, nil}, // A class reference, see RegexpTableBuilder.Class
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			refs, groups := scanGroups(tc.pattern)
+			var found []string
+			for _, ref := range refs {
+				found = append(found, tc.pattern[ref.start:ref.end])
+			}
+			if !slices.Equal(found, tc.refs) {
+				t.Errorf("Expected backreferences %q, got %q", tc.refs, found)
+			}
+			if !slices.Equal(groups, tc.groups) {
+				t.Errorf("Expected groups %q, got %q", tc.groups, groups)
+			}
+		})
+	}
+}
+
+func TestRegexpTable_BackreferencesUnsupported(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(a)\1`, "double")
+	table.AddPattern(`(?P<q>')\w+\k<q>`, "quoted")
+	err := table.Recompile()
+	if err == nil {
+		t.Fatal("Expected backreferences to be rejected")
+	}
+	for _, want := range []string{`backreference \1, which`, `backreference \k<q>, which`, "does not support"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+
+	errs := NewRegexpTableBuilder[string]().AddPattern(`(a)\1`, "double").Validate(true, true)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "does not support") {
+		t.Errorf("Expected Validate to report the backreference once, got %v", errs)
+	}
+}
+
+func TestRegexpTable_BackreferencesRenumbered(t *testing.T) {
+	engine := &backrefEngine{}
+	table := NewRegexpTableWithEngine[string](engine, true, true)
+	table.AddPattern(`(x)(y)`, "pair")
+	table.AddPattern(`(?P<q>["'])\w+\k<q>`, "quoted")
+	table.AddPattern(`(?P<q>\d)\1`, "double") // Reuses the name q
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	union := engine.compiled[len(engine.compiled)-1]
+	// The groups are: 1 and 4 and 6 for the table's, 2 and 3 for pair, 5 for
+	// quoted and 7 for double.
+	for _, want := range []string{`\w+\g{5})`, `\d)\g{7})`} {
+		if !strings.Contains(union, want) {
+			t.Errorf("Expected the union to contain %s, got %s", want, union)
+		}
+	}
+	result, err := table.LookupResult(`'abc'`)
+	if err != nil || result.Value != "quoted" {
+		t.Fatalf("Expected quoted, got %v, %v", result, err)
+	}
+	if q, _ := result.Field("q"); q != "'" {
+		t.Errorf("Expected the quote in group q, got %q", q)
+	}
+
+	table.AddPattern(`(a)\2`, "missing")
+	if err := table.Recompile(); err == nil || !strings.Contains(err.Error(), `backreference \2 to a group it does not have`) {
+		t.Errorf("Expected a reference to a missing group to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package regexptable
+
+import (
+	"errors"
+	"time"
+)
+
+// LookupBatchWithBudget classifies inputs in order until they are all done or the
+// budget has been spent, and returns how many were processed, which suits
+// best-effort enrichment in latency-sensitive pipelines. The results are parallel
+// to inputs: results[i] is the Result of inputs[i] for i < processed, or nil if no
+// pattern matches it, and nil for the inputs that were not reached.
+//
+// The budget is checked before each input, so a single slow lookup can overrun
+// it, and recompiling the table counts against it. Lookup errors other than
+// ErrNoMatch, such as a compilation failure, are returned immediately along with
+// the results so far.
+func (rt *RegexpTable[T]) LookupBatchWithBudget(inputs []string, budget time.Duration) ([]*Result[T], int, error) {
+	results := make([]*Result[T], len(inputs))
+	deadline := rt.clock().Add(budget)
+	processed := 0
+	for i, input := range inputs {
+		if !rt.clock().Before(deadline) {
+			break
+		}
+		result, err := rt.LookupResult(input)
+		if err != nil && !errors.Is(err, ErrNoMatch) {
+			return results, processed, err
+		}
+		results[i] = result
+		processed++
+	}
+	return results, processed, nil
+}
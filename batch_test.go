@@ -0,0 +1,52 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegexpTable_LookupBatchWithBudget(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+
+	// Each reading of the clock advances it by a millisecond, so after setting
+	// the deadline the budget allows one input per millisecond.
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	table.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+
+	inputs := []string{"1", "!", "abc", "22", "xyz"}
+	results, processed, err := table.LookupBatchWithBudget(inputs, 4*time.Millisecond)
+	if err != nil {
+		t.Fatalf("LookupBatchWithBudget failed: %v", err)
+	}
+	if processed != 3 || len(results) != len(inputs) {
+		t.Fatalf("Expected 3 of %d inputs processed, got %d of %d", len(inputs), processed, len(results))
+	}
+	if results[0].Value != "number" || results[1] != nil || results[2].Value != "word" {
+		t.Errorf("Unexpected results %v", results[:processed])
+	}
+	if results[3] != nil || results[4] != nil {
+		t.Errorf("Expected no results for unprocessed inputs, got %v", results[processed:])
+	}
+
+	if _, processed, _ := table.LookupBatchWithBudget(inputs, time.Hour); processed != len(inputs) {
+		t.Errorf("Expected all inputs processed, got %d", processed)
+	}
+	if _, processed, _ := table.LookupBatchWithBudget(inputs, 0); processed != 0 {
+		t.Errorf("Expected no inputs processed with no budget, got %d", processed)
+	}
+}
+
+func TestRegexpTable_LookupBatchWithBudgetError(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(`, "bad")
+	_, processed, err := table.LookupBatchWithBudget([]string{"a", "b"}, time.Hour)
+	if err == nil || !strings.Contains(err.Error(), "failed to compile") || processed != 0 {
+		t.Errorf("Expected a compilation error with nothing processed, got %d, %v", processed, err)
+	}
+}
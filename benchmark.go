@@ -0,0 +1,64 @@
+package regexptable
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// PatternCost is the measured cost of one of a table's patterns over a corpus,
+// see BenchmarkPatterns.
+type PatternCost struct {
+	Index     int // The pattern's insertion index, as in Result.Index
+	Pattern   string
+	Matches   int           // Number of corpus inputs the pattern matches on its own
+	HitRate   float64       // Matches as a fraction of the corpus
+	MeanTime  time.Duration // Average time to match the pattern against an input
+	TotalTime time.Duration // Time to match the pattern against the whole corpus
+}
+
+// BenchmarkPatterns matches every pattern of the table on its own, with the
+// table's anchoring and modes, against every input of the corpus and reports
+// what each one costs, most expensive first. It guides rule authors towards the
+// rules worth rewriting: a pattern with a high mean time costs every lookup that
+// needs it, and one with a high hit rate as well is hot. Inputs are normalized as
+// they are for lookups. The timings are taken from a single pass over the corpus,
+// so they are only as reliable as the corpus is large.
+func (rt *RegexpTable[T]) BenchmarkPatterns(corpus []string) ([]PatternCost, error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, err
+	}
+	inputs := corpus
+	if rt.normalizers != nil {
+		inputs = make([]string, len(corpus))
+		for i, input := range corpus {
+			inputs[i], _ = rt.normalize(input)
+		}
+	}
+
+	costs := make([]PatternCost, 0, len(rt.maplets))
+	for _, entry := range rt.maplets {
+		compiled, err := rt.individualRegexp(entry)
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to compile pattern '%s': %w", entry.Pattern, err)
+		}
+		cost := PatternCost{Index: entry.insertionIndex(), Pattern: entry.Pattern}
+		start := time.Now()
+		for _, input := range inputs {
+			if compiled.FindStringSubmatch(input) != nil {
+				cost.Matches++
+			}
+		}
+		cost.TotalTime = time.Since(start)
+		if len(inputs) > 0 {
+			cost.HitRate = float64(cost.Matches) / float64(len(inputs))
+			cost.MeanTime = cost.TotalTime / time.Duration(len(inputs))
+		}
+		costs = append(costs, cost)
+	}
+	slices.SortStableFunc(costs, func(x, y PatternCost) int {
+		return cmp.Compare(y.TotalTime, x.TotalTime)
+	})
+	return costs, nil
+}
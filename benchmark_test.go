@@ -0,0 +1,40 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_BenchmarkPatterns(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`(?:\w+\s?){1,50}x`, "slow")
+	table.SetNormalizers(TrimSpace())
+
+	corpus := []string{" 42 ", "7", "abc", strings.Repeat("word ", 40)}
+	costs, err := table.BenchmarkPatterns(corpus)
+	if err != nil {
+		t.Fatalf("BenchmarkPatterns failed: %v", err)
+	}
+	if len(costs) != 2 {
+		t.Fatalf("Expected a cost per pattern, got %+v", costs)
+	}
+	if costs[0].TotalTime < costs[1].TotalTime {
+		t.Errorf("Expected the most expensive pattern first, got %+v", costs)
+	}
+	for _, cost := range costs {
+		if cost.Index == 0 && (cost.Matches != 2 || cost.HitRate != 0.5) {
+			t.Errorf("Expected the number pattern to match half the normalized corpus, got %+v", cost)
+		}
+		if cost.Index == 1 && cost.Matches != 0 {
+			t.Errorf("Expected the slow pattern to match nothing, got %+v", cost)
+		}
+		if cost.MeanTime < 0 || cost.MeanTime > cost.TotalTime {
+			t.Errorf("Expected a mean time within the total, got %+v", cost)
+		}
+	}
+
+	if costs, err := table.BenchmarkPatterns(nil); err != nil || costs[0].HitRate != 0 {
+		t.Errorf("Expected an empty corpus to give zero costs, got %+v, %v", costs, err)
+	}
+}
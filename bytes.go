@@ -0,0 +1,256 @@
+package regexptable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// matchBytes matches a byte slice against the compiled union and returns the
+// winning entry with its index pairs. If the engine cannot match bytes directly
+// the input is matched through a reader instead. The match counts towards the
+// entry's hit statistics and runs its match callbacks, as a string lookup does.
+func (rt *RegexpTable[T]) matchBytes(input []byte) (*ValueAndPattern[T], []int, error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rt.checkNoNormalizers("byte slice lookup"); err != nil {
+		return nil, nil, err
+	}
+	input, err = rt.limitBytes(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rt.compiled == nil {
+		return nil, nil, ErrNoPatterns
+	}
+
+	var loc []int
+	if matcher, ok := rt.compiled.(ByteMatcher); ok {
+		loc = matcher.FindSubmatchIndex(input)
+	} else if matcher, ok := rt.compiled.(ReaderMatcher); ok {
+		loc = matcher.FindReaderSubmatchIndex(bytes.NewReader(input))
+	} else {
+		return nil, nil, codeErrorf(CodeEngineUnsupported, "regexp engine does not support index-based matching")
+	}
+	if loc == nil {
+		return nil, nil, ErrNoMatch
+	}
+
+	entry, indexes, ok := rt.groupIndexes(loc)
+	if !ok && rt.tombstones == 0 {
+		return nil, nil, codeErrorf(CodeInternal, "internal error: match found but no capture group matched")
+	}
+	if !ok || entry.exclusion != nil {
+		// A removed pattern or one with an exclusion won the union.
+		entry, indexes, err = rt.excludingIndexes(string(input), rt.individualRegexp)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if entry.hits != nil || rt.matchCallbacks != nil {
+		// Only convert the input when something will record it.
+		rt.recordHit(entry, string(input), SubmatchStrings(input, indexes))
+	}
+	return entry, indexes, nil
+}
+
+// ByteToken is a single lexeme recognised by a ByteTokenizer. Lexeme is a sub-slice
+// of the tokenized input, Start/End are byte offsets within it and Groups holds
+// the index pairs of the full match and the pattern's capture groups, also
+// relative to the start of the input (-1 for groups that did not participate).
+type ByteToken[T any] struct {
+	Value     T
+	Lexeme    []byte
+	Start     int
+	End       int
+	Groups    []int
+	Unmatched bool // Whether the token is a run of input that no pattern matched
+}
+
+// ByteTokenizer is the []byte counterpart of Tokenizer, for inputs such as protocol
+// frames that arrive as byte slices. It works entirely with byte offsets and
+// sub-slices of the input and performs no string conversions, provided the
+// engine's compiled regexps implement ByteMatcher or ReaderMatcher.
+type ByteTokenizer[T any] struct {
+	table      *RegexpTable[T]
+	input      []byte
+	pos        int
+	err        error
+	unknown    T    // Value of unmatched runs, when hasUnknown is set
+	hasUnknown bool // Whether unmatched runs become tokens rather than errors
+}
+
+// NewByteTokenizer creates a ByteTokenizer that scans input using the given table.
+// The table must be anchored at the start, otherwise Next reports an error.
+func NewByteTokenizer[T any](table *RegexpTable[T], input []byte) *ByteTokenizer[T] {
+	return &ByteTokenizer[T]{table: table, input: input}
+}
+
+// SetUnknownValue makes the tokenizer emit runs of input that no pattern matches
+// as tokens with the given value, as Tokenizer.SetUnknownValue does.
+func (tk *ByteTokenizer[T]) SetUnknownValue(value T) {
+	tk.unknown = value
+	tk.hasUnknown = true
+}
+
+// Next returns the next token and true, or a zero token and false when the input
+// is exhausted or an error occurred. Use Err to distinguish the two cases.
+func (tk *ByteTokenizer[T]) Next() (ByteToken[T], bool) {
+	var zero ByteToken[T]
+	if tk.err != nil || tk.pos >= len(tk.input) {
+		return zero, false
+	}
+	if !tk.table.anchorStart {
+		tk.err = codeErrorf(CodeTableUnsupported, "tokenizer requires a start-anchored table")
+		return zero, false
+	}
+
+	entry, indexes, err := tk.table.matchBytes(tk.input[tk.pos:])
+	if tk.hasUnknown && (errors.Is(err, ErrNoMatch) || (err == nil && indexes[1] == indexes[0])) {
+		return tk.unmatchedRun()
+	}
+	if err != nil {
+		tk.err = fmt.Errorf("no token at offset %d: %w", tk.pos, err)
+		return zero, false
+	}
+	if indexes[1] == indexes[0] {
+		tk.err = fmt.Errorf("empty token at offset %d", tk.pos)
+		return zero, false
+	}
+
+	for i := range indexes {
+		if indexes[i] >= 0 {
+			indexes[i] += tk.pos
+		}
+	}
+	token := ByteToken[T]{
+		Value:  entry.Value,
+		Lexeme: tk.input[indexes[0]:indexes[1]],
+		Start:  indexes[0],
+		End:    indexes[1],
+		Groups: indexes,
+	}
+	tk.pos = token.End
+	return token, true
+}
+
+// unmatchedRun returns the run of input from the current offset that no pattern
+// matches as a token with the unknown value.
+func (tk *ByteTokenizer[T]) unmatchedRun() (ByteToken[T], bool) {
+	end := tk.pos
+	for {
+		_, width := utf8.DecodeRune(tk.input[end:])
+		end += width
+		if end >= len(tk.input) {
+			break
+		}
+		_, indexes, err := tk.table.matchBytes(tk.input[end:])
+		if err == nil && indexes[1] > indexes[0] {
+			break
+		}
+		if err != nil && !errors.Is(err, ErrNoMatch) {
+			tk.err = fmt.Errorf("no token at offset %d: %w", end, err)
+			return ByteToken[T]{}, false
+		}
+	}
+
+	token := ByteToken[T]{
+		Value:     tk.unknown,
+		Lexeme:    tk.input[tk.pos:end],
+		Start:     tk.pos,
+		End:       end,
+		Groups:    []int{tk.pos, end},
+		Unmatched: true,
+	}
+	tk.pos = end
+	return token, true
+}
+
+// Offset returns the byte offset of the next token to be scanned.
+func (tk *ByteTokenizer[T]) Offset() int {
+	return tk.pos
+}
+
+// Err returns the error that stopped the tokenizer, or nil if the input was
+// consumed completely (or has not been consumed yet).
+func (tk *ByteTokenizer[T]) Err() error {
+	return tk.err
+}
+
+// TokenizeBytes is the eager counterpart of ByteTokenizer: it scans the whole input
+// and returns all tokens. On failure it returns the tokens recognised so far
+// together with the error.
+func TokenizeBytes[T any](table *RegexpTable[T], input []byte) ([]ByteToken[T], error) {
+	tokenizer := NewByteTokenizer(table, input)
+	var tokens []ByteToken[T]
+	for {
+		token, ok := tokenizer.Next()
+		if !ok {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, tokenizer.Err()
+}
+
+// LookupBytes is the []byte counterpart of Lookup. Matching is performed on the
+// byte slice directly when the engine supports it; the submatches are returned as
+// strings.
+func (rt *RegexpTable[T]) LookupBytes(input []byte) (T, []string, error) {
+	var zero T
+
+	entry, indexes, err := rt.matchBytes(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	return entry.Value, SubmatchStrings(input, indexes), nil
+}
+
+// LookupBytesIndex is like LookupBytes but returns the submatches as pairs of byte
+// offsets into input, with -1 for groups that did not participate, rather than as
+// strings. No memory is allocated per group; SubmatchBytes gives views of the
+// submatches and SubmatchStrings copies them into strings when they are needed.
+func (rt *RegexpTable[T]) LookupBytesIndex(input []byte) (T, []int, error) {
+	var zero T
+
+	entry, indexes, err := rt.matchBytes(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	return entry.Value, indexes, nil
+}
+
+// SubmatchBytes returns the submatches described by index pairs, as returned by
+// LookupBytesIndex, as sub-slices of input that share its memory. Groups that did
+// not participate are nil.
+func SubmatchBytes(input []byte, indexes []int) [][]byte {
+	submatches := make([][]byte, len(indexes)/2)
+	for i := range submatches {
+		if indexes[2*i] >= 0 {
+			submatches[i] = input[indexes[2*i]:indexes[2*i+1]:indexes[2*i+1]]
+		}
+	}
+	return submatches
+}
+
+// SubmatchStrings returns the submatches described by index pairs, as returned by
+// LookupBytesIndex, as strings. Groups that did not participate are "".
+func SubmatchStrings(input []byte, indexes []int) []string {
+	submatches := make([]string, len(indexes)/2)
+	for i := range submatches {
+		if indexes[2*i] >= 0 {
+			submatches[i] = string(input[indexes[2*i]:indexes[2*i+1]])
+		}
+	}
+	return submatches
+}
+
+// TryLookupBytes is like LookupBytes but returns a boolean success indicator instead of an error.
+func (rt *RegexpTable[T]) TryLookupBytes(input []byte) (T, []string, bool) {
+	value, matches, err := rt.LookupBytes(input)
+	return value, matches, err == nil
+}
@@ -0,0 +1,159 @@
+package regexptable
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenizeBytes(t *testing.T) {
+	// A tiny Redis-like protocol: commands, bulk lengths and CRLF terminators.
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\r\n`, "crlf").
+		AddPattern(`\*(\d+)`, "array").
+		AddPattern(`\$(\d+)`, "bulk").
+		AddPattern(`[A-Za-z]+`, "word").
+		MustBuild(true, false)
+
+	input := []byte("*1\r\n$4\r\nPING\r\n")
+	tokens, err := TokenizeBytes(table, input)
+	if err != nil {
+		t.Fatalf("TokenizeBytes failed: %v", err)
+	}
+
+	var kinds []string
+	for _, token := range tokens {
+		kinds = append(kinds, token.Value)
+	}
+	expected := []string{"array", "crlf", "bulk", "crlf", "word", "crlf"}
+	if !slices.Equal(kinds, expected) {
+		t.Fatalf("Expected kinds %v, got %v", expected, kinds)
+	}
+
+	bulk := tokens[2]
+	if bulk.Start != 4 || bulk.End != 6 || string(bulk.Lexeme) != "$4" {
+		t.Errorf("Unexpected bulk token: %+v", bulk)
+	}
+	if string(input[bulk.Groups[2]:bulk.Groups[3]]) != "4" {
+		t.Errorf("Expected the bulk length group to be '4', got %v", bulk.Groups)
+	}
+
+	// Lexemes are views into the input rather than copies.
+	if &tokens[4].Lexeme[0] != &input[8] {
+		t.Error("Expected the lexeme to share memory with the input")
+	}
+}
+
+func TestTokenizeBytes_Errors(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`[a-z]+`, "word").
+		MustBuild(true, false)
+
+	tokens, err := TokenizeBytes(table, []byte("abc!"))
+	if err == nil {
+		t.Fatal("Expected an error for unmatched input")
+	}
+	if len(tokens) != 1 || string(tokens[0].Lexeme) != "abc" {
+		t.Errorf("Expected the tokens before the error, got %v", tokens)
+	}
+}
+
+func TestRegexpTable_LookupBytes(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`GET (\S+)`, "get").
+		AddPattern(`POST (\S+)`, "post").
+		MustBuild(true, false)
+
+	value, matches, err := table.LookupBytes([]byte("POST /submit HTTP/1.1"))
+	if err != nil {
+		t.Fatalf("LookupBytes failed: %v", err)
+	}
+	if value != "post" || !slices.Equal(matches, []string{"POST /submit", "/submit"}) {
+		t.Errorf("Unexpected result %q %v", value, matches)
+	}
+
+	if _, _, ok := table.TryLookupBytes([]byte("PUT /x")); ok {
+		t.Error("Expected no match for PUT")
+	}
+}
+
+func TestRegexpTable_LookupBytesHits(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`GET (\S+)`, "get").
+		AddPattern(`POST (\S+)`, "post").
+		WithHitStats(true, 1).
+		MustBuild(true, false)
+	var matched []string
+	OnMatch(table, "post", func(m Match[string]) { matched = append(matched, m.Result.Groups[1]) })
+
+	table.LookupBytes([]byte("POST /a"))
+	table.LookupBytesIndex([]byte("POST /b"))
+	table.Lookup("GET /c")
+	if table.HitCount(0) != 1 || table.HitCount(1) != 2 {
+		t.Errorf("Expected byte lookups to count as hits, got %d and %d", table.HitCount(0), table.HitCount(1))
+	}
+	if examples := table.RecentExamples(1); !slices.Equal(examples, []string{"POST /b"}) {
+		t.Errorf("Expected the last byte input as an example, got %q", examples)
+	}
+	if !slices.Equal(matched, []string{"/a", "/b"}) {
+		t.Errorf("Expected the callback to see both byte lookups, got %q", matched)
+	}
+}
+
+func TestRegexpTable_LookupBytesIndex(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\w+)=(\d+)?`, "assignment").
+		MustBuild(true, false)
+
+	input := []byte("x= rest")
+	value, indexes, err := table.LookupBytesIndex(input)
+	if err != nil {
+		t.Fatalf("LookupBytesIndex failed: %v", err)
+	}
+	if value != "assignment" || !slices.Equal(indexes, []int{0, 2, 0, 1, -1, -1}) {
+		t.Errorf("Unexpected result %q %v", value, indexes)
+	}
+
+	views := SubmatchBytes(input, indexes)
+	if len(views) != 3 || string(views[0]) != "x=" || string(views[1]) != "x" || views[2] != nil {
+		t.Errorf("Unexpected views %q", views)
+	}
+	// Views share the input's memory but cannot grow into the rest of it.
+	if &views[1][0] != &input[0] || cap(views[1]) != 1 {
+		t.Error("Expected the views to be capped sub-slices of the input")
+	}
+
+	if strs := SubmatchStrings(input, indexes); !slices.Equal(strs, []string{"x=", "x", ""}) {
+		t.Errorf("Unexpected strings %q", strs)
+	}
+
+	if _, _, err := table.LookupBytesIndex([]byte("!")); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+}
+
+func TestByteTokenizer_UnknownValue(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		MustBuild(true, false)
+
+	tokenizer := NewByteTokenizer(table, []byte("?1é2"))
+	tokenizer.SetUnknownValue("unknown")
+	var lexemes []string
+	for {
+		token, ok := tokenizer.Next()
+		if !ok {
+			break
+		}
+		if token.Unmatched != (token.Value == "unknown") {
+			t.Errorf("Expected Unmatched to be set exactly for unknown tokens, got %+v", token)
+		}
+		lexemes = append(lexemes, string(token.Lexeme))
+	}
+	if tokenizer.Err() != nil {
+		t.Fatalf("Unexpected error: %v", tokenizer.Err())
+	}
+	if !slices.Equal(lexemes, []string{"?", "1", "é", "2"}) {
+		t.Errorf("Unexpected lexemes %q", lexemes)
+	}
+}
@@ -0,0 +1,106 @@
+package regexptable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// TableCache shares compiled tables between builds of identical specifications,
+// so that code that builds the same table over and over, such as per-request
+// construction or tests, compiles it only once. Builders use a cache when given
+// one with WithTableCache. A TableCache is safe for concurrent use and may hold
+// tables of any value type.
+//
+// Tables from a cache are shared by every builder that asks for them, so they
+// must not be modified, for instance with AddPattern or the Set methods. Sharing
+// them between goroutines is subject to the usual rules for concurrent lookups;
+// see SetPrecompileIndividuals.
+type TableCache struct {
+	mu     sync.Mutex
+	tables map[string]any // Each value is a *RegexpTable[T] for some T
+}
+
+// SharedTableCache is a process-wide TableCache.
+var SharedTableCache = NewTableCache()
+
+// NewTableCache returns an empty TableCache.
+func NewTableCache() *TableCache {
+	return &TableCache{tables: make(map[string]any)}
+}
+
+// Len returns the number of tables in the cache.
+func (c *TableCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tables)
+}
+
+// Clear removes all tables from the cache. Tables already handed out are
+// unaffected.
+func (c *TableCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.tables)
+}
+
+// get returns the table cached under key, if any.
+func (c *TableCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	table, ok := c.tables[key]
+	return table, ok
+}
+
+// put caches table under key and returns the table now cached there, which is an
+// earlier one if a concurrent build got there first.
+func (c *TableCache) put(key string, table any) any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.tables[key]; ok {
+		return cached
+	}
+	c.tables[key] = table
+	return table
+}
+
+// WithTableCache makes Build look for an identical table in the cache before
+// compiling one, and add the tables it compiles to the cache. Two builds are
+// identical when they have the same engine, value type, options, anchoring and
+// patterns with the same values, priorities, names and tags, added in the same
+// order, since the order decides each pattern's Result.Index. Builders with
+// normalizers or diagnostics, which cannot be compared, always compile a new
+// table. See TableCache for the restrictions on cached tables.
+func (b *RegexpTableBuilder[T]) WithTableCache(cache *TableCache) *RegexpTableBuilder[T] {
+	b.cache = cache
+	return b
+}
+
+// cacheKey returns the key identifying the table built from entries with the
+// given anchoring, and false if the builder's table cannot be cached.
+func (b *RegexpTableBuilder[T]) cacheKey(entries []patternEntry[T], anchorStart, anchorEnd bool) (string, bool) {
+	if b.cache == nil || len(b.normalizers) > 0 || b.diagnosticHook != nil {
+		return "", false
+	}
+
+	hash := sha256.New()
+	var value T
+	fmt.Fprintf(hash, "%#v\n%T\n%v %v\n", b.engine, value, anchorStart, anchorEnd)
+	// Every option that affects the built table must be listed here.
+	fmt.Fprintln(hash, b.nonCapturing, b.prefixFactoring, b.suffixFactoring, b.literalOrdering,
+		b.ungreedy, b.caseInsensitive, b.positional, b.twoPhase, b.prefixDispatch, b.omitWrapper, b.precompile, b.allowReDoS,
+		b.memoCapacity, b.memoComputed, b.sharedMatches, b.hitStats, b.hitExamples, b.maxInputLength, b.truncateInput, b.valueFolding,
+		b.anchorPolicy, b.adaptive, b.adaptivePromote, b.pooling)
+	for _, entry := range entries {
+		fmt.Fprintf(hash, "%d %q %#v %#v %d %q %q %v %v %q %q", entry.position, entry.pattern, entry.value, entry.moreValues, entry.priority, entry.name, entry.tags, entry.confidence, entry.groupTypes, entry.doc, entry.examples)
+		if entry.exclusion != nil {
+			fmt.Fprintf(hash, " %#v", *entry.exclusion)
+		}
+		if entry.schedule != nil {
+			fmt.Fprintf(hash, " %v", *entry.schedule)
+		}
+		fmt.Fprintln(hash)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
@@ -0,0 +1,104 @@
+package regexptable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRegexpTableBuilder_WithTableCache(t *testing.T) {
+	cache := NewTableCache()
+	build := func(value string, anchorEnd bool) *RegexpTable[string] {
+		return NewRegexpTableBuilder[string]().
+			WithTableCache(cache).
+			AddPattern(`\d+`, "number").
+			AddPattern(`[a-z]+`, value).
+			MustBuild(true, anchorEnd)
+	}
+
+	first := build("word", true)
+	if second := build("word", true); second != first {
+		t.Error("Expected an identical build to return the cached table")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 cached table, got %d", cache.Len())
+	}
+	if other := build("identifier", true); other == first {
+		t.Error("Expected a different value to build a new table")
+	}
+	if other := build("word", false); other == first {
+		t.Error("Expected a different anchoring to build a new table")
+	}
+	if other := NewRegexpTableBuilder[string]().WithTableCache(cache).WithCaseInsensitive(true).
+		AddPattern(`\d+`, "number").AddPattern(`[a-z]+`, "word").MustBuild(true, true); other == first {
+		t.Error("Expected a different option to build a new table")
+	}
+	if other := NewRegexpTableBuilder[any]().WithTableCache(cache).
+		AddPattern(`\d+`, "number").AddPattern(`[a-z]+`, "word").MustBuild(true, true); any(other) == any(first) {
+		t.Error("Expected a different value type to build a new table")
+	}
+	if cache.Len() != 5 {
+		t.Errorf("Expected 5 cached tables, got %d", cache.Len())
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 || build("word", true) == first {
+		t.Error("Expected Clear to empty the cache")
+	}
+}
+
+func TestRegexpTableBuilder_WithTableCacheOrder(t *testing.T) {
+	cache := NewTableCache()
+	first := NewRegexpTableBuilder[string]().WithTableCache(cache).
+		AddPatternWithPriority(`[a-z]+`, "word", 1).
+		AddPatternWithPriority(`abc`, "abc", 2).
+		MustBuild(true, true)
+	second := NewRegexpTableBuilder[string]().WithTableCache(cache).
+		AddPatternWithPriority(`abc`, "abc", 2).
+		AddPatternWithPriority(`[a-z]+`, "word", 1).
+		MustBuild(true, true)
+	if second == first {
+		t.Fatal("Expected patterns added in a different order to build a new table")
+	}
+	for table, want := range map[*RegexpTable[string]]int{first: 1, second: 0} {
+		if result, err := table.LookupResult("abc"); err != nil || result.Index != want {
+			t.Errorf("Expected abc at index %d, got %v, %v", want, result, err)
+		}
+	}
+}
+
+func TestRegexpTableBuilder_WithTableCacheUncacheable(t *testing.T) {
+	cache := NewTableCache()
+	builder := NewRegexpTableBuilder[string]().
+		WithTableCache(cache).
+		WithNormalizers(TrimSpace()).
+		AddPattern(`a`, "a")
+	if builder.MustBuild(true, true) == builder.MustBuild(true, true) || cache.Len() != 0 {
+		t.Error("Expected builders with normalizers to bypass the cache")
+	}
+
+	if _, err := NewRegexpTableBuilder[string]().WithTableCache(cache).AddPattern(`(`, "x").Build(true, true); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+	if cache.Len() != 0 {
+		t.Error("Expected failed builds not to be cached")
+	}
+}
+
+func TestRegexpTableBuilder_WithTableCacheConcurrent(t *testing.T) {
+	cache := NewTableCache()
+	tables := make([]*RegexpTable[int], 8)
+	var wg sync.WaitGroup
+	for i := range tables {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tables[i] = NewRegexpTableBuilder[int]().WithTableCache(cache).AddPattern(`x`, 1).MustBuild(true, true)
+		}()
+	}
+	wg.Wait()
+	for _, table := range tables {
+		if table != tables[0] {
+			t.Fatal("Expected concurrent builds to share one table")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package regexptable
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Match describes a lookup won by a pattern with match callbacks, see OnMatch, or
+// a match found by ScanReader.
+type Match[T any] struct {
+	Input  string     // The input as given to the lookup, before any normalization, or the text ScanReader matched
+	Result *Result[T] // The winning pattern and its submatches; Start and End are -1 for callbacks
+}
+
+// String renders the match on one line as the quoted input followed by the
+// result in the form used by Result.String, for example
+//
+//	input="on 2024-02" value=date index=1 pattern=`(?P<year>\d{4})-(\d{2})` span=[3,10) groups=[...]
+func (m Match[T]) String() string {
+	if m.Result == nil {
+		return "input=" + strconv.Quote(m.Input) + " no match"
+	}
+	return "input=" + strconv.Quote(m.Input) + " " + m.Result.String()
+}
+
+// matchCallback is a callback registered with OnMatch or OnRuleMatch, together
+// with the entries it applies to.
+type matchCallback[T any] struct {
+	applies  func(*ValueAndPattern[T]) bool
+	callback func(Match[T])
+}
+
+// OnMatch registers a callback that is called whenever a pattern with the given
+// value wins a lookup, so that side effects such as counters or alerts run when
+// particular rules fire. It applies to patterns added after it is registered too.
+// OnMatch is a function rather than a method because it compares values, which
+// needs T to be comparable; for other value types, see OnRuleMatch.
+//
+// Callbacks are run by the lookups that record hit statistics, see SetHitStats,
+// in the order they were registered, on the goroutine doing the lookup, so they
+// may run concurrently and should be quick. A callback that panics does not
+// disturb the lookup: the panic is recovered and passed to the handler set with
+// OnMatchPanic, if any.
+func OnMatch[T comparable](rt *RegexpTable[T], value T, callback func(Match[T])) {
+	rt.matchCallbacks = append(rt.matchCallbacks, matchCallback[T]{
+		applies:  func(entry *ValueAndPattern[T]) bool { return entry.Value == value },
+		callback: callback,
+	})
+}
+
+// OnRuleMatch is like OnMatch but registers a callback that is called whenever
+// the pattern added with the given name, see AddNamedPattern, wins a lookup.
+func (rt *RegexpTable[T]) OnRuleMatch(name string, callback func(Match[T])) {
+	rt.matchCallbacks = append(rt.matchCallbacks, matchCallback[T]{
+		applies:  func(entry *ValueAndPattern[T]) bool { return entry.name == name },
+		callback: callback,
+	})
+}
+
+// OnMatchPanic sets the handler for panics recovered from match callbacks, which
+// is given the match and the value the callback panicked with. Without a handler
+// such panics are discarded.
+func (rt *RegexpTable[T]) OnMatchPanic(handler func(Match[T], any)) {
+	rt.matchPanic = handler
+}
+
+// notifyMatch runs the match callbacks that apply to the winning entry.
+func (rt *RegexpTable[T]) notifyMatch(entry *ValueAndPattern[T], input string, matches []string) {
+	var match Match[T]
+	for _, registered := range rt.matchCallbacks {
+		if !registered.applies(entry) {
+			continue
+		}
+		if match.Result == nil {
+			// The matches may be shared with the caller, see SetSharedMatches.
+			match = Match[T]{Input: input, Result: rt.newResult(entry, slices.Clone(matches))}
+			match.Result.Start, match.Result.End = -1, -1
+		}
+		rt.runMatchCallback(registered.callback, match)
+	}
+}
+
+// runMatchCallback calls a match callback, recovering from any panic.
+func (rt *RegexpTable[T]) runMatchCallback(callback func(Match[T]), match Match[T]) {
+	defer func() {
+		if recovered := recover(); recovered != nil && rt.matchPanic != nil {
+			rt.matchPanic(match, recovered)
+		}
+	}()
+	callback(match)
+}
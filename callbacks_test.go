@@ -0,0 +1,71 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOnMatch(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(\d+)`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+
+	var numbers []string
+	OnMatch(table, "number", func(m Match[string]) {
+		numbers = append(numbers, m.Input+"="+m.Result.Groups[1])
+	})
+	OnMatch(table, "alert", func(m Match[string]) {
+		t.Errorf("Expected no alert, got %q", m.Input)
+	})
+	table.AddPattern(`!+`, "alert") // Added after registration, but never matched
+
+	table.Lookup("42")
+	table.Lookup("abc")
+	table.LookupResult("7")
+	table.LookupAnchored("9 lives", AnchorStart)
+	table.Lookup("?")
+	if want := []string{"42=42", "7=7", "9 lives=9"}; !slices.Equal(numbers, want) {
+		t.Errorf("Expected callbacks for %q, got %q", want, numbers)
+	}
+}
+
+func TestOnRuleMatch(t *testing.T) {
+	table := NewRegexpTable[[]int](true, true) // Not comparable
+	table.AddNamedPattern("digits", `\d+`, []int{1})
+	table.AddPattern(`\w+`, []int{2})
+
+	var fired []Match[[]int]
+	table.OnRuleMatch("digits", func(m Match[[]int]) {
+		fired = append(fired, m)
+	})
+	table.Lookup("123")
+	table.Lookup("abc")
+	if len(fired) != 1 || fired[0].Input != "123" || fired[0].Result.RuleName != "digits" {
+		t.Errorf("Expected one callback for the digits rule, got %+v", fired)
+	}
+	if fired[0].Result.Start != -1 {
+		t.Errorf("Expected no span, got %d", fired[0].Result.Start)
+	}
+}
+
+func TestOnMatchPanic(t *testing.T) {
+	table := NewRegexpTable[int](true, true)
+	table.AddPattern(`x`, 1)
+
+	calls := 0
+	OnMatch(table, 1, func(Match[int]) { panic("boom") })
+	OnMatch(table, 1, func(Match[int]) { calls++ })
+	if value, _, err := table.Lookup("x"); err != nil || value != 1 {
+		t.Errorf("Expected the lookup to succeed despite the panic, got %d, %v", value, err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the later callback to run, got %d calls", calls)
+	}
+
+	var recovered any
+	table.OnMatchPanic(func(m Match[int], r any) { recovered = r })
+	table.Lookup("x")
+	if recovered != "boom" {
+		t.Errorf("Expected the panic to reach the handler, got %v", recovered)
+	}
+}
@@ -0,0 +1,47 @@
+package regexptable
+
+// LookupCandidates is like LookupResult but only considers the patterns with the
+// given insertion indexes (see Result.Index), matching each of them on its own
+// and choosing the winner the union would choose among them: the leftmost match,
+// with earlier patterns winning ties. Indexes that name no pattern are ignored.
+//
+// It is the confirmation step for prefilters that find candidate patterns by
+// other means, such as a Hyperscan database built from the table's patterns that
+// reports the IDs of the patterns occurring in an input (see the hsprefilter
+// module). The engine's compiled regexps must
+// implement IndexMatcher.
+func (rt *RegexpTable[T]) LookupCandidates(input string, indexes []int) (*Result[T], error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, err
+	}
+	input, err = rt.limitInput(input)
+	if err != nil {
+		return nil, err
+	}
+	normalized, offsets := input, []int(nil)
+	if rt.normalizers != nil {
+		normalized, offsets = rt.normalize(input)
+	}
+
+	wanted := make(map[int]bool, len(indexes))
+	for _, index := range indexes {
+		wanted[index] = true
+	}
+	var candidates []*ValueAndPattern[T]
+	for _, entry := range rt.maplets {
+		if wanted[entry.insertionIndex()] {
+			candidates = append(candidates, entry)
+		}
+	}
+	entry, loc, err := rt.leftmostIndexes(normalized, candidates, rt.individualRegexp)
+	if err != nil {
+		return nil, err
+	}
+	matches := submatchesAt(normalized, loc)
+	rt.recordHit(entry, input, matches)
+	result := rt.newResult(entry, matches)
+	result.locate(loc, offsets)
+	result.complete = len(matches[0]) == len(normalized)
+	return result, nil
+}
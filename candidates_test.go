@@ -0,0 +1,81 @@
+package regexptable
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegexpTable_LookupCandidates(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`(\d+)`, "number")
+	table.AddPattern(`err(or)?`, "error")
+
+	tests := []struct {
+		input      string
+		candidates []int
+		value      string
+		start      int
+	}{
+		{"x 42 error", []int{1, 2}, "number", 2},
+		{"x 42 error", []int{2}, "error", 5},
+		{"x 42 error", []int{0, 1, 2}, "word", 0},
+		{"error", []int{2, 0}, "word", 0}, // Table order breaks ties, not the order given
+		{"x 42 error", []int{1, 7}, "number", 2},
+	}
+	for _, test := range tests {
+		result, err := table.LookupCandidates(test.input, test.candidates)
+		if err != nil {
+			t.Errorf("LookupCandidates(%q, %v) failed: %v", test.input, test.candidates, err)
+			continue
+		}
+		if result.Value != test.value || result.Start != test.start {
+			t.Errorf("LookupCandidates(%q, %v): expected %q at %d, got %q at %d", test.input, test.candidates, test.value, test.start, result.Value, result.Start)
+		}
+	}
+
+	result, err := table.LookupCandidates("x 42", []int{1})
+	if err != nil || len(result.Groups) != 2 || result.Groups[1] != "42" || result.End != 4 {
+		t.Errorf("Expected the number's groups and span, got %+v, %v", result, err)
+	}
+
+	if _, err := table.LookupCandidates("42", []int{0, 2}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+	if _, err := table.LookupCandidates("42", nil); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch for no candidates, got %v", err)
+	}
+}
+
+func TestRegexpTable_LookupCandidatesNormalized(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`abc`, "abc")
+	table.SetNormalizers(TrimSpace(), Lowercase())
+
+	result, err := table.LookupCandidates("  ABC ", []int{0})
+	if err != nil {
+		t.Fatalf("LookupCandidates failed: %v", err)
+	}
+	if result.Groups[0] != "abc" || result.Start != 2 || result.End != 5 {
+		t.Errorf("Expected abc at [2, 5), got %q at [%d, %d)", result.Groups[0], result.Start, result.End)
+	}
+}
+
+func TestRegexpTable_IndexAt(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`a.*`, "short")
+	table.AddPattern(`abc.*`, "long")
+	table.SetLiteralOrdering(true)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+
+	// Literal ordering puts the longer literal prefix first.
+	if table.PatternAt(0) != `abc.*` || table.IndexAt(0) != 1 || table.IndexAt(1) != 0 {
+		t.Errorf("Expected abc.* with index 1 first, got %q with index %d", table.PatternAt(0), table.IndexAt(0))
+	}
+	result, err := table.LookupCandidates("abcd", []int{table.IndexAt(1)})
+	if err != nil || result.Value != "short" {
+		t.Errorf("Expected the candidate to win, got %+v, %v", result, err)
+	}
+}
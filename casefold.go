@@ -0,0 +1,61 @@
+package regexptable
+
+import (
+	"unicode"
+)
+
+// SetCaseInsensitive switches the table into (or out of) case-insensitive mode, in
+// which every pattern is compiled as if it started with the (?i) flag. With Go's
+// standard engine letters then match under Unicode simple case folding, so k also
+// matches K and the Kelvin sign, and ſ matches s. Like SetUngreedy, the mode
+// requires an engine that implements FlagFormatter.
+//
+// Simple case folding maps single characters to single characters, so it does
+// not make ß match ss, and it treats i and I alike in every language. Where
+// locale-specific casing matters, such as the dotted and dotless i of Turkish,
+// lower-case the input with a SpecialCase normalizer instead and write the
+// patterns in lower case.
+func (rt *RegexpTable[T]) SetCaseInsensitive(enabled bool) {
+	if rt.caseInsensitive != enabled {
+		rt.caseInsensitive = enabled
+		rt.needsRecompile = true
+		for _, entry := range rt.maplets {
+			entry.compiledPattern = nil
+		}
+	}
+}
+
+// SpecialCase returns a Normalizer that maps the input to lower case using the
+// casing rules of a language, for instance SpecialCase(unicode.TurkishCase), which
+// lower-cases I to dotless ı and İ to i. Bytes that are not valid UTF-8 are left
+// alone.
+func SpecialCase(c unicode.SpecialCase) Normalizer {
+	return mapRunes(c.ToLower)
+}
+
+// modeFlags returns the inline flags that table modes apply to every pattern.
+func modeFlags(caseInsensitive, ungreedy bool) string {
+	var flags string
+	if caseInsensitive {
+		flags += "i"
+	}
+	if ungreedy {
+		flags += "U"
+	}
+	return flags
+}
+
+// checkModeFlags reports an error if the engine cannot apply the given flags.
+func checkModeFlags(engine RegexpEngine, flags string) error {
+	if flags == "" {
+		return nil
+	}
+	formatter, ok := engine.(FlagFormatter)
+	if !ok {
+		return codeErrorf(CodeEngineUnsupported, "regexp engine does not support inline flags, needed for the %q flags", flags)
+	}
+	if _, ok := formatter.FormatFlags(flags, ""); !ok {
+		return codeErrorf(CodeEngineUnsupported, "regexp engine does not support the %q flags", flags)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package regexptable
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestRegexpTable_CaseInsensitive(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`kelvin`, "unit").
+		AddPattern(`straße`, "street").
+		WithCaseInsensitive(true).
+		WithUngreedy(true).
+		MustBuild(true, true)
+
+	for input, want := range map[string]string{
+		"KELVIN":      "unit",
+		"\u212Aelvin": "unit", // Kelvin sign
+		"STRAẞE":      "street",
+	} {
+		if value, _, ok := table.TryLookup(input); !ok || value != want {
+			t.Errorf("Expected %q for %q, got %q", want, input, value)
+		}
+	}
+	// Simple case folding does not expand ß to ss.
+	if _, _, ok := table.TryLookup("STRASSE"); ok {
+		t.Error("Expected STRASSE not to match under simple case folding")
+	}
+
+	table.SetCaseInsensitive(false)
+	if _, _, ok := table.TryLookup("KELVIN"); ok {
+		t.Error("Expected matching to be case sensitive again")
+	}
+
+	_, err := NewRegexpTableBuilderWithEngine[string](NewMockRegexpEngine("(?<%s>%s)")).
+		AddPattern(`a`, "a").
+		WithCaseInsensitive(true).
+		Build(true, true)
+	if err == nil {
+		t.Error("Expected an engine without FlagFormatter to be rejected")
+	}
+}
+
+func TestSpecialCase(t *testing.T) {
+	normalized, offsets := SpecialCase(unicode.TurkishCase).Normalize("İSTANBUL")
+	if normalized != "istanbul" {
+		t.Errorf("Expected %q, got %q", "istanbul", normalized)
+	}
+	if len(offsets) != len(normalized)+1 || offsets[1] != 2 {
+		t.Errorf("Expected offsets past the two-byte İ, got %v", offsets)
+	}
+
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`ılık`, "lukewarm").
+		WithNormalizers(SpecialCase(unicode.TurkishCase)).
+		MustBuild(true, true)
+	if value, _, ok := table.TryLookup("ILIK"); !ok || value != "lukewarm" {
+		t.Errorf("Expected Turkish casing to map ILIK to ılık, got %q", value)
+	}
+}
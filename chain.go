@@ -0,0 +1,72 @@
+package regexptable
+
+import (
+	"errors"
+)
+
+// TableChain is a fallback chain of tables that share a value type. A lookup tries
+// each table in turn and the first one that matches wins, which makes it easy to
+// compose, say, a fast literal table, a strict table and a permissive catch-all.
+type TableChain[T any] struct {
+	tables []*RegexpTable[T]
+}
+
+// NewTableChain creates a chain that consults the given tables in order.
+func NewTableChain[T any](tables ...*RegexpTable[T]) *TableChain[T] {
+	return &TableChain[T]{tables: tables}
+}
+
+// Append adds tables to the end of the chain and returns the chain.
+func (c *TableChain[T]) Append(tables ...*RegexpTable[T]) *TableChain[T] {
+	c.tables = append(c.tables, tables...)
+	return c
+}
+
+// Tables returns the tables of the chain in the order they are consulted.
+func (c *TableChain[T]) Tables() []*RegexpTable[T] {
+	return c.tables
+}
+
+// Lookup returns the value and submatches from the first table in the chain that
+// matches the input. Tables without patterns are skipped. If no table matches the
+// error is ErrNoMatch; any other error, such as a table failing to compile, stops
+// the search and is returned.
+func (c *TableChain[T]) Lookup(input string) (T, []string, error) {
+	var zero T
+
+	result, _, err := c.LookupResult(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	return result.Value, result.Groups, nil
+}
+
+// LookupResult is like Lookup but returns the rich Result, together with the
+// position in the chain of the table that matched.
+func (c *TableChain[T]) LookupResult(input string) (*Result[T], int, error) {
+	for i, table := range c.tables {
+		result, err := table.LookupResult(input)
+		if err == nil {
+			return result, i, nil
+		}
+		if !errors.Is(err, ErrNoMatch) && !errors.Is(err, ErrNoPatterns) {
+			return nil, -1, err
+		}
+	}
+	return nil, -1, ErrNoMatch
+}
+
+// TryLookup is like Lookup but reports failure with a boolean.
+func (c *TableChain[T]) TryLookup(input string) (T, []string, bool) {
+	value, matches, err := c.Lookup(input)
+	return value, matches, err == nil
+}
+
+// LookupOrElse is like Lookup but returns defaultValue when no table matches.
+func (c *TableChain[T]) LookupOrElse(input string, defaultValue T) (T, []string) {
+	value, matches, err := c.Lookup(input)
+	if err != nil {
+		return defaultValue, []string{}
+	}
+	return value, matches
+}
@@ -0,0 +1,72 @@
+package regexptable
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTableChain_Lookup(t *testing.T) {
+	keywords := NewRegexpTableBuilder[string]().
+		AddPattern(`if|else|while`, "keyword").
+		MustBuild(true, true)
+	strict := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]\w*`, "identifier").
+		MustBuild(true, true)
+	catchAll := NewRegexpTableBuilder[string]().
+		AddPattern(`.*`, "other").
+		MustBuild(true, true)
+	chain := NewTableChain(keywords, NewRegexpTable[string](true, true), strict, catchAll)
+
+	testCases := []struct {
+		input    string
+		expected string
+		index    int
+	}{
+		{"while", "keyword", 0},
+		{"whilst", "identifier", 2},
+		{"42", "number", 2},
+		{"$$$", "other", 3},
+	}
+	for _, tc := range testCases {
+		result, index, err := chain.LookupResult(tc.input)
+		if err != nil {
+			t.Errorf("LookupResult(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if result.Value != tc.expected || index != tc.index {
+			t.Errorf("LookupResult(%q): expected (%q, %d), got (%q, %d)", tc.input, tc.expected, tc.index, result.Value, index)
+		}
+	}
+}
+
+func TestTableChain_NoMatch(t *testing.T) {
+	chain := NewTableChain(NewRegexpTableBuilder[int]().AddPattern(`a`, 1).MustBuild(true, true))
+	if _, _, err := chain.Lookup("b"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+	if value, matches := chain.LookupOrElse("b", -1); value != -1 || len(matches) != 0 {
+		t.Errorf("Expected default value, got %d, %v", value, matches)
+	}
+
+	chain.Append(NewRegexpTableBuilder[int]().AddPattern(`b`, 2).MustBuild(true, true))
+	if value, _, ok := chain.TryLookup("b"); !ok || value != 2 {
+		t.Errorf("Expected 2 from the appended table, got %d, %v", value, ok)
+	}
+	if len(chain.Tables()) != 2 {
+		t.Errorf("Expected 2 tables, got %d", len(chain.Tables()))
+	}
+}
+
+func TestTableChain_StopsOnError(t *testing.T) {
+	broken := NewRegexpTable[string](true, true)
+	if err := broken.AddPattern(`(`, "broken"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	fallback := NewRegexpTableBuilder[string]().AddPattern(`.*`, "other").MustBuild(true, true)
+
+	_, _, err := NewTableChain(broken, fallback).Lookup("x")
+	if err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected the compile error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package regexptable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Class defines a named character class that patterns added to the builder can
+// refer to as \k{name}, so that a class used by many rules is written once. The
+// class must be a single bracket expression, such as [0-9] or [\p{L}_], and may
+// itself refer to classes defined before it.
+//
+// References are expanded when the table is built, wherever the patterns were
+// added. Outside a bracket expression \k{name} stands for the whole class; inside
+// one it contributes the class's members, so [\k{digit}a-f] is [0-9a-f] when digit
+// is [0-9]. A negated class such as [^,] cannot be used inside another bracket
+// expression. Build reports an error for an invalid class or a reference to an
+// unknown one. The table holds the expanded patterns.
+func (b *RegexpTableBuilder[T]) Class(name, class string) *RegexpTableBuilder[T] {
+	switch {
+	case !isClassName(name):
+		b.errs = append(b.errs, fmt.Errorf("invalid class name %q", name))
+		return b
+	case b.classes[name] != "":
+		b.errs = append(b.errs, fmt.Errorf("class %q is already defined", name))
+		return b
+	case len(class) < 2 || class[0] != '[' || skipClass(class, 0) != len(class)-1 || class[len(class)-1] != ']':
+		b.errs = append(b.errs, fmt.Errorf("class %q must be a single bracket expression, got '%s'", name, class))
+		return b
+	}
+	expanded, err := b.expandClasses(class)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("class %q: %w", name, err))
+		return b
+	}
+	if b.classes == nil {
+		b.classes = make(map[string]string)
+	}
+	b.classes[name] = expanded
+	return b
+}
+
+// isClassName reports whether name is a valid class name: letters, digits and
+// underscores.
+func isClassName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune reports whether r is an ASCII letter, digit or underscore.
+func isWordRune(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// expandClasses replaces the class references in a pattern, as described by Class.
+func (b *RegexpTableBuilder[T]) expandClasses(pattern string) (string, error) {
+	if !strings.Contains(pattern, `\k{`) {
+		return pattern, nil
+	}
+	var expanded strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			class, width, err := b.classReference(pattern[i:])
+			if err != nil {
+				return "", err
+			}
+			if width > 0 {
+				expanded.WriteString(class)
+				i += width - 1
+				continue
+			}
+			expanded.WriteString(pattern[i:min(i+2, len(pattern))])
+			i++ // Skip the escaped character
+		case '[':
+			end := skipClass(pattern, i)
+			members, err := b.expandClassMembers(pattern[i : end+1])
+			if err != nil {
+				return "", err
+			}
+			expanded.WriteString(members)
+			i = end
+		default:
+			expanded.WriteByte(pattern[i])
+		}
+	}
+	return expanded.String(), nil
+}
+
+// expandClassMembers replaces the class references inside a bracket expression
+// with the members of the classes they name.
+func (b *RegexpTableBuilder[T]) expandClassMembers(bracket string) (string, error) {
+	var expanded strings.Builder
+	for i := 0; i < len(bracket); i++ {
+		if bracket[i] != '\\' {
+			expanded.WriteByte(bracket[i])
+			continue
+		}
+		class, width, err := b.classReference(bracket[i:])
+		if err != nil {
+			return "", err
+		}
+		if width == 0 {
+			expanded.WriteString(bracket[i:min(i+2, len(bracket))])
+			i++ // Skip the escaped character
+			continue
+		}
+		if strings.HasPrefix(class, "[^") {
+			return "", fmt.Errorf("negated class %s cannot be used inside a bracket expression", bracket[i:i+width])
+		}
+		expanded.WriteString(class[1 : len(class)-1])
+		i += width - 1
+	}
+	return expanded.String(), nil
+}
+
+// classReference checks whether s starts with a class reference \k{name} and if
+// so returns the class it names and the length of the reference. The length is 0
+// if s starts with some other escape.
+func (b *RegexpTableBuilder[T]) classReference(s string) (string, int, error) {
+	if !strings.HasPrefix(s, `\k{`) {
+		return "", 0, nil
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated class reference '%s'", s)
+	}
+	name := s[3:end]
+	class, ok := b.classes[name]
+	if !ok {
+		return "", 0, fmt.Errorf("unknown class %q", name)
+	}
+	return class, end + 1, nil
+}
@@ -0,0 +1,91 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTableBuilder_Class(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`0x[\k{hex}]+`, "hex"). // Classes may be defined after use
+		Class("digit", `[0-9]`).
+		Class("hex", `[\k{digit}a-fA-F]`).
+		Class("sep", `[^,;]`).
+		AddPattern(`\k{digit}+`, "number").
+		AddPattern(`\k{sep}+`, "field").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := []string{`0x[0-9a-fA-F]+`, `[0-9]+`, `[^,;]+`}
+	if patterns := table.Patterns(); strings.Join(patterns, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected expanded patterns %v, got %v", expected, patterns)
+	}
+
+	tests := map[string]string{"0x1F": "hex", "42": "number", "a b": "field"}
+	for input, want := range tests {
+		if value, _, err := table.Lookup(input); err != nil || value != want {
+			t.Errorf("Lookup(%q): expected %q, got %q, %v", input, want, value, err)
+		}
+	}
+}
+
+func TestRegexpTableBuilder_ClassEscapes(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().Class("digit", `[0-9]`)
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{`\\k{digit}`, `\\k{digit}`},   // An escaped backslash followed by k{digit}
+		{`[\]\k{digit}]`, `[\]0-9]`},   // An escaped ] does not end the class
+		{`[]\k{digit}]`, `[]0-9]`},     // Nor does a leading ]
+		{`a\k{digit}\.b`, `a[0-9]\.b`}, // Other escapes are kept
+		{`[[:alpha:]\k{digit}]`, `[[:alpha:]0-9]`},
+	}
+	for _, test := range tests {
+		got, err := builder.expandClasses(test.pattern)
+		if err != nil || got != test.expected {
+			t.Errorf("expandClasses(%q): expected %q, got %q, %v", test.pattern, test.expected, got, err)
+		}
+	}
+}
+
+func TestRegexpTableBuilder_ClassErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *RegexpTableBuilder[string]
+		message string
+	}{
+		{"unknown", NewRegexpTableBuilder[string]().AddPattern(`\k{digit}`, "x"), `unknown class "digit"`},
+		{"unterminated", NewRegexpTableBuilder[string]().AddPattern(`\k{digit`, "x"), "unterminated class reference"},
+		{"not a class", NewRegexpTableBuilder[string]().Class("digit", `\d`), "single bracket expression"},
+		{"two classes", NewRegexpTableBuilder[string]().Class("ab", `[a][b]`), "single bracket expression"},
+		{"bad name", NewRegexpTableBuilder[string]().Class("a-b", `[a]`), "invalid class name"},
+		{"redefined", NewRegexpTableBuilder[string]().Class("a", `[a]`).Class("a", `[b]`), "already defined"},
+		{"negated member", NewRegexpTableBuilder[string]().
+			Class("sep", `[^,]`).
+			AddPattern(`[\k{sep}x]`, "x"), "negated class"},
+		{"unknown in class", NewRegexpTableBuilder[string]().Class("hex", `[\k{digit}a-f]`), `class "hex": unknown class "digit"`},
+	}
+	for _, test := range tests {
+		_, err := test.builder.AddPattern(`ok`, "ok").Build(true, true)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.message, err)
+		}
+		if errs := test.builder.Validate(true, true); len(errs) == 0 {
+			t.Errorf("%s: expected Validate to report the problem", test.name)
+		}
+	}
+}
+
+func TestRegexpTableBuilder_ClassClone(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().Class("digit", `[0-9]`)
+	clone := builder.Clone().Class("word", `[a-z]`)
+	if _, err := clone.AddPattern(`\k{digit}\k{word}`, "x").Build(true, true); err != nil {
+		t.Errorf("Expected the clone to keep the original's classes, got %v", err)
+	}
+	if _, err := builder.AddPattern(`\k{word}`, "x").Build(true, true); err == nil {
+		t.Error("Expected classes defined on a clone not to affect the original")
+	}
+}
@@ -0,0 +1,92 @@
+package regexptable
+
+// ClassifierTable is a table for classification only: a lookup returns the value
+// of the winning pattern and nothing else. Most uses of a table are of this kind,
+// and a ClassifierTable is cheaper than a RegexpTable, which is built for
+// extraction. Its patterns are compiled without capture groups, as in
+// RegexpTable's non-capturing mode, so the engine does less work, and lookups do
+// not build submatch slices or results. Use a RegexpTable when the matched text
+// or capture groups are needed.
+//
+// A ClassifierTable is created with NewClassifierTable or built with
+// RegexpTableBuilder.BuildClassifier, sharing the builder with RegexpTable.
+type ClassifierTable[T any] struct {
+	table *RegexpTable[T]
+}
+
+// NewClassifierTable creates an empty classifier using the standard regexp engine.
+// Its patterns are anchored to the start and end of the input as requested.
+func NewClassifierTable[T any](anchorStart, anchorEnd bool) *ClassifierTable[T] {
+	return newClassifierTable(NewRegexpTable[T](anchorStart, anchorEnd))
+}
+
+// newClassifierTable wraps a table, switching it to non-capturing mode.
+func newClassifierTable[T any](table *RegexpTable[T]) *ClassifierTable[T] {
+	table.SetNonCapturing(true)
+	return &ClassifierTable[T]{table: table}
+}
+
+// AddPattern adds a pattern with its associated value, as RegexpTable.AddPattern
+// does.
+func (c *ClassifierTable[T]) AddPattern(pattern string, value T) error {
+	return c.table.AddPattern(pattern, value)
+}
+
+// RemovePattern removes every entry with the given pattern and returns how many
+// were removed, as RegexpTable.RemovePattern does.
+func (c *ClassifierTable[T]) RemovePattern(pattern string) int {
+	return c.table.RemovePattern(pattern)
+}
+
+// Len returns the number of patterns in the classifier.
+func (c *ClassifierTable[T]) Len() int {
+	return c.table.Len()
+}
+
+// Recompile compiles the classifier's patterns, which otherwise happens on the
+// next lookup after they change.
+func (c *ClassifierTable[T]) Recompile() error {
+	return c.table.Recompile()
+}
+
+// Lookup returns the value of the pattern that matches the input, or ErrNoMatch
+// if none does.
+func (c *ClassifierTable[T]) Lookup(input string) (T, error) {
+	entry, _, err := c.table.find(input)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return entry.Value, nil
+}
+
+// TryLookup is like Lookup but reports failure with a boolean.
+func (c *ClassifierTable[T]) TryLookup(input string) (T, bool) {
+	value, err := c.Lookup(input)
+	return value, err == nil
+}
+
+// LookupOrElse is like Lookup but returns defaultValue if the lookup fails.
+func (c *ClassifierTable[T]) LookupOrElse(input string, defaultValue T) T {
+	value, err := c.Lookup(input)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// Matches reports whether any pattern matches the input.
+func (c *ClassifierTable[T]) Matches(input string) bool {
+	_, err := c.Lookup(input)
+	return err == nil
+}
+
+// BuildClassifier builds a ClassifierTable from the builder's patterns and
+// options, compiling it without capture groups whatever WithNonCapturing says.
+func (b *RegexpTableBuilder[T]) BuildClassifier(anchorStart, anchorEnd bool) (*ClassifierTable[T], error) {
+	table, err := b.Clone().WithNonCapturing(true).Build(anchorStart, anchorEnd)
+	if err != nil {
+		return nil, err
+	}
+	return newClassifierTable(table), nil
+}
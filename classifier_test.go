@@ -0,0 +1,61 @@
+package regexptable
+
+import (
+	"testing"
+)
+
+func TestClassifierTable(t *testing.T) {
+	classifier := NewClassifierTable[string](true, true)
+	classifier.AddPattern(`(\d+)-(\d+)`, "range")
+	classifier.AddPattern(`(?P<word>[a-z]+)`, "word")
+	if classifier.Len() != 2 {
+		t.Errorf("Expected 2 patterns, got %d", classifier.Len())
+	}
+
+	if value, err := classifier.Lookup("1-9"); err != nil || value != "range" {
+		t.Errorf("Expected range, got %q, %v", value, err)
+	}
+	if value, ok := classifier.TryLookup("abc"); !ok || value != "word" {
+		t.Errorf("Expected word, got %q", value)
+	}
+	if value := classifier.LookupOrElse("?", "other"); value != "other" {
+		t.Errorf("Expected other, got %q", value)
+	}
+	if _, err := classifier.Lookup("?"); err != ErrNoMatch {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+	if !classifier.Matches("42-43") || classifier.Matches("4-") {
+		t.Error("Expected Matches to agree with Lookup")
+	}
+	if !classifier.table.nonCapturing {
+		t.Error("Expected the classifier to compile without capture groups")
+	}
+
+	classifier.RemovePattern(`(\d+)-(\d+)`)
+	if classifier.Matches("1-9") {
+		t.Error("Expected the removed pattern not to match")
+	}
+}
+
+func TestRegexpTableBuilder_BuildClassifier(t *testing.T) {
+	builder := NewRegexpTableBuilder[int]().
+		AddPattern(`(a)(b)?`, 1).
+		AddPattern(`(\d)+`, 2)
+	classifier, err := builder.BuildClassifier(true, true)
+	if err != nil {
+		t.Fatalf("BuildClassifier failed: %v", err)
+	}
+	if value, err := classifier.Lookup("ab"); err != nil || value != 1 {
+		t.Errorf("Expected 1, got %d, %v", value, err)
+	}
+
+	// The builder itself is unchanged, so it can still build an extraction table.
+	table := builder.MustBuild(true, true)
+	if _, matches, _ := table.Lookup("ab"); len(matches) != 3 {
+		t.Errorf("Expected the capture groups, got %q", matches)
+	}
+
+	if _, err := NewRegexpTableBuilder[int]().AddPattern(`(`, 1).BuildClassifier(true, true); err == nil {
+		t.Error("Expected an invalid pattern to fail to build")
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// maxLineLength is the longest input line classify accepts.
+const maxLineLength = 16 << 20
+
+// flushThreshold is how much output a worker buffers before writing it out.
+const flushThreshold = 64 << 10
+
+// classifyRecord is the NDJSON record written for each classified line.
+type classifyRecord struct {
+	File   string   `json:"file"`
+	Line   int      `json:"line"`
+	Value  any      `json:"value"`
+	Groups []string `json:"groups"`
+}
+
+// classifyProgress counts the work done so far, for the progress report.
+type classifyProgress struct {
+	files   atomic.Int64
+	lines   atomic.Int64
+	matched atomic.Int64
+}
+
+// runClassify classifies every line of the given files with a spec, writing one
+// NDJSON record per matching line to standard output. Files are processed in
+// parallel, so records from different files are interleaved, but the records of
+// each file appear in line order. Files whose names end in .gz are decompressed.
+func runClassify(args []string) error {
+	return classify(args, os.Stdout, os.Stderr)
+}
+
+// classify implements runClassify, writing the records to stdout and the
+// progress report to stderr.
+func classify(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("classify", flag.ContinueOnError)
+	workers := flags.Int("workers", runtime.NumCPU(), "number of files to process in parallel")
+	progress := flags.Bool("progress", false, "report progress on standard error")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 || *workers < 1 {
+		return fmt.Errorf("usage: regexptable %s", classifyUsage)
+	}
+
+	table, err := loadTable(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	// The table is shared by the workers, so nothing may be compiled lazily.
+	table.SetPrecompileIndividuals(true)
+	if err := table.Recompile(); err != nil {
+		return err
+	}
+
+	paths, err := expandPaths(flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(stdout)
+	var outMu sync.Mutex
+	counts := &classifyProgress{}
+	if *progress {
+		stop := reportProgress(stderr, counts, len(paths))
+		defer stop()
+	}
+
+	jobs := make(chan string)
+	errs := make(chan error, len(paths))
+	var wg sync.WaitGroup
+	for range min(*workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				if err := classifyFile(table, path, out, &outMu, counts); err != nil {
+					errs <- fmt.Errorf("%s: %w", path, err)
+				}
+				counts.files.Add(1)
+			}
+		}()
+	}
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	var failures []string
+	for err := range errs {
+		failures = append(failures, err.Error())
+	}
+	if len(failures) > 0 {
+		slices.Sort(failures)
+		return fmt.Errorf("failed to classify %d file(s):\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// expandPaths expands glob patterns among the arguments, keeping arguments that
+// match nothing as they are so that opening them reports the problem.
+func expandPaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+		for _, match := range matches {
+			if !slices.Contains(paths, match) {
+				paths = append(paths, match)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// classifyFile classifies the lines of one file, writing its records to out in
+// batches so that workers rarely contend for it.
+func classifyFile(table *regexptable.RegexpTable[any], path string, out io.Writer, outMu *sync.Mutex, counts *classifyProgress) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var input io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		input = gz
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	flush := func() error {
+		outMu.Lock()
+		defer outMu.Unlock()
+		_, err := buffer.WriteTo(out)
+		return err
+	}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineLength)
+	line := 0
+	for scanner.Scan() {
+		line++
+		counts.lines.Add(1)
+		value, groups, ok := table.TryLookup(scanner.Text())
+		if !ok {
+			continue
+		}
+		counts.matched.Add(1)
+		record := classifyRecord{File: path, Line: line, Value: value, Groups: groups}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		if buffer.Len() >= flushThreshold {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	// Write out the records of the lines read before any error, too.
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
+
+// reportProgress prints the progress counts to w once a second until the
+// returned function is called, which prints the final counts.
+func reportProgress(w io.Writer, counts *classifyProgress, total int) func() {
+	report := func(end string) {
+		fmt.Fprintf(w, "\rfiles %d/%d, lines %d, matched %d%s",
+			counts.files.Load(), total, counts.lines.Load(), counts.matched.Load(), end)
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report("")
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		report("\n")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const classifySpec = `{"version": 1, "anchorStart": true, "anchorEnd": true, "entries": [
+	{"pattern": "(\\d+)", "value": "number"},
+	{"pattern": "[a-z]+", "value": "word"}
+]}`
+
+// writeFile writes data to a file in dir, compressing it if the name ends in .gz.
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	content := []byte(data)
+	if strings.HasSuffix(name, ".gz") {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(content)
+		gz.Close()
+		content = compressed.Bytes()
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readRecords parses the NDJSON output of classify.
+func readRecords(t *testing.T, out []byte) []classifyRecord {
+	t.Helper()
+	var records []classifyRecord
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		var record classifyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	spec := writeFile(t, dir, "spec.json", classifySpec)
+	logs := filepath.Join(dir, "logs")
+	os.Mkdir(logs, 0o755)
+	writeFile(t, logs, "a.log", "42\n?\nabc\n")
+	writeFile(t, logs, "b.log", "x\n")
+	writeFile(t, dir, "c.log.gz", "7\n!\n")
+	missing := filepath.Join(dir, "missing.log")
+
+	var stdout, stderr bytes.Buffer
+	err := classify([]string{"-workers", "2", "-progress", spec, filepath.Join(logs, "*.log"), filepath.Join(dir, "c.log.gz"), missing}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "failed to classify 1 file(s)") || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Expected the missing file to be reported, got %v", err)
+	}
+
+	var got []string
+	for _, record := range readRecords(t, stdout.Bytes()) {
+		got = append(got, filepath.Base(record.File)+":"+strings.Join(record.Groups, ",")+"="+record.Value.(string))
+		if record.Line < 1 {
+			t.Errorf("Expected a line number, got %+v", record)
+		}
+	}
+	slices.Sort(got)
+	want := []string{"a.log:42,42=number", "a.log:abc=word", "b.log:x=word", "c.log.gz:7,7=number"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected records %v, got %v", want, got)
+	}
+	if !strings.Contains(stderr.String(), "files 4/4, lines 6, matched 4") {
+		t.Errorf("Expected the final progress counts, got %q", stderr.String())
+	}
+}
+
+func TestClassify_ReadError(t *testing.T) {
+	dir := t.TempDir()
+	spec := writeFile(t, dir, "spec.json", classifySpec)
+	path := writeFile(t, dir, "truncated.log.gz", strings.Repeat("42\n", 1000))
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, data[:len(data)-8], 0o644) // Drop the gzip trailer
+
+	var stdout, stderr bytes.Buffer
+	err := classify([]string{spec, path}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected the truncated file to be reported, got %v", err)
+	}
+	// The lines read before the error are still written out.
+	if records := readRecords(t, stdout.Bytes()); len(records) != 1000 {
+		t.Errorf("Expected 1000 records before the error, got %d", len(records))
+	}
+}
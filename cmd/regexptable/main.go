@@ -0,0 +1,249 @@
+// Command regexptable is a tool for working with regexp table spec files.
+//
+// Usage:
+//
+//	regexptable explain <spec.json> <input>
+//	regexptable examples <spec.json> [count]
+//	regexptable classify [-workers n] [-progress] <spec.json> <file|glob>...
+//	regexptable complexity <spec.json> [budget]
+//	regexptable export <grok|vrl> <spec.json>
+//	regexptable docs <markdown|html> <spec.json>
+//
+// Errors are reported on standard error, starting with the error's code, such as
+// REGEXPTABLE_COMPILE_FAILED, when it has one; see regexptable.ErrorCode.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+const (
+	explainUsage    = "explain <spec.json> <input>"
+	examplesUsage   = "examples <spec.json> [count]"
+	classifyUsage   = "classify [-workers n] [-progress] <spec.json> <file|glob>..."
+	complexityUsage = "complexity <spec.json> [budget]"
+	exportUsage     = "export <grok|vrl> <spec.json>"
+	docsUsage       = "docs <markdown|html> <spec.json>"
+)
+
+var commands = []command{
+	{name: "explain", usage: explainUsage, run: runExplain},
+	{name: "examples", usage: examplesUsage, run: runExamples},
+	{name: "classify", usage: classifyUsage, run: runClassify},
+	{name: "complexity", usage: complexityUsage, run: runComplexity},
+	{name: "export", usage: exportUsage, run: runExport},
+	{name: "docs", usage: docsUsage, run: runDocs},
+}
+
+// findCommand returns the subcommand with the given name.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := findCommand(os.Args[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		// Errors from the package carry a code that scripts can match on, which is
+		// printed first so that it is easy to pick out.
+		if code := regexptable.ErrorCodeOf(err); code != "" {
+			fmt.Fprintf(os.Stderr, "regexptable %s: %s: %v\n", os.Args[1], code, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "regexptable %s: %v\n", os.Args[1], err)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  regexptable %s\n", cmd.usage)
+	}
+}
+
+// loadTable reads a spec file and builds the table it describes. Values are kept
+// as generic JSON values since the tool does not know the application's types.
+func loadTable(path string) (*regexptable.RegexpTable[any], error) {
+	return regexptable.NewLoader[any](regexptable.LoadStrict).LoadFile(path)
+}
+
+// runExplain prints how each rule of a spec treats a single input.
+func runExplain(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: regexptable %s", explainUsage)
+	}
+	table, err := loadTable(args[0])
+	if err != nil {
+		return err
+	}
+	explanation, err := table.Explain(args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("input:     %q\n", explanation.Input)
+	fmt.Printf("anchoring: start=%v end=%v\n", explanation.AnchorStart, explanation.AnchorEnd)
+	fmt.Println("rules:")
+	for _, rule := range explanation.Rules {
+		marker := " "
+		if rule.Index == explanation.Winner {
+			marker = "*"
+		}
+		status := "no match"
+		switch {
+		case rule.Matched:
+			status = fmt.Sprintf("matched %q", rule.Match)
+		case rule.MatchesAnywhere:
+			status = "no match (matches when unanchored)"
+		}
+		fmt.Printf(" %s %3d  %-30s %-40s %v\n", marker, rule.InsertionIndex, rule.Pattern, status, rule.Duration)
+	}
+	fmt.Printf("result:    %s\n", regexptable.FormatResult(table.LookupResult(args[1])))
+	fmt.Printf("reason:    %s\n", explanation.Reason)
+	return nil
+}
+
+// runExamples prints sample inputs generated from each rule of a spec, followed by
+// the value the table actually gives them. A sample that the table classifies
+// differently is shadowed by a higher-priority rule.
+func runExamples(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: regexptable %s", examplesUsage)
+	}
+	count := 3
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid count %q", args[1])
+		}
+		count = n
+	}
+
+	loader := regexptable.NewLoader[any](regexptable.LoadStrict)
+	spec, err := loader.ParseSpecFile(args[0])
+	if err != nil {
+		return err
+	}
+	table, err := loader.Build(spec)
+	if err != nil {
+		return err
+	}
+
+	for i := range spec.Entries {
+		entry := &spec.Entries[i]
+		fmt.Printf("%s\n", entry.Pattern)
+		for _, example := range entry.Examples(count) {
+			value, _, ok := table.TryLookup(example)
+			if !ok {
+				fmt.Printf("    %-30q (no match)\n", example)
+				continue
+			}
+			fmt.Printf("    %-30q %v\n", example, value)
+		}
+	}
+	return nil
+}
+
+// runComplexity prints the complexity of each rule of a spec, most complex first,
+// followed by that of the whole union. Given a budget, it fails if any rule
+// exceeds it, which lets CI keep expensive rules out of a rule set.
+func runComplexity(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: regexptable %s", complexityUsage)
+	}
+	budget := -1
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid budget %q", args[1])
+		}
+		budget = n
+	}
+	table, err := loadTable(args[0])
+	if err != nil {
+		return err
+	}
+	report, err := table.ComplexityReport()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range report.Patterns {
+		fmt.Printf("%6d  %3d  %s\n", p.Complexity, p.Index, p.Pattern)
+	}
+	fmt.Printf("%6d  union\n", report.Union)
+	if budget < 0 {
+		return nil
+	}
+	if over := report.OverBudget(budget); len(over) > 0 {
+		return fmt.Errorf("%d rules exceed the complexity budget of %d", len(over), budget)
+	}
+	return nil
+}
+
+// runExport prints the rules of a spec as grok patterns or as a VRL program that
+// classifies .message into .class. Rules that cannot be translated are reported
+// after the others have been printed.
+func runExport(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: regexptable %s", exportUsage)
+	}
+	table, err := loadTable(args[1])
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "grok":
+		return table.ExportGrok(os.Stdout, nil)
+	case "vrl":
+		return table.ExportVRL(os.Stdout, "string!(.message)", ".class", nil)
+	}
+	return fmt.Errorf("unknown export format %q", args[0])
+}
+
+// runDocs prints a catalogue of the rules of a spec, including those of any specs
+// it includes, as Markdown or HTML.
+func runDocs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: regexptable %s", docsUsage)
+	}
+	var format regexptable.DocFormat
+	switch args[0] {
+	case "markdown":
+		format = regexptable.DocMarkdown
+	case "html":
+		format = regexptable.DocHTML
+	default:
+		return fmt.Errorf("unknown documentation format %q", args[0])
+	}
+	spec, err := regexptable.NewLoader[any](regexptable.LoadStrict).ParseSpecFile(args[1])
+	if err != nil {
+		return err
+	}
+	return spec.GenerateDocs(os.Stdout, format)
+}
@@ -0,0 +1,84 @@
+package regexptable
+
+import (
+	"cmp"
+	"regexp/syntax"
+	"slices"
+)
+
+// Complexity estimates the cost of a pattern as the number of instructions in
+// the program Go's regexp package compiles it to. Patterns with a higher score
+// take longer to compile and, roughly in proportion, to match; counted
+// repetitions such as \w{1,100} and large Unicode classes are the usual culprits.
+// The pattern must use Go's syntax.
+func Complexity(pattern string) (int, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, err
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return 0, err
+	}
+	return len(prog.Inst), nil
+}
+
+// PatternComplexity is the Complexity of one of a table's patterns.
+type PatternComplexity struct {
+	Index      int // The pattern's insertion index, as in Result.Index
+	Pattern    string
+	Complexity int
+}
+
+// ComplexityReport describes which of a table's patterns dominate its compile
+// size and lookup cost.
+type ComplexityReport struct {
+	Patterns []PatternComplexity // Most complex first, ties in table order
+	Union    int                 // Complexity of the table's compiled union
+}
+
+// OverBudget returns the patterns whose complexity exceeds budget, most complex
+// first, so that CI can reject rules that are too expensive.
+func (r *ComplexityReport) OverBudget(budget int) []PatternComplexity {
+	var over []PatternComplexity
+	for _, p := range r.Patterns {
+		if p.Complexity > budget {
+			over = append(over, p)
+		}
+	}
+	return over
+}
+
+// ComplexityReport scores every pattern of the table with Complexity, as it is
+// compiled with the table's modes, and scores the union as a whole. It requires
+// an engine that accepts Go's syntax, such as the standard engine.
+func (rt *RegexpTable[T]) ComplexityReport() (*ComplexityReport, error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ComplexityReport{Patterns: make([]PatternComplexity, 0, len(rt.maplets))}
+	for _, entry := range rt.maplets {
+		complexity, err := Complexity(rt.effectivePattern(entry))
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to score pattern '%s': %w", entry.Pattern, err)
+		}
+		report.Patterns = append(report.Patterns, PatternComplexity{
+			Index:      entry.insertionIndex(),
+			Pattern:    entry.Pattern,
+			Complexity: complexity,
+		})
+	}
+	slices.SortStableFunc(report.Patterns, func(x, y PatternComplexity) int {
+		return cmp.Compare(y.Complexity, x.Complexity)
+	})
+
+	if rt.compiledUnion != "" {
+		report.Union, err = Complexity(rt.compiledUnion)
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to score union: %w", err)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,66 @@
+package regexptable
+
+import (
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	short, err := Complexity(`abc`)
+	if err != nil {
+		t.Fatalf("Complexity failed: %v", err)
+	}
+	long, err := Complexity(`\w{1,100}`)
+	if err != nil {
+		t.Fatalf("Complexity failed: %v", err)
+	}
+	if short <= 0 || long <= short {
+		t.Errorf("Expected a counted repetition to score more than a literal, got %d and %d", long, short)
+	}
+	if _, err := Complexity(`(`); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestRegexpTable_ComplexityReport(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`abc`, "abc")
+	table.AddPattern(`\w{1,100}`, "word")
+	table.AddPattern(`xyz`, "xyz")
+
+	report, err := table.ComplexityReport()
+	if err != nil {
+		t.Fatalf("ComplexityReport failed: %v", err)
+	}
+	if len(report.Patterns) != 3 {
+		t.Fatalf("Expected 3 patterns, got %d", len(report.Patterns))
+	}
+	first, second, third := report.Patterns[0], report.Patterns[1], report.Patterns[2]
+	if first.Index != 1 || first.Pattern != `\w{1,100}` || second.Index != 0 || third.Index != 2 {
+		t.Errorf("Expected the word pattern first, then ties in table order, got %v", report.Patterns)
+	}
+	if report.Union < first.Complexity+second.Complexity+third.Complexity {
+		t.Errorf("Expected the union to cost at least its patterns, got %d", report.Union)
+	}
+
+	over := report.OverBudget(second.Complexity)
+	if len(over) != 1 || over[0].Index != 1 {
+		t.Errorf("Expected only the word pattern over budget, got %v", over)
+	}
+	if over := report.OverBudget(first.Complexity); len(over) != 0 {
+		t.Errorf("Expected no pattern over budget, got %v", over)
+	}
+}
+
+func TestRegexpTable_ComplexityReportModes(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(a)(b)`, "ab")
+	capturing, _ := table.ComplexityReport()
+	table.SetNonCapturing(true)
+	stripped, err := table.ComplexityReport()
+	if err != nil {
+		t.Fatalf("ComplexityReport failed: %v", err)
+	}
+	if stripped.Patterns[0].Pattern != `(a)(b)` || stripped.Patterns[0].Complexity >= capturing.Patterns[0].Complexity {
+		t.Errorf("Expected non-capturing mode to lower the score, got %d and then %d", capturing.Patterns[0].Complexity, stripped.Patterns[0].Complexity)
+	}
+}
@@ -0,0 +1,94 @@
+package regexptable
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// RecompileContext is like Recompile but gives up when ctx is cancelled or its
+// deadline passes, returning ctx.Err(), so that reloading the rules of a large
+// table cannot hang a service indefinitely. The union is compiled on a copy of the
+// table in a separate goroutine; the table only changes once that succeeds, so
+// after a cancellation or a compilation error the previously compiled union stays
+// in place. An abandoned compilation runs to completion in the background and its
+// result is discarded.
+//
+// The table still needs recompiling afterwards, which the next lookup does
+// without a deadline. To keep serving the previous rules instead, reload through a
+// StagedTable and CommitContext.
+func (rt *RegexpTable[T]) RecompileContext(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return rt.Recompile() // Cannot be cancelled
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	start := time.Now()
+	rt.SweepExpired() // Here rather than on the copy, so that observers are told
+
+	staged := rt.stagingCopy()
+	done := make(chan error, 1)
+	go func() {
+		done <- staged.recompile()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-done:
+		if err == nil {
+			rt.adopt(staged)
+		}
+	}
+	if len(rt.observers.recompile) > 0 {
+		rt.notifyRecompile(start, err)
+	}
+	return err
+}
+
+// stagingCopy returns a copy of the table that can be recompiled without
+// affecting the table, even while it is being read.
+func (rt *RegexpTable[T]) stagingCopy() *RegexpTable[T] {
+	staged := *rt
+	staged.memo = nil // Cleared by adopt instead, once the new union is in place
+	staged.observers = observers{}
+	staged.maplets = make([]*ValueAndPattern[T], len(rt.maplets))
+	for i, entry := range rt.maplets {
+		copied := *entry
+		copied.groupNames = slices.Clone(entry.groupNames) // Recompiling reuses the slice
+		staged.maplets[i] = &copied
+	}
+	return &staged
+}
+
+// adopt replaces the state of the table with that of a recompiled staging copy.
+func (rt *RegexpTable[T]) adopt(staged *RegexpTable[T]) {
+	memo, observers := rt.memo, rt.observers
+	*rt = *staged
+	rt.memo, rt.observers = memo, observers
+	if rt.memo != nil {
+		rt.memo.clear()
+	}
+}
+
+// BuildContext is like Build but compiles the table with RecompileContext, so
+// that building can be abandoned when ctx is cancelled.
+func (b *RegexpTableBuilder[T]) BuildContext(ctx context.Context, anchorStart, anchorEnd bool) (*RegexpTable[T], error) {
+	return b.build(ctx, anchorStart, anchorEnd)
+}
+
+// CommitContext is like Commit but gives up when ctx is cancelled, returning
+// ctx.Err() and leaving the previously published table in place.
+func (st *StagedTable[T]) CommitContext(ctx context.Context) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	table, err := st.buildContext(ctx, st.staging)
+	if err != nil {
+		return err
+	}
+	st.committed = st.staging.Clone()
+	st.published.Store(table)
+	return nil
+}
@@ -0,0 +1,147 @@
+package regexptable
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowEngine is the standard engine, except that compiling any pattern that
+// mentions "slow" waits until release is closed.
+type slowEngine struct {
+	StandardRegexpEngine
+	release chan struct{}
+}
+
+func (e *slowEngine) Compile(pattern string) (CompiledRegexp, error) {
+	if strings.Contains(pattern, "slow") {
+		<-e.release
+	}
+	return e.StandardRegexpEngine.Compile(pattern)
+}
+
+func TestRegexpTable_RecompileContextCancelled(t *testing.T) {
+	engine := &slowEngine{release: make(chan struct{})}
+	defer close(engine.release)
+	table := NewRegexpTableWithEngine[string](engine, true, true)
+	table.AddPattern(`(\d+)`, "number")
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	union := table.compiledUnion
+
+	var stats []RecompileStats
+	table.OnRecompile(func(s RecompileStats) { stats = append(stats, s) })
+	table.AddPattern(`slow`, "slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := table.RecompileContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+	if table.compiledUnion != union || !table.needsRecompile {
+		t.Errorf("Expected the previous union to stay in place, got %s", table.compiledUnion)
+	}
+	if entry, _, err := table.matchEntry("42"); err != nil || entry.Value != "number" {
+		t.Errorf("Expected the previous union to still match, got %v", err)
+	}
+	if len(stats) != 1 || !errors.Is(stats[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the cancellation to be reported, got %+v", stats)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := table.RecompileContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected an already cancelled context to fail, got %v", err)
+	}
+}
+
+func TestRegexpTable_RecompileContext(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(\d+)`, "number")
+	table.SetMemoization(10, false)
+	table.Lookup("1")
+
+	table.AddPattern(`(?P<word>[a-z]+)`, "word")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := table.RecompileContext(ctx); err != nil {
+		t.Fatalf("RecompileContext failed: %v", err)
+	}
+	if table.needsRecompile || !strings.Contains(table.compiledUnion, "[a-z]+") {
+		t.Errorf("Expected the new union to be in place, got %s", table.compiledUnion)
+	}
+	result, err := table.LookupResult("abc")
+	if err != nil || result.Value != "word" {
+		t.Fatalf("Expected word, got %v, %v", result, err)
+	}
+	if word, _ := result.Field("word"); word != "abc" {
+		t.Errorf("Expected the word group, got %s", result)
+	}
+	if value, _, _ := table.Lookup("1"); value != "number" {
+		t.Errorf("Expected number, got %q", value)
+	}
+
+	table.AddPattern(`(`, "bad")
+	if err := table.RecompileContext(ctx); err == nil {
+		t.Error("Expected a compilation error")
+	}
+	if !strings.Contains(table.compiledUnion, "[a-z]+") {
+		t.Errorf("Expected the previous union to survive a failed compilation, got %s", table.compiledUnion)
+	}
+}
+
+func TestRegexpTableBuilder_BuildContext(t *testing.T) {
+	engine := &slowEngine{release: make(chan struct{})}
+	defer close(engine.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := NewRegexpTableBuilderWithEngine[string](engine).AddPattern(`slow`, "slow").BuildContext(ctx, true, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	table, err := NewRegexpTableBuilder[string]().AddPattern(`a`, "a").BuildContext(context.Background(), true, true)
+	if err != nil || table.Len() != 1 {
+		t.Errorf("Expected BuildContext to build the table, got %v", err)
+	}
+}
+
+func TestStagedTable_CommitContext(t *testing.T) {
+	engine := &slowEngine{release: make(chan struct{})}
+	staged, err := NewStagedTable(NewRegexpTableBuilderWithEngine[string](engine).AddPattern(`\d+`, "number"), true, true)
+	if err != nil {
+		t.Fatalf("NewStagedTable failed: %v", err)
+	}
+
+	staged.AddPattern(`slow`, "slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		// Readers carry on with the published table meanwhile.
+		defer close(done)
+		for range 100 {
+			if value, _, _ := staged.Lookup("42"); value != "number" {
+				t.Errorf("Expected number, got %q", value)
+				return
+			}
+		}
+	}()
+	if err := staged.CommitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be exceeded, got %v", err)
+	}
+	<-done
+	if staged.Table().Len() != 1 {
+		t.Errorf("Expected the previous table to stay published, got %d patterns", staged.Table().Len())
+	}
+
+	close(engine.release)
+	if err := staged.CommitContext(context.Background()); err != nil {
+		t.Fatalf("CommitContext failed: %v", err)
+	}
+	if value, _, _ := staged.Lookup("slow"); value != "slow" {
+		t.Errorf("Expected the staged pattern after committing, got %q", value)
+	}
+}
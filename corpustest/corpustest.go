@@ -0,0 +1,201 @@
+// Package corpustest checks how a table classifies a corpus of inputs against
+// expected-output files, so that teams who keep their rules as data can
+// regression-test them with a single call from go test:
+//
+//	func TestRules(t *testing.T) {
+//		corpustest.Run(t, loadRulesTable(), "testdata/corpus", nil)
+//	}
+//
+// The corpus directory holds input files named *.input, one input per line. Next
+// to each is an expected-output file with the same name ending in .expected
+// instead, giving the classification of each input on the corresponding line.
+// Expected-output files are written by Run when they do not exist yet or when
+// regexptabletest.UpdateGoldenEnv is set, so that intended changes can be
+// accepted by re-running the tests and reviewing the diff.
+package corpustest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+	"github.com/sfkleach/regexptable/regexptabletest"
+)
+
+// File name suffixes of input and expected-output files.
+const (
+	InputSuffix    = ".input"
+	ExpectedSuffix = ".expected"
+)
+
+// NoMatch is the classification of an input that no pattern matches.
+const NoMatch = "<no match>"
+
+// missing stands for a line that one of the files does not have.
+const missing = "<missing>"
+
+// Format renders a lookup result as a line of an expected-output file. The
+// default, used when a nil Format is given, renders the result's value with %v.
+type Format[T any] func(result *regexptable.Result[T]) string
+
+// Mismatch is an input whose classification differs from the expected one.
+type Mismatch struct {
+	File     string // The input file
+	Line     int    // The line of the input, counting from 1
+	Input    string
+	Expected string
+	Got      string
+}
+
+// String describes the mismatch, e.g. logs.input:3: "GET /" expected access, got <no match>.
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s:%d: %q expected %s, got %s", m.File, m.Line, m.Input, m.Expected, m.Got)
+}
+
+// Classify looks up every line of the input file at path and returns the
+// classification of each.
+func Classify[T any](table *regexptable.RegexpTable[T], path string, format Format[T]) (inputs, classifications []string, err error) {
+	if format == nil {
+		format = func(result *regexptable.Result[T]) string { return fmt.Sprint(result.Value) }
+	}
+	inputs, err = readLines(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, input := range inputs {
+		result, err := table.LookupResult(input)
+		switch {
+		case errors.Is(err, regexptable.ErrNoMatch):
+			classifications = append(classifications, NoMatch)
+		case err != nil:
+			return nil, nil, fmt.Errorf("%s: lookup of %q failed: %w", path, input, err)
+		default:
+			classifications = append(classifications, format(result))
+		}
+	}
+	return inputs, classifications, nil
+}
+
+// Check classifies every input file in dir and compares the classifications with
+// the expected-output files, returning the mismatches. A missing expected-output
+// file is an error.
+func Check[T any](table *regexptable.RegexpTable[T], dir string, format Format[T]) ([]Mismatch, error) {
+	paths, err := inputFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []Mismatch
+	for _, path := range paths {
+		inputs, got, err := Classify(table, path, format)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := readLines(expectedPath(path))
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, compare(path, inputs, expected, got)...)
+	}
+	return mismatches, nil
+}
+
+// Run checks the corpus in dir like Check, reporting each mismatch as a test
+// error, or writes the expected-output files as described in the package
+// documentation.
+func Run[T any](t testing.TB, table *regexptable.RegexpTable[T], dir string, format Format[T]) {
+	t.Helper()
+	paths, err := inputFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to read corpus: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("Corpus %s has no %s files", dir, InputSuffix)
+	}
+
+	for _, path := range paths {
+		inputs, got, err := Classify(table, path, format)
+		if err != nil {
+			t.Fatalf("Failed to classify corpus: %v", err)
+		}
+		expected, err := readLines(expectedPath(path))
+		if os.Getenv(regexptabletest.UpdateGoldenEnv) != "" || errors.Is(err, os.ErrNotExist) {
+			if err := writeLines(expectedPath(path), got); err != nil {
+				t.Fatalf("Failed to write expected output: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to read expected output: %v", err)
+		}
+		for _, mismatch := range compare(path, inputs, expected, got) {
+			t.Errorf("Classification changed: %s (set %s=1 to accept the change)", mismatch, regexptabletest.UpdateGoldenEnv)
+		}
+	}
+}
+
+// compare returns the lines on which the expected and actual classifications of
+// the inputs of an input file differ.
+func compare(path string, inputs, expected, got []string) []Mismatch {
+	var mismatches []Mismatch
+	for i := range max(len(inputs), len(expected)) {
+		mismatch := Mismatch{File: path, Line: i + 1, Expected: missing, Got: missing}
+		if i < len(inputs) {
+			mismatch.Input, mismatch.Got = inputs[i], got[i]
+		}
+		if i < len(expected) {
+			mismatch.Expected = expected[i]
+		}
+		if mismatch.Expected != mismatch.Got {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return mismatches
+}
+
+// inputFiles returns the input files of the corpus in dir, sorted.
+func inputFiles(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+InputSuffix))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// expectedPath returns the path of the expected-output file for an input file.
+func expectedPath(inputPath string) string {
+	return strings.TrimSuffix(inputPath, InputSuffix) + ExpectedSuffix
+}
+
+// readLines returns the lines of a file, without their line endings.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// writeLines writes lines to a file, each followed by a newline.
+func writeLines(path string, lines []string) error {
+	var data strings.Builder
+	for _, line := range lines {
+		data.WriteString(line + "\n")
+	}
+	return os.WriteFile(path, []byte(data.String()), 0o644)
+}
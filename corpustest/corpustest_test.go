@@ -0,0 +1,73 @@
+package corpustest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+	"github.com/sfkleach/regexptable/regexptabletest"
+)
+
+func newTable() *regexptable.RegexpTable[string] {
+	return regexptable.NewRegexpTableBuilder[string]().
+		AddPattern(`(?P<method>GET|POST) \S+`, "request").
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		MustBuild(true, true)
+}
+
+func TestRun(t *testing.T) {
+	Run(t, newTable(), "testdata", nil)
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.input"), []byte("1\nx\nGET /\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "a.expected"), []byte("number\nnumber\n"), 0o644)
+	mismatches, err := Check(newTable(), dir, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %v", mismatches)
+	}
+	if mismatches[0].Line != 2 || mismatches[0].Got != "word" || mismatches[0].Expected != "number" {
+		t.Errorf("Unexpected first mismatch %v", mismatches[0])
+	}
+	if got := mismatches[1].String(); !strings.HasSuffix(got, `a.input:3: "GET /" expected <missing>, got request`) {
+		t.Errorf("Unexpected second mismatch %s", got)
+	}
+
+	format := func(result *regexptable.Result[string]) string {
+		method, _ := result.Field("method")
+		return result.Value + " " + method
+	}
+	os.WriteFile(filepath.Join(dir, "a.expected"), []byte("number \nword \nrequest GET\n"), 0o644)
+	if mismatches, err := Check(newTable(), dir, format); err != nil || len(mismatches) != 0 {
+		t.Errorf("Expected the custom format to match, got %v, %v", mismatches, err)
+	}
+
+	os.Remove(filepath.Join(dir, "a.expected"))
+	if _, err := Check(newTable(), dir, nil); err == nil {
+		t.Error("Expected a missing expected-output file to be an error")
+	}
+}
+
+func TestRunWritesExpected(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.input"), []byte("1\n?\n"), 0o644)
+	Run(t, newTable(), dir, nil)
+	data, err := os.ReadFile(filepath.Join(dir, "a.expected"))
+	if err != nil || string(data) != "number\n<no match>\n" {
+		t.Fatalf("Expected the expected output to be written, got %q, %v", data, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.input"), []byte("x\n"), 0o644)
+	t.Setenv(regexptabletest.UpdateGoldenEnv, "1")
+	Run(t, newTable(), dir, nil)
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.expected")); string(data) != "word\n" {
+		t.Errorf("Expected the expected output to be updated, got %q", data)
+	}
+}
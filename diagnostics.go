@@ -0,0 +1,126 @@
+package regexptable
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiagnosticKind identifies a slow path taken by a lookup.
+type DiagnosticKind int
+
+const (
+	// DiagnosticDisambiguation reports that the union could not tell which entry
+	// matched, because the engine reports no group offsets and the match was
+	// empty, so the entries were matched one at a time.
+	DiagnosticDisambiguation DiagnosticKind = iota
+
+	// DiagnosticExclusion reports that an entry with an Exclusion won the union
+	// match, so the entries were matched one at a time to check it.
+	DiagnosticExclusion
+
+	// DiagnosticTombstone reports that an entry removed since the table was
+	// compiled won the union match, so the live entries were matched one at a
+	// time. See RegexpTable.RemovePattern.
+	DiagnosticTombstone
+)
+
+// String returns the name of the diagnostic kind.
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticDisambiguation:
+		return "disambiguation"
+	case DiagnosticExclusion:
+		return "exclusion"
+	case DiagnosticTombstone:
+		return "tombstone"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic describes a lookup that took a slow path.
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Input    string
+	Pattern  string        // The pattern that finally won, "" if none did
+	Duration time.Duration // Time spent on the slow path
+}
+
+// Sampler limits how often diagnostics are produced, so that they can stay
+// enabled in production. It is safe for concurrent use and may be shared by
+// several tables, e.g. to apply one budget to a whole service.
+type Sampler struct {
+	every      uint64
+	oncePerKey bool
+	count      atomic.Uint64
+	seen       sync.Map // Keys already reported when oncePerKey is set
+}
+
+// NewSampler returns a sampler that admits one in every events (every event when
+// every is 1 or less). With oncePerKey, each distinct key, such as a pattern, is
+// reported at most once for the lifetime of the sampler.
+func NewSampler(every int, oncePerKey bool) *Sampler {
+	return &Sampler{every: uint64(max(every, 1)), oncePerKey: oncePerKey}
+}
+
+// Sample reports whether the next event should be examined. It is cheap, so it is
+// called before doing any diagnostic work.
+func (s *Sampler) Sample() bool {
+	if s == nil {
+		return true
+	}
+	return (s.count.Add(1)-1)%s.every == 0
+}
+
+// First reports whether an examined event with the given key should be reported.
+// It is always true unless the sampler reports each key only once.
+func (s *Sampler) First(key string) bool {
+	if s == nil || !s.oncePerKey {
+		return true
+	}
+	_, seen := s.seen.LoadOrStore(key, struct{}{})
+	return !seen
+}
+
+// diagnostics holds a table's diagnostic hook and its sampler.
+type diagnostics struct {
+	hook    func(Diagnostic)
+	sampler *Sampler
+}
+
+// SetDiagnostics installs a hook that is called when a lookup takes one of the
+// slow paths described by DiagnosticKind, which helps find rules that defeat the
+// single-pass union. The sampler limits how often the hook is called and how
+// often the slow path is timed; a nil sampler reports every event. A nil hook
+// disables diagnostics. The hook may be called concurrently from concurrent
+// lookups.
+func (rt *RegexpTable[T]) SetDiagnostics(hook func(Diagnostic), sampler *Sampler) {
+	if hook == nil {
+		rt.diagnostics = nil
+		return
+	}
+	rt.diagnostics = &diagnostics{hook: hook, sampler: sampler}
+}
+
+// traceFallback runs a slow lookup path, reporting it as a diagnostic of the given
+// kind when diagnostics are enabled and the event is sampled.
+func (rt *RegexpTable[T]) traceFallback(kind DiagnosticKind, input string, fallback func() (located[T], error)) (located[T], error) {
+	d := rt.diagnostics
+	if d == nil || !d.sampler.Sample() {
+		return fallback()
+	}
+
+	start := time.Now()
+	found, err := fallback()
+	duration := time.Since(start)
+
+	pattern := ""
+	if found.entry != nil {
+		pattern = found.entry.Pattern
+	}
+	if d.sampler.First(kind.String() + "\x00" + pattern) {
+		d.hook(Diagnostic{Kind: kind, Input: input, Pattern: pattern, Duration: duration})
+	}
+	return found, err
+}
@@ -0,0 +1,89 @@
+package regexptable
+
+import (
+	"testing"
+)
+
+func TestRegexpTable_DiagnosticsDisambiguation(t *testing.T) {
+	var reported []Diagnostic
+	table, err := NewRegexpTableBuilderWithEngine[string](&stringOnlyEngine{}).
+		AddPattern(`a*`, "as").
+		AddPattern(`b*`, "bs").
+		WithDiagnostics(func(d Diagnostic) { reported = append(reported, d) }, nil).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// A non-empty match identifies the winner without a fallback.
+	if value, _, _ := table.TryLookup("aa"); value != "as" {
+		t.Errorf("Expected as, got %q", value)
+	}
+	if len(reported) != 0 {
+		t.Fatalf("Expected no diagnostics, got %v", reported)
+	}
+
+	// The empty match needs disambiguation.
+	if value, _, _ := table.TryLookup(""); value != "as" {
+		t.Errorf("Expected as, got %q", value)
+	}
+	if len(reported) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", reported)
+	}
+	if d := reported[0]; d.Kind != DiagnosticDisambiguation || d.Pattern != `a*` || d.Input != "" {
+		t.Errorf("Unexpected diagnostic %+v", d)
+	}
+
+	table.SetDiagnostics(nil, nil)
+	table.TryLookup("")
+	if len(reported) != 1 {
+		t.Errorf("Expected diagnostics to be disabled, got %v", reported)
+	}
+}
+
+func TestRegexpTable_DiagnosticsSampling(t *testing.T) {
+	var reported []Diagnostic
+	table := NewRegexpTable[string](true, true)
+	if err := table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `if`}); err != nil {
+		t.Fatalf("AddPatternExcluding failed: %v", err)
+	}
+	if err := table.AddPattern(`if`, "keyword"); err != nil {
+		t.Fatalf("AddPattern failed: %v", err)
+	}
+	table.SetDiagnostics(func(d Diagnostic) { reported = append(reported, d) }, NewSampler(3, false))
+
+	for i := 0; i < 7; i++ {
+		table.TryLookup("word")
+	}
+	// Events 1, 4 and 7 are sampled.
+	if len(reported) != 3 {
+		t.Fatalf("Expected 3 sampled diagnostics, got %d", len(reported))
+	}
+	if reported[0].Kind != DiagnosticExclusion || reported[0].Pattern != `[a-z]+` {
+		t.Errorf("Unexpected diagnostic %+v", reported[0])
+	}
+
+	// Once per pattern: "word" and "if" are won by different patterns.
+	reported = nil
+	table.SetDiagnostics(func(d Diagnostic) { reported = append(reported, d) }, NewSampler(1, true))
+	for _, input := range []string{"word", "other", "if", "if"} {
+		table.TryLookup(input)
+	}
+	if len(reported) != 2 || reported[0].Pattern != `[a-z]+` || reported[1].Pattern != `if` {
+		t.Errorf("Expected one diagnostic per pattern, got %+v", reported)
+	}
+}
+
+func TestSampler(t *testing.T) {
+	var nilSampler *Sampler
+	if !nilSampler.Sample() || !nilSampler.First("x") {
+		t.Error("Expected a nil sampler to admit everything")
+	}
+	sampler := NewSampler(0, true)
+	if !sampler.Sample() || !sampler.Sample() {
+		t.Error("Expected a sampler with every <= 1 to admit every event")
+	}
+	if !sampler.First("x") || sampler.First("x") || !sampler.First("y") {
+		t.Error("Expected each key to be admitted once")
+	}
+}
@@ -0,0 +1,147 @@
+package regexptable
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetPrefixDispatch enables or disables prefix dispatch, which suits tables of
+// rules that each start with literal text, such as URL paths like /api/v2/users/
+// or log prefixes. Recompile builds a radix tree from the literal prefix of each
+// pattern, and a lookup walks the input down the tree to find the few patterns
+// whose prefixes it starts with. Only those candidates are matched, each on its
+// own, and the first in table order that matches wins, as it would in the union.
+//
+// Dispatch needs a table anchored at the start and an engine that implements
+// LiteralAnalyzer, and applies only when every pattern has a non-empty,
+// case-sensitive literal prefix; otherwise lookups use the union as usual. Like
+// SetTwoPhaseLookup, which it takes precedence over, it applies to the string
+// lookups, Lookup, LookupResult and the methods built on them, and requires
+// compiled regexps that implement IndexMatcher. Consider
+// SetPrecompileIndividuals too, so that candidates are not compiled on first use.
+func (rt *RegexpTable[T]) SetPrefixDispatch(enabled bool) {
+	if rt.prefixDispatch != enabled {
+		rt.prefixDispatch = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// PrefixDispatching reports whether lookups use prefix dispatch, which depends on
+// the patterns as well as on SetPrefixDispatch. It is only meaningful once the
+// table has been compiled.
+func (rt *RegexpTable[T]) PrefixDispatching() bool {
+	return rt.dispatch != nil
+}
+
+// radixNode is a node of the prefix dispatch tree. The path from the root to a
+// node spells out a string, and entries holds the positions in the table of the
+// entries whose literal prefix is exactly that string.
+type radixNode struct {
+	label    string       // The text on the edge leading to the node
+	children []*radixNode // Ordered by the first byte of their labels, which differ
+	entries  []int
+}
+
+// insert adds the entry at the given position below n, where prefix is the part
+// of the entry's literal prefix that follows the text n spells out.
+func (n *radixNode) insert(prefix string, position int) {
+	for {
+		if prefix == "" {
+			n.entries = append(n.entries, position)
+			return
+		}
+		i, found := slices.BinarySearchFunc(n.children, prefix[0], func(child *radixNode, b byte) int {
+			return int(child.label[0]) - int(b)
+		})
+		if !found {
+			n.children = slices.Insert(n.children, i, &radixNode{label: prefix, entries: []int{position}})
+			return
+		}
+		child := n.children[i]
+		common := commonPrefixLength(child.label, prefix)
+		if common < len(child.label) {
+			// Split the edge where the prefix leaves it.
+			split := &radixNode{label: child.label[:common], children: []*radixNode{child}}
+			child.label = child.label[common:]
+			n.children[i] = split
+			child = split
+		}
+		n, prefix = child, prefix[common:]
+	}
+}
+
+// commonPrefixLength returns the length of the longest common prefix of a and b.
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// candidates returns the positions of the entries whose literal prefixes the
+// input starts with, in table order.
+func (n *radixNode) candidates(input string) []int {
+	var positions []int
+	for {
+		positions = append(positions, n.entries...)
+		i, found := slices.BinarySearchFunc(n.children, input, func(child *radixNode, s string) int {
+			if s == "" {
+				return 1
+			}
+			return int(child.label[0]) - int(s[0])
+		})
+		if !found || !strings.HasPrefix(input, n.children[i].label) {
+			break
+		}
+		n, input = n.children[i], input[len(n.children[i].label):]
+	}
+	slices.Sort(positions)
+	return positions
+}
+
+// newPrefixDispatch builds the dispatch tree for the table's entries, or returns
+// nil if the table does not qualify for prefix dispatch.
+func (rt *RegexpTable[T]) newPrefixDispatch() *radixNode {
+	analyzer, ok := rt.engine.(LiteralAnalyzer)
+	if !ok || !rt.anchorStart || len(rt.maplets) == 0 {
+		return nil
+	}
+	root := &radixNode{}
+	for i, entry := range rt.maplets {
+		prefix, _, ok := analyzer.LiteralPrefix(rt.effectivePattern(entry))
+		// Go matches invalid UTF-8 in the input as U+FFFD, which a comparison of
+		// bytes would not, so the prefix stops short of any U+FFFD.
+		if cut := strings.IndexRune(prefix, utf8.RuneError); cut >= 0 {
+			prefix = prefix[:cut]
+		}
+		if !ok || prefix == "" {
+			return nil
+		}
+		root.insert(prefix, i)
+	}
+	return root
+}
+
+// locateDispatch finds the winning entry among the candidates that the dispatch
+// tree selects for the input.
+func (rt *RegexpTable[T]) locateDispatch(input string) (located[T], error) {
+	positions := rt.dispatch.candidates(input)
+	candidates := make([]*ValueAndPattern[T], len(positions))
+	for i, position := range positions {
+		candidates[i] = rt.maplets[position]
+	}
+	entry, loc, err := rt.leftmostIndexes(input, candidates, rt.individualRegexp)
+	if err != nil {
+		return located[T]{}, err
+	}
+	return indexed(entry, input, loc), nil
+}
+
+// WithPrefixDispatch requests that the built table dispatches lookups on the
+// literal prefixes of its patterns. See RegexpTable.SetPrefixDispatch.
+func (b *RegexpTableBuilder[T]) WithPrefixDispatch(enabled bool) *RegexpTableBuilder[T] {
+	b.prefixDispatch = enabled
+	return b
+}
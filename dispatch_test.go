@@ -0,0 +1,103 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRadixNode_Candidates(t *testing.T) {
+	root := &radixNode{}
+	for i, prefix := range []string{"/api/v2/users/", "/api/v1/", "/api/", "/static/", "/api/v2/"} {
+		root.insert(prefix, i)
+	}
+
+	testCases := []struct {
+		input string
+		want  []int
+	}{
+		{"/api/v2/users/42", []int{0, 2, 4}},
+		{"/api/v1/users/42", []int{1, 2}},
+		{"/api/v3", []int{2}},
+		{"/static/app.js", []int{3}},
+		{"/ap", nil},
+		{"", nil},
+		{"/other", nil},
+	}
+	for _, tc := range testCases {
+		if got := root.candidates(tc.input); !slices.Equal(got, tc.want) {
+			t.Errorf("Expected candidates %v for %q, got %v", tc.want, tc.input, got)
+		}
+	}
+}
+
+func TestRegexpTable_PrefixDispatch(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		WithPrefixDispatch(true).
+		AddPattern(`/api/v2/users/(\d+)`, "user").
+		AddPattern(`/api/v2/.*`, "v2").
+		AddPattern(`/api/.*`, "api").
+		AddPattern(`/static/[\w.]+`, "static").
+		AddPattern(`(?:/health|/healthz)`, "health"). // Go factors out the /health prefix
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !table.PrefixDispatching() {
+		t.Fatal("Expected the table to dispatch on prefixes")
+	}
+
+	testCases := []struct {
+		input, value, group string
+	}{
+		{"/api/v2/users/42", "user", "42"},
+		{"/api/v2/users/me", "v2", ""},
+		{"/api/v1/users/42", "api", ""},
+		{"/static/app.js", "static", ""},
+		{"/healthz", "health", ""},
+	}
+	for _, tc := range testCases {
+		value, matches, err := table.Lookup(tc.input)
+		if err != nil || value != tc.value {
+			t.Errorf("Expected %s for %q, got %q, %v", tc.value, tc.input, value, err)
+			continue
+		}
+		if tc.group != "" && (len(matches) < 2 || matches[1] != tc.group) {
+			t.Errorf("Expected group %q for %q, got %q", tc.group, tc.input, matches)
+		}
+	}
+	if _, _, err := table.Lookup("/other"); err != ErrNoMatch {
+		t.Errorf("Expected no match, got %v", err)
+	}
+
+	table.RemovePattern(`/api/v2/.*`)
+	if value, _, _ := table.Lookup("/api/v2/users/me"); value != "api" {
+		t.Errorf("Expected api after removing the v2 rule, got %q", value)
+	}
+}
+
+func TestRegexpTable_PrefixDispatchFallback(t *testing.T) {
+	testCases := []struct {
+		name        string
+		anchorStart bool
+		patterns    []string
+	}{
+		{"unanchored", false, []string{`/api/.*`}},
+		{"no prefix", true, []string{`/api/.*`, `[a-z]+`}},
+		{"case-insensitive prefix", true, []string{`(?i)/api/.*`}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table := NewRegexpTable[string](tc.anchorStart, false)
+			table.SetPrefixDispatch(true)
+			for _, pattern := range tc.patterns {
+				table.AddPattern(pattern, pattern)
+			}
+			if value, _, err := table.Lookup("/api/x"); err != nil || value != tc.patterns[0] {
+				t.Errorf("Expected %s, got %q, %v", tc.patterns[0], value, err)
+			}
+			if table.PrefixDispatching() {
+				t.Error("Expected the table to fall back to the union")
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package regexptable
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DocFormat selects the output of Spec.GenerateDocs.
+type DocFormat int
+
+const (
+	// DocMarkdown writes the catalogue as a Markdown document.
+	DocMarkdown DocFormat = iota
+
+	// DocHTML writes the catalogue as a standalone HTML page.
+	DocHTML
+)
+
+// String returns the name of the format.
+func (f DocFormat) String() string {
+	switch f {
+	case DocMarkdown:
+		return "markdown"
+	case DocHTML:
+		return "html"
+	default:
+		return "unknown"
+	}
+}
+
+// docExamples is the number of examples generated for entries without tests.
+const docExamples = 3
+
+// docRule holds what the catalogue says about one entry of a spec, rendered as
+// text but not yet escaped for the output format.
+type docRule struct {
+	pattern    string
+	value      string
+	priority   int
+	confidence float64
+	flags      string
+	tags       []string
+	groups     []string // Typed groups, as name=type
+	doc        string
+	examples   []string // Inputs the rule classifies
+	rejects    []string // Inputs the rule must not classify
+	sampled    bool     // Whether the examples were generated rather than taken from tests
+}
+
+// GenerateDocs writes a human-readable catalogue of the spec's rules, so that
+// operators can read the rules from the same source the runtime table is built
+// from. It lists the spec's name, description, anchoring and engine, followed by
+// each entry in order with its pattern, value, priority, confidence, flags, tags,
+// group types and doc.
+// The inputs of an entry's tests are listed as examples and counterexamples; an
+// entry without tests is illustrated by examples generated from its pattern
+// instead, see SpecEntry.Examples. Values are shown as compact JSON.
+func (s *Spec) GenerateDocs(w io.Writer, format DocFormat) error {
+	if format != DocMarkdown && format != DocHTML {
+		return codeErrorf(CodeInvalidArgument, "unknown documentation format %d", format)
+	}
+	rules := make([]docRule, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		rules = append(rules, newDocRule(&entry))
+	}
+	title := s.Name
+	if title == "" {
+		title = "Rules"
+	}
+	engine := s.Engine
+	if engine == "" {
+		engine = engineName(NewStandardRegexpEngine())
+	}
+	summary := fmt.Sprintf("Rules: %d. Anchoring: %s. Engine: %s.", len(rules), NewAnchoring(s.AnchorStart, s.AnchorEnd), engine)
+
+	out := bufio.NewWriter(w)
+	if format == DocHTML {
+		writeHTMLDocs(out, title, s.Description, summary, rules)
+	} else {
+		writeMarkdownDocs(out, title, s.Description, summary, rules)
+	}
+	return out.Flush()
+}
+
+// newDocRule gathers what the catalogue says about an entry.
+func newDocRule(entry *SpecEntry) docRule {
+	rule := docRule{
+		pattern:    entry.Pattern,
+		value:      string(entry.Value),
+		priority:   entry.Priority,
+		confidence: entry.Confidence,
+		flags:      entry.Flags,
+		tags:       entry.Tags,
+		doc:        strings.TrimSpace(entry.Doc),
+	}
+	for _, name := range slices.Sorted(maps.Keys(entry.Groups)) {
+		rule.groups = append(rule.groups, name+"="+string(entry.Groups[name]))
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, entry.Value) == nil {
+		rule.value = compact.String()
+	}
+	for _, test := range entry.Tests {
+		if test.Reject {
+			rule.rejects = append(rule.rejects, test.Input)
+		} else {
+			rule.examples = append(rule.examples, test.Input)
+		}
+	}
+	if len(entry.Tests) == 0 {
+		rule.examples = entry.Examples(docExamples)
+		rule.sampled = true
+	}
+	return rule
+}
+
+// docInput renders an example input as it appears in the catalogue, quoted as a
+// Go string if it contains control characters such as newlines.
+func docInput(input string) string {
+	if input == "" || strings.ContainsFunc(input, unicode.IsControl) {
+		return strconv.Quote(input)
+	}
+	return input
+}
+
+// writeMarkdownDocs writes the catalogue in Markdown.
+func writeMarkdownDocs(out io.Writer, title, description, summary string, rules []docRule) {
+	fmt.Fprintf(out, "# %s\n\n", title)
+	if description != "" {
+		fmt.Fprintf(out, "%s\n\n", description)
+	}
+	fmt.Fprintf(out, "%s\n", summary)
+	for i, rule := range rules {
+		fmt.Fprintf(out, "\n## Rule %d\n\n", i)
+		if rule.doc != "" {
+			fmt.Fprintf(out, "%s\n\n", rule.doc)
+		}
+		fmt.Fprintf(out, "- **Pattern:** %s\n", markdownCode(rule.pattern))
+		fmt.Fprintf(out, "- **Value:** %s\n", markdownCode(rule.value))
+		if rule.priority != 0 {
+			fmt.Fprintf(out, "- **Priority:** %d\n", rule.priority)
+		}
+		if rule.confidence != 0 {
+			fmt.Fprintf(out, "- **Confidence:** %v\n", rule.confidence)
+		}
+		if rule.flags != "" {
+			fmt.Fprintf(out, "- **Flags:** %s\n", markdownCode(rule.flags))
+		}
+		if len(rule.tags) > 0 {
+			fmt.Fprintf(out, "- **Tags:** %s\n", markdownCodes(rule.tags))
+		}
+		if len(rule.groups) > 0 {
+			fmt.Fprintf(out, "- **Group types:** %s\n", markdownCodes(rule.groups))
+		}
+		label := "Examples"
+		if rule.sampled {
+			label = "Generated examples"
+		}
+		if len(rule.examples) > 0 {
+			fmt.Fprintf(out, "- **%s:** %s\n", label, markdownCodes(rule.examples))
+		}
+		if len(rule.rejects) > 0 {
+			fmt.Fprintf(out, "- **Counterexamples:** %s\n", markdownCodes(rule.rejects))
+		}
+	}
+}
+
+// markdownCodes renders example inputs or tags as a comma-separated list of code
+// spans.
+func markdownCodes(texts []string) string {
+	codes := make([]string, len(texts))
+	for i, text := range texts {
+		codes[i] = markdownCode(docInput(text))
+	}
+	return strings.Join(codes, ", ")
+}
+
+// markdownCode renders text as a Markdown code span, delimited by a run of
+// backquotes longer than any in the text and padded with spaces where the text
+// starts or ends with a backquote.
+func markdownCode(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+// writeHTMLDocs writes the catalogue as an HTML page.
+func writeHTMLDocs(out io.Writer, title, description, summary string, rules []docRule) {
+	fmt.Fprintf(out, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+	fmt.Fprintf(out, "<h1>%s</h1>\n", html.EscapeString(title))
+	if description != "" {
+		fmt.Fprintf(out, "<p>%s</p>\n", html.EscapeString(description))
+	}
+	fmt.Fprintf(out, "<p>%s</p>\n", html.EscapeString(summary))
+	for i, rule := range rules {
+		fmt.Fprintf(out, "<section id=\"rule-%d\">\n<h2>Rule %d</h2>\n", i, i)
+		if rule.doc != "" {
+			fmt.Fprintf(out, "<p>%s</p>\n", html.EscapeString(rule.doc))
+		}
+		fmt.Fprintln(out, "<dl>")
+		fmt.Fprintf(out, "<dt>Pattern</dt><dd><code>%s</code></dd>\n", html.EscapeString(rule.pattern))
+		fmt.Fprintf(out, "<dt>Value</dt><dd><code>%s</code></dd>\n", html.EscapeString(rule.value))
+		if rule.priority != 0 {
+			fmt.Fprintf(out, "<dt>Priority</dt><dd>%d</dd>\n", rule.priority)
+		}
+		if rule.confidence != 0 {
+			fmt.Fprintf(out, "<dt>Confidence</dt><dd>%v</dd>\n", rule.confidence)
+		}
+		if rule.flags != "" {
+			fmt.Fprintf(out, "<dt>Flags</dt><dd><code>%s</code></dd>\n", html.EscapeString(rule.flags))
+		}
+		if len(rule.tags) > 0 {
+			fmt.Fprintf(out, "<dt>Tags</dt><dd>%s</dd>\n", htmlCodes(rule.tags))
+		}
+		if len(rule.groups) > 0 {
+			fmt.Fprintf(out, "<dt>Group types</dt><dd>%s</dd>\n", htmlCodes(rule.groups))
+		}
+		label := "Examples"
+		if rule.sampled {
+			label = "Generated examples"
+		}
+		if len(rule.examples) > 0 {
+			fmt.Fprintf(out, "<dt>%s</dt><dd>%s</dd>\n", label, htmlCodes(rule.examples))
+		}
+		if len(rule.rejects) > 0 {
+			fmt.Fprintf(out, "<dt>Counterexamples</dt><dd>%s</dd>\n", htmlCodes(rule.rejects))
+		}
+		fmt.Fprintln(out, "</dl>\n</section>")
+	}
+	fmt.Fprintln(out, "</body>\n</html>")
+}
+
+// htmlCodes renders example inputs or tags as a comma-separated list of escaped
+// code elements.
+func htmlCodes(texts []string) string {
+	codes := make([]string, len(texts))
+	for i, text := range texts {
+		codes[i] = "<code>" + html.EscapeString(docInput(text)) + "</code>"
+	}
+	return strings.Join(codes, ", ")
+}
@@ -0,0 +1,96 @@
+package regexptable
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func docsTestSpec() *Spec {
+	return &Spec{
+		Version:     SpecVersion,
+		Name:        "HTTP statuses",
+		Description: "Classifies status lines.",
+		AnchorStart: true,
+		AnchorEnd:   true,
+		Entries: []SpecEntry{
+			{
+				Pattern:  `5\d\d`,
+				Value:    json.RawMessage(`{ "class": "server error" }`),
+				Priority: 2,
+				Tags:     []string{"alert"},
+				Doc:      "Server errors <page someone>.",
+				Tests:    []SpecTest{{Input: "503"}, {Input: "404", Reject: true}},
+			},
+			{Pattern: "a`b", Value: json.RawMessage(`"tick"`), Flags: "i"},
+		},
+	}
+}
+
+func TestSpec_GenerateDocsMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := docsTestSpec().GenerateDocs(&out, DocMarkdown); err != nil {
+		t.Fatalf("GenerateDocs failed: %v", err)
+	}
+	docs := out.String()
+	for _, want := range []string{
+		"# HTTP statuses\n\nClassifies status lines.\n",
+		"Rules: 2. Anchoring: AnchorBoth. Engine: go-regexp.",
+		"## Rule 0\n\nServer errors <page someone>.\n\n- **Pattern:** `5\\d\\d`\n",
+		"- **Value:** `{\"class\":\"server error\"}`\n- **Priority:** 2\n- **Tags:** `alert`\n",
+		"- **Examples:** `503`\n- **Counterexamples:** `404`\n",
+		"- **Pattern:** ``a`b``\n",
+		"- **Flags:** `i`\n",
+		"- **Generated examples:** ``A`B``, ",
+	} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("Expected the docs to contain %q, got:\n%s", want, docs)
+		}
+	}
+}
+
+func TestSpec_GenerateDocsHTML(t *testing.T) {
+	var out bytes.Buffer
+	if err := docsTestSpec().GenerateDocs(&out, DocHTML); err != nil {
+		t.Fatalf("GenerateDocs failed: %v", err)
+	}
+	docs := out.String()
+	for _, want := range []string{
+		"<title>HTTP statuses</title>",
+		`<section id="rule-0">`,
+		"<p>Server errors &lt;page someone&gt;.</p>",
+		"<dt>Value</dt><dd><code>{&#34;class&#34;:&#34;server error&#34;}</code></dd>",
+		"<dt>Counterexamples</dt><dd><code>404</code></dd>",
+	} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("Expected the docs to contain %q, got:\n%s", want, docs)
+		}
+	}
+	if strings.Contains(docs, "<page") {
+		t.Error("Expected the doc text to be escaped")
+	}
+}
+
+func TestSpec_GenerateDocsUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	if err := docsTestSpec().GenerateDocs(&out, DocFormat(7)); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected an invalid argument error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing to be written, got %q", out.String())
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	testCases := []struct{ text, want string }{
+		{`\d+`, "`\\d+`"},
+		{"a``b", "```a``b```"},
+		{"`x", "`` `x ``"},
+	}
+	for _, tc := range testCases {
+		if got := markdownCode(tc.text); got != tc.want {
+			t.Errorf("Expected %s for %q, got %s", tc.want, tc.text, got)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+// Package enginetest checks that a regexptable.RegexpEngine behaves as tables
+// rely on, for the authors of adapters for other regexp engines. It is kept
+// separate from the main package so that programs using tables do not link in
+// the testing package.
+//
+// An adapter's tests call Run once with the engine:
+//
+//	func TestConformance(t *testing.T) {
+//		enginetest.Run(t, myengine.New())
+//	}
+//
+// Each area of behaviour is a subtest, so a failure names what is wrong, such as
+// SubexpNames leaving out the full match or an engine reporting offsets in runes
+// rather than bytes. The optional interfaces, such as IndexMatcher and
+// FlagFormatter, are checked if the engine implements them and skipped if not.
+// Patterns are kept to syntax that Perl, Go, .NET and most of their relatives
+// share, and named groups are always written with FormatNamedGroup.
+//
+// Some differences between engines are allowed because tables do not depend on
+// them, and are not checked: whether $ also matches before a final newline,
+// which characters \w, \d and \s match beyond ASCII, and how long matching
+// takes.
+package enginetest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// Run checks the engine against everything tables expect of it, as a subtest for
+// each area of behaviour.
+func Run(t *testing.T, engine regexptable.RegexpEngine) {
+	t.Helper()
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			r := &report{engine: engine}
+			c.run(r)
+			for _, problem := range r.problems {
+				t.Error(problem)
+			}
+			if r.skipped != "" {
+				t.Skip(r.skipped)
+			}
+		})
+	}
+}
+
+// check is one area of behaviour that Run tests.
+type check struct {
+	name string
+	run  func(r *report)
+}
+
+// checks are the areas of behaviour that Run tests, in order.
+var checks = []check{
+	{"Compile", checkCompile},
+	{"SubexpNames", checkSubexpNames},
+	{"NamedGroups", checkNamedGroups},
+	{"Submatches", checkSubmatches},
+	{"LeftmostFirst", checkLeftmostFirst},
+	{"Anchoring", checkAnchoring},
+	{"EmptyMatches", checkEmptyMatches},
+	{"Unicode", checkUnicode},
+	{"IndexMatcher", checkIndexMatcher},
+	{"FlagFormatter", checkFlagFormatter},
+	{"Backreferencer", checkBackreferencer},
+	{"CaptureStripper", checkCaptureStripper},
+	{"Table", checkTable},
+}
+
+// report collects the problems a check finds with an engine.
+type report struct {
+	engine   regexptable.RegexpEngine
+	problems []string
+	skipped  string // Why the check does not apply to the engine, if it does not
+}
+
+// errorf records a problem.
+func (r *report) errorf(format string, args ...any) {
+	r.problems = append(r.problems, fmt.Sprintf(format, args...))
+}
+
+// skip records that the check does not apply to the engine.
+func (r *report) skip(reason string) {
+	r.skipped = reason
+}
+
+// compile compiles a pattern, recording a problem and returning nil if it fails.
+func (r *report) compile(pattern string) regexptable.CompiledRegexp {
+	compiled, err := r.engine.Compile(pattern)
+	if err != nil {
+		r.errorf("Expected pattern %q to compile, got %v", pattern, err)
+		return nil
+	}
+	if compiled == nil {
+		r.errorf("Expected a compiled regexp for pattern %q, got nil", pattern)
+	}
+	return compiled
+}
+
+// expectNames records a problem if the pattern's SubexpNames differ from want.
+func (r *report) expectNames(pattern string, want []string) {
+	compiled := r.compile(pattern)
+	if compiled == nil {
+		return
+	}
+	if names := compiled.SubexpNames(); !slices.Equal(names, want) {
+		r.errorf("Pattern %q: expected SubexpNames %q, got %q", pattern, want, names)
+	}
+}
+
+// expectMatch records a problem if matching the pattern against the input does
+// not give the submatches want, where nil means no match.
+func (r *report) expectMatch(pattern, input string, want []string) {
+	compiled := r.compile(pattern)
+	if compiled == nil {
+		return
+	}
+	r.expectSubmatches(compiled, pattern, input, want)
+}
+
+// expectSubmatches is like expectMatch for a pattern that is already compiled.
+func (r *report) expectSubmatches(compiled regexptable.CompiledRegexp, pattern, input string, want []string) {
+	matches := compiled.FindStringSubmatch(input)
+	switch {
+	case want == nil && matches != nil:
+		r.errorf("Pattern %q on %q: expected no match, got %q", pattern, input, matches)
+	case want != nil && matches == nil:
+		r.errorf("Pattern %q on %q: expected submatches %q, got no match", pattern, input, want)
+	case !slices.Equal(matches, want):
+		r.errorf("Pattern %q on %q: expected submatches %q, got %q", pattern, input, want, matches)
+	}
+}
+
+// positional reports whether the engine has no named groups, see
+// regexptable.PositionalGrouper.
+func (r *report) positional() bool {
+	grouper, ok := r.engine.(regexptable.PositionalGrouper)
+	return ok && grouper.PositionalGroups()
+}
+
+// checkCompile checks that valid patterns compile and invalid ones do not.
+func checkCompile(r *report) {
+	r.compile(`abc`)
+	r.compile(``)
+	for _, pattern := range []string{`(`, `a)`, `[a`} {
+		if _, err := r.engine.Compile(pattern); err == nil {
+			r.errorf("Expected an error compiling invalid pattern %q, got none", pattern)
+		}
+	}
+}
+
+// checkSubexpNames checks that capture groups are counted and ordered as tables
+// expect: the full match first, then each group in the order it opens, leaving
+// out non-capturing groups and escaped or bracketed parentheses.
+func checkSubexpNames(r *report) {
+	r.expectNames(`abc`, []string{""})
+	r.expectNames(`(a)`, []string{"", ""})
+	r.expectNames(`(a)(?:b)((c)d)`, []string{"", "", "", ""})
+	r.expectNames(`\(a\)[()]`, []string{""})
+	r.expectNames(`(a)|(b)`, []string{"", "", ""})
+}
+
+// checkNamedGroups checks that FormatNamedGroup makes named groups that
+// SubexpNames reports, including the names tables give their own groups.
+func checkNamedGroups(r *report) {
+	if r.positional() {
+		r.skip("the engine has positional groups only")
+		return
+	}
+	named := r.engine.FormatNamedGroup
+	pattern := named("key", "[a-z]+") + "=(" + named("value", "[0-9]+") + ")?"
+	r.expectNames(pattern, []string{"", "key", "", "value"})
+	r.expectMatch(pattern, "k=1", []string{"k=1", "k", "1", "1"})
+	r.expectNames(named("outer", "a"+named("inner", "b")), []string{"", "outer", "inner"})
+
+	// The names a table gives the groups it wraps around each pattern.
+	groupName := func(i int) string { return fmt.Sprintf("__REGEXPTABLE_%d__", i) }
+	if namer, ok := r.engine.(regexptable.GroupNamer); ok {
+		groupName = namer.GroupName
+		if groupName(1) == groupName(2) {
+			r.errorf("Expected distinct group names for distinct patterns, got %q twice", groupName(1))
+		}
+	}
+	union := named(groupName(1), "a") + "|" + named(groupName(2), "b")
+	r.expectNames(union, []string{"", groupName(1), groupName(2)})
+	r.expectMatch(union, "b", []string{"b", "", "b"})
+}
+
+// checkSubmatches checks that FindStringSubmatch reports the full match and a
+// submatch for every group, with "" for groups that did not participate.
+func checkSubmatches(r *report) {
+	r.expectMatch(`b`, "abc", []string{"b"})
+	r.expectMatch(`x`, "abc", nil)
+	r.expectMatch(`(a)(?:b)((c)d)`, "xabcd", []string{"abcd", "a", "cd", "c"})
+	r.expectMatch(`(a)|(b)`, "b", []string{"b", "", "b"})
+	r.expectMatch(`(x)?y`, "y", []string{"y", ""})
+	r.expectMatch(`(a)+`, "aaa", []string{"aaa", "a"})
+	r.expectMatch(`a+`, "aaa", []string{"aaa"})
+	r.expectMatch(`a+?`, "aaa", []string{"a"})
+}
+
+// checkLeftmostFirst checks that the engine picks the leftmost match and, among
+// those, the first alternative that matches, as Perl does. A union of patterns
+// relies on this to give a match to the first pattern in the table; engines with
+// POSIX leftmost-longest semantics fail.
+func checkLeftmostFirst(r *report) {
+	r.expectMatch(`a|ab`, "ab", []string{"a"})
+	r.expectMatch(`ab|a`, "ab", []string{"ab"})
+	r.expectMatch(`b|a`, "ab", []string{"a"})
+	r.expectMatch(`(a)|(ab)`, "ab", []string{"a", "a", ""})
+}
+
+// checkAnchoring checks patterns anchored with regexptable.AnchorPattern, as
+// tables anchor their unions, and that ^ only matches at the start of the input.
+func checkAnchoring(r *report) {
+	want := map[regexptable.Anchoring][]string{
+		// Matches of a|ab in ab, xab and abx.
+		regexptable.AnchorNone:  {"a", "a", "a"},
+		regexptable.AnchorStart: {"a", "", "a"},
+		regexptable.AnchorEnd:   {"ab", "ab", ""},
+		regexptable.AnchorBoth:  {"ab", "", ""},
+	}
+	for _, anchoring := range []regexptable.Anchoring{regexptable.AnchorNone, regexptable.AnchorStart, regexptable.AnchorEnd, regexptable.AnchorBoth} {
+		pattern := regexptable.AnchorPattern(`a|ab`, anchoring)
+		compiled := r.compile(pattern)
+		if compiled == nil {
+			continue
+		}
+		for i, input := range []string{"ab", "xab", "abx"} {
+			var match []string
+			if text := want[anchoring][i]; text != "" {
+				match = []string{text}
+			}
+			r.expectSubmatches(compiled, pattern, input, match)
+		}
+	}
+	r.expectMatch(`^a`, "x\na", nil)
+}
+
+// checkEmptyMatches checks that an empty match is reported as a match, which
+// tables must tell apart from no match at all.
+func checkEmptyMatches(r *report) {
+	r.expectMatch(``, "", []string{""})
+	r.expectMatch(`x*`, "abc", []string{""})
+	r.expectMatch(`a|`, "b", []string{""})
+	r.expectMatch(`(x*)`, "", []string{"", ""})
+	r.expectMatch(`(x*)y`, "y", []string{"y", ""})
+	r.expectMatch(`^$`, "", []string{""})
+}
+
+// checkUnicode checks that the engine matches runes rather than bytes.
+func checkUnicode(r *report) {
+	r.expectMatch(`.`, "é", []string{"é"})
+	r.expectMatch(`^.$`, "😀", []string{"😀"})
+	r.expectMatch(`(.)(.)`, "añb", []string{"añ", "a", "ñ"})
+	r.expectMatch(`[éü]+`, "aüé", []string{"üé"})
+	r.expectMatch(`日本`, "こんにちは日本", []string{"日本"})
+}
+
+// checkIndexMatcher checks that FindStringSubmatchIndex reports byte offsets,
+// with -1 for groups that did not participate, and agrees with
+// FindStringSubmatch.
+func checkIndexMatcher(r *report) {
+	probe := r.compile(`a`)
+	if probe == nil {
+		return
+	}
+	if _, ok := probe.(regexptable.IndexMatcher); !ok {
+		r.skip("the engine's compiled regexps do not implement IndexMatcher")
+		return
+	}
+	cases := []struct {
+		pattern, input string
+		want           []int
+	}{
+		{`(a)|(b)`, "éb", []int{2, 3, -1, -1, 2, 3}},
+		{`(x*)`, "é", []int{0, 0, 0, 0}},
+		{`(x)?y`, "y", []int{0, 1, -1, -1}},
+		{`(.)b`, "日本b", []int{3, 7, 3, 6}},
+		{`x`, "abc", nil},
+	}
+	for _, c := range cases {
+		compiled := r.compile(c.pattern)
+		if compiled == nil {
+			continue
+		}
+		loc := compiled.(regexptable.IndexMatcher).FindStringSubmatchIndex(c.input)
+		if !slices.Equal(loc, c.want) || (loc == nil) != (c.want == nil) {
+			r.errorf("Pattern %q on %q: expected offsets %v, got %v", c.pattern, c.input, c.want, loc)
+			continue
+		}
+		var texts []string
+		for i := 0; i < len(loc); i += 2 {
+			text := ""
+			if loc[i] >= 0 {
+				text = c.input[loc[i]:loc[i+1]]
+			}
+			texts = append(texts, text)
+		}
+		r.expectSubmatches(compiled, c.pattern, c.input, texts)
+	}
+}
+
+// checkFlagFormatter checks that FormatFlags applies each flag it accepts to the
+// pattern it is given and nothing else.
+func checkFlagFormatter(r *report) {
+	formatter, ok := r.engine.(regexptable.FlagFormatter)
+	if !ok {
+		r.skip("the engine does not implement FlagFormatter")
+		return
+	}
+	// Without flags, matching is case-sensitive, . does not match a newline, and
+	// ^ and $ match only at the ends of the input.
+	r.expectMatch(`abc`, "ABC", nil)
+	r.expectMatch(`a.b`, "a\nb", nil)
+	r.expectMatch(`^b`, "a\nb", nil)
+	cases := []struct {
+		flags, pattern, input string
+		want                  []string
+	}{
+		{"i", `abc`, "xAbC", []string{"AbC"}},
+		{"s", `a.b`, "a\nb", []string{"a\nb"}},
+		{"m", `^b$`, "a\nb\nc", []string{"b"}},
+		{"U", `a+`, "aaa", []string{"a"}},
+	}
+	for _, c := range cases {
+		pattern, ok := formatter.FormatFlags(c.flags, c.pattern)
+		if !ok {
+			continue
+		}
+		r.expectMatch(pattern, c.input, c.want)
+	}
+	if pattern, ok := formatter.FormatFlags("i", "a"); ok {
+		r.expectMatch(pattern+"b", "AB", nil)
+		r.expectNames(pattern, []string{""})
+	}
+}
+
+// checkBackreferencer checks that FormatBackreference refers to the group with
+// the given number.
+func checkBackreferencer(r *report) {
+	referencer, ok := r.engine.(regexptable.Backreferencer)
+	if !ok {
+		r.skip("the engine does not implement Backreferencer")
+		return
+	}
+	pattern := `(a|b)(c)` + referencer.FormatBackreference(1) + referencer.FormatBackreference(2)
+	r.expectMatch(pattern, "xbcbc", []string{"bcbc", "b", "c"})
+	r.expectMatch(pattern, "bcac", nil)
+}
+
+// checkCaptureStripper checks that StripCaptures removes every capture group
+// without changing what the pattern matches.
+func checkCaptureStripper(r *report) {
+	stripper, ok := r.engine.(regexptable.CaptureStripper)
+	if !ok {
+		r.skip("the engine does not implement CaptureStripper")
+		return
+	}
+	pattern := `(a)(?:b)((c)d)|\(e\)`
+	if !r.positional() {
+		pattern += "|" + r.engine.FormatNamedGroup("f", "f")
+	}
+	stripped, err := stripper.StripCaptures(pattern)
+	if err != nil {
+		r.errorf("Expected pattern %q to be stripped, got %v", pattern, err)
+		return
+	}
+	r.expectNames(stripped, []string{""})
+	for _, input := range []string{"abcd", "x(e)", "f", "abc"} {
+		var want []string
+		if compiled := r.compile(pattern); compiled != nil {
+			if matches := compiled.FindStringSubmatch(input); matches != nil {
+				want = matches[:1]
+			}
+		}
+		r.expectMatch(stripped, input, want)
+	}
+}
+
+// checkTable checks that tables built with the engine look up as they do with
+// Go's engine.
+func checkTable(r *report) {
+	builder := regexptable.NewRegexpTableBuilderWithEngine[string](r.engine).
+		AddPattern(`[0-9]+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`abc`, "abc"). // Never wins, as word comes first
+		AddPattern(`([a-z]+)=([0-9]*)`, "pair").
+		AddPattern(`x*`, "empty")
+	if !r.positional() {
+		builder.AddPattern(r.engine.FormatNamedGroup("sign", "[-+]")+"[0-9]+", "signed")
+	}
+	table, err := builder.Build(true, true)
+	if err != nil {
+		r.errorf("Expected the table to build, got %v", err)
+		return
+	}
+	cases := []struct {
+		input, value string
+		groups       []string
+	}{
+		{"42", "number", []string{"42"}},
+		{"abc", "word", []string{"abc"}},
+		{"k=", "pair", []string{"k=", "k", ""}},
+		{"k=1", "pair", []string{"k=1", "k", "1"}},
+		{"", "empty", []string{""}},
+		{"xx", "word", []string{"xx"}},
+		{"é", "", nil},
+	}
+	if !r.positional() {
+		cases = append(cases, struct {
+			input, value string
+			groups       []string
+		}{"-1", "signed", []string{"-1", "-"}})
+	}
+	for _, c := range cases {
+		result, err := table.LookupResult(c.input)
+		switch {
+		case c.groups == nil && err == nil:
+			r.errorf("Table lookup of %q: expected no match, got %s", c.input, result.Value)
+		case c.groups == nil:
+		case err != nil:
+			r.errorf("Table lookup of %q: expected %s, got %v", c.input, c.value, err)
+		case result.Value != c.value || !slices.Equal(result.Groups, c.groups):
+			r.errorf("Table lookup of %q: expected %s with groups %q, got %s with groups %q", c.input, c.value, c.groups, result.Value, result.Groups)
+		}
+	}
+	if !r.positional() {
+		if result, err := table.LookupResult("+7"); err == nil {
+			if sign := result.Fields()["sign"]; sign != "+" {
+				r.errorf("Table lookup of %q: expected field sign to be +, got %q", "+7", sign)
+			}
+		}
+	}
+}
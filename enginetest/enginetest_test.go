@@ -0,0 +1,98 @@
+package enginetest
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// longestEngine is the standard engine with leftmost-longest semantics.
+type longestEngine struct {
+	regexptable.StandardRegexpEngine
+}
+
+func (e *longestEngine) Compile(pattern string) (regexptable.CompiledRegexp, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Longest()
+	return regexptable.NewStandardCompiledRegexp(compiled), nil
+}
+
+// unnamedEngine is the standard engine with a FormatNamedGroup that forgets the
+// name.
+type unnamedEngine struct {
+	regexptable.StandardRegexpEngine
+}
+
+func (e *unnamedEngine) FormatNamedGroup(groupName, pattern string) string {
+	return "(" + pattern + ")"
+}
+
+// backreferenceEngine is the standard engine claiming to support backreferences,
+// which Go's regexp does not.
+type backreferenceEngine struct {
+	regexptable.StandardRegexpEngine
+}
+
+func (e *backreferenceEngine) FormatBackreference(i int) string {
+	return `\` + strconv.Itoa(i)
+}
+
+// problems runs the named check against the engine.
+func problems(t *testing.T, name string, engine regexptable.RegexpEngine) *report {
+	t.Helper()
+	for _, c := range checks {
+		if c.name == name {
+			r := &report{engine: engine}
+			c.run(r)
+			return r
+		}
+	}
+	t.Fatalf("No check named %s", name)
+	return nil
+}
+
+func TestRun_StandardEngine(t *testing.T) {
+	Run(t, regexptable.NewStandardRegexpEngine())
+}
+
+func TestChecks_StandardEngine(t *testing.T) {
+	for _, c := range checks {
+		r := problems(t, c.name, regexptable.NewStandardRegexpEngine())
+		if len(r.problems) > 0 {
+			t.Errorf("Expected no problems with check %s, got %q", c.name, r.problems)
+		}
+		if r.skipped != "" && c.name != "Backreferencer" {
+			t.Errorf("Expected check %s to apply to the standard engine, got skipped: %s", c.name, r.skipped)
+		}
+	}
+}
+
+func TestChecks_Failures(t *testing.T) {
+	cases := []struct {
+		check  string
+		engine regexptable.RegexpEngine
+	}{
+		{"LeftmostFirst", &longestEngine{}},
+		{"NamedGroups", &unnamedEngine{}},
+		{"Table", &unnamedEngine{}},
+		{"Backreferencer", &backreferenceEngine{}},
+	}
+	for _, c := range cases {
+		r := problems(t, c.check, c.engine)
+		if len(r.problems) == 0 {
+			t.Errorf("Expected check %s to report problems with %T, got none", c.check, c.engine)
+		}
+	}
+}
+
+func TestChecks_Skipped(t *testing.T) {
+	r := problems(t, "Backreferencer", regexptable.NewStandardRegexpEngine())
+	if r.skipped == "" || len(r.problems) > 0 {
+		t.Errorf("Expected the check to be skipped without problems, got %q and %q", r.skipped, r.problems)
+	}
+}
@@ -0,0 +1,142 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Entry describes a pattern together with everything a builder can record about
+// it, for RegexpTableBuilder.Add. Only Pattern and Value are needed; the zero
+// value of each other field leaves that attribute unset, so that any combination
+// of attributes can be given in one call. For example:
+//
+//	builder.Add(regexptable.Entry[string]{
+//	    Pattern:  `(?P<code>[A-Z]{3})-(?P<n>\d+)`,
+//	    Value:    "ticket",
+//	    Priority: 10,
+//	    Tags:     []string{"jira"},
+//	    Flags:    "i",
+//	    Groups:   map[string]regexptable.GroupType{"n": regexptable.GroupInt},
+//	    Doc:      "A ticket reference such as ABC-123.",
+//	    Examples: []string{"ABC-123", "abc-7"},
+//	})
+type Entry[T any] struct {
+	Pattern    string
+	Value      T
+	MoreValues []T                  // Further values after Value, see AddPatternValues
+	Name       string               // A unique name for the pattern, see AddNamedPattern
+	Priority   int                  // Higher priorities take precedence, see AddPatternWithPriority
+	Tags       []string             // Labels recorded for audits, see AddPatternWithTags
+	Flags      string               // Inline flags for this pattern alone, any of i, m, s and U
+	Confidence float64              // Between 0 and 1, 0 if unset, see AddPatternWithConfidence
+	Groups     map[string]GroupType // Types of named groups, see AddTypedPattern
+	Exclusion  *Exclusion           // Matches to reject, see AddPatternExcluding
+	Schedule   *Schedule            // When the pattern is active, see AddPatternWithSchedule
+	Doc        string               // What the pattern is for, recorded for audits
+	Examples   []string             // Inputs that the pattern must classify, checked by Build
+}
+
+// Add adds a pattern with every attribute given in the entry, for patterns that
+// need more than one of the attributes that the AddPatternWithX methods set, or
+// ones that only an Entry can give: flags, a doc and examples. Flags are
+// applied with the engine's FlagFormatter, so they appear in Result.Pattern, as
+// they do for specs. The Doc is recorded with the pattern for audits, see
+// RegexpTable.ExportAudit.
+//
+// Build, and BuildTiered, check that the table classifies each of the Examples
+// by this entry and report an error listing those it does not. Examples of a
+// pattern that its schedule makes inactive at the time are not checked. Build
+// reports any other problem with the entry, as the AddPatternWithX methods do.
+func (b *RegexpTableBuilder[T]) Add(e Entry[T]) *RegexpTableBuilder[T] {
+	pattern, err := b.flagPattern(e.Pattern, e.Flags)
+	if err != nil {
+		b.errs = append(b.errs, err)
+	}
+	if e.Confidence != 0 && !(e.Confidence > 0 && e.Confidence <= 1) {
+		b.errs = append(b.errs, fmt.Errorf("pattern '%s' has confidence %v, which is not in (0, 1]", e.Pattern, e.Confidence))
+	}
+	entry := patternEntry[T]{
+		pattern:    pattern,
+		value:      e.Value,
+		moreValues: slices.Clone(e.MoreValues),
+		priority:   e.Priority,
+		tags:       slices.Clone(e.Tags),
+		name:       e.Name,
+		confidence: e.Confidence,
+		doc:        e.Doc,
+		examples:   slices.Clone(e.Examples),
+	}
+	if e.Exclusion != nil {
+		exclusion := *e.Exclusion
+		entry.exclusion = &exclusion
+	}
+	if e.Schedule != nil {
+		if err := e.Schedule.Validate(); err != nil {
+			b.errs = append(b.errs, codeErrorf(CodeInvalidArgument, "pattern '%s': %w", e.Pattern, err))
+		}
+		schedule := *e.Schedule
+		entry.schedule = &schedule
+	}
+	b.patterns = append(b.patterns, entry)
+	b.setGroupTypes(len(b.patterns)-1, e.Groups)
+	return b
+}
+
+// flagPattern applies inline flags to a pattern using the builder's engine, see
+// Entry.Flags.
+func (b *RegexpTableBuilder[T]) flagPattern(pattern, flags string) (string, error) {
+	if flags == "" {
+		return pattern, nil
+	}
+	for _, flag := range flags {
+		if !strings.ContainsRune(specFlags, flag) {
+			return pattern, fmt.Errorf("pattern '%s' has unknown flag %q", pattern, flag)
+		}
+	}
+	if err := checkModeFlags(b.engine, flags); err != nil {
+		return pattern, fmt.Errorf("pattern '%s': %w", pattern, err)
+	}
+	flagged, _ := b.engine.(FlagFormatter).FormatFlags(flags, pattern)
+	return flagged, nil
+}
+
+// checkExamples reports an error listing the examples of the entries that
+// classify does not give to the table entry added for them, added[i] being the
+// one for entries[i]. See Entry.Examples.
+func checkExamples[T any](entries []patternEntry[T], added []*ValueAndPattern[T], classify func(string) *ValueAndPattern[T]) error {
+	var errs []error
+	for i, entry := range entries {
+		if added[i].dormant {
+			continue
+		}
+		for _, example := range entry.examples {
+			if classify(example) != added[i] {
+				errs = append(errs, fmt.Errorf("pattern '%s'%s: example %q is not classified by this pattern", entry.pattern, entry.describeName(), example))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return codeErrorf(CodeCompileFailed, "failed examples: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// classify returns the entry that wins the input, or nil if none does, like a
+// lookup but without recording a hit. The caller must ensure the table has been
+// compiled.
+func (rt *RegexpTable[T]) classify(input string) *ValueAndPattern[T] {
+	input, err := rt.limitInput(input)
+	if err != nil {
+		return nil
+	}
+	if rt.normalizers != nil {
+		input, _ = rt.normalize(input)
+	}
+	found, err := rt.locateEntry(input)
+	if err != nil {
+		return nil
+	}
+	return found.entry
+}
@@ -0,0 +1,160 @@
+package regexptable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdd_Attributes(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`.*`, "other").
+		Add(Entry[string]{
+			Pattern:    `(?P<code>[A-Z]{3})-(?P<n>\d+)`,
+			Value:      "ticket",
+			MoreValues: []string{"reference"},
+			Name:       "ticket",
+			Priority:   10,
+			Tags:       []string{"jira"},
+			Flags:      "i",
+			Confidence: 0.9,
+			Groups:     map[string]GroupType{"n": GroupInt},
+			Exclusion:  &Exclusion{NotMatching: `TMP-\d+`},
+			Doc:        "A ticket reference such as ABC-123.",
+			Examples:   []string{"ABC-123", "abc-7"},
+		}).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	result, err := table.LookupResult("abc-7")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if result.Value != "ticket" || result.RuleName != "ticket" || result.Index != 1 || result.Confidence != 0.9 {
+		t.Errorf("Expected the second rule, with its name and confidence, got %+v", result)
+	}
+	if len(result.Values) != 2 || result.Values[1] != "reference" {
+		t.Errorf("Expected both values, got %q", result.Values)
+	}
+	if result.Pattern != `(?i:(?P<code>[A-Z]{3})-(?P<n>\d+))` {
+		t.Errorf("Expected the pattern with its flags, got %s", result.Pattern)
+	}
+	fields, err := result.TypedFields()
+	if err != nil || fields["n"] != 7 {
+		t.Errorf("Expected n to be the int 7, got %#v, %v", fields["n"], err)
+	}
+	if value, _, _ := table.Lookup("TMP-1"); value != "other" {
+		t.Errorf("Expected the exclusion to pass TMP-1 on, got %s", value)
+	}
+
+	var audit bytes.Buffer
+	if err := table.ExportAudit(&audit, nil); err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	if !strings.Contains(audit.String(), `"doc": "A ticket reference such as ABC-123."`) {
+		t.Errorf("Expected the doc in the audit, got %s", audit.String())
+	}
+	if strings.Count(audit.String(), `"doc"`) != 1 {
+		t.Errorf("Expected no doc for the rule without one, got %s", audit.String())
+	}
+}
+
+func TestAdd_PlainEntry(t *testing.T) {
+	table, err := NewRegexpTableBuilder[int]().
+		Add(Entry[int]{Pattern: `\d+`, Value: 1}).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	result, err := table.LookupResult("42")
+	if err != nil || result.Value != 1 || result.Pattern != `\d+` || result.Confidence != 1 {
+		t.Errorf("Expected an entry like AddPattern's, got %+v, %v", result, err)
+	}
+}
+
+func TestAdd_Examples(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().
+		WithHitStats(true, 0).
+		Add(Entry[string]{Pattern: `[a-z]+`, Value: "word", Examples: []string{"abc", "if"}}).
+		Add(Entry[string]{Pattern: `if|else`, Value: "keyword", Examples: []string{"else", "if"}})
+	_, err := builder.Build(true, true)
+	if ErrorCodeOf(err) != CodeCompileFailed {
+		t.Fatalf("Expected %s for the examples the keyword rule loses, got %v", CodeCompileFailed, err)
+	}
+	for _, example := range []string{`"else"`, `"if"`} {
+		if !strings.Contains(err.Error(), "pattern 'if|else': example "+example) {
+			t.Errorf("Expected example %s to be reported, got %v", example, err)
+		}
+	}
+	if strings.Contains(err.Error(), "abc") {
+		t.Errorf("Expected the word rule's examples to pass, got %v", err)
+	}
+
+	// With a higher priority, the keyword rule wins its examples.
+	builder = NewRegexpTableBuilder[string]().
+		WithHitStats(true, 0).
+		Add(Entry[string]{Pattern: `[a-z]+`, Value: "word", Examples: []string{"abc"}}).
+		Add(Entry[string]{Pattern: `if|else`, Value: "keyword", Priority: 1, Examples: []string{"else", "if"}})
+	table, err := builder.Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if hits := table.HitCount(0) + table.HitCount(1); hits != 0 {
+		t.Errorf("Expected checking the examples not to count as hits, got %d", hits)
+	}
+	if _, err := builder.BuildTiered(true, true); err != nil {
+		t.Errorf("Expected the examples to pass in tiers, got %v", err)
+	}
+}
+
+func TestAdd_ExamplesTiered(t *testing.T) {
+	// A higher tier wins whenever it matches, whatever the anchoring.
+	_, err := NewRegexpTableBuilder[string]().
+		Add(Entry[string]{Pattern: `x`, Value: "x", Priority: 1}).
+		Add(Entry[string]{Pattern: `[a-z]+`, Value: "word", Examples: []string{"box", "abc"}}).
+		BuildTiered(false, false)
+	if err == nil || !strings.Contains(err.Error(), `example "box"`) || strings.Contains(err.Error(), "abc") {
+		t.Errorf("Expected only the example the higher tier takes to fail, got %v", err)
+	}
+}
+
+func TestAdd_ExamplesFolded(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		WithValueFolding(true).
+		Add(Entry[string]{Pattern: `a+`, Value: "letters", Examples: []string{"aa"}}).
+		Add(Entry[string]{Pattern: `b+`, Value: "letters", Examples: []string{"bb", "ab"}}).
+		Build(true, true)
+	if err == nil || !strings.Contains(err.Error(), `example "ab"`) || strings.Contains(err.Error(), `"bb"`) {
+		t.Errorf("Expected the merged examples to be checked, got %v", err)
+	}
+}
+
+func TestAdd_Invalid(t *testing.T) {
+	cases := []struct {
+		entry Entry[string]
+		want  string
+	}{
+		{Entry[string]{Pattern: `a`, Flags: "x"}, "unknown flag 'x'"},
+		{Entry[string]{Pattern: `a`, Confidence: 2}, "not in (0, 1]"},
+		{Entry[string]{Pattern: `a`, Schedule: &Schedule{Start: 25 * time.Hour}}, "times of day"},
+		{Entry[string]{Pattern: `(?P<x>a)`, Groups: map[string]GroupType{"y": GroupInt}}, "no group named y"},
+		{Entry[string]{Pattern: `a`, Name: "1st"}, "invalid pattern name"},
+	}
+	for _, c := range cases {
+		_, err := NewRegexpTableBuilder[string]().Add(c.entry).Build(true, true)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Expected an error containing %q for %+v, got %v", c.want, c.entry, err)
+		}
+	}
+
+	engine := NewMockRegexpEngine("(?P<%s>%s)")
+	_, err := NewRegexpTableBuilderWithEngine[string](engine).
+		Add(Entry[string]{Pattern: `a`, Flags: "i"}).
+		Build(true, true)
+	if ErrorCodeOf(err) != CodeEngineUnsupported {
+		t.Errorf("Expected %s for flags the engine cannot apply, got %v", CodeEngineUnsupported, err)
+	}
+}
@@ -0,0 +1,70 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable, machine-readable name for a kind of failure, so that
+// callers can branch on failures without matching the text of error messages,
+// which may change between releases. Codes are never renamed or reused; new ones
+// may be added. ErrorCodeOf returns the code of an error.
+type ErrorCode string
+
+// The codes that errors returned by this package may carry.
+const (
+	CodeNoPatterns        ErrorCode = "REGEXPTABLE_NO_PATTERNS"        // A lookup in a table without patterns, see ErrNoPatterns
+	CodeNoMatch           ErrorCode = "REGEXPTABLE_NO_MATCH"           // A lookup that no pattern matched, see ErrNoMatch
+	CodeCompileFailed     ErrorCode = "REGEXPTABLE_COMPILE_FAILED"     // A pattern or the union was rejected while compiling
+	CodeEngineUnsupported ErrorCode = "REGEXPTABLE_ENGINE_UNSUPPORTED" // The engine lacks a feature that the table or call needs
+	CodeTableUnsupported  ErrorCode = "REGEXPTABLE_TABLE_UNSUPPORTED"  // The call does not support the table's configuration
+	CodeReDoSRisk         ErrorCode = "REGEXPTABLE_REDOS_RISK"         // A pattern risks catastrophic backtracking, see SetAllowReDoS
+	CodeInvalidArgument   ErrorCode = "REGEXPTABLE_INVALID_ARGUMENT"   // An argument is out of range or names nothing known
+	CodeInvalidSpec       ErrorCode = "REGEXPTABLE_INVALID_SPEC"       // A spec or rule table could not be parsed or is invalid
+	CodeAuditMismatch     ErrorCode = "REGEXPTABLE_AUDIT_MISMATCH"     // An audit snapshot does not match its digest
+	CodeUntranslatable    ErrorCode = "REGEXPTABLE_UNTRANSLATABLE"     // Rules could not be exported to another syntax
+	CodeInternal          ErrorCode = "REGEXPTABLE_INTERNAL"           // A bug in this package or in an engine
+	CodeInputTooLong      ErrorCode = "REGEXPTABLE_INPUT_TOO_LONG"     // An input exceeds the table's maximum length, see InputTooLongError
+)
+
+// CodedError is an error carrying an ErrorCode. Errors returned by this package
+// wrap one wherever a code applies, so it should be looked for with errors.As or
+// ErrorCodeOf rather than by comparing the error's type.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the error's code.
+func (e *CodedError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// ErrorCodeOf returns the code of the first error in err's tree that has one, in
+// the order errors.As searches it, or "" if none has. An error has a code if it
+// has an ErrorCode method returning one, as CodedError and TranslationError do.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return ""
+}
+
+// codeErrorf is like fmt.Errorf but gives the error the code, unless an error it
+// wraps already has a code, which is then more specific and kept.
+func codeErrorf(code ErrorCode, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if ErrorCodeOf(err) != "" {
+		return err
+	}
+	return &CodedError{Code: code, Err: err}
+}
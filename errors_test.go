@@ -0,0 +1,41 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	if _, _, err := table.Lookup("x"); ErrorCodeOf(err) != CodeNoPatterns {
+		t.Errorf("Expected %s, got %q for %v", CodeNoPatterns, ErrorCodeOf(err), err)
+	}
+	table.AddPattern(`a`, "a")
+	_, _, err := table.Lookup("x")
+	if ErrorCodeOf(err) != CodeNoMatch || !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected %s, got %q for %v", CodeNoMatch, ErrorCodeOf(err), err)
+	}
+
+	table.AddPattern(`(`, "bad")
+	if err := table.Recompile(); ErrorCodeOf(err) != CodeCompileFailed {
+		t.Errorf("Expected %s, got %q for %v", CodeCompileFailed, ErrorCodeOf(err), err)
+	}
+
+	_, err = NewRegexpTableBuilder[string]().AddPattern(`(a)\1`, "double").Build(true, true)
+	if ErrorCodeOf(err) != CodeEngineUnsupported {
+		t.Errorf("Expected the builder to keep the more specific %s, got %q for %v", CodeEngineUnsupported, ErrorCodeOf(err), err)
+	}
+
+	_, err = NewLoader[string](LoadStrict).LoadBytes([]byte(`{"version": 99}`))
+	if ErrorCodeOf(err) != CodeInvalidSpec {
+		t.Errorf("Expected %s, got %q for %v", CodeInvalidSpec, ErrorCodeOf(err), err)
+	}
+
+	if code := ErrorCodeOf(fmt.Errorf("wrapped: %w", &TranslationError{})); code != CodeUntranslatable {
+		t.Errorf("Expected %s, got %q", CodeUntranslatable, code)
+	}
+	if code := ErrorCodeOf(errors.New("plain")); code != "" {
+		t.Errorf("Expected no code, got %q", code)
+	}
+}
@@ -0,0 +1,140 @@
+package regexptable
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxExampleRepeat bounds how many times an unbounded repetition such as x* or x+
+// is unrolled when generating examples, keeping the examples short.
+const maxExampleRepeat = 3
+
+// Examples returns up to n distinct strings that the pattern matches in full,
+// generated by walking the parsed pattern with regexp/syntax. They are useful for
+// documenting rule files and as seeds for fuzzing. The output is deterministic for
+// a given pattern and n. Zero-width assertions such as \b are not modelled, so
+// candidates are checked against the pattern and only matching ones are kept;
+// fewer than n examples are returned when the pattern matches few strings. An
+// invalid pattern yields no examples.
+func Examples(pattern string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	check, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil
+	}
+
+	seed := fnv.New64a()
+	seed.Write([]byte(pattern))
+	g := &exampleGenerator{rng: rand.New(rand.NewPCG(seed.Sum64(), uint64(n)))}
+
+	seen := make(map[string]bool)
+	var examples []string
+	for attempt := 0; attempt < 20*n && len(examples) < n; attempt++ {
+		var sb strings.Builder
+		if !g.generate(&sb, re.Simplify()) {
+			continue
+		}
+		example := sb.String()
+		if !seen[example] && check.MatchString(example) {
+			seen[example] = true
+			examples = append(examples, example)
+		}
+	}
+	return examples
+}
+
+// exampleGenerator produces random strings from a parsed pattern.
+type exampleGenerator struct {
+	rng *rand.Rand
+}
+
+// generate appends a random string matched by re to sb, returning false if re
+// cannot match anything.
+func (g *exampleGenerator) generate(sb *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rng.IntN(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		r, ok := g.classRune(re.Rune)
+		if !ok {
+			return false
+		}
+		sb.WriteRune(r)
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		sb.WriteByte(byte('a' + g.rng.IntN(26)))
+	case syntax.OpCapture:
+		return g.generate(sb, re.Sub[0])
+	case syntax.OpStar:
+		return g.repeat(sb, re.Sub[0], 0, maxExampleRepeat)
+	case syntax.OpPlus:
+		return g.repeat(sb, re.Sub[0], 1, maxExampleRepeat)
+	case syntax.OpQuest:
+		return g.repeat(sb, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		limit := re.Max
+		if limit < 0 || limit > re.Min+maxExampleRepeat {
+			limit = re.Min + maxExampleRepeat
+		}
+		return g.repeat(sb, re.Sub[0], re.Min, limit)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !g.generate(sb, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return g.generate(sb, re.Sub[g.rng.IntN(len(re.Sub))])
+	}
+	// OpEmptyMatch and the zero-width assertions contribute no text.
+	return true
+}
+
+// repeat appends between lo and hi repetitions of re to sb.
+func (g *exampleGenerator) repeat(sb *strings.Builder, re *syntax.Regexp, lo, hi int) bool {
+	count := lo + g.rng.IntN(hi-lo+1)
+	for i := 0; i < count; i++ {
+		if !g.generate(sb, re) {
+			return false
+		}
+	}
+	return true
+}
+
+// classRune picks a rune from a character class given as sorted range pairs. It
+// prefers printable ASCII so that examples of broad classes such as [^,] stay
+// readable.
+func (g *exampleGenerator) classRune(ranges []rune) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	pair := 2 * g.rng.IntN(len(ranges)/2)
+	lo, hi := ranges[pair], ranges[pair+1]
+	return lo + rune(g.rng.IntN(int(hi-lo)+1)), true
+}
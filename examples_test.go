@@ -0,0 +1,53 @@
+package regexptable
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	patterns := []string{
+		`\d{3}-\d{4}`,
+		`[a-z]+@[a-z]+\.(com|org)`,
+		`(?i)hello`,
+		`colou?r`,
+		`\bword\b`,
+		`[^,]+`,
+		`é+`,
+		`x*`,
+		`a|b|c`,
+	}
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			examples := Examples(pattern, 5)
+			if len(examples) == 0 {
+				t.Fatal("Expected at least one example")
+			}
+			check := regexp.MustCompile(`^(?:` + pattern + `)$`)
+			for _, example := range examples {
+				if !check.MatchString(example) {
+					t.Errorf("Example %q does not match", example)
+				}
+			}
+			if !slices.Equal(examples, Examples(pattern, 5)) {
+				t.Error("Expected the examples to be deterministic")
+			}
+		})
+	}
+}
+
+func TestExamples_Limits(t *testing.T) {
+	if examples := Examples(`a|b`, 10); len(examples) != 2 {
+		t.Errorf("Expected exactly the 2 possible examples, got %v", examples)
+	}
+	if examples := Examples(`(`, 3); examples != nil {
+		t.Errorf("Expected no examples for an invalid pattern, got %v", examples)
+	}
+	if examples := Examples(`abc`, 0); examples != nil {
+		t.Errorf("Expected no examples for n=0, got %v", examples)
+	}
+	if examples := Examples(`[^\x00-\x{10FFFF}]`, 3); len(examples) != 0 {
+		t.Errorf("Expected no examples for an empty class, got %v", examples)
+	}
+}
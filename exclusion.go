@@ -0,0 +1,132 @@
+package regexptable
+
+// Exclusion describes matches that an entry must reject. Go's standard engine
+// (RE2) has no lookaround, so rules ported from PCRE that rely on negative
+// lookahead cannot be written directly. An Exclusion emulates the common idioms by
+// checking a match after the fact: a rejected match does not count and the lookup
+// carries on with the other entries, as if the entry had not matched.
+//
+// Exclusions are checked against the leftmost match of the entry only. Unlike a
+// real lookahead, a rejected match does not make the entry look for a later one,
+// which makes no difference in tables anchored at the start.
+type Exclusion struct {
+	// NotFollowedBy emulates X(?!Y): the match is rejected when this pattern
+	// matches the text immediately following it. The pattern sees only the rest
+	// of the input, so assertions such as \b at its start cannot look back.
+	NotFollowedBy string
+
+	// NotMatching emulates (?!Y$)X: the match is rejected when this pattern
+	// matches the whole of it, e.g. a list of keywords excluded from an identifier
+	// rule.
+	NotMatching string
+}
+
+// exclusion is the compiled form of an Exclusion.
+type exclusion struct {
+	spec          Exclusion      // The exclusion as it was given
+	notFollowedBy CompiledRegexp // Anchored at the start of the text after the match
+	notMatching   CompiledRegexp // Anchored at both ends of the match
+}
+
+// rejects reports whether the match input[start:end] is excluded.
+func (e *exclusion) rejects(input string, start, end int) bool {
+	if e.notFollowedBy != nil && e.notFollowedBy.FindStringSubmatch(input[end:]) != nil {
+		return true
+	}
+	return e.notMatching != nil && e.notMatching.FindStringSubmatch(input[start:end]) != nil
+}
+
+// compileExclusion compiles the patterns of an Exclusion with the table's engine.
+func (rt *RegexpTable[T]) compileExclusion(spec Exclusion) (*exclusion, error) {
+	compiled := &exclusion{spec: spec}
+	var err error
+	if spec.NotFollowedBy != "" {
+		compiled.notFollowedBy, err = rt.engine.Compile(AnchorPattern(spec.NotFollowedBy, AnchorStart))
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "invalid NotFollowedBy pattern '%s': %w", spec.NotFollowedBy, locatePatternError(spec.NotFollowedBy, err))
+		}
+	}
+	if spec.NotMatching != "" {
+		compiled.notMatching, err = rt.engine.Compile(AnchorPattern(spec.NotMatching, AnchorBoth))
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "invalid NotMatching pattern '%s': %w", spec.NotMatching, locatePatternError(spec.NotMatching, err))
+		}
+	}
+	if compiled.notFollowedBy == nil && compiled.notMatching == nil {
+		return nil, nil
+	}
+	return compiled, nil
+}
+
+// AddPatternExcluding is like AddPattern but matches of the pattern are rejected
+// when the exclusion applies. For example, an identifier rule that must not claim
+// keywords, or a number that must not be followed by a unit:
+//
+//	table.AddPatternExcluding(`[a-z]+`, "identifier", Exclusion{NotMatching: `if|else|while`})
+//	table.AddPatternExcluding(`\d+`, "count", Exclusion{NotFollowedBy: `px|em`})
+//
+// Lookups only pay for the check when an entry with an exclusion wins the union
+// match, in which case the entries are matched one at a time. This requires the
+// engine's compiled regexps to implement IndexMatcher. LookupReader cannot check
+// exclusions and reports an error when such an entry wins.
+func (rt *RegexpTable[T]) AddPatternExcluding(pattern string, value T, exclusion Exclusion) error {
+	compiled, err := rt.compileExclusion(exclusion)
+	if err != nil {
+		return err
+	}
+	err = rt.AddPattern(pattern, value)
+	if err != nil {
+		return err
+	}
+	rt.maplets[len(rt.maplets)-1].ownAttrs().exclusion = compiled
+	return nil
+}
+
+// locateExcluding finds the winning entry by matching each entry on its own and
+// skipping excluded matches. The winner is the entry with the leftmost accepted
+// match, with earlier entries winning ties, which is the choice the union makes.
+func (rt *RegexpTable[T]) locateExcluding(input string, individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (located[T], error) {
+	entry, loc, err := rt.excludingIndexes(input, individual)
+	if err != nil {
+		return located[T]{}, err
+	}
+	return indexed(entry, input, loc), nil
+}
+
+// excludingIndexes is matchExcluding returning the index pairs of the winner's
+// submatches.
+func (rt *RegexpTable[T]) excludingIndexes(input string, individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (*ValueAndPattern[T], []int, error) {
+	return rt.leftmostIndexes(input, rt.maplets, individual)
+}
+
+// leftmostIndexes is excludingIndexes restricted to the given entries, which must
+// be in table order.
+func (rt *RegexpTable[T]) leftmostIndexes(input string, entries []*ValueAndPattern[T], individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (*ValueAndPattern[T], []int, error) {
+	var best *ValueAndPattern[T]
+	var bestLoc []int
+	for _, entry := range entries {
+		compiled, err := individual(entry)
+		if err != nil {
+			continue // Skip invalid patterns (should never happen)
+		}
+		matcher, ok := compiled.(IndexMatcher)
+		if !ok {
+			return nil, nil, codeErrorf(CodeEngineUnsupported, "regexp engine does not report match offsets, see IndexMatcher")
+		}
+		loc := matcher.FindStringSubmatchIndex(input)
+		if loc == nil || (best != nil && loc[0] >= bestLoc[0]) {
+			continue
+		}
+		if entry.exclusion != nil && entry.exclusion.rejects(input, loc[0], loc[1]) {
+			continue
+		}
+		best, bestLoc = entry, loc
+	}
+	if best == nil {
+		return nil, nil, ErrNoMatch
+	}
+	if rt.nonCapturing {
+		bestLoc = bestLoc[:2]
+	}
+	return best, bestLoc, nil
+}
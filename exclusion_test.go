@@ -0,0 +1,112 @@
+package regexptable
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_ExclusionNotMatching(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPatternExcluding(`[a-z]+`, "identifier", Exclusion{NotMatching: `if|else|while`}).
+		AddPattern(`if|else|while`, "keyword").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testCases := []struct{ input, expected string }{
+		{"if", "keyword"},
+		{"while", "keyword"},
+		{"iffy", "identifier"},
+		{"x", "identifier"},
+	}
+	for _, tc := range testCases {
+		value, matches, err := table.Lookup(tc.input)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if value != tc.expected || matches[0] != tc.input {
+			t.Errorf("Lookup(%q): expected %q, got %q (%v)", tc.input, tc.expected, value, matches)
+		}
+	}
+}
+
+func TestRegexpTable_ExclusionNotFollowedBy(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	if err := table.AddPatternExcluding(`(\d+)`, "count", Exclusion{NotFollowedBy: `px|em`}); err != nil {
+		t.Fatalf("AddPatternExcluding failed: %v", err)
+	}
+	if err := table.AddPattern(`(\d+)(px|em)`, "length"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+		matches  []string
+	}{
+		{"12 apples", "count", []string{"12", "12"}},
+		{"12", "count", []string{"12", "12"}},
+		{"12px wide", "length", []string{"12px", "12", "px"}},
+		{"3em", "length", []string{"3em", "3", "em"}},
+	}
+	for _, tc := range testCases {
+		value, matches, err := table.Lookup(tc.input)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if value != tc.expected || !slices.Equal(matches, tc.matches) {
+			t.Errorf("Lookup(%q): expected (%q, %v), got (%q, %v)", tc.input, tc.expected, tc.matches, value, matches)
+		}
+	}
+
+	// The byte-oriented lookup honours exclusions too.
+	if value, matches, err := table.LookupBytes([]byte("7px")); err != nil || value != "length" || matches[0] != "7px" {
+		t.Errorf("LookupBytes: expected length, got %q, %v, %v", value, matches, err)
+	}
+
+	// All matches excluded.
+	excluded := NewRegexpTable[string](true, false)
+	if err := excluded.AddPatternExcluding(`\d+`, "count", Exclusion{NotFollowedBy: `px`}); err != nil {
+		t.Fatalf("AddPatternExcluding failed: %v", err)
+	}
+	if _, _, err := excluded.Lookup("7px"); err != ErrNoMatch {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+	if _, _, err := excluded.LookupReader(strings.NewReader("7px")); err == nil {
+		t.Error("Expected LookupReader to refuse to check an exclusion")
+	}
+}
+
+func TestRegexpTable_ExclusionUnanchored(t *testing.T) {
+	// The leftmost accepted match wins, as it would in the union.
+	table := NewRegexpTable[string](false, false)
+	if err := table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `the`}); err != nil {
+		t.Fatalf("AddPatternExcluding failed: %v", err)
+	}
+	if err := table.AddPattern(`\d+`, "number"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if value, matches, _ := table.TryLookup("the 42 cats"); value != "number" || matches[0] != "42" {
+		t.Errorf("Expected number 42, got %q, %v", value, matches)
+	}
+	if value, matches, _ := table.TryLookup("cats 42"); value != "word" || matches[0] != "cats" {
+		t.Errorf("Expected word cats, got %q, %v", value, matches)
+	}
+}
+
+func TestRegexpTable_ExclusionInvalidPattern(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	if err := table.AddPatternExcluding(`a`, "a", Exclusion{NotFollowedBy: `(`}); err == nil {
+		t.Error("Expected an error for an invalid exclusion pattern")
+	}
+	if len(table.maplets) != 0 {
+		t.Error("Expected the pattern not to be added")
+	}
+	if _, err := NewRegexpTableBuilder[string]().AddPatternExcluding(`a`, "a", Exclusion{NotMatching: `[`}).Build(true, true); err == nil {
+		t.Error("Expected Build to report the invalid exclusion pattern")
+	}
+}
@@ -0,0 +1,72 @@
+package regexptable
+
+import (
+	"time"
+)
+
+// AddPatternWithTTL is like AddPattern but the entry expires once ttl has elapsed.
+// Expired entries are swept out of the table by the first lookup after the
+// earliest expiry time has passed, and leave tombstones as RemovePattern does.
+// This suits temporary rules that must not linger in the table forever.
+func (rt *RegexpTable[T]) AddPatternWithTTL(pattern string, value T, ttl time.Duration) error {
+	err := rt.AddPattern(pattern, value)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := rt.clock().Add(ttl)
+	rt.maplets[len(rt.maplets)-1].ownAttrs().expiresAt = expiresAt
+	if rt.nextExpiry.IsZero() || expiresAt.Before(rt.nextExpiry) {
+		rt.nextExpiry = expiresAt
+	}
+	return nil
+}
+
+// SweepExpired removes all expired entries from the table and returns how many
+// were removed. Lookups sweep on demand, so calling this is only necessary to
+// release expired entries promptly on a table that is rarely used.
+func (rt *RegexpTable[T]) SweepExpired() int {
+	if rt.nextExpiry.IsZero() {
+		return 0
+	}
+
+	now := rt.clock()
+	live := rt.maplets[:0]
+	rt.nextExpiry = time.Time{}
+	var removed []*ValueAndPattern[T]
+	for _, entry := range rt.maplets {
+		if !entry.expiresAt.IsZero() {
+			if !now.Before(entry.expiresAt) {
+				removed = append(removed, entry)
+				continue
+			}
+			if rt.nextExpiry.IsZero() || entry.expiresAt.Before(rt.nextExpiry) {
+				rt.nextExpiry = entry.expiresAt
+			}
+		}
+		live = append(live, entry)
+	}
+	clear(rt.maplets[len(live):]) // Release references to the removed entries
+	rt.maplets = live
+
+	if len(removed) > 0 {
+		rt.bury(len(removed))
+	}
+	for _, entry := range removed {
+		rt.notifyMutate(ChangeRemoved, entry)
+	}
+	return len(removed)
+}
+
+// hasExpiredEntries reports whether at least one entry has passed its expiry time.
+func (rt *RegexpTable[T]) hasExpiredEntries() bool {
+	return !rt.nextExpiry.IsZero() && !rt.clock().Before(rt.nextExpiry)
+}
+
+// clock returns the current time, using the table's injected clock if any.
+func (rt *RegexpTable[T]) clock() time.Time {
+	if rt.now != nil {
+		return rt.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,92 @@
+package regexptable
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegexpTable_AddPatternWithTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	table := NewRegexpTable[string](true, true)
+	table.now = func() time.Time { return now }
+
+	if err := table.AddPattern(`permanent`, "permanent"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if err := table.AddPatternWithTTL(`short`, "short", time.Minute); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if err := table.AddPatternWithTTL(`long`, "long", time.Hour); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+
+	for _, input := range []string{"permanent", "short", "long"} {
+		if _, _, ok := table.TryLookup(input); !ok {
+			t.Errorf("Expected match for %q before expiry", input)
+		}
+	}
+
+	// After a minute only the short-lived entry has gone.
+	now = now.Add(time.Minute)
+	if _, _, ok := table.TryLookup("short"); ok {
+		t.Error("Expected 'short' to have expired")
+	}
+	if _, _, ok := table.TryLookup("long"); !ok {
+		t.Error("Expected 'long' to still be active")
+	}
+	if len(table.maplets) != 2 {
+		t.Errorf("Expected the expired entry to be removed, have %d entries", len(table.maplets))
+	}
+
+	// An explicit sweep removes the remaining timed entry.
+	now = now.Add(time.Hour)
+	if removed := table.SweepExpired(); removed != 1 {
+		t.Errorf("Expected SweepExpired to remove 1 entry, removed %d", removed)
+	}
+	if _, _, ok := table.TryLookup("long"); ok {
+		t.Error("Expected 'long' to have expired")
+	}
+	if value, _, ok := table.TryLookup("permanent"); !ok || value != "permanent" {
+		t.Error("Expected 'permanent' to remain")
+	}
+	if removed := table.SweepExpired(); removed != 0 {
+		t.Errorf("Expected nothing left to sweep, removed %d", removed)
+	}
+}
+
+func TestRegexpTable_AddPatternWithTTL_Memoized(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	table := NewRegexpTable[int](true, true)
+	table.now = func() time.Time { return now }
+	table.SetMemoization(10, false)
+	if err := table.AddPatternWithTTL(`abd`, 1, time.Minute); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if value, _, err := table.Lookup("abd"); err != nil || value != 1 {
+		t.Fatalf("Expected 1 before expiry, got %v, %v", value, err)
+	}
+
+	// Expiry empties the table, which must not leave the memoized match behind.
+	now = now.Add(time.Hour)
+	if value, matches, err := table.Lookup("abd"); err == nil {
+		t.Errorf("Expected no match after expiry, got %v %q", value, matches)
+	}
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	if value, matches, err := table.Lookup("abd"); err == nil {
+		t.Errorf("Expected no match after recompiling, got %v %q", value, matches)
+	}
+
+	// Removing the last pattern empties the table too.
+	if err := table.AddPattern(`abd`, 2); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if value, _, err := table.Lookup("abd"); err != nil || value != 2 {
+		t.Fatalf("Expected 2, got %v, %v", value, err)
+	}
+	table.RemovePattern(`abd`)
+	if value, matches, err := table.Lookup("abd"); err == nil {
+		t.Errorf("Expected no match after removing the pattern, got %v %q", value, matches)
+	}
+}
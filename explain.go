@@ -0,0 +1,117 @@
+package regexptable
+
+import (
+	"fmt"
+	"time"
+)
+
+// Explanation describes how a table classifies a single input: which rules match
+// on their own, which rule wins in the union and why. It is intended for
+// answering "why was this input classified as X" rather than for hot paths.
+type Explanation[T any] struct {
+	Input       string
+	Normalized  string // The input after normalization, which is what the rules are matched against
+	AnchorStart bool
+	AnchorEnd   bool
+	Rules       []RuleTrace[T]
+	Winner      int    // Index into Rules of the winning rule, or -1 if nothing matched
+	Reason      string // Human readable account of why the winner was chosen, numbering rules as Result.Index does
+}
+
+// RuleTrace records how a single rule behaves for the explained input.
+type RuleTrace[T any] struct {
+	Index           int // Position of the rule in the order the table tries rules
+	InsertionIndex  int // Position of the rule in insertion order, as in Result.Index
+	Pattern         string
+	Value           T
+	Matched         bool          // Whether the rule matches on its own with the table's anchoring
+	Match           string        // The full match, when Matched
+	MatchesAnywhere bool          // Whether the rule matches somewhere in the input when not anchored
+	Duration        time.Duration // Time taken to test the rule individually
+}
+
+// Explain classifies input and reports how each rule contributed to the result.
+// Every rule is tested individually, so this is considerably slower than Lookup.
+func (rt *RegexpTable[T]) Explain(input string) (*Explanation[T], error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation[T]{
+		Input:       input,
+		Normalized:  input,
+		AnchorStart: rt.anchorStart,
+		AnchorEnd:   rt.anchorEnd,
+		Winner:      -1,
+	}
+	if rt.normalizers != nil {
+		input, _ = rt.normalize(input)
+		explanation.Normalized = input
+	}
+
+	for i, entry := range rt.maplets {
+		trace := RuleTrace[T]{Index: i, InsertionIndex: entry.insertionIndex(), Pattern: entry.Pattern, Value: entry.Value}
+
+		individual, err := rt.individualRegexp(entry)
+		if err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to compile pattern '%s': %w", entry.Pattern, err)
+		}
+		start := time.Now()
+		matches := individual.FindStringSubmatch(input)
+		trace.Duration = time.Since(start)
+		if matches != nil {
+			trace.Matched = true
+			trace.Match = matches[0]
+		}
+
+		unanchored, err := rt.engine.Compile(WrapNonCapturing(rt.effectivePattern(entry)))
+		if err == nil {
+			trace.MatchesAnywhere = unanchored.FindStringSubmatch(input) != nil
+		}
+
+		explanation.Rules = append(explanation.Rules, trace)
+	}
+
+	winner, _, err := rt.matchEntry(input)
+	if err != nil {
+		explanation.Reason = rt.explainNoMatch(explanation)
+		return explanation, nil
+	}
+	for i, entry := range rt.maplets {
+		if entry == winner {
+			explanation.Winner = i
+		}
+	}
+	explanation.Reason = explainWinner(explanation)
+	return explanation, nil
+}
+
+// explainNoMatch accounts for an input that no rule classifies, pointing out
+// rules that were only excluded by anchoring.
+func (rt *RegexpTable[T]) explainNoMatch(explanation *Explanation[T]) string {
+	for _, trace := range explanation.Rules {
+		if trace.MatchesAnywhere {
+			return fmt.Sprintf("no rule matched; rule %d (%s) matches part of the input but is excluded by anchoring", trace.InsertionIndex, trace.Pattern)
+		}
+	}
+	return "no rule matched"
+}
+
+// explainWinner accounts for the choice of winner among the rules that matched.
+func explainWinner[T any](explanation *Explanation[T]) string {
+	winner := explanation.Rules[explanation.Winner]
+	for _, trace := range explanation.Rules[:explanation.Winner] {
+		if trace.Matched {
+			// An earlier rule matched on its own but lost in the union, which only
+			// happens when the winner's match starts further to the left.
+			return fmt.Sprintf("rule %d (%s) matched %q; earlier rule %d (%s) also matches but starts further right", winner.InsertionIndex, winner.Pattern, winner.Match, trace.InsertionIndex, trace.Pattern)
+		}
+	}
+	for _, trace := range explanation.Rules[explanation.Winner+1:] {
+		if trace.Matched {
+			return fmt.Sprintf("rule %d (%s) matched %q and takes precedence over later matching rules", winner.InsertionIndex, winner.Pattern, winner.Match)
+		}
+	}
+	return fmt.Sprintf("rule %d (%s) matched %q and is the only matching rule", winner.InsertionIndex, winner.Pattern, winner.Match)
+}
@@ -0,0 +1,60 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_Explain(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`if`, "keyword").
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`\d+`, "number").
+		MustBuild(true, true)
+
+	explanation, err := table.Explain("if")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Winner != 0 {
+		t.Errorf("Expected rule 0 to win, got %d", explanation.Winner)
+	}
+	if !explanation.Rules[0].Matched || !explanation.Rules[1].Matched || explanation.Rules[2].Matched {
+		t.Errorf("Unexpected individual matches: %+v", explanation.Rules)
+	}
+	if !strings.Contains(explanation.Reason, "precedence") {
+		t.Errorf("Expected the reason to mention precedence, got %q", explanation.Reason)
+	}
+
+	explanation, err = table.Explain("abc123")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Winner != -1 {
+		t.Errorf("Expected no winner, got %d", explanation.Winner)
+	}
+	if !explanation.Rules[1].MatchesAnywhere || explanation.Rules[1].Matched {
+		t.Errorf("Expected rule 1 to match only without anchoring: %+v", explanation.Rules[1])
+	}
+	if !strings.Contains(explanation.Reason, "anchoring") {
+		t.Errorf("Expected the reason to mention anchoring, got %q", explanation.Reason)
+	}
+}
+
+func TestRegexpTable_ExplainLeftmostWinner(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		MustBuild(false, false)
+
+	explanation, err := table.Explain("abc 123")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Winner != 1 {
+		t.Fatalf("Expected the leftmost match to win, got %d", explanation.Winner)
+	}
+	if !strings.Contains(explanation.Reason, "further right") {
+		t.Errorf("Expected the reason to explain the leftmost rule, got %q", explanation.Reason)
+	}
+}
@@ -0,0 +1,57 @@
+package regexptable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// EngineNamer is an optional interface that a RegexpEngine may implement to give
+// itself a stable name, as reported by RegexpTable.EngineName and recorded in
+// fingerprints, audits and specs. Engines that do not implement it are identified
+// by their Go type.
+type EngineNamer interface {
+	Name() string
+}
+
+// Name returns "go-regexp".
+func (e *StandardRegexpEngine) Name() string {
+	return "go-regexp"
+}
+
+// Fingerprint returns a hash of the anchored union pattern the table compiles and
+// the name of its engine. It changes whenever the composition of the pattern does,
+// for example when patterns are reordered or the anchoring, grouping or table
+// modes change, but not when only values change. Golden tests in consuming
+// projects can record it to catch such changes; see the regexptabletest package.
+func (rt *RegexpTable[T]) Fingerprint() string {
+	// A table that fails to compile still has a well-defined union pattern, so
+	// the compilation error does not matter here.
+	_ = rt.ensureCompiled()
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s", engineName(rt.engine), rt.anchorPattern(rt.unionPattern))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Engine returns the regexp engine the table compiles its patterns with, so that
+// wrappers can tell which engine's semantics apply.
+func (rt *RegexpTable[T]) Engine() RegexpEngine {
+	return rt.engine
+}
+
+// EngineName returns the name of the table's engine: the name it gives itself by
+// implementing EngineNamer, such as "go-regexp" for the standard engine, or else
+// its Go type, such as "*mypkg.Engine". Metrics and serialised specs can record
+// it to say which engine's semantics a table was built with.
+func (rt *RegexpTable[T]) EngineName() string {
+	return engineName(rt.engine)
+}
+
+// engineName returns the name identifying an engine, see EngineName.
+func engineName(engine RegexpEngine) string {
+	if namer, ok := engine.(EngineNamer); ok {
+		return namer.Name()
+	}
+	return fmt.Sprintf("%T", engine)
+}
@@ -0,0 +1,61 @@
+package regexptable
+
+import (
+	"testing"
+)
+
+func TestRegexpTable_Fingerprint(t *testing.T) {
+	build := func(values ...string) *RegexpTableBuilder[string] {
+		return NewRegexpTableBuilder[string]().
+			AddPattern(`\d+`, values[0]).
+			AddPattern(`[a-z]+`, values[1])
+	}
+	base := build("number", "word").MustBuild(true, true).Fingerprint()
+
+	if len(base) != 64 {
+		t.Errorf("Expected a SHA-256 hex digest, got %q", base)
+	}
+	if again := build("number", "word").MustBuild(true, true).Fingerprint(); again != base {
+		t.Errorf("Expected identical tables to have the same fingerprint")
+	}
+	if values := build("n", "w").MustBuild(true, true).Fingerprint(); values != base {
+		t.Errorf("Expected the fingerprint to ignore values")
+	}
+
+	changed := map[string]string{
+		"anchoring":     build("number", "word").MustBuild(true, false).Fingerprint(),
+		"order":         NewRegexpTableBuilder[string]().AddPattern(`[a-z]+`, "word").AddPattern(`\d+`, "number").MustBuild(true, true).Fingerprint(),
+		"non-capturing": build("number", "word").WithNonCapturing(true).MustBuild(true, true).Fingerprint(),
+		"ungreedy":      build("number", "word").WithUngreedy(true).MustBuild(true, true).Fingerprint(),
+		"engine":        NewRegexpTableBuilderWithEngine[string](NewMockRegexpEngine("(?P<%s>%s)")).AddPattern(`\d+`, "number").AddPattern(`[a-z]+`, "word").MustBuild(true, true).Fingerprint(),
+	}
+	for change, fingerprint := range changed {
+		if fingerprint == base {
+			t.Errorf("Expected a change of %s to change the fingerprint", change)
+		}
+	}
+}
+
+func TestRegexpTable_EngineName(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	if table.EngineName() != "go-regexp" {
+		t.Errorf("Expected go-regexp, got %q", table.EngineName())
+	}
+	if _, ok := table.Engine().(*StandardRegexpEngine); !ok {
+		t.Errorf("Expected the standard engine, got %T", table.Engine())
+	}
+
+	engine := &stringOnlyEngine{}
+	table = NewRegexpTableWithEngine[string](engine, true, true)
+	if table.Engine() != engine {
+		t.Errorf("Expected the table's own engine, got %v", table.Engine())
+	}
+	// The embedded standard engine's Name is promoted.
+	if table.EngineName() != "go-regexp" {
+		t.Errorf("Expected go-regexp, got %q", table.EngineName())
+	}
+	table = NewRegexpTableWithEngine[string](&MockRegexpEngine{}, true, true)
+	if table.EngineName() != "*regexptable.MockRegexpEngine" {
+		t.Errorf("Expected the engine's type, got %q", table.EngineName())
+	}
+}
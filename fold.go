@@ -0,0 +1,79 @@
+package regexptable
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// WithValueFolding makes Build merge each run of consecutive patterns that map to
+// the same value into a single pattern, the alternation of the run, which
+// shrinks the union and the number of groups the engine tracks. Lookups give
+// the same values and matches as without folding, since the alternation prefers
+// its branches in the same order as the table would, but the merged patterns
+// count as one: Result.Pattern is the alternation, Result.Index and the other
+// insertion indexes count each run once, and hit statistics are kept per run.
+//
+// Patterns are compared after ordering by priority. Only plain patterns are
+// folded: those added with a single value that is comparable with ==, no name
+// or exclusion, and the same priority, confidence, tags and doc, whose pattern
+// has no capture groups, unless the table is non-capturing. The examples of the
+// merged patterns, see Entry.Examples, go to the run. Folding is off by default.
+func (b *RegexpTableBuilder[T]) WithValueFolding(enabled bool) *RegexpTableBuilder[T] {
+	b.valueFolding = enabled
+	return b
+}
+
+// foldEntries merges runs of foldable entries with equal values, see
+// WithValueFolding.
+func (b *RegexpTableBuilder[T]) foldEntries(entries []patternEntry[T]) []patternEntry[T] {
+	folded := make([]patternEntry[T], 0, len(entries))
+	var run []string // The patterns merged into the last folded entry, nil if it cannot take more
+	for _, entry := range entries {
+		foldable := b.foldable(entry)
+		if foldable && run != nil && sameFolding(folded[len(folded)-1], entry) {
+			run = append(run, entry.pattern)
+			folded[len(folded)-1].pattern = foldPatterns(run)
+			folded[len(folded)-1].examples = append(slices.Clip(folded[len(folded)-1].examples), entry.examples...)
+			continue
+		}
+		folded = append(folded, entry)
+		run = nil
+		if foldable {
+			run = []string{entry.pattern}
+		}
+	}
+	return folded
+}
+
+// foldable reports whether an entry may be merged with its neighbours.
+func (b *RegexpTableBuilder[T]) foldable(entry patternEntry[T]) bool {
+	if entry.exclusion != nil || entry.moreValues != nil || entry.name != "" {
+		return false
+	}
+	value := reflect.ValueOf(any(entry.value))
+	if value.IsValid() && !value.Comparable() {
+		return false
+	}
+	if b.nonCapturing {
+		return true
+	}
+	compiled, err := b.engine.Compile(entry.pattern)
+	return err == nil && len(compiled.SubexpNames()) == 1
+}
+
+// sameFolding reports whether two foldable entries may be merged.
+func sameFolding[T any](x, y patternEntry[T]) bool {
+	return any(x.value) == any(y.value) && x.priority == y.priority &&
+		x.confidence == y.confidence && slices.Equal(x.tags, y.tags) && x.doc == y.doc
+}
+
+// foldPatterns returns the alternation of a run of patterns, each wrapped so that
+// inline flags such as (?i) apply to it alone.
+func foldPatterns(patterns []string) string {
+	wrapped := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		wrapped[i] = WrapNonCapturing(pattern)
+	}
+	return strings.Join(wrapped, "|")
+}
@@ -0,0 +1,82 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+// foldingTestBuilder returns a builder whose runs of equal values can be folded,
+// apart from the entries that must be kept on their own.
+func foldingTestBuilder() *RegexpTableBuilder[string] {
+	return NewRegexpTableBuilder[string]().
+		AddPattern(`if|else`, "keyword").
+		AddPattern(`(?i)while`, "keyword").
+		AddPattern(`for`, "keyword").
+		AddPattern(`\d+\.\d+`, "number").
+		AddPattern(`\d+`, "number").
+		AddPattern(`0x[0-9a-f]+`, "hex").
+		AddPattern(`(\w+)=`, "number"). // Has a group, so stays on its own
+		AddPattern(`\d+e\d+`, "number").
+		AddPatternWithPriority(`[a-z]+`, "identifier", 0).
+		AddPatternWithPriority(`_\w*`, "identifier", 1).
+		AddPattern(`\s+`, "space").
+		AddPattern(`\t`, "space")
+}
+
+func TestRegexpTableBuilder_WithValueFolding(t *testing.T) {
+	inputs := []string{
+		"if", "else", "WHILE", "for", "foo", "_x", "1.5", "15", "1e5", "0x1f", "ab=", " ", "\t", "?",
+		"if 15", "x = 0x1f", "1.5e3", "  for", "\tz",
+	}
+	for _, anchoring := range []Anchoring{AnchorNone, AnchorStart, AnchorEnd, AnchorBoth} {
+		plain := foldingTestBuilder().MustBuild(anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+		folded := foldingTestBuilder().WithValueFolding(true).MustBuild(anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+		if folded.Len() != 8 {
+			t.Errorf("Expected 8 patterns after folding, got %d: %v", folded.Len(), folded.Patterns())
+		}
+		for _, input := range inputs {
+			want, wantMatches, wantErr := plain.Lookup(input)
+			got, gotMatches, gotErr := folded.Lookup(input)
+			if got != want || !slices.Equal(gotMatches, wantMatches) || (gotErr == nil) != (wantErr == nil) {
+				t.Errorf("%v: expected %q %q %v for %q, got %q %q %v", anchoring, want, wantMatches, wantErr, input, got, gotMatches, gotErr)
+			}
+		}
+	}
+
+	table := foldingTestBuilder().WithValueFolding(true).MustBuild(true, true)
+	if table.PatternAt(1) != `(?:if|else)|(?:(?i)while)|(?:for)` {
+		t.Errorf("Expected the keywords to be folded, got %q", table.PatternAt(1))
+	}
+	result, _ := table.LookupResult("WHILE")
+	if result.Index != 0 || result.Value != "keyword" { // The first folded pattern's position
+		t.Errorf("Expected the folded keyword rule to win, got %v", result)
+	}
+}
+
+func TestRegexpTableBuilder_WithValueFoldingNonCapturing(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(a)b`, "x").
+		AddPattern(`(c)d`, "x").
+		WithNonCapturing(true).
+		WithValueFolding(true).
+		MustBuild(true, true)
+	if table.Len() != 1 {
+		t.Errorf("Expected groups not to prevent folding in non-capturing mode, got %v", table.Patterns())
+	}
+	if value, _, err := table.Lookup("cd"); err != nil || value != "x" {
+		t.Errorf("Expected x, got %q, %v", value, err)
+	}
+}
+
+func TestRegexpTableBuilder_WithValueFoldingUncomparable(t *testing.T) {
+	table := NewRegexpTableBuilder[any]().
+		AddPattern(`a`, []int{1}).
+		AddPattern(`b`, []int{1}).
+		AddPattern(`c`, 2).
+		AddPattern(`d`, 2).
+		WithValueFolding(true).
+		MustBuild(true, true)
+	if table.Len() != 3 {
+		t.Errorf("Expected only comparable values to be folded, got %v", table.Patterns())
+	}
+}
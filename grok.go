@@ -0,0 +1,207 @@
+package regexptable
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ExportGrok writes the table's rules as a grok pattern file, one pattern per
+// line in match order, so that a log pipeline using grok, such as Logstash, can
+// classify with the same rules. Each pattern is called by the rule's name, if it
+// was added with AddNamedPattern, or RULE_<index> otherwise, and is preceded by a
+// comment giving the rule's values, rendered by formatValue or with %v if it is
+// nil. Grok has no notion of values, so matching the patterns in order and
+// stopping at the first match, e.g. with break_on_match, is left to the pipeline.
+//
+// Patterns are rewritten from Go syntax into grok's Oniguruma syntax, with the
+// table's anchoring and modes applied. For tables anchored at the start, trying
+// the patterns in order finds the same rule as a lookup; otherwise a lookup
+// prefers the rule matching furthest left, which grok does not. Rules that
+// cannot be rewritten, such as those with exclusions or in another engine's
+// syntax, are left out and reported in a *TranslationError after the other
+// rules have been written.
+func (rt *RegexpTable[T]) ExportGrok(w io.Writer, formatValue func(T) string) error {
+	rules, translationErr := rt.translateRules(onigurumaDialect, formatValue)
+	var incomplete *TranslationError
+	if translationErr != nil && !errors.As(translationErr, &incomplete) {
+		return translationErr
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "# Grok patterns exported from a regexp table, in match order.")
+	for _, rule := range rules {
+		name := rule.name
+		if name == "" {
+			name = fmt.Sprintf("RULE_%d", rule.index)
+		}
+		// Braces are always escaped, so %{ never starts a reference to another
+		// grok pattern by accident.
+		fmt.Fprintf(out, "# rule %d: %s\n%s %s\n", rule.index, strings.Join(rule.values, ", "), name, rule.expression)
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return translationErr
+}
+
+//go:embed grok.patterns
+var bundledGrokPatterns string
+
+// bundledGrokLibrary returns the patterns in grok.patterns, parsed once.
+var bundledGrokLibrary = sync.OnceValue(func() map[string]string {
+	var library GrokLibrary
+	if err := library.ReadPatterns(strings.NewReader(bundledGrokPatterns)); err != nil {
+		panic(err)
+	}
+	return library.patterns
+})
+
+// GrokLibrary is a set of named grok patterns, used to convert grok expressions
+// such as %{IPV4:client} %{WORD:method} into patterns for a table with Expand.
+// The zero value is an empty library; NewGrokLibrary returns one holding the
+// bundled patterns.
+type GrokLibrary struct {
+	patterns map[string]string
+}
+
+// NewGrokLibrary returns a library holding the bundled patterns, which follow the
+// standard grok patterns of Logstash (IPV4, HOSTNAME, TIMESTAMP_ISO8601,
+// COMBINEDAPACHELOG and so on), rewritten for Go's syntax. Define and
+// ReadPatterns add to them or override them.
+func NewGrokLibrary() *GrokLibrary {
+	return &GrokLibrary{patterns: maps.Clone(bundledGrokLibrary())}
+}
+
+// Define adds a named pattern to the library, replacing any pattern of the same
+// name. The pattern is in Go syntax and may refer to other patterns of the
+// library, including ones defined later.
+func (l *GrokLibrary) Define(name, pattern string) error {
+	if !isClassName(name) {
+		return fmt.Errorf("invalid grok pattern name %q", name)
+	}
+	if l.patterns == nil {
+		l.patterns = make(map[string]string)
+	}
+	l.patterns[name] = pattern
+	return nil
+}
+
+// ReadPatterns adds the patterns of a grok pattern file to the library. Each line
+// holds a name and a pattern separated by white space; blank lines and lines
+// starting with # are ignored.
+func (l *GrokLibrary) ReadPatterns(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, pattern, ok := strings.Cut(text, " ")
+		if !ok {
+			name, pattern, ok = strings.Cut(text, "\t")
+		}
+		if !ok {
+			return fmt.Errorf("line %d: expected a name and a pattern", line)
+		}
+		if err := l.Define(name, strings.TrimSpace(pattern)); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Expand converts a grok expression into a pattern in Go syntax by replacing its
+// references to the library's patterns, recursively. A reference %{NAME} stands
+// for the named pattern in a non-capturing group and %{NAME:field} for the
+// pattern captured as the named group field, so that lookups report it through
+// Result.Fields. Characters that cannot appear in group names are replaced by
+// underscores, so the fields [client][ip] and client.ip both become client_ip.
+// A type suffix, as in %{NUMBER:bytes:int}, is ignored, since values are matched
+// as strings. Everything outside references is kept as it is.
+func (l *GrokLibrary) Expand(expression string) (string, error) {
+	return l.expand(expression, nil)
+}
+
+// expand is Expand with the names of the patterns being expanded, which must not
+// refer to themselves.
+func (l *GrokLibrary) expand(expression string, expanding []string) (string, error) {
+	var expanded strings.Builder
+	for {
+		start := strings.Index(expression, "%{")
+		if start < 0 {
+			expanded.WriteString(expression)
+			return expanded.String(), nil
+		}
+		end := strings.IndexByte(expression[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated grok reference '%s'", expression[start:])
+		}
+		reference := expression[start+2 : start+end]
+		expanded.WriteString(expression[:start])
+		expression = expression[start+end+1:]
+
+		name, field, _ := strings.Cut(reference, ":")
+		field, _, _ = strings.Cut(field, ":") // Drop the type, if any
+		pattern, ok := l.patterns[name]
+		if !ok {
+			return "", fmt.Errorf("unknown grok pattern %q", name)
+		}
+		if slices.Contains(expanding, name) {
+			return "", fmt.Errorf("grok pattern %q refers to itself", name)
+		}
+		pattern, err := l.expand(pattern, append(expanding, name))
+		if err != nil {
+			return "", err
+		}
+		if field == "" {
+			expanded.WriteString(WrapNonCapturing(pattern))
+			continue
+		}
+		group, err := grokGroupName(field)
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString("(?P<" + group + ">" + pattern + ")")
+	}
+}
+
+// grokGroupName returns the group name for a grok field.
+func grokGroupName(field string) (string, error) {
+	words := strings.FieldsFunc(field, func(r rune) bool { return !isWordRune(r) })
+	name := strings.Join(words, "_")
+	if name == "" || !unicode.IsLetter(rune(name[0])) && name[0] != '_' {
+		return "", fmt.Errorf("invalid grok field %q", field)
+	}
+	return name, nil
+}
+
+// WithGrokLibrary sets the library that AddGrokPattern expands grok expressions
+// with. By default the builder uses NewGrokLibrary.
+func (b *RegexpTableBuilder[T]) WithGrokLibrary(library *GrokLibrary) *RegexpTableBuilder[T] {
+	b.grok = library
+	return b
+}
+
+// AddGrokPattern adds the pattern given by a grok expression, such as
+// %{IPV4:client} %{WORD:method}, expanded with the builder's grok library (see
+// GrokLibrary.Expand). Build reports an error for an expression that cannot be
+// expanded.
+func (b *RegexpTableBuilder[T]) AddGrokPattern(expression string, value T) *RegexpTableBuilder[T] {
+	if b.grok == nil {
+		b.grok = NewGrokLibrary()
+	}
+	pattern, err := b.grok.Expand(expression)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("grok expression '%s': %w", expression, err))
+		return b
+	}
+	return b.AddPattern(pattern, value)
+}
@@ -0,0 +1,169 @@
+package regexptable
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_ExportGrok(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddNamedPattern("percent", `\d+%\{`, "percent").
+		AddPatternValues(`(?i)get|post`, "method", "verb").
+		AddPatternWithPriority(`\bok`, "ok", 1).
+		MustBuild(true, false)
+
+	var out bytes.Buffer
+	if err := table.ExportGrok(&out, nil); err != nil {
+		t.Fatalf("ExportGrok failed: %v", err)
+	}
+	expected := "# Grok patterns exported from a regexp table, in match order.\n" +
+		"# rule 3: ok\n" +
+		`RULE_3 \A(?:(?:(?<=[0-9A-Za-z_])(?![0-9A-Za-z_])|(?<![0-9A-Za-z_])(?=[0-9A-Za-z_]))ok)` + "\n" +
+		"# rule 0: number\n" +
+		`RULE_0 \A(?:[0-9]+)` + "\n" +
+		"# rule 1: percent\n" +
+		`percent \A(?:[0-9]+%\{)` + "\n" +
+		"# rule 2: method, verb\n" +
+		`RULE_2 \A(?:(?i:GET)|(?i:POST))` + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestRegexpTable_ExportGrokIncomplete(t *testing.T) {
+	table := NewRegexpTable[int](true, true)
+	table.AddPatternExcluding(`[a-z]+`, 1, Exclusion{NotMatching: `if`})
+	table.AddPattern(`x`, 2)
+	var out bytes.Buffer
+	err := table.ExportGrok(&out, func(v int) string { return "#" + string(rune('0'+v)) })
+	var incomplete *TranslationError
+	if !errors.As(err, &incomplete) || len(incomplete.Rules) != 1 {
+		t.Errorf("Expected one untranslatable rule, got %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("# rule 1: #2\nRULE_1 \\A(?:x)\\z\n")) {
+		t.Errorf("Expected the other rule to be written, got\n%s", out.String())
+	}
+}
+
+func TestNewGrokLibrary_BundledPatterns(t *testing.T) {
+	library := NewGrokLibrary()
+	for name := range library.patterns {
+		pattern, err := library.Expand("%{" + name + "}")
+		if err != nil {
+			t.Errorf("Expected %s to expand, got %v", name, err)
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			t.Errorf("Expected %s to compile, got %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		match bool
+	}{
+		{"IPV4", "192.168.0.255", true},
+		{"IPV4", "192.168.0.256", false},
+		{"IPV6", "2001:db8::1", true},
+		{"IPV6", "::ffff:10.0.0.1", true},
+		{"IPV6", "fe80::1%eth0", true},
+		{"IPV6", "1:2:3", false},
+		{"HOSTNAME", "www.example.com", true},
+		{"EMAILADDRESS", "someone@example.com", true},
+		{"UUID", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"TIMESTAMP_ISO8601", "2024-03-01T12:34:56.789Z", true},
+		{"HTTPDATE", "10/Oct/2000:13:55:36 -0700", true},
+		{"QUOTEDSTRING", `"say \"hi\""`, true},
+		{"NUMBER", "-1.5", true},
+		{"LOGLEVEL", "WARNING", true},
+		{"URI", "https://user@example.com:8080/a/b?c=d", true},
+	}
+	for _, tt := range tests {
+		pattern, _ := library.Expand("%{" + tt.name + "}")
+		if matched := regexp.MustCompile(AnchorPattern(pattern, AnchorBoth)).MatchString(tt.input); matched != tt.match {
+			t.Errorf("Expected %s matching %q to be %v", tt.name, tt.input, tt.match)
+		}
+	}
+}
+
+func TestGrokLibrary_Expand(t *testing.T) {
+	var library GrokLibrary
+	library.Define("DIGITS", `[0-9]+`)
+	library.Define("PAIR", `%{DIGITS:left}-%{DIGITS}`)
+	pattern, err := library.Expand(`%{PAIR:[pair][all]} %{DIGITS:n:int}`)
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if pattern != `(?P<pair_all>(?P<left>[0-9]+)-(?:[0-9]+)) (?P<n>[0-9]+)` {
+		t.Errorf("Unexpected expansion %s", pattern)
+	}
+
+	library.Define("LOOP", `a%{LOOP}`)
+	errorTests := []struct {
+		expression string
+		message    string
+	}{
+		{`%{MISSING}`, `unknown grok pattern "MISSING"`},
+		{`%{LOOP}`, `grok pattern "LOOP" refers to itself`},
+		{`%{DIGITS`, `unterminated grok reference`},
+		{`%{DIGITS:9lives}`, `invalid grok field "9lives"`},
+	}
+	for _, tt := range errorTests {
+		if _, err := library.Expand(tt.expression); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected %q for %s, got %v", tt.message, tt.expression, err)
+		}
+	}
+	if err := library.Define("bad name", "x"); err == nil {
+		t.Error("Expected an invalid name to be rejected")
+	}
+}
+
+func TestGrokLibrary_ReadPatterns(t *testing.T) {
+	library := NewGrokLibrary()
+	err := library.ReadPatterns(strings.NewReader("# custom\n\nSTATUS (?:ok|failed)\nJOB\tjob-%{POSINT}\n"))
+	if err != nil {
+		t.Fatalf("ReadPatterns failed: %v", err)
+	}
+	if pattern, err := library.Expand(`%{JOB} %{STATUS}`); err != nil || !regexp.MustCompile(pattern).MatchString("job-42 ok") {
+		t.Errorf("Expected the custom patterns to be usable, got %s, %v", pattern, err)
+	}
+	if err := library.ReadPatterns(strings.NewReader("FINE x\nBROKEN\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
+
+func TestRegexpTableBuilder_AddGrokPattern(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddGrokPattern(`%{IPV4:client} %{WORD:method} %{URIPATHPARAM:request}`, "access").
+		AddGrokPattern(`%{SYSLOGBASE} %{GREEDYDATA:message}`, "syslog").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	result, err := table.LookupResult("10.0.0.1 GET /index.html?q=1")
+	if err != nil || result.Value != "access" {
+		t.Fatalf("Expected access, got %v, %v", result, err)
+	}
+	if client, _ := result.Field("client"); client != "10.0.0.1" {
+		t.Errorf("Expected the client field, got %s", result)
+	}
+	result, err = table.LookupResult("Mar  1 12:00:00 host sshd[42]: Accepted key")
+	if err != nil || result.Value != "syslog" {
+		t.Fatalf("Expected syslog, got %v, %v", result, err)
+	}
+	if pid, _ := result.Field("pid"); pid != "42" {
+		t.Errorf("Expected the pid field, got %s", result)
+	}
+
+	var library GrokLibrary
+	library.Define("ID", `[a-z]+`)
+	_, err = NewRegexpTableBuilder[string]().WithGrokLibrary(&library).AddGrokPattern(`%{IPV4}`, "ip").Build(true, true)
+	if err == nil || !strings.Contains(err.Error(), `unknown grok pattern "IPV4"`) {
+		t.Errorf("Expected the custom library to be used, got %v", err)
+	}
+}
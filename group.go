@@ -0,0 +1,50 @@
+package regexptable
+
+import (
+	"errors"
+)
+
+// GroupInputs classifies a batch of inputs and buckets them by the value they are
+// classified as, preserving the order of the inputs within each bucket. Inputs
+// that no pattern matches are returned separately. Any other lookup error, such
+// as ErrNoPatterns or a compilation failure, is returned immediately.
+//
+// GroupInputs is a function rather than a method because it needs the table's
+// values to be usable as map keys. For tables of other value types, see
+// RegexpTable.GroupInputsByPattern.
+func GroupInputs[T comparable](rt *RegexpTable[T], inputs []string) (map[T][]string, []string, error) {
+	groups := make(map[T][]string)
+	var unmatched []string
+	for _, input := range inputs {
+		entry, _, err := rt.find(input)
+		if errors.Is(err, ErrNoMatch) {
+			unmatched = append(unmatched, input)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		groups[entry.Value] = append(groups[entry.Value], input)
+	}
+	return groups, unmatched, nil
+}
+
+// GroupInputsByPattern is like GroupInputs but buckets the inputs by the winning
+// pattern, as it was added to the table, so it works whatever the value type.
+// Entries that were added with the same pattern text share a bucket.
+func (rt *RegexpTable[T]) GroupInputsByPattern(inputs []string) (map[string][]string, []string, error) {
+	groups := make(map[string][]string)
+	var unmatched []string
+	for _, input := range inputs {
+		entry, _, err := rt.find(input)
+		if errors.Is(err, ErrNoMatch) {
+			unmatched = append(unmatched, input)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		groups[entry.Pattern] = append(groups[entry.Pattern], input)
+	}
+	return groups, unmatched, nil
+}
@@ -0,0 +1,56 @@
+package regexptable
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGroupInputs(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`0x[0-9a-f]+`, "number").
+		MustBuild(true, true)
+
+	groups, unmatched, err := GroupInputs(table, []string{"12", "abc", "!", "7", "xyz", "?"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"12", "7"}; !slices.Equal(groups["number"], want) {
+		t.Errorf("Expected numbers %v, got %v", want, groups["number"])
+	}
+	if want := []string{"abc", "xyz"}; !slices.Equal(groups["word"], want) {
+		t.Errorf("Expected words %v, got %v", want, groups["word"])
+	}
+	if want := []string{"!", "?"}; !slices.Equal(unmatched, want) {
+		t.Errorf("Expected unmatched %v, got %v", want, unmatched)
+	}
+
+	_, _, err = GroupInputs(NewRegexpTable[string](true, true), []string{"x"})
+	if !errors.Is(err, ErrNoPatterns) {
+		t.Errorf("Expected ErrNoPatterns, got %v", err)
+	}
+}
+
+func TestRegexpTable_GroupInputsByPattern(t *testing.T) {
+	type rule struct{ name string }
+	table := NewRegexpTableBuilder[*rule]().
+		AddPattern(`\d+`, &rule{"number"}).
+		AddPattern(`[a-z]+`, &rule{"word"}).
+		MustBuild(true, true)
+
+	groups, unmatched, err := table.GroupInputsByPattern([]string{"1", "a", "22", "-"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"1", "22"}; !slices.Equal(groups[`\d+`], want) {
+		t.Errorf("Expected %v, got %v", want, groups[`\d+`])
+	}
+	if want := []string{"a"}; !slices.Equal(groups[`[a-z]+`], want) {
+		t.Errorf("Expected %v, got %v", want, groups[`[a-z]+`])
+	}
+	if want := []string{"-"}; !slices.Equal(unmatched, want) {
+		t.Errorf("Expected unmatched %v, got %v", want, unmatched)
+	}
+}
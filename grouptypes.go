@@ -0,0 +1,222 @@
+package regexptable
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupType declares how the text captured by a named group is converted into a
+// typed value by Result.TypedFields and LookupInto. It is one of the GroupType
+// constants or time:LAYOUT, where LAYOUT is the name of one of the time
+// package's layouts, such as RFC3339 or DateTime, or a layout written out in
+// full, such as time:2006-01-02.
+type GroupType string
+
+// The group types other than time:LAYOUT.
+const (
+	GroupString   GroupType = "string"   // The text as captured, the default
+	GroupInt      GroupType = "int"      // An int, in decimal or with a 0x, 0o or 0b prefix
+	GroupFloat    GroupType = "float"    // A float64
+	GroupBool     GroupType = "bool"     // A bool, as accepted by strconv.ParseBool
+	GroupDuration GroupType = "duration" // A time.Duration, as accepted by time.ParseDuration
+	GroupIP       GroupType = "ip"       // A netip.Addr, IPv4 or IPv6
+)
+
+// timeLayouts are the named layouts a time group type may refer to.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// Validate reports an error if the group type is not one that Convert knows.
+func (g GroupType) Validate() error {
+	switch g {
+	case GroupString, GroupInt, GroupFloat, GroupBool, GroupDuration, GroupIP:
+		return nil
+	}
+	if layout, ok := strings.CutPrefix(string(g), "time:"); ok && layout != "" {
+		return nil
+	}
+	return fmt.Errorf("unknown group type %q", g)
+}
+
+// Convert converts captured text into a value of the group type.
+func (g GroupType) Convert(text string) (any, error) {
+	var value any
+	var err error
+	switch g {
+	case GroupString:
+		return text, nil
+	case GroupInt:
+		var n int64
+		n, err = strconv.ParseInt(text, 0, strconv.IntSize)
+		value = int(n)
+	case GroupFloat:
+		value, err = strconv.ParseFloat(text, 64)
+	case GroupBool:
+		value, err = strconv.ParseBool(text)
+	case GroupDuration:
+		value, err = time.ParseDuration(text)
+	case GroupIP:
+		value, err = netip.ParseAddr(text)
+	default:
+		layout, ok := strings.CutPrefix(string(g), "time:")
+		if !ok || layout == "" {
+			return nil, g.Validate()
+		}
+		if named, ok := timeLayouts[layout]; ok {
+			layout = named
+		}
+		value, err = time.Parse(layout, text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %q to %s: %w", text, g, err)
+	}
+	return value, nil
+}
+
+// AddTypedPattern adds a pattern whose named groups have the given types, so that
+// Result.TypedFields and LookupInto convert their text, e.g.
+//
+//	builder.AddTypedPattern(`(?P<status>\d{3}) (?P<at>\S+)`, "response", map[string]GroupType{
+//	    "status": GroupInt,
+//	    "at":     "time:RFC3339",
+//	})
+//
+// Groups without a type are strings. Build reports an error if a type is unknown
+// or names a group the pattern does not have.
+func (b *RegexpTableBuilder[T]) AddTypedPattern(pattern string, value T, types map[string]GroupType) *RegexpTableBuilder[T] {
+	b.patterns = append(b.patterns, patternEntry[T]{pattern: pattern, value: value})
+	b.setGroupTypes(len(b.patterns)-1, types)
+	return b
+}
+
+// setGroupTypes gives the entry at index i the group types, recording any
+// problems with them for Build.
+func (b *RegexpTableBuilder[T]) setGroupTypes(i int, types map[string]GroupType) {
+	if len(types) == 0 {
+		return
+	}
+	entry := &b.patterns[i]
+	entry.groupTypes = maps.Clone(types)
+	// An invalid pattern is reported by Build, so only check the names of a valid one.
+	compiled, compileErr := b.engine.Compile(entry.pattern)
+	for _, name := range slices.Sorted(maps.Keys(types)) {
+		if err := types[name].Validate(); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("pattern '%s', group %s: %w", entry.pattern, name, err))
+		} else if compileErr == nil && (name == "" || !slices.Contains(compiled.SubexpNames(), name)) {
+			b.errs = append(b.errs, fmt.Errorf("pattern '%s' has no group named %s to give type %s", entry.pattern, name, types[name]))
+		}
+	}
+}
+
+// TypedFields is like Fields but converts the text of each named group according
+// to the type declared for it, see GroupType, leaving undeclared groups as
+// strings. Typed groups that captured nothing, such as optional groups that did
+// not take part in the match, are left out. It returns the first conversion
+// error, wrapped with the name of the group.
+func (r *Result[T]) TypedFields() (map[string]any, error) {
+	fields := make(map[string]any)
+	for name, text := range r.Fields() {
+		groupType, typed := r.groupTypes[name]
+		if !typed {
+			fields[name] = text
+			continue
+		}
+		if text == "" && groupType != GroupString {
+			continue
+		}
+		value, err := groupType.Convert(text)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", name, err)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// LookupInto looks up the input and stores the typed fields of the winning
+// pattern, see Result.TypedFields, in the struct that dst points to. A field
+// receives the group named by its regexptable tag, as in
+//
+//	Status int `regexptable:"status"`
+//
+// or, without a tag, the group whose name equals the field's name ignoring case.
+// A value is stored if it is assignable or convertible to the field's type, such
+// as an int to an int64 field, and is otherwise an error. Fields without a group,
+// or whose group captured nothing, are left unchanged. The result of the lookup is
+// returned as well.
+func (rt *RegexpTable[T]) LookupInto(input string, dst any) (*Result[T], error) {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return nil, codeErrorf(CodeInvalidArgument, "LookupInto needs a non-nil pointer to a struct, not %T", dst)
+	}
+	result, err := rt.LookupResult(input)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := result.TypedFields()
+	if err != nil {
+		return nil, codeErrorf(CodeInvalidArgument, "pattern '%s': %w", result.Pattern, err)
+	}
+
+	target = target.Elem()
+	for i := range target.NumField() {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, ok := fieldValue(fields, field)
+		if !ok {
+			continue
+		}
+		converted := reflect.ValueOf(value)
+		switch {
+		case converted.Type().AssignableTo(field.Type):
+		case converted.CanConvert(field.Type) && (field.Type.Kind() == reflect.String) == (converted.Kind() == reflect.String):
+			// Numbers convert to other numeric types, but not to strings, where
+			// Go's conversion would make a character of the number.
+			converted = converted.Convert(field.Type)
+		default:
+			return nil, codeErrorf(CodeInvalidArgument, "cannot store %T from group of pattern '%s' in field %s of type %s", value, result.Pattern, field.Name, field.Type)
+		}
+		target.Field(i).Set(converted)
+	}
+	return result, nil
+}
+
+// fieldValue returns the value among fields that belongs in a struct field.
+func fieldValue(fields map[string]any, field reflect.StructField) (any, bool) {
+	if name, ok := field.Tag.Lookup("regexptable"); ok {
+		value, found := fields[name]
+		return value, found
+	}
+	for name, value := range fields {
+		if strings.EqualFold(name, field.Name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,161 @@
+package regexptable
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupType_Convert(t *testing.T) {
+	at := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	testCases := []struct {
+		groupType GroupType
+		text      string
+		expected  any
+	}{
+		{GroupString, "abc", "abc"},
+		{GroupInt, "42", 42},
+		{GroupInt, "0x1f", 31},
+		{GroupFloat, "2.5", 2.5},
+		{GroupBool, "true", true},
+		{GroupDuration, "1m30s", 90 * time.Second},
+		{GroupIP, "10.0.0.1", netip.MustParseAddr("10.0.0.1")},
+		{GroupIP, "::1", netip.MustParseAddr("::1")},
+		{"time:RFC3339", "2024-02-03T04:05:06Z", at},
+		{"time:2006-01-02 15:04:05", "2024-02-03 04:05:06", at},
+	}
+	for _, tc := range testCases {
+		got, err := tc.groupType.Convert(tc.text)
+		if err != nil || got != tc.expected {
+			t.Errorf("Expected %v from %s %q, got %v, %v", tc.expected, tc.groupType, tc.text, got, err)
+		}
+	}
+
+	if _, err := GroupInt.Convert("x"); err == nil || !strings.Contains(err.Error(), `cannot convert "x" to int`) {
+		t.Errorf("Expected a conversion error, got %v", err)
+	}
+	for _, groupType := range []GroupType{"uint", "time:", ""} {
+		if err := groupType.Validate(); err == nil {
+			t.Errorf("Expected %q to be invalid", groupType)
+		}
+	}
+}
+
+func TestAddTypedPattern(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddTypedPattern(`(?P<status>\d{3}) (?P<took>\S+) (?P<client>\S+)(?: (?P<retry>\w+))?`, "response", map[string]GroupType{
+			"status": GroupInt,
+			"took":   GroupDuration,
+			"client": GroupIP,
+			"retry":  GroupBool,
+		}).
+		AddPattern(`(?P<word>[a-z]+)`, "word").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+
+	result, err := table.LookupResult("404 15ms 192.168.0.1")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	fields, err := result.TypedFields()
+	if err != nil {
+		t.Fatalf("TypedFields failed: %v", err)
+	}
+	if fields["status"] != 404 || fields["took"] != 15*time.Millisecond || fields["client"] != netip.MustParseAddr("192.168.0.1") {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if _, ok := fields["retry"]; ok {
+		t.Errorf("Expected the unmatched retry group to be left out, got %v", fields)
+	}
+
+	result, _ = table.LookupResult("abc")
+	if fields, err := result.TypedFields(); err != nil || fields["word"] != "abc" {
+		t.Errorf("Expected untyped groups as strings, got %v, %v", fields, err)
+	}
+
+	result, _ = table.LookupResult("200 soon 10.0.0.1")
+	if _, err := result.TypedFields(); err == nil || !strings.Contains(err.Error(), "group took") {
+		t.Errorf("Expected a conversion error for took, got %v", err)
+	}
+}
+
+func TestAddTypedPattern_Invalid(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		AddTypedPattern(`(?P<n>\d+)`, "n", map[string]GroupType{"n": "uint", "m": GroupInt}).
+		Build(true, true)
+	if err == nil || !strings.Contains(err.Error(), `unknown group type "uint"`) || !strings.Contains(err.Error(), "no group named m") {
+		t.Errorf("Expected both problems to be reported, got %v", err)
+	}
+}
+
+func TestLookupInto(t *testing.T) {
+	type method string
+	type request struct {
+		Method  method
+		Path    string `regexptable:"path"`
+		Status  int64
+		At      time.Time `regexptable:"time"`
+		Ignored string
+		hidden  int
+	}
+	table, err := NewRegexpTableBuilder[string]().
+		AddTypedPattern(`(?P<method>[A-Z]+) (?P<path>\S+) (?P<status>\d+) (?P<time>\S+)`, "request", map[string]GroupType{
+			"status": GroupInt,
+			"time":   "time:RFC3339",
+		}).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+
+	req := request{Ignored: "kept"}
+	result, err := table.LookupInto("GET /index.html 200 2024-02-03T04:05:06Z", &req)
+	if err != nil {
+		t.Fatalf("LookupInto failed: %v", err)
+	}
+	expected := request{Method: "GET", Path: "/index.html", Status: 200, At: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC), Ignored: "kept"}
+	if req != expected || result.Value != "request" {
+		t.Errorf("Expected %+v, got %+v with %v", expected, req, result)
+	}
+
+	if _, err := table.LookupInto("GET / 200 2024-02-03T04:05:06Z", req); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected %s for a non-pointer, got %v", CodeInvalidArgument, err)
+	}
+	var wrong struct{ Status string }
+	if _, err := table.LookupInto("GET / 200 2024-02-03T04:05:06Z", &wrong); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected %s for an int in a string field, got %v", CodeInvalidArgument, err)
+	}
+	if _, err := table.LookupInto("nothing", &req); ErrorCodeOf(err) != CodeNoMatch {
+		t.Errorf("Expected %s, got %v", CodeNoMatch, err)
+	}
+}
+
+func TestLoader_GroupTypes(t *testing.T) {
+	spec := `{"version": 1, "anchorStart": true, "anchorEnd": true, "entries": [
+		{"pattern": "(?P<code>\\d+)", "value": "code", "groups": {"code": "int"}}
+	]}`
+	table, err := NewLoader[string](LoadStrict).LoadBytes([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	result, _ := table.LookupResult("17")
+	if fields, err := result.TypedFields(); err != nil || fields["code"] != 17 {
+		t.Errorf("Expected code 17, got %v, %v", fields, err)
+	}
+
+	bad := strings.Replace(spec, `"int"`, `"integer"`, 1)
+	if _, err := NewLoader[string](LoadStrict).LoadBytes([]byte(bad)); ErrorCodeOf(err) != CodeInvalidSpec {
+		t.Errorf("Expected %s, got %v", CodeInvalidSpec, err)
+	}
+	table, err = NewLoader[string](LoadLenient).LoadBytes([]byte(bad))
+	if err != nil {
+		t.Fatalf("Expected a lenient loader to ignore the type, got %v", err)
+	}
+	result, _ = table.LookupResult("17")
+	if fields, _ := result.TypedFields(); fields["code"] != "17" {
+		t.Errorf("Expected code as a string, got %v", fields)
+	}
+}
@@ -0,0 +1,16 @@
+// Package hsprefilter classifies inputs with a regexptable, using a Hyperscan (or
+// Vectorscan) database built from the table's patterns to find the few patterns
+// worth matching. With tens of thousands of patterns a table's union is slow to
+// compile and match, while Hyperscan scans an input for all of them in a single
+// pass; the table then confirms Hyperscan's candidates with
+// RegexpTable.LookupCandidates, which picks the winner, its groups and its span
+// exactly as the table's own lookups would.
+//
+// Hyperscan is a C library used through cgo, so the adapter is only built with
+// the hyperscan build tag and the library installed:
+//
+//	go build -tags hyperscan
+//
+// The adapter lives in a module of its own so that regexptable itself keeps no
+// dependencies beyond the standard library.
+package hsprefilter
@@ -0,0 +1,9 @@
+module github.com/sfkleach/regexptable/hsprefilter
+
+go 1.24.2
+
+require github.com/sfkleach/regexptable v0.0.0
+
+require github.com/flier/gohs v1.2.3
+
+replace github.com/sfkleach/regexptable => ../
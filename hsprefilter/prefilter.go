@@ -0,0 +1,141 @@
+//go:build hyperscan
+
+package hsprefilter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/flier/gohs/hyperscan"
+	"github.com/sfkleach/regexptable"
+)
+
+// Options configures New. Hyperscan must see the input the way the table's
+// patterns do, or it may miss candidates, so the options mirror the table's own
+// modes.
+type Options struct {
+	Caseless  bool                // Whether the table is case-insensitive, see RegexpTable.SetCaseInsensitive
+	Normalize func(string) string // The table's normalizers applied in turn, nil if it has none
+}
+
+// Prefilter classifies inputs with a table, scanning each input with a Hyperscan
+// database to choose the patterns worth matching. It is safe for concurrent use
+// provided the table is: build the table with WithPrecompileIndividuals(true) so
+// that lookups do not modify it.
+type Prefilter[T any] struct {
+	table     *regexptable.RegexpTable[T]
+	normalize func(string) string
+	database  hyperscan.BlockDatabase
+	prototype *hyperscan.Scratch
+	scratches sync.Pool // Of *hyperscan.Scratch cloned from prototype, one per concurrent lookup
+}
+
+// New compiles a Hyperscan database from the table's patterns, giving each the
+// pattern's insertion index as its ID. Every pattern is compiled in Hyperscan's
+// prefilter mode, in which it may report false positives but never misses a
+// match, so that constructs Hyperscan cannot match exactly, such as capture
+// groups used for backreferences, still work; the table weeds the false
+// positives out. The table's anchoring is applied by the table, so the database
+// is compiled from the unanchored patterns. Close releases the database.
+func New[T any](table *regexptable.RegexpTable[T], opts Options) (*Prefilter[T], error) {
+	if err := table.Recompile(); err != nil {
+		return nil, err
+	}
+	flags := hyperscan.SingleMatch | hyperscan.PrefilterMode | hyperscan.Utf8Mode | hyperscan.AllowEmpty
+	if opts.Caseless {
+		flags |= hyperscan.Caseless
+	}
+	patterns := make([]*hyperscan.Pattern, table.Len())
+	for i := range table.Len() {
+		pattern := hyperscan.NewPattern(table.PatternAt(i), flags)
+		pattern.Id = table.IndexAt(i)
+		patterns[i] = pattern
+	}
+	if len(patterns) == 0 {
+		return nil, regexptable.ErrNoPatterns
+	}
+	database, err := hyperscan.NewBlockDatabase(patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile Hyperscan database: %w", err)
+	}
+	prototype, err := hyperscan.NewScratch(database)
+	if err != nil {
+		_ = database.Close()
+		return nil, fmt.Errorf("failed to allocate Hyperscan scratch space: %w", err)
+	}
+	return &Prefilter[T]{table: table, normalize: opts.Normalize, database: database, prototype: prototype}, nil
+}
+
+// Candidates returns the insertion indexes of the patterns that Hyperscan reports
+// for the input, in the order it reports them, which may include false positives.
+func (p *Prefilter[T]) Candidates(input string) ([]int, error) {
+	scratch, err := p.scratch()
+	if err != nil {
+		return nil, err
+	}
+	defer p.scratches.Put(scratch)
+
+	text := input
+	if p.normalize != nil {
+		text = p.normalize(input)
+	}
+	var candidates []int
+	handler := func(id uint, from, to uint64, flags uint, context any) error {
+		candidates = append(candidates, int(id))
+		return nil
+	}
+	if err := p.database.Scan([]byte(text), scratch, handler, nil); err != nil {
+		return nil, fmt.Errorf("failed to scan with Hyperscan: %w", err)
+	}
+	return candidates, nil
+}
+
+// LookupResult classifies an input as the table's LookupResult would, matching
+// only the patterns Hyperscan reports for it. It returns regexptable.ErrNoMatch
+// if no pattern matches.
+func (p *Prefilter[T]) LookupResult(input string) (*regexptable.Result[T], error) {
+	candidates, err := p.Candidates(input)
+	if err != nil {
+		return nil, err
+	}
+	return p.table.LookupCandidates(input, candidates)
+}
+
+// Lookup is LookupResult returning just the value and the submatches.
+func (p *Prefilter[T]) Lookup(input string) (T, []string, error) {
+	var zero T
+	result, err := p.LookupResult(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	return result.Value, result.Groups, nil
+}
+
+// Close releases the Hyperscan database and scratch space. The prefilter must not
+// be used afterwards.
+func (p *Prefilter[T]) Close() error {
+	var errs []error
+	for {
+		scratch, ok := p.scratches.Get().(*hyperscan.Scratch)
+		if !ok {
+			break
+		}
+		errs = append(errs, scratch.Free())
+	}
+	errs = append(errs, p.prototype.Free(), p.database.Close())
+	return errors.Join(errs...)
+}
+
+// scratch returns scratch space for one scan, cloning the prototype if the pool
+// is empty.
+func (p *Prefilter[T]) scratch() (*hyperscan.Scratch, error) {
+	if scratch, ok := p.scratches.Get().(*hyperscan.Scratch); ok {
+		return scratch, nil
+	}
+	scratch, err := p.prototype.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate Hyperscan scratch space: %w", err)
+	}
+	return scratch, nil
+}
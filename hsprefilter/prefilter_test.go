@@ -0,0 +1,104 @@
+//go:build hyperscan
+
+package hsprefilter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+func newTestTable(t *testing.T) *regexptable.RegexpTable[string] {
+	t.Helper()
+	table, err := regexptable.NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)-(\d+)`, "range").
+		AddPattern(`\d+`, "number").
+		AddPatternWithPriority(`err(or)?`, "error", 5).
+		AddPattern(`[a-z]+`, "word").
+		WithPrecompileIndividuals(true).
+		Build(false, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return table
+}
+
+func TestPrefilter_AgreesWithTable(t *testing.T) {
+	table := newTestTable(t)
+	prefilter, err := New(table, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer prefilter.Close()
+
+	for _, input := range []string{"12-34", "x 42", "error", "an error 7", "ERROR", "", "?!", "3-x"} {
+		want, wantErr := table.LookupResult(input)
+		got, gotErr := prefilter.LookupResult(input)
+		if (wantErr == nil) != (gotErr == nil) {
+			t.Errorf("%q: expected error %v, got %v", input, wantErr, gotErr)
+			continue
+		}
+		if wantErr != nil {
+			if !errors.Is(gotErr, regexptable.ErrNoMatch) {
+				t.Errorf("%q: expected ErrNoMatch, got %v", input, gotErr)
+			}
+			continue
+		}
+		if got.Value != want.Value || got.Index != want.Index || !slices.Equal(got.Groups, want.Groups) || got.Start != want.Start {
+			t.Errorf("%q: expected %v, got %v", input, want, got)
+		}
+	}
+}
+
+func TestPrefilter_Options(t *testing.T) {
+	table, err := regexptable.NewRegexpTableBuilder[string]().
+		AddPattern(`abc`, "abc").
+		WithCaseInsensitive(true).
+		WithNormalizers(regexptable.TrimSpace()).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	prefilter, err := New(table, Options{Caseless: true, Normalize: strings.TrimSpace})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer prefilter.Close()
+	if value, _, err := prefilter.Lookup("  ABC "); err != nil || value != "abc" {
+		t.Errorf("Expected abc, got %q, %v", value, err)
+	}
+}
+
+func TestPrefilter_Concurrent(t *testing.T) {
+	table := newTestTable(t)
+	prefilter, err := New(table, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer prefilter.Close()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if value, _, err := prefilter.Lookup("x 42"); err != nil || value != "word" {
+					t.Errorf("Expected word, got %q, %v", value, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNew_NoPatterns(t *testing.T) {
+	if _, err := New(regexptable.NewRegexpTable[string](false, false), Options{}); !errors.Is(err, regexptable.ErrNoPatterns) {
+		t.Errorf("Expected ErrNoPatterns, got %v", err)
+	}
+}
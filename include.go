@@ -0,0 +1,126 @@
+package regexptable
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ParseSpecFile reads a JSON spec from a file, resolving its includes, and
+// validates it according to the loader's mode.
+//
+// A spec may list other specs in its include field, by paths relative to its own
+// file, so that rule sets which share a large common base need not copy it. The
+// included specs, which may include others in turn, are read in order and their
+// entries come first; an entry with the same pattern and flags as one from an
+// earlier file replaces it where it stands, which overrides its value, priority
+// and the rest, and the other entries are added after them. Entries within one
+// file never replace each other. The anchoring comes from the including spec, as
+// do its name, description and engine unless they are empty. Every file is
+// validated on its own, and an include cycle is an error. Only JSON specs can be
+// included, so an include whose name does not end in .json is an error.
+func (l *Loader[T]) ParseSpecFile(name string) (*Spec, error) {
+	return l.parseIncluding(name, os.ReadFile, func(from, include string) string {
+		return filepath.Join(filepath.Dir(from), include)
+	}, nil)
+}
+
+// ParseSpecFS is like ParseSpecFile but reads the specs from a file system, such
+// as an embed.FS, where paths use forward slashes.
+func (l *Loader[T]) ParseSpecFS(fsys fs.FS, name string) (*Spec, error) {
+	return l.parseIncluding(name, func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}, func(from, include string) string {
+		return path.Join(path.Dir(from), include)
+	}, nil)
+}
+
+// LoadFile reads a JSON spec from a file, resolving its includes, and builds the
+// table it describes.
+func (l *Loader[T]) LoadFile(name string) (*RegexpTable[T], error) {
+	spec, err := l.ParseSpecFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return l.Build(spec)
+}
+
+// parseIncluding reads the spec called name with read and merges in the specs it
+// includes, whose names resolve finds. including lists the specs being read that
+// include this one, to detect cycles.
+func (l *Loader[T]) parseIncluding(name string, read func(string) ([]byte, error), resolve func(from, include string) string, including []string) (*Spec, error) {
+	if slices.Contains(including, name) {
+		return nil, codeErrorf(CodeInvalidSpec, "spec %s includes itself via %q", name, including)
+	}
+	data, err := read(name)
+	if err != nil {
+		return nil, codeErrorf(CodeInvalidSpec, "failed to read spec: %w", err)
+	}
+	spec, err := l.decodeSpec(bytes.NewReader(data))
+	if err == nil {
+		err = l.checkSpec(spec)
+	}
+	if err != nil {
+		return nil, codeErrorf(CodeInvalidSpec, "spec %s: %w", name, err)
+	}
+	if len(spec.Include) == 0 {
+		return spec, nil
+	}
+
+	merged := &Spec{}
+	for _, include := range spec.Include {
+		if !strings.EqualFold(path.Ext(include), ".json") {
+			return nil, codeErrorf(CodeInvalidSpec, "spec %s: include %q is not a JSON spec", name, include)
+		}
+		base, err := l.parseIncluding(resolve(name, include), read, resolve, append(slices.Clip(including), name))
+		if err != nil {
+			return nil, err
+		}
+		mergeSpec(merged, base)
+	}
+	mergeSpec(merged, spec)
+	merged.Version = spec.Version
+	merged.AnchorStart, merged.AnchorEnd = spec.AnchorStart, spec.AnchorEnd
+	return merged, nil
+}
+
+// mergeSpec adds the entries of another spec to a spec, each replacing any entry
+// already there with the same pattern and flags, and takes its name, description
+// and engine unless they are empty. Each existing entry is replaced at most once,
+// so that entries of the other spec do not replace each other.
+func mergeSpec(spec, other *Spec) {
+	if other.Name != "" {
+		spec.Name = other.Name
+	}
+	if other.Description != "" {
+		spec.Description = other.Description
+	}
+	if other.Engine != "" {
+		spec.Engine = other.Engine
+	}
+	existing := make(map[string]int, len(spec.Entries))
+	for i, entry := range spec.Entries {
+		existing[mergeKey(entry)] = i
+	}
+	for _, entry := range other.Entries {
+		key := mergeKey(entry)
+		if i, ok := existing[key]; ok {
+			spec.Entries[i] = entry
+			delete(existing, key)
+		} else {
+			spec.Entries = append(spec.Entries, entry)
+		}
+	}
+}
+
+// mergeKey identifies the entries that replace each other when specs are merged:
+// those with the same pattern and the same flags, in any order.
+func mergeKey(entry SpecEntry) string {
+	flags := []byte(entry.Flags)
+	slices.Sort(flags)
+	return string(flags) + "/" + entry.Pattern
+}
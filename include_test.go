@@ -0,0 +1,125 @@
+package regexptable
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoader_ParseSpecFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/base.json": {Data: []byte(`{"version": 1, "name": "base", "entries": [
+			{"pattern": "\\d+", "value": "number"},
+			{"pattern": "[a-z]+", "value": "word"},
+			{"pattern": "[A-Z]+", "value": "shout"}
+		]}`)},
+		"common/extra.json": {Data: []byte(`{"version": 1, "entries": [
+			{"pattern": "[A-Z]+", "value": "upper"}
+		]}`)},
+		"product/rules.json": {Data: []byte(`{"version": 1, "description": "product rules", "include": ["../common/base.json", "../common/extra.json"],
+			"anchorStart": true, "anchorEnd": true, "entries": [
+			{"pattern": "[a-z]+", "value": "identifier", "priority": 5},
+			{"pattern": "\\s+", "value": "space"}
+		]}`)},
+	}
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpecFS(fsys, "product/rules.json")
+	if err != nil {
+		t.Fatalf("ParseSpecFS failed: %v", err)
+	}
+	if spec.Name != "base" || spec.Description != "product rules" || !spec.AnchorStart || len(spec.Include) != 0 {
+		t.Errorf("Expected the fields to be merged, got %+v", spec)
+	}
+	want := []struct{ pattern, value string }{
+		{`\d+`, `"number"`}, {`[a-z]+`, `"identifier"`}, {`[A-Z]+`, `"upper"`}, {`\s+`, `"space"`},
+	}
+	if len(spec.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), spec.Entries)
+	}
+	for i, w := range want {
+		if spec.Entries[i].Pattern != w.pattern || string(spec.Entries[i].Value) != w.value {
+			t.Errorf("Expected entry %d to be %s %s, got %s %s", i, w.pattern, w.value, spec.Entries[i].Pattern, spec.Entries[i].Value)
+		}
+	}
+	if spec.Entries[1].Priority != 5 {
+		t.Errorf("Expected the overriding priority, got %d", spec.Entries[1].Priority)
+	}
+
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if value, _, _ := table.Lookup("abc"); value != "identifier" {
+		t.Errorf("Expected identifier, got %q", value)
+	}
+}
+
+func TestLoader_IncludeMergeKeys(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.json": {Data: []byte(`{"version": 1, "entries": [
+			{"pattern": "abc", "value": "plain"},
+			{"pattern": "abc", "flags": "im", "value": "folded"}
+		]}`)},
+		"rules.json": {Data: []byte(`{"version": 1, "include": ["base.json"], "entries": [
+			{"pattern": "abc", "flags": "mi", "value": "override"},
+			{"pattern": "x", "value": "first"},
+			{"pattern": "x", "value": "second"}
+		]}`)},
+	}
+	spec, err := NewLoader[string](LoadStrict).ParseSpecFS(fsys, "rules.json")
+	if err != nil {
+		t.Fatalf("ParseSpecFS failed: %v", err)
+	}
+	// Only the entry with the same flags is replaced, and the including file's
+	// own entries with the same pattern are all kept.
+	want := []string{`"plain"`, `"override"`, `"first"`, `"second"`}
+	if len(spec.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), spec.Entries)
+	}
+	for i, w := range want {
+		if string(spec.Entries[i].Value) != w {
+			t.Errorf("Expected entry %d to be %s, got %s", i, w, spec.Entries[i].Value)
+		}
+	}
+}
+
+func TestLoader_IncludeErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.json":       {Data: []byte(`{"version": 1, "include": ["b.json"], "entries": []}`)},
+		"b.json":       {Data: []byte(`{"version": 1, "include": ["a.json"], "entries": []}`)},
+		"missing.json": {Data: []byte(`{"version": 1, "include": ["nowhere.json"], "entries": []}`)},
+		"bad.json":     {Data: []byte(`{"version": 1, "include": ["invalid.json"], "entries": []}`)},
+		"invalid.json": {Data: []byte(`{"version": 1, "entries": [{"pattern": "x"}]}`)},
+		"yaml.json":    {Data: []byte(`{"version": 1, "include": ["base.yaml"], "entries": []}`)},
+		"base.yaml":    {Data: []byte("version: 1\nentries: []\n")},
+	}
+	loader := NewLoader[string](LoadStrict)
+	for _, name := range []string{"a.json", "missing.json", "bad.json", "yaml.json"} {
+		if _, err := loader.ParseSpecFS(fsys, name); ErrorCodeOf(err) != CodeInvalidSpec {
+			t.Errorf("Expected %s for %s, got %v", CodeInvalidSpec, name, err)
+		}
+	}
+	if _, err := loader.ParseSpec(bytes.NewReader(fsys["a.json"].Data)); ErrorCodeOf(err) != CodeInvalidSpec {
+		t.Errorf("Expected a spec with includes to need a file, got %v", err)
+	}
+}
+
+func TestLoader_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "base.json"), []byte(`{"version": 1, "entries": [{"pattern": "\\d+", "value": "number"}]}`), 0o644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sub", "rules.json"), []byte(`{"version": 1, "include": ["../base.json"], "anchorStart": true, "anchorEnd": true, "entries": [{"pattern": "\\w+", "value": "word"}]}`), 0o644)
+
+	table, err := NewLoader[string](LoadStrict).LoadFile(filepath.Join(dir, "sub", "rules.json"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if value, _, _ := table.Lookup("42"); value != "number" {
+		t.Errorf("Expected number from the included spec, got %q", value)
+	}
+	if value, _, _ := table.Lookup("abc"); value != "word" {
+		t.Errorf("Expected word, got %q", value)
+	}
+}
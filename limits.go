@@ -0,0 +1,81 @@
+package regexptable
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// InputTooLongError reports a lookup whose input is longer than the table
+// allows, see SetMaxInputLength.
+type InputTooLongError struct {
+	Length int // The length of the input in bytes
+	Max    int // The maximum the table allows
+}
+
+func (e *InputTooLongError) Error() string {
+	return fmt.Sprintf("input of %d bytes exceeds the maximum of %d", e.Length, e.Max)
+}
+
+// ErrorCode returns CodeInputTooLong.
+func (e *InputTooLongError) ErrorCode() ErrorCode {
+	return CodeInputTooLong
+}
+
+// SetMaxInputLength limits the length in bytes of the inputs the table matches,
+// which protects services such as log ingestion from pathological multi-megabyte
+// lines. An input longer than n is rejected with an *InputTooLongError, or, if
+// truncate is set, cut short to at most n bytes, without splitting a UTF-8
+// sequence, and matched as if that were the whole input. Zero or less removes
+// the limit.
+//
+// The limit applies to the input before any normalization, in the string
+// lookups, Lookup, LookupResult and the methods built on them, and in
+// LookupAnchored, LookupCandidates, LookupAll and the byte slice lookups.
+func (rt *RegexpTable[T]) SetMaxInputLength(n int, truncate bool) {
+	rt.maxInputLength = max(n, 0)
+	rt.truncateInput = truncate
+}
+
+// limitInput applies the table's maximum input length to an input.
+func (rt *RegexpTable[T]) limitInput(input string) (string, error) {
+	if rt.maxInputLength == 0 || len(input) <= rt.maxInputLength {
+		return input, nil
+	}
+	if !rt.truncateInput {
+		return "", &InputTooLongError{Length: len(input), Max: rt.maxInputLength}
+	}
+	return input[:truncationPoint(input[:rt.maxInputLength+1])], nil
+}
+
+// limitBytes is limitInput for byte slices.
+func (rt *RegexpTable[T]) limitBytes(input []byte) ([]byte, error) {
+	if rt.maxInputLength == 0 || len(input) <= rt.maxInputLength {
+		return input, nil
+	}
+	if !rt.truncateInput {
+		return nil, &InputTooLongError{Length: len(input), Max: rt.maxInputLength}
+	}
+	return input[:truncationPoint(input[:rt.maxInputLength+1])], nil
+}
+
+// truncationPoint returns where to cut text, one byte longer than the maximum, so
+// that it is shortened without splitting a UTF-8 sequence. Invalid UTF-8 may be
+// cut anywhere.
+func truncationPoint[S string | []byte](text S) int {
+	cut := len(text) - 1
+	for i := 1; i < utf8.UTFMax && cut > 0 && !utf8.RuneStart(text[cut]); i++ {
+		cut--
+	}
+	if !utf8.RuneStart(text[cut]) {
+		return len(text) - 1
+	}
+	return cut
+}
+
+// WithMaxInputLength requests that the built table limits the length of the
+// inputs it matches. See RegexpTable.SetMaxInputLength.
+func (b *RegexpTableBuilder[T]) WithMaxInputLength(n int, truncate bool) *RegexpTableBuilder[T] {
+	b.maxInputLength = n
+	b.truncateInput = truncate
+	return b
+}
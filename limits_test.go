@@ -0,0 +1,72 @@
+package regexptable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_MaxInputLength(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	table.AddPattern(`[a-zé]+`, "word")
+	table.SetMaxInputLength(8, false)
+
+	if value, _, err := table.Lookup("abcdefgh"); err != nil || value != "word" {
+		t.Errorf("Expected an input at the limit to match, got %q, %v", value, err)
+	}
+	_, _, err := table.Lookup("abcdefghi")
+	var tooLong *InputTooLongError
+	if !errors.As(err, &tooLong) || tooLong.Length != 9 || tooLong.Max != 8 {
+		t.Fatalf("Expected an InputTooLongError, got %v", err)
+	}
+	if ErrorCodeOf(err) != CodeInputTooLong {
+		t.Errorf("Expected %s, got %q", CodeInputTooLong, ErrorCodeOf(err))
+	}
+	for name, lookup := range map[string]func(string) error{
+		"LookupResult":     func(s string) error { _, err := table.LookupResult(s); return err },
+		"LookupAnchored":   func(s string) error { _, _, err := table.LookupAnchored(s, AnchorNone); return err },
+		"LookupCandidates": func(s string) error { _, err := table.LookupCandidates(s, []int{0}); return err },
+		"LookupAll":        func(s string) error { _, err := table.LookupAll(s); return err },
+		"LookupBytes":      func(s string) error { _, _, err := table.LookupBytes([]byte(s)); return err },
+	} {
+		if err := lookup(strings.Repeat("a", 100)); !errors.As(err, &tooLong) {
+			t.Errorf("Expected %s to reject the input, got %v", name, err)
+		}
+	}
+
+	table.SetMaxInputLength(8, true)
+	result, err := table.LookupResult("abcdefgéé") // é is two bytes
+	if err != nil || result.Groups[0] != "abcdefg" {
+		t.Errorf("Expected the input to be truncated before the é, got %v, %v", result, err)
+	}
+	if _, matches, err := table.LookupBytes([]byte("abcdefghij")); err != nil || matches[0] != "abcdefgh" {
+		t.Errorf("Expected the bytes to be truncated, got %q, %v", matches, err)
+	}
+
+	built := NewRegexpTableBuilder[string]().AddPattern(`a+`, "a").WithMaxInputLength(2, false).MustBuild(true, true)
+	if _, _, err := built.Lookup("aaa"); !errors.As(err, &tooLong) {
+		t.Errorf("Expected the built table to reject the input, got %v", err)
+	}
+
+	table.SetMaxInputLength(0, false)
+	if _, _, err := table.Lookup(strings.Repeat("a", 100)); err != nil {
+		t.Errorf("Expected no limit, got %v", err)
+	}
+}
+
+func TestTruncationPoint(t *testing.T) {
+	testCases := []struct {
+		text string
+		want int
+	}{
+		{"abcd", 3},
+		{"abé", 2},                  // Cut before a two-byte rune
+		{"a€", 1},                   // Cut before a three-byte rune
+		{"\x80\x80\x80\x80\x80", 4}, // Invalid UTF-8 is cut at the maximum
+	}
+	for _, tc := range testCases {
+		if got := truncationPoint(tc.text); got != tc.want {
+			t.Errorf("Expected %d for %q, got %d", tc.want, tc.text, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package regexptable
+
+import "slices"
+
+// located is the outcome of a match in the table core: the winning entry and its
+// submatches, numbered as in the entry's own pattern. When the engine's compiled
+// regexps implement IndexMatcher, every lookup path reports the submatches as
+// offsets in the input and their text is only cut out when a caller asks for it,
+// so that lookups returning spans, such as LookupResult, need not match again to
+// find them. Other engines report the text, and the offsets are unknown.
+type located[T any] struct {
+	entry *ValueAndPattern[T]
+	input string   // The input the offsets refer to
+	loc   []int    // Index pairs of the submatches in input, nil if unknown
+	text  []string // The submatches, when their offsets are unknown
+}
+
+// indexed returns the match of an entry whose submatches have the given offsets.
+func indexed[T any](entry *ValueAndPattern[T], input string, loc []int) located[T] {
+	return located[T]{entry: entry, input: input, loc: loc}
+}
+
+// textual returns the match of an entry whose submatches are known only by their
+// text.
+func textual[T any](entry *ValueAndPattern[T], matches []string) located[T] {
+	return located[T]{entry: entry, text: matches}
+}
+
+// submatches returns the text of the submatches, storing it in buf if it has the
+// capacity, as pooled results do, see SetPooling; buf may be nil. Submatches known
+// only by their text are returned as they are.
+func (l located[T]) submatches(buf []string) []string {
+	if l.loc == nil {
+		return l.text
+	}
+	return appendSubmatches(buf[:0], l.input, l.loc)
+}
+
+// submatchesAt returns the text of the submatches whose index pairs are given,
+// "" for those that did not participate.
+func submatchesAt(input string, loc []int) []string {
+	return appendSubmatches(nil, input, loc)
+}
+
+// appendSubmatches is submatchesAt appending to buf.
+func appendSubmatches(buf []string, input string, loc []int) []string {
+	buf = slices.Grow(buf, len(loc)/2)
+	for i := 0; i < len(loc); i += 2 {
+		group := ""
+		if loc[i] >= 0 {
+			group = input[loc[i]:loc[i+1]]
+		}
+		buf = append(buf, group)
+	}
+	return buf
+}
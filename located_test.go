@@ -0,0 +1,98 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+// countingEngine is the standard engine, counting the index-based matches made by
+// its compiled regexps.
+type countingEngine struct {
+	StandardRegexpEngine
+	matches int
+}
+
+type countingRegexp struct {
+	CompiledRegexp
+	engine *countingEngine
+}
+
+func (e *countingEngine) Compile(pattern string) (CompiledRegexp, error) {
+	compiled, err := e.StandardRegexpEngine.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &countingRegexp{compiled, e}, nil
+}
+
+func (r *countingRegexp) FindStringSubmatchIndex(input string) []int {
+	r.engine.matches++
+	return r.CompiledRegexp.(IndexMatcher).FindStringSubmatchIndex(input)
+}
+
+func TestLookupResult_MatchesOnce(t *testing.T) {
+	engine := &countingEngine{}
+	table := NewRegexpTableWithEngine[string](engine, false, false)
+	table.AddPattern(`(\d+)-(\d+)`, "range")
+	table.AddPattern(`[a-z]+`, "word")
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+
+	engine.matches = 0
+	result, err := table.LookupResult("from 3-14")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if engine.matches != 1 {
+		t.Errorf("Expected the union to be matched once, got %d matches", engine.matches)
+	}
+	if result.Value != "word" || result.Start != 0 || result.End != 4 {
+		t.Errorf("Expected word at [0,4), got %s", result)
+	}
+	result, _ = table.LookupResult("3-14")
+	if start, end := result.GroupSpan(2); result.Value != "range" || start != 2 || end != 4 {
+		t.Errorf("Expected the second group at [2,4), got %s", result)
+	}
+}
+
+func TestLookupResult_PathsAgree(t *testing.T) {
+	type lookup struct {
+		groups []string
+		spans  []int
+	}
+	expected := map[string]lookup{
+		"key=value": {[]string{"key=value", "value"}, []int{0, 9, 4, 9}},
+		"key=":      {[]string{"key=", ""}, []int{0, 4, 4, 4}},
+		"id 42":     {[]string{"id 42", "42"}, []int{0, 5, 3, 5}},
+		"idle":      {[]string{"idle"}, []int{0, 4}},
+	}
+	configure := map[string]func(*RegexpTableBuilder[string]) *RegexpTableBuilder[string]{
+		"union":     func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b },
+		"two-phase": func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithTwoPhaseLookup(true) },
+		"dispatch":  func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithPrefixDispatch(true) },
+		"memo":      func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithMemoization(10, false) },
+	}
+	for name, with := range configure {
+		table := with(NewRegexpTableBuilder[string]().
+			AddPattern(`key=(\w*)`, "pair").
+			AddPatternExcluding(`id(\w*)`, "ident", Exclusion{NotMatching: `idle`}).
+			AddPattern(`id (\d+)`, "id").
+			AddPattern(`\w+`, "word")).
+			MustBuild(true, true)
+		for input, want := range expected {
+			result, err := table.LookupResult(input)
+			if err != nil {
+				t.Fatalf("%s: LookupResult(%q) failed: %v", name, input, err)
+			}
+			got := lookup{groups: result.Groups}
+			for i := range result.Groups {
+				start, end := result.GroupSpan(i)
+				got.spans = append(got.spans, start, end)
+			}
+			if !slices.Equal(got.groups, want.groups) || !slices.Equal(got.spans, want.spans) {
+				t.Errorf("%s: expected %v for %q, got %v", name, want, input, got)
+			}
+		}
+	}
+}
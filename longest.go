@@ -0,0 +1,71 @@
+package regexptable
+
+// TieCandidate describes a pattern that matched the longest lexeme at a position,
+// as passed to a TieBreak.
+type TieCandidate[T any] struct {
+	Value    T
+	Pattern  string // The pattern as it was added to the table
+	Priority int    // The pattern's priority, see RegexpTableBuilder.AddPatternWithPriority
+	Index    int    // The pattern's position in insertion order, see Result.Index
+}
+
+// TieBreak chooses between two patterns that match lexemes of the same length in
+// longest-match mode, returning a negative number if a should win, a positive one
+// if b should, and zero to leave the choice to insertion order.
+type TieBreak[T any] func(a, b TieCandidate[T]) int
+
+// SetLongestMatch puts the tokenizer in longest-match mode, in which each token is
+// the longest lexeme that any pattern matches at the current offset, rather than
+// the lexeme of the first pattern in table order that matches there. This is the
+// maximal munch rule of most lexers, under which, say, >>= is one operator rather
+// than > followed by >=, whatever the order of the patterns.
+//
+// When several patterns match the longest lexeme, tieBreak picks the winner, e.g.
+// making keywords beat identifiers, and patterns it cannot separate are taken in
+// insertion order; a nil tieBreak leaves insertion order alone to decide. Since
+// every pattern is tried at every offset, longest-match mode is much slower than
+// the default, and the engine's compiled regexps must implement IndexMatcher.
+func (tk *Tokenizer[T]) SetLongestMatch(tieBreak TieBreak[T]) {
+	tk.longest = true
+	tk.tieBreak = tieBreak
+}
+
+// lookupLongest returns the value and submatches of the pattern that matches the
+// longest lexeme at the start of the input, see SetLongestMatch.
+func (tk *Tokenizer[T]) lookupLongest(input string) (T, []string, error) {
+	var zero T
+	results, _, err := tk.table.lookupAll(input)
+	if err != nil {
+		return zero, nil, err
+	}
+	var best *Result[T]
+	for _, result := range results {
+		if best == nil || tk.beats(result, best) {
+			best = result
+		}
+	}
+	return best.Value, best.Groups, nil
+}
+
+// beats reports whether result should win over best in longest-match mode.
+func (tk *Tokenizer[T]) beats(result, best *Result[T]) bool {
+	if len(result.Groups[0]) != len(best.Groups[0]) {
+		return len(result.Groups[0]) > len(best.Groups[0])
+	}
+	if tk.tieBreak != nil {
+		if order := tk.tieBreak(tk.candidate(result), tk.candidate(best)); order != 0 {
+			return order < 0
+		}
+	}
+	return result.Index < best.Index
+}
+
+// candidate describes a result to a TieBreak.
+func (tk *Tokenizer[T]) candidate(result *Result[T]) TieCandidate[T] {
+	return TieCandidate[T]{
+		Value:    result.Value,
+		Pattern:  result.Pattern,
+		Priority: result.priority,
+		Index:    result.Index,
+	}
+}
@@ -0,0 +1,119 @@
+package regexptable
+
+import (
+	"cmp"
+	"testing"
+)
+
+func TestTokenizer_LongestMatch(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\s+`, "space").
+		AddPattern(`>`, "gt").
+		AddPattern(`>=`, "ge").
+		AddPattern(`>>`, "shr").
+		AddPattern(`>>=`, "shr-assign").
+		AddPattern(`[a-z]+`, "ident").
+		MustBuild(true, false)
+
+	tokenizer := NewTokenizer(table, "a >>= b > c")
+	tokenizer.SetLongestMatch(nil)
+	tokens, err := collectTokens(tokenizer)
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	expected := []string{"ident", "space", "shr-assign", "space", "ident", "space", "gt", "space", "ident"}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i, want := range expected {
+		if tokens[i].Value != want {
+			t.Errorf("Token %d: expected %s, got %s", i, want, tokens[i].Value)
+		}
+	}
+
+	// In table order, the first pattern to match wins instead.
+	tokens, err = Tokenize(table, ">>")
+	if err != nil || len(tokens) != 2 || tokens[0].Value != "gt" {
+		t.Errorf("Expected two gt tokens by default, got %v, %v", tokens, err)
+	}
+}
+
+func TestTokenizer_LongestMatchTieBreak(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`[a-z]+`, "ident").
+		AddPattern(`if|else`, "keyword").
+		AddPattern(`\s+`, "space").
+		MustBuild(true, false)
+
+	// Without a tie break, insertion order decides, and ident was added first.
+	tokenizer := NewTokenizer(table, "if")
+	tokenizer.SetLongestMatch(nil)
+	token, ok := tokenizer.Next()
+	if !ok || token.Value != "ident" {
+		t.Errorf("Expected ident by insertion order, got %+v", token)
+	}
+
+	keywordsFirst := func(a, b TieCandidate[string]) int {
+		switch {
+		case a.Value == b.Value:
+			return 0
+		case a.Value == "keyword":
+			return -1
+		case b.Value == "keyword":
+			return 1
+		}
+		return 0
+	}
+	tokenizer = NewTokenizer(table, "if iffy")
+	tokenizer.SetLongestMatch(keywordsFirst)
+	tokens, err := collectTokens(tokenizer)
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if len(tokens) != 3 || tokens[0].Value != "keyword" || tokens[2].Value != "ident" || tokens[2].Lexeme != "iffy" {
+		t.Errorf("Expected keyword, space, ident, got %v", tokens)
+	}
+
+	// A tie break that cannot separate the candidates falls back to insertion order.
+	tokenizer = NewTokenizer(table, "else")
+	tokenizer.SetLongestMatch(func(a, b TieCandidate[string]) int { return 0 })
+	token, ok = tokenizer.Next()
+	if !ok || token.Value != "ident" {
+		t.Errorf("Expected ident by insertion order, got %+v", token)
+	}
+}
+
+func TestTokenizer_LongestMatchPriority(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPatternWithPriority(`[a-z]+`, "ident", 0).
+		AddPatternWithPriority(`if|else`, "keyword", 1).
+		MustBuild(true, false)
+
+	// The builder orders keyword first, but a tie break may prefer lower priorities.
+	tokenizer := NewTokenizer(table, "if")
+	tokenizer.SetLongestMatch(func(a, b TieCandidate[string]) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	token, ok := tokenizer.Next()
+	if !ok || token.Value != "ident" {
+		t.Errorf("Expected ident by lower priority, got %+v", token)
+	}
+}
+
+func TestTokenizer_LongestMatchUnknown(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`a`, "a").
+		AddPattern(`ab`, "ab").
+		MustBuild(true, false)
+
+	tokenizer := NewTokenizer(table, "ab?a")
+	tokenizer.SetLongestMatch(nil)
+	tokenizer.SetUnknownValue("?")
+	tokens, err := collectTokens(tokenizer)
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if len(tokens) != 3 || tokens[0].Value != "ab" || !tokens[1].Unmatched || tokens[2].Value != "a" {
+		t.Errorf("Expected ab, unmatched, a, got %v", tokens)
+	}
+}
@@ -0,0 +1,80 @@
+package regexptable
+
+// LookupAll is like LookupResult but returns a Result for every pattern that
+// matches the input, in the order the table tries them, rather than for the
+// winner alone. Each pattern is matched on its own with the table's anchoring and
+// reports its own leftmost match; matches rejected by a pattern's exclusion do
+// not count. It returns ErrNoMatch if no pattern matches. Matching every pattern
+// individually makes this much slower than LookupResult, and the engine's
+// compiled regexps must implement IndexMatcher.
+func (rt *RegexpTable[T]) LookupAll(input string) ([]*Result[T], error) {
+	results, _, err := rt.lookupAll(input)
+	return results, err
+}
+
+// LookupAllFields merges the named groups of every pattern that matches the input,
+// as found by LookupAll, into a map from each group name to the texts captured
+// under that name, in the order the table tries the patterns. Groups that take
+// no part in a pattern's match are left out. This suits enrichment stages that
+// extract whatever fields any rule can find.
+func (rt *RegexpTable[T]) LookupAllFields(input string) (map[string][]string, error) {
+	results, locs, err := rt.lookupAll(input)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string][]string)
+	for i, result := range results {
+		for k, name := range result.Names {
+			if name != "" && locs[i][2*k] >= 0 {
+				fields[name] = append(fields[name], result.Groups[k])
+			}
+		}
+	}
+	return fields, nil
+}
+
+// lookupAll does the work of LookupAll, also returning the index pairs of each
+// result's submatches in the normalized input.
+func (rt *RegexpTable[T]) lookupAll(input string) ([]*Result[T], [][]int, error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, nil, err
+	}
+	input, err = rt.limitInput(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalized, offsets := input, []int(nil)
+	if rt.normalizers != nil {
+		normalized, offsets = rt.normalize(input)
+	}
+
+	var results []*Result[T]
+	var locs [][]int
+	for _, entry := range rt.maplets {
+		compiled, err := rt.individualRegexp(entry)
+		if err != nil {
+			return nil, nil, codeErrorf(CodeCompileFailed, "failed to compile pattern '%s': %w", entry.Pattern, err)
+		}
+		matcher, ok := compiled.(IndexMatcher)
+		if !ok {
+			return nil, nil, codeErrorf(CodeEngineUnsupported, "regexp engine does not report match offsets, see IndexMatcher")
+		}
+		loc := matcher.FindStringSubmatchIndex(normalized)
+		if loc == nil || entry.exclusion != nil && entry.exclusion.rejects(normalized, loc[0], loc[1]) {
+			continue
+		}
+		result := rt.newResult(entry, submatchesAt(normalized, loc))
+		result.locate(loc, offsets)
+		result.complete = loc[1]-loc[0] == len(normalized)
+		results = append(results, result)
+		locs = append(locs, loc)
+	}
+	if len(results) == 0 {
+		if len(rt.maplets) == 0 {
+			return nil, nil, ErrNoPatterns
+		}
+		return nil, nil, ErrNoMatch
+	}
+	return results, locs, nil
+}
@@ -0,0 +1,69 @@
+package regexptable
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegexpTable_LookupAll(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`user=(?P<user>\w+)`, "user").
+		AddPattern(`ip=(?P<ip>[\d.]+)`, "ip").
+		AddPattern(`(?P<user>\w+)@(?P<host>[\w.]+)`, "email").
+		AddPattern(`port=(?P<port>\d+)`, "port").
+		AddPatternExcluding(`(?P<word>[a-z]+)`, "word", Exclusion{NotMatching: `user|ip`}).
+		MustBuild(false, false)
+
+	input := "user=alice ip=10.0.0.1 from bob@example.com"
+	results, err := table.LookupAll(input)
+	if err != nil {
+		t.Fatalf("LookupAll failed: %v", err)
+	}
+	var values []string
+	for _, result := range results {
+		values = append(values, result.Value)
+		if input[result.Start:result.End] != result.Groups[0] {
+			t.Errorf("Expected the span to locate the match, got %s", result)
+		}
+	}
+	// The word pattern's leftmost match, user, is excluded.
+	if !reflect.DeepEqual(values, []string{"user", "ip", "email"}) {
+		t.Errorf("Expected user, ip and email to match, got %v", values)
+	}
+
+	fields, err := table.LookupAllFields(input)
+	if err != nil {
+		t.Fatalf("LookupAllFields failed: %v", err)
+	}
+	if !reflect.DeepEqual(fields["ip"], []string{"10.0.0.1"}) || fields["host"][0] != "example.com" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if !reflect.DeepEqual(fields["user"], []string{"alice", "bob"}) {
+		t.Errorf("Expected the user field from both patterns, got %v", fields["user"])
+	}
+	if _, ok := fields["port"]; ok {
+		t.Errorf("Expected no port field, got %v", fields)
+	}
+
+	if _, err := table.LookupAll("!!!"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+	if _, err := NewRegexpTable[string](false, false).LookupAllFields("x"); !errors.Is(err, ErrNoPatterns) {
+		t.Errorf("Expected ErrNoPatterns, got %v", err)
+	}
+}
+
+func TestRegexpTable_LookupAllFieldsOptionalGroups(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(?P<number>\d+)(?P<unit>px)?`, "length").
+		AddPattern(`(?P<unit>em|px)`, "unit").
+		MustBuild(false, false)
+	fields, err := table.LookupAllFields("width: 12 or 3em")
+	if err != nil {
+		t.Fatalf("LookupAllFields failed: %v", err)
+	}
+	if !reflect.DeepEqual(fields, map[string][]string{"number": {"12"}, "unit": {"em"}}) {
+		t.Errorf("Expected groups that took no part to be left out, got %v", fields)
+	}
+}
@@ -0,0 +1,128 @@
+package regexptable
+
+import "slices"
+
+// MapValues returns a table with the same patterns, options and anchoring as rt
+// but with every value transformed by fn, which suits a rule set that feeds
+// components wanting different value types, such as their own enums. The new
+// table shares rt's compiled regexps rather than compiling them again, so making
+// it is cheap; rt is compiled first if it needs to be. fn is called once for each
+// value, in table order, and the tables are independent afterwards: changes to
+// either, including SetDiagnostics, do not affect the other.
+//
+// The new table starts with fresh hit statistics and an empty memoization cache,
+// and without rt's callbacks, which take values of the old type. It keeps rt's
+// diagnostic hook and Sampler, so the two tables draw on one sampling budget
+// until either is given new diagnostics. MapValues is a function rather than a
+// method because methods cannot have type parameters.
+func MapValues[T, U any](rt *RegexpTable[T], fn func(T) U) *RegexpTable[U] {
+	_ = rt.ensureCompiled() // A table that fails to compile reports it on use instead
+
+	// Every field of RegexpTable is either carried over here or deliberately left
+	// fresh; TestMapValues_Fields fails when a new field is neither.
+	mapped := &RegexpTable[U]{
+		engine:           rt.engine,
+		compiled:         rt.compiled,
+		nextGroupID:      rt.nextGroupID,
+		needsRecompile:   rt.needsRecompile,
+		anchorStart:      rt.anchorStart,
+		anchorEnd:        rt.anchorEnd,
+		nonCapturing:     rt.nonCapturing,
+		prefixFactoring:  rt.prefixFactoring,
+		suffixFactoring:  rt.suffixFactoring,
+		literalOrdering:  rt.literalOrdering,
+		ungreedy:         rt.ungreedy,
+		caseInsensitive:  rt.caseInsensitive,
+		precompile:       rt.precompile,
+		allowReDoS:       rt.allowReDoS,
+		sharedMatches:    rt.sharedMatches,
+		unionPattern:     rt.unionPattern,
+		unionStripped:    rt.unionStripped,
+		unionFlags:       rt.unionFlags,
+		compiledUnion:    rt.compiledUnion,
+		normalizers:      slices.Clone(rt.normalizers),
+		hitStats:         rt.hitStats,
+		hitExamples:      rt.hitExamples,
+		adaptive:         rt.adaptive,
+		adaptivePromote:  rt.adaptivePromote,
+		reordered:        slices.Clone(rt.reordered),
+		variants:         new(anchoredVariants[U]),
+		nextExpiry:       rt.nextExpiry,
+		nextTransition:   rt.nextTransition,
+		now:              rt.now,
+		maxInputLength:   rt.maxInputLength,
+		truncateInput:    rt.truncateInput,
+		positionalGroups: rt.positionalGroups,
+		twoPhase:         rt.twoPhase,
+		omitWrapper:      rt.omitWrapper,
+		prefilter:        rt.prefilter,
+		tombstones:       rt.tombstones,
+		prefixDispatch:   rt.prefixDispatch,
+		dispatch:         rt.dispatch,
+	}
+	if rt.diagnostics != nil {
+		diagnostics := *rt.diagnostics
+		mapped.diagnostics = &diagnostics
+	}
+	if rt.memo != nil {
+		mapped.memo = newMemoCache[U](rt.memo.capacity, rt.memo.recordComputed)
+	}
+	mapped.SetPooling(rt.results != nil)
+
+	// The prefilter and dispatch tree refer to entries by position, so the new
+	// entries must keep the old order.
+	entries := make([]ValueAndPattern[U], len(rt.maplets))
+	mapped.maplets = make([]*ValueAndPattern[U], len(rt.maplets))
+	counterparts := make(map[*ValueAndPattern[T]]*ValueAndPattern[U], len(rt.maplets))
+	for i, entry := range rt.maplets {
+		var moreValues []U
+		if entry.moreValues != nil {
+			moreValues = make([]U, len(entry.moreValues))
+		}
+		entries[i] = ValueAndPattern[U]{
+			GroupName:       entry.GroupName,
+			namedPattern:    entry.namedPattern,
+			Value:           fn(entry.Value),
+			Pattern:         entry.Pattern,
+			compiledPattern: entry.compiledPattern,
+			factoredPrefix:  entry.factoredPrefix,
+			factoredSuffix:  entry.factoredSuffix,
+			firstGroup:      entry.firstGroup,
+			groupCount:      entry.groupCount,
+			groupNames:      slices.Clone(entry.groupNames),
+			order:           entry.order,
+			source:          entry.source,
+			strippedPattern: entry.strippedPattern,
+			moreValues:      moreValues,
+			name:            entry.name,
+			hits:            mapped.newHits(),
+			entryAttrs:      entry.cloneAttrs(),
+			unionGroup:      entry.unionGroup,
+		}
+		for j, value := range entry.moreValues {
+			moreValues[j] = fn(value)
+		}
+		mapped.maplets[i] = &entries[i]
+		counterparts[entry] = &entries[i]
+	}
+
+	if rt.unionEntries != nil {
+		mapped.unionEntries = make([]*ValueAndPattern[U], len(rt.unionEntries))
+		for i, entry := range rt.unionEntries {
+			counterpart, ok := counterparts[entry]
+			if !ok {
+				// A tombstone: the union must be rebuilt from scratch next time.
+				mapped.unionEntries = nil
+				break
+			}
+			mapped.unionEntries[i] = counterpart
+		}
+	}
+	for literal, entry := range rt.hotLiterals {
+		if mapped.hotLiterals == nil {
+			mapped.hotLiterals = make(map[string]*ValueAndPattern[U], len(rt.hotLiterals))
+		}
+		mapped.hotLiterals[literal] = counterparts[entry]
+	}
+	return mapped
+}
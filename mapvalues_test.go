@@ -0,0 +1,122 @@
+package regexptable
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMapValues(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)`, "number").
+		AddPatternValues(`[a-z]+`, "word", "lower").
+		WithHitStats(true, 0).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+	calls := 0
+	mapped := MapValues(table, func(value string) int {
+		calls++
+		return len(value)
+	})
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if mapped.compiled != table.compiled {
+		t.Error("Expected the compiled union to be shared")
+	}
+
+	value, matches, err := mapped.Lookup("42")
+	if err != nil || value != 6 || !slices.Equal(matches, []string{"42", "42"}) {
+		t.Errorf("Expected 6 with [42 42], got %d with %v, %v", value, matches, err)
+	}
+	result, err := mapped.LookupResult("abc")
+	if err != nil || !slices.Equal(result.Values, []int{4, 5}) || result.Index != 1 {
+		t.Errorf("Expected [4 5] from pattern 1, got %v, %v", result, err)
+	}
+	if mapped.needsRecompile {
+		t.Error("Expected no recompilation")
+	}
+	if table.HitCount(0) != 0 || mapped.HitCount(0) != 1 {
+		t.Errorf("Expected separate hit statistics, got %d and %d", table.HitCount(0), mapped.HitCount(0))
+	}
+
+	// The tables are independent afterwards.
+	mapped.AddPattern(`[A-Z]+`, 99)
+	if value, _, err := mapped.Lookup("ABC"); err != nil || value != 99 {
+		t.Errorf("Expected 99, got %d, %v", value, err)
+	}
+	if _, _, err := table.Lookup("ABC"); err == nil {
+		t.Error("Expected the original table to be unchanged")
+	}
+	table.RemovePattern(`(\d+)`)
+	if value, _, err := mapped.Lookup("7"); err != nil || value != 6 {
+		t.Errorf("Expected 6, got %d, %v", value, err)
+	}
+}
+
+func TestMapValues_Uncompiled(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`a+`, "a")
+	mapped := MapValues(table, strings.ToUpper)
+	if value, _, err := mapped.Lookup("xaax"); err != nil || value != "A" {
+		t.Errorf("Expected A, got %q, %v", value, err)
+	}
+
+	table.AddPattern(`(`, "bad")
+	if _, _, err := MapValues(table, strings.ToUpper).Lookup("a"); ErrorCodeOf(err) != CodeCompileFailed {
+		t.Errorf("Expected %s, got %v", CodeCompileFailed, err)
+	}
+}
+
+func TestMapValues_Diagnostics(t *testing.T) {
+	var original, copied []Diagnostic
+	table := NewRegexpTable[string](true, true)
+	table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `if`})
+	table.AddPattern(`if`, "keyword")
+	table.SetDiagnostics(func(d Diagnostic) { original = append(original, d) }, nil)
+	mapped := MapValues(table, strings.ToUpper)
+
+	// Changing the new table's diagnostics and rules leaves the old table's alone.
+	mapped.SetDiagnostics(func(d Diagnostic) { copied = append(copied, d) }, nil)
+	mapped.AddPattern(`[0-9]+`, "NUMBER")
+	if value, _, _ := table.TryLookup("word"); value != "word" || len(original) != 1 || len(copied) != 0 {
+		t.Errorf("Expected word with one diagnostic for the old table, got %q with %d and %d", value, len(original), len(copied))
+	}
+	if _, _, err := table.Lookup("42"); err == nil {
+		t.Error("Expected the old table to be unchanged")
+	}
+	if value, _, _ := mapped.TryLookup("word"); value != "WORD" || len(original) != 1 || len(copied) != 1 {
+		t.Errorf("Expected WORD with one diagnostic for the new table, got %q with %d and %d", value, len(original), len(copied))
+	}
+
+	// And the other way round.
+	table.SetDiagnostics(nil, nil)
+	table.RemovePattern(`if`)
+	if value, _, _ := mapped.TryLookup("if"); value != "KEYWORD" || len(copied) != 2 {
+		t.Errorf("Expected KEYWORD with a second diagnostic, got %q with %d", value, len(copied))
+	}
+}
+
+func TestMapValues_Fields(t *testing.T) {
+	// The fields MapValues carries over, and those it leaves fresh on purpose.
+	handled := []string{
+		"engine", "compiled", "maplets", "nextGroupID", "needsRecompile", "anchorStart", "anchorEnd",
+		"nonCapturing", "prefixFactoring", "suffixFactoring", "literalOrdering", "ungreedy",
+		"caseInsensitive", "precompile", "allowReDoS", "memo", "sharedMatches", "unionPattern",
+		"unionEntries", "unionStripped", "unionFlags", "compiledUnion", "diagnostics", "normalizers",
+		"hitStats", "hitExamples", "adaptive", "adaptivePromote", "hotLiterals", "reordered", "variants",
+		"nextExpiry", "nextTransition", "now", "maxInputLength", "truncateInput", "positionalGroups",
+		"twoPhase", "omitWrapper", "prefilter", "tombstones", "prefixDispatch", "dispatch", "results",
+	}
+	fresh := []string{"observers", "matchCallbacks", "matchPanic"}
+	tableType := reflect.TypeFor[RegexpTable[int]]()
+	for i := range tableType.NumField() {
+		field := tableType.Field(i)
+		if !slices.Contains(handled, field.Name) && !slices.Contains(fresh, field.Name) {
+			t.Errorf("RegexpTable.%s is new: carry it over in MapValues and list it here", field.Name)
+		}
+	}
+}
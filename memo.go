@@ -0,0 +1,142 @@
+package regexptable
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+)
+
+// memoEntry is a cached lookup result.
+type memoEntry[T any] struct {
+	input    string
+	entry    *ValueAndPattern[T] // The winning entry, nil for computed values
+	value    T                   // The computed value, only used when computed is set
+	matches  []string
+	computed bool // Produced by a LookupOrCompute fallback rather than a pattern match
+}
+
+// memoCache is a bounded least-recently-used cache of lookup results. It is
+// guarded by a mutex because lookups, which populate it, may run concurrently.
+type memoCache[T any] struct {
+	mu             sync.Mutex
+	capacity       int
+	recordComputed bool
+	order          *list.List // Front is most recently used
+	items          map[string]*list.Element
+}
+
+func newMemoCache[T any](capacity int, recordComputed bool) *memoCache[T] {
+	return &memoCache[T]{
+		capacity:       capacity,
+		recordComputed: recordComputed,
+		order:          list.New(),
+		items:          make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached entry for input, if any, and marks it as recently used.
+func (c *memoCache[T]) get(input string) (memoEntry[T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[input]
+	if !ok {
+		return memoEntry[T]{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(memoEntry[T]), true
+}
+
+// put records an entry, evicting the least recently used entry when full.
+func (c *memoCache[T]) put(entry memoEntry[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[entry.input]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[entry.input] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(memoEntry[T]).input)
+	}
+}
+
+// clear discards all cached entries.
+func (c *memoCache[T]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.items)
+}
+
+// SetMemoization enables a least-recently-used cache of up to capacity lookup
+// results, which is worthwhile when the same inputs are classified repeatedly.
+// A capacity of zero or less disables the cache. When recordComputed is true,
+// values produced by the fallback of LookupOrCompute are cached as well. The
+// cache is cleared whenever the table is recompiled.
+func (rt *RegexpTable[T]) SetMemoization(capacity int, recordComputed bool) {
+	if capacity <= 0 {
+		rt.memo = nil
+		return
+	}
+	rt.memo = newMemoCache[T](capacity, recordComputed)
+}
+
+// LookupOrCompute returns the value of the pattern matching input or, if no pattern
+// matches, the result of calling fallback on the input. This supports layering
+// heuristics underneath the table's rules. If memoization is enabled with
+// recordComputed, the fallback result is cached so that the fallback is not called
+// again for the same input until the table is recompiled.
+func (rt *RegexpTable[T]) LookupOrCompute(input string, fallback func(string) T) T {
+	value, _, err := rt.Lookup(input)
+	if err == nil {
+		return value
+	}
+
+	memo := rt.memo
+	if memo != nil && memo.recordComputed {
+		if entry, ok := memo.get(input); ok && entry.computed {
+			return entry.value
+		}
+	}
+
+	value = fallback(input)
+	if memo != nil && memo.recordComputed {
+		memo.put(memoEntry[T]{input: input, value: value, computed: true})
+	}
+	return value
+}
+
+// SetSharedMatches controls the ownership of the submatch slices returned by
+// lookups. By default every lookup returns a slice that belongs to the caller,
+// which may retain and modify it freely; to guarantee this, results stored in the
+// memoization cache are copied on the way in and out. When shared is true those
+// copies are skipped and cached lookups return views of the cache's own slices,
+// which may be returned to other callers too. Callers must then treat submatch
+// slices as read-only. Without memoization no slices are shared and the setting
+// has no effect.
+func (rt *RegexpTable[T]) SetSharedMatches(shared bool) {
+	rt.sharedMatches = shared
+}
+
+// memoizedFind consults the memoization cache before performing a real match.
+func (rt *RegexpTable[T]) memoizedFind(input string) (*ValueAndPattern[T], []string, error) {
+	memo := rt.memo
+	if cached, ok := memo.get(input); ok && !cached.computed {
+		if rt.sharedMatches {
+			return cached.entry, cached.matches, nil
+		}
+		return cached.entry, slices.Clone(cached.matches), nil
+	}
+	entry, matches, err := rt.matchEntry(input)
+	if err == nil {
+		stored := matches
+		if !rt.sharedMatches {
+			stored = slices.Clone(matches)
+		}
+		memo.put(memoEntry[T]{input: input, entry: entry, matches: stored})
+	}
+	return entry, matches, err
+}
@@ -0,0 +1,157 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_LookupOrCompute(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		WithMemoization(16, true).
+		MustBuild(true, true)
+
+	calls := 0
+	fallback := func(input string) string {
+		calls++
+		return "heuristic:" + strings.ToUpper(input)
+	}
+
+	if value := table.LookupOrCompute("42", fallback); value != "number" {
+		t.Errorf("Expected 'number', got %q", value)
+	}
+	if calls != 0 {
+		t.Errorf("Expected fallback not to be called for a match, called %d times", calls)
+	}
+
+	for range 3 {
+		if value := table.LookupOrCompute("abc", fallback); value != "heuristic:ABC" {
+			t.Errorf("Expected 'heuristic:ABC', got %q", value)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected fallback result to be memoized, called %d times", calls)
+	}
+
+	// Computed values must not leak into ordinary lookups.
+	if _, _, ok := table.TryLookup("abc"); ok {
+		t.Error("Expected no match for 'abc' from Lookup")
+	}
+
+	// Recompiling discards the cache.
+	if err := table.AddAndCheckPattern(`[a-z]+`, "word"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if value := table.LookupOrCompute("abc", fallback); value != "word" {
+		t.Errorf("Expected 'word' after adding a pattern, got %q", value)
+	}
+}
+
+func TestRegexpTable_LookupOrComputeWithoutRecording(t *testing.T) {
+	table := NewRegexpTableBuilder[int]().
+		AddPattern(`one`, 1).
+		MustBuild(true, true)
+
+	calls := 0
+	fallback := func(input string) int {
+		calls++
+		return len(input)
+	}
+	for range 2 {
+		if value := table.LookupOrCompute("three", fallback); value != 5 {
+			t.Errorf("Expected 5, got %d", value)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected fallback to be called each time without memoization, called %d times", calls)
+	}
+}
+
+func TestMemoCache_Eviction(t *testing.T) {
+	cache := newMemoCache[int](2, false)
+	cache.put(memoEntry[int]{input: "a", value: 1})
+	cache.put(memoEntry[int]{input: "b", value: 2})
+	cache.get("a") // Make "b" the least recently used entry
+	cache.put(memoEntry[int]{input: "c", value: 3})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected 'b' to be evicted")
+	}
+	if entry, ok := cache.get("a"); !ok || entry.value != 1 {
+		t.Error("Expected 'a' to be retained")
+	}
+	if entry, ok := cache.get("c"); !ok || entry.value != 3 {
+		t.Error("Expected 'c' to be retained")
+	}
+}
+
+func TestRegexpTable_MemoizedLookupReturnsCopies(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\w+)@(\w+)`, "email").
+		WithMemoization(4, false).
+		MustBuild(true, true)
+
+	_, matches, err := table.Lookup("bob@example")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	matches[1] = "mallory"
+
+	_, matches, err = table.Lookup("bob@example")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if matches[1] != "bob" {
+		t.Errorf("Expected cached matches to be unaffected by caller mutation, got %v", matches)
+	}
+}
+
+func TestRegexpTable_SharedMatches(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\w+)@(\w+)`, "email").
+		WithMemoization(4, false).
+		WithSharedMatches(true).
+		MustBuild(true, true)
+
+	_, first, err := table.Lookup("bob@example")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	_, second, err := table.Lookup("bob@example")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if &first[0] != &second[0] {
+		t.Error("Expected cached lookups to share the submatch slice")
+	}
+	if second[1] != "bob" {
+		t.Errorf("Expected bob, got %v", second)
+	}
+}
+
+func TestRegexpTable_MatchesOwnedByCaller(t *testing.T) {
+	// Without memoization every path must hand out fresh slices, shared mode or not.
+	for _, shared := range []bool{false, true} {
+		table := NewRegexpTableBuilder[string]().
+			AddPattern(`(\w+)@(\w+)`, "email").
+			AddPattern(`x*`, "xs").
+			WithSharedMatches(shared).
+			MustBuild(false, false)
+
+		for _, input := range []string{"bob@example", "!x"} {
+			_, first, err := table.Lookup(input)
+			if err != nil {
+				t.Fatalf("Lookup(%q) failed: %v", input, err)
+			}
+			want := strings.Clone(first[0])
+			first[0] = "mutated"
+			result, err := table.LookupResult(input)
+			if err != nil {
+				t.Fatalf("LookupResult(%q) failed: %v", input, err)
+			}
+			if result.Groups[0] != want {
+				t.Errorf("Shared=%v, input %q: expected %q after mutating an earlier result, got %q", shared, input, want, result.Groups[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package regexptable
+
+import (
+	"slices"
+	"time"
+	"unsafe"
+)
+
+// entryAttrs holds the attributes of an entry that most entries leave unset.
+// Entries without any share noAttrs, so that a table of plain patterns, such as
+// one whose values are integers or enums, does not carry them for every entry.
+// The attributes are read through the entry, but must be set through ownAttrs.
+type entryAttrs struct {
+	expiresAt  time.Time            // When the entry expires, zero for entries that never expire
+	exclusion  *exclusion           // Compiled exclusion checks, nil for ordinary entries
+	priority   int                  // The priority the entry was built with, see AddPatternWithPriority
+	tags       []string             // Labels recorded for audits, see AddPatternWithTags
+	confidence float64              // The confidence the entry was built with, 0 if none, see AddPatternWithConfidence
+	groupTypes map[string]GroupType // Types of named groups, see AddTypedPattern
+	schedule   *Schedule            // When the entry is active, nil if always, see AddPatternWithSchedule
+	dormant    bool                 // Whether the schedule makes the entry inactive, so that it cannot match
+	doc        string               // What the entry is for, recorded for audits, see Entry.Doc
+}
+
+// noAttrs is the attributes of every entry that has none of its own. It must
+// never be modified.
+var noAttrs = &entryAttrs{}
+
+// ownAttrs returns the entry's attributes for modification, giving the entry a
+// set of its own in place of noAttrs first.
+func (vp *ValueAndPattern[T]) ownAttrs() *entryAttrs {
+	if vp.entryAttrs == noAttrs {
+		vp.entryAttrs = &entryAttrs{}
+	}
+	return vp.entryAttrs
+}
+
+// cloneAttrs returns a copy of the entry's attributes for another entry, or
+// noAttrs if it has none of its own.
+func (vp *ValueAndPattern[T]) cloneAttrs() *entryAttrs {
+	if vp.entryAttrs == noAttrs {
+		return noAttrs
+	}
+	attrs := *vp.entryAttrs
+	return &attrs
+}
+
+// TableStats describes the size of a table and the decisions adaptive mode has
+// made for it, see RegexpTable.Stats.
+type TableStats struct {
+	Patterns      int   // Number of live entries
+	Tombstones    int   // Removed entries still in the compiled union, see RemovePattern
+	EntryBytes    int   // Bytes held by the entries, their values and pattern text
+	CompiledBytes int   // Rough estimate of the bytes held by compiled regexps, 0 if not compiled
+	Promoted      []int // Insertion indexes of the literals adaptive mode promoted, see SetAdaptive
+	Reordered     []int // Insertion indexes of the literals adaptive mode reordered, in their new order
+}
+
+// MemoryBytes returns the table's estimated memory use in bytes.
+func (s TableStats) MemoryBytes() int {
+	return s.EntryBytes + s.CompiledBytes
+}
+
+// Stats reports the size of the table, which helps to budget memory in
+// deployments with many tables, such as one per tenant. EntryBytes is measured
+// from the entries themselves; CompiledBytes is only an estimate, the same one a
+// TableSet uses for its memory limit.
+//
+// Entries only carry the attributes they were given, such as tags, a priority or
+// an exclusion, so the entries of a table of plain patterns, such as one whose
+// values are integers or enums, take little more than their pattern text. Each
+// entry is still a structure of its own whatever T is; values are not packed into
+// a compact slice for integer or enum types.
+func (rt *RegexpTable[T]) Stats() TableStats {
+	stats := TableStats{Patterns: len(rt.maplets), Tombstones: rt.tombstones, Reordered: slices.Clone(rt.reordered)}
+	var entry ValueAndPattern[T]
+	var value T
+	entrySize, valueSize := int(unsafe.Sizeof(entry)), int(unsafe.Sizeof(value))
+	attrsSize := int(unsafe.Sizeof(entryAttrs{}))
+	stats.EntryBytes = len(rt.maplets) * entrySize
+	for _, entry := range rt.maplets {
+		stats.EntryBytes += len(entry.GroupName) + len(entry.namedPattern) + len(entry.Pattern) + len(entry.strippedPattern)
+		stats.EntryBytes += cap(entry.moreValues) * valueSize
+		if entry.entryAttrs != noAttrs {
+			stats.EntryBytes += attrsSize
+		}
+	}
+	if rt.compiled != nil {
+		stats.CompiledBytes = approxTableBytes(rt)
+	}
+	if rt.hotLiterals != nil {
+		stats.Promoted = rt.promotedIndexes()
+	}
+	return stats
+}
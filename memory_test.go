@@ -0,0 +1,89 @@
+package regexptable
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRegexpTable_Stats(t *testing.T) {
+	table := NewRegexpTable[int](true, true)
+	if stats := table.Stats(); stats.Patterns != 0 || stats.MemoryBytes() != 0 {
+		t.Errorf("Expected an empty table to use no memory, got %+v", stats)
+	}
+	for i := range 100 {
+		table.AddPattern("k"+strconv.Itoa(i), i)
+	}
+	before := table.Stats()
+	if before.Patterns != 100 || before.EntryBytes == 0 || before.CompiledBytes != 0 {
+		t.Errorf("Expected 100 uncompiled entries, got %+v", before)
+	}
+	table.Recompile()
+	after := table.Stats()
+	if after.CompiledBytes == 0 || after.EntryBytes < before.EntryBytes {
+		t.Errorf("Expected the compiled table to be counted, got %+v", after)
+	}
+	table.RemovePattern("k0")
+	if stats := table.Stats(); stats.Patterns != 99 || stats.Tombstones != 1 {
+		t.Errorf("Expected 99 entries and a tombstone, got %+v", stats)
+	}
+}
+
+func TestRegexpTable_EntryAttrs(t *testing.T) {
+	table := NewRegexpTableBuilder[int]().
+		AddPattern(`a`, 1).
+		AddPatternWithPriority(`b`, 2, 5).
+		AddPatternWithTags(`c`, 3, "tag").
+		AddPatternExcluding(`d+`, 4, Exclusion{NotMatching: `dd`}).
+		Add(Entry[int]{Pattern: `e`, Value: 5, Doc: "e", Schedule: &Schedule{Days: []time.Weekday{time.Monday}}}).
+		MustBuild(true, true)
+	table.AddPatternWithTTL(`f`, 6, time.Hour)
+	table.Lookup("d")
+
+	plain, shared := table.maplets[len(table.maplets)-1], 0
+	for _, entry := range table.maplets {
+		if entry.entryAttrs == noAttrs {
+			shared++
+			plain = entry
+		}
+	}
+	if shared != 1 || plain.Pattern != `a` {
+		t.Errorf("Expected only the plain pattern to share the empty attributes, got %d sharing", shared)
+	}
+	if !reflect.ValueOf(*noAttrs).IsZero() {
+		t.Errorf("Expected the shared attributes to stay empty, got %+v", *noAttrs)
+	}
+	mapped := MapValues(table, func(v int) int { return -v })
+	mapped.maplets[0].ownAttrs().priority = 7
+	if table.maplets[0].priority != 5 {
+		t.Error("Expected MapValues to copy the attributes")
+	}
+}
+
+// BenchmarkTableMemory measures the memory that the entries of a small table with
+// integer values take, as in deployments with a table per tenant: the bytes
+// allocated while adding the patterns, and those Stats reports, per pattern.
+func BenchmarkTableMemory(b *testing.B) {
+	const patterns = 100
+	names := make([]string, patterns)
+	for i := range names {
+		names[i] = "k" + strconv.Itoa(i)
+	}
+	var before, after runtime.MemStats
+	var allocated uint64
+	var stats TableStats
+	for b.Loop() {
+		runtime.ReadMemStats(&before)
+		table := NewRegexpTable[int](true, true)
+		for i, name := range names {
+			table.AddPattern(name, i)
+		}
+		runtime.ReadMemStats(&after)
+		allocated += after.TotalAlloc - before.TotalAlloc
+		stats = table.Stats()
+	}
+	b.ReportMetric(float64(allocated)/float64(b.N*patterns), "B/pattern")
+	b.ReportMetric(float64(stats.EntryBytes)/patterns, "entry-B/pattern")
+}
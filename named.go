@@ -0,0 +1,68 @@
+package regexptable
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// AddNamedPattern is like AddPattern but gives the pattern a name, which must
+// start with a letter, consist of letters, digits and underscores, and be unique
+// within the table. The name is used for the pattern's internal capture group,
+// namespaced as __REGEXPTABLE_name__ unless the engine chooses group names itself
+// (see GroupNamer), so that it appears in error messages and in the compiled
+// union instead of a number, and lookups report it as Result.RuleName.
+func (rt *RegexpTable[T]) AddNamedPattern(name, pattern string, value T) error {
+	if err := checkRuleName(name); err != nil {
+		return err
+	}
+	if _, ok := rt.IndexOfName(name); ok {
+		return fmt.Errorf("pattern name %q is already in use", name)
+	}
+	rt.addPattern(name, pattern, value)
+	return nil
+}
+
+// IndexOfName returns the insertion index, as in Result.Index, of the pattern
+// added with the given name. The boolean is false if there is no such pattern.
+func (rt *RegexpTable[T]) IndexOfName(name string) (int, bool) {
+	for _, entry := range rt.maplets {
+		if name != "" && entry.name == name {
+			return entry.insertionIndex(), true
+		}
+	}
+	return 0, false
+}
+
+// checkRuleName reports whether name is valid for AddNamedPattern.
+func checkRuleName(name string) error {
+	if !isClassName(name) || !unicode.IsLetter(rune(name[0])) {
+		return fmt.Errorf("invalid pattern name %q: must start with a letter and contain only letters, digits and underscores", name)
+	}
+	return nil
+}
+
+// entryGroupName returns the name of the group that wraps the i-th pattern added
+// to a table using the engine, given the pattern's name, if any.
+func entryGroupName(engine RegexpEngine, i int, name string) string {
+	if _, ok := engine.(GroupNamer); name != "" && !ok {
+		return "__REGEXPTABLE_" + name + "__"
+	}
+	return internalGroupName(engine, i)
+}
+
+// label returns the name that identifies the entry in messages: the name it was
+// added with, if any, and its group name otherwise.
+func (vp *ValueAndPattern[T]) label() string {
+	if vp.name != "" {
+		return vp.name
+	}
+	return vp.GroupName
+}
+
+// describeName returns " (name)" for a pattern entry with a name, for messages.
+func (e patternEntry[T]) describeName() string {
+	if e.name == "" {
+		return ""
+	}
+	return " (" + e.name + ")"
+}
@@ -0,0 +1,93 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_AddNamedPattern(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	if err := table.AddNamedPattern("login", `user=(\w+)`, "login"); err != nil {
+		t.Fatalf("AddNamedPattern failed: %v", err)
+	}
+
+	result, err := table.LookupResult("user=ann")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if result.RuleName != "login" || result.Groups[1] != "ann" {
+		t.Errorf("Expected the login rule with group ann, got %s", result)
+	}
+	if !strings.Contains(result.String(), " rule=login ") {
+		t.Errorf("Expected the rule name in %s", result)
+	}
+	if result, _ := table.LookupResult("42"); result.RuleName != "" {
+		t.Errorf("Expected no rule name for an unnamed pattern, got %q", result.RuleName)
+	}
+	if !strings.Contains(table.compiledUnion, "__REGEXPTABLE_login__") {
+		t.Errorf("Expected the name in the union, got %s", table.compiledUnion)
+	}
+	if index, ok := table.IndexOfName("login"); !ok || index != 1 {
+		t.Errorf("Expected IndexOfName to find index 1, got %d, %v", index, ok)
+	}
+	if _, ok := table.IndexOfName("missing"); ok {
+		t.Error("Expected IndexOfName to fail for an unknown name")
+	}
+
+	for _, name := range []string{"", "1st", "a-b", "_x", "login"} {
+		if err := table.AddNamedPattern(name, `x`, "x"); err == nil {
+			t.Errorf("Expected an error for the name %q", name)
+		}
+	}
+	if table.Len() != 2 {
+		t.Errorf("Expected rejected patterns not to be added, got %d patterns", table.Len())
+	}
+}
+
+func TestRegexpTable_NamedPatternErrors(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddNamedPattern("broken_rule", `(`, "x")
+	if err := table.Recompile(); err == nil || !strings.Contains(err.Error(), "group broken_rule (pattern: ()") {
+		t.Errorf("Expected the rule name in the error, got %v", err)
+	}
+
+	_, err := NewRegexpTableBuilder[string]().AddNamedPattern("broken_rule", `(`, "x").Build(true, true)
+	if err == nil || !strings.Contains(err.Error(), "group broken_rule") {
+		t.Errorf("Expected the rule name in the build error, got %v", err)
+	}
+	errs := NewRegexpTableBuilder[string]().AddNamedPattern("broken_rule", `(`, "x").Validate(true, true)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "(broken_rule)") {
+		t.Errorf("Expected the rule name in the validation error, got %v", errs)
+	}
+}
+
+func TestRegexpTableBuilder_AddNamedPattern(t *testing.T) {
+	table, err := NewRegexpTableBuilderWithEngine[string](&shortNameEngine{}).
+		AddNamedPattern("word", `[a-z]+`, "word").
+		AddPattern(`\d+`, "number").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	// Engines that choose their own group names keep them, but results still
+	// report the rule name.
+	if table.maplets[0].GroupName != "rt1" {
+		t.Errorf("Expected the engine's group name, got %q", table.maplets[0].GroupName)
+	}
+	if result, err := table.LookupResult("abc"); err != nil || result.RuleName != "word" {
+		t.Errorf("Expected the word rule, got %v, %v", result, err)
+	}
+
+	builder := NewRegexpTableBuilder[string]().
+		AddNamedPattern("a", `a`, "a").
+		AddNamedPattern("a", `b`, "b").
+		AddNamedPattern("9", `c`, "c")
+	errs := builder.Validate(true, true)
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "already in use") || !strings.Contains(errs[1].Error(), "invalid pattern name") {
+		t.Errorf("Expected duplicate and invalid name errors, got %v", errs)
+	}
+	if _, err := builder.Build(true, true); err == nil {
+		t.Error("Expected Build to reject a duplicate name")
+	}
+}
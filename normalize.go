@@ -0,0 +1,232 @@
+package regexptable
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalizer transforms inputs before they are matched, so that a table can, for
+// instance, ignore case or the escape sequences of coloured terminal output
+// without every pattern having to allow for them. See RegexpTable.SetNormalizers.
+type Normalizer interface {
+
+	// Normalize returns the normalized input together with a map from byte
+	// offsets in the normalized text to byte offsets in input. The map has
+	// len(normalized)+1 entries: offsets[i] is where the text that became byte i
+	// starts in input and the last entry is where the normalized text ends in
+	// input. offsets is nil when the input is unchanged.
+	Normalize(input string) (normalized string, offsets []int)
+}
+
+// NormalizerFunc adapts an ordinary function to the Normalizer interface.
+type NormalizerFunc func(input string) (string, []int)
+
+// Normalize calls f(input).
+func (f NormalizerFunc) Normalize(input string) (string, []int) {
+	return f(input)
+}
+
+// TrimSpace returns a Normalizer that removes leading and trailing white space.
+func TrimSpace() Normalizer {
+	return NormalizerFunc(func(input string) (string, []int) {
+		start := len(input) - len(strings.TrimLeftFunc(input, unicode.IsSpace))
+		end := len(strings.TrimRightFunc(input, unicode.IsSpace))
+		if start == 0 && end == len(input) {
+			return input, nil
+		}
+		if start > end {
+			start = end
+		}
+		var nb normalizedBuilder
+		nb.copy(input[start:end], start)
+		return nb.finish(end)
+	})
+}
+
+// CollapseWhitespace returns a Normalizer that replaces every run of white space
+// with a single space.
+func CollapseWhitespace() Normalizer {
+	return NormalizerFunc(func(input string) (string, []int) {
+		if !hasCollapsibleSpace(input) {
+			return input, nil
+		}
+		var nb normalizedBuilder
+		inSpace := false
+		for i := 0; i < len(input); {
+			r, width := utf8.DecodeRuneInString(input[i:])
+			switch {
+			case !unicode.IsSpace(r):
+				inSpace = false
+				nb.copy(input[i:i+width], i)
+			case !inSpace:
+				inSpace = true
+				nb.replace(" ", i)
+			}
+			i += width
+		}
+		return nb.finish(len(input))
+	})
+}
+
+// hasCollapsibleSpace reports whether CollapseWhitespace would change input.
+func hasCollapsibleSpace(input string) bool {
+	previous := false
+	for _, r := range input {
+		space := unicode.IsSpace(r)
+		if space && (previous || r != ' ') {
+			return true
+		}
+		previous = space
+	}
+	return false
+}
+
+// Lowercase returns a Normalizer that maps every letter to lower case with
+// Unicode's simple case mapping, as unicode.ToLower does. Bytes that are not
+// valid UTF-8 are left alone.
+func Lowercase() Normalizer {
+	return mapRunes(unicode.ToLower)
+}
+
+// mapRunes returns a Normalizer that replaces every rune r of the input with
+// mapping(r), leaving bytes that are not valid UTF-8 alone.
+func mapRunes(mapping func(rune) rune) Normalizer {
+	return NormalizerFunc(func(input string) (string, []int) {
+		if !strings.ContainsFunc(input, func(r rune) bool { return mapping(r) != r }) {
+			return input, nil
+		}
+		var nb normalizedBuilder
+		for i := 0; i < len(input); {
+			r, width := utf8.DecodeRuneInString(input[i:])
+			if mapped := mapping(r); mapped != r && r != utf8.RuneError {
+				nb.replace(string(mapped), i)
+			} else {
+				nb.copy(input[i:i+width], i)
+			}
+			i += width
+		}
+		return nb.finish(len(input))
+	})
+}
+
+// StripANSI returns a Normalizer that removes ANSI terminal escape sequences,
+// such as the colour codes in coloured log output. It recognises control
+// sequences (ESC [ ... final byte), operating system commands (ESC ] ...
+// terminated by BEL or ESC \) and two-byte escapes.
+func StripANSI() Normalizer {
+	return NormalizerFunc(func(input string) (string, []int) {
+		if !strings.Contains(input, "\x1b") {
+			return input, nil
+		}
+		var nb normalizedBuilder
+		for i := 0; i < len(input); {
+			if input[i] != '\x1b' {
+				nb.copy(input[i:i+1], i)
+				i++
+				continue
+			}
+			i += ansiSequenceLength(input[i:])
+		}
+		return nb.finish(len(input))
+	})
+}
+
+// ansiSequenceLength returns the length of the escape sequence at the start of s,
+// which starts with ESC. An unterminated sequence extends to the end of s.
+func ansiSequenceLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	default:
+		return 2
+	}
+}
+
+// normalizedBuilder accumulates normalized text together with its offset map.
+type normalizedBuilder struct {
+	text    strings.Builder
+	offsets []int
+}
+
+// copy appends text that is unchanged from the input, where it starts at offset.
+func (nb *normalizedBuilder) copy(text string, offset int) {
+	nb.text.WriteString(text)
+	for i := range len(text) {
+		nb.offsets = append(nb.offsets, offset+i)
+	}
+}
+
+// replace appends text that replaces the input starting at offset.
+func (nb *normalizedBuilder) replace(text string, offset int) {
+	nb.text.WriteString(text)
+	for range len(text) {
+		nb.offsets = append(nb.offsets, offset)
+	}
+}
+
+// finish returns the normalized text and its offset map, whose last entry is end.
+func (nb *normalizedBuilder) finish(end int) (string, []int) {
+	return nb.text.String(), append(nb.offsets, end)
+}
+
+// SetNormalizers installs a pipeline of normalizers that are applied in order to
+// every input of the string lookup methods, Explain and LookupAnchored before it
+// is matched. Submatches are taken from the normalized text, while the Start and
+// End offsets of a Result refer to the input as given. Calling SetNormalizers with
+// no arguments removes the pipeline.
+//
+// Normalization needs the input as a string, so LookupBytes, LookupReader and the
+// tokenizers report an error for tables with normalizers.
+func (rt *RegexpTable[T]) SetNormalizers(normalizers ...Normalizer) {
+	rt.normalizers = append([]Normalizer(nil), normalizers...)
+	if rt.memo != nil {
+		rt.memo.clear()
+	}
+}
+
+// normalize applies the table's normalizers to an input, returning the normalized
+// input and a map of its offsets to offsets in input, nil when the two coincide.
+func (rt *RegexpTable[T]) normalize(input string) (string, []int) {
+	var offsets []int
+	for _, normalizer := range rt.normalizers {
+		normalized, stage := normalizer.Normalize(input)
+		if stage != nil {
+			if offsets != nil {
+				for i, offset := range stage {
+					stage[i] = offsets[offset]
+				}
+			}
+			offsets = stage
+		}
+		input = normalized
+	}
+	return input, offsets
+}
+
+// checkNoNormalizers reports an error for tables with normalizers, on behalf of
+// lookup methods that cannot apply them.
+func (rt *RegexpTable[T]) checkNoNormalizers(method string) error {
+	if len(rt.normalizers) > 0 {
+		return codeErrorf(CodeTableUnsupported, "%s does not support tables with normalizers", method)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		normalizer Normalizer
+		input      string
+		expected   string
+		offsets    []int
+	}{
+		{"trim", TrimSpace(), "  ab \t", "ab", []int{2, 3, 4}},
+		{"trim unchanged", TrimSpace(), "ab", "ab", nil},
+		{"trim blank", TrimSpace(), "   ", "", []int{0}},
+		{"collapse", CollapseWhitespace(), "a  \tb c", "a b c", []int{0, 1, 4, 5, 6, 7}},
+		{"collapse single tab", CollapseWhitespace(), "a\tb", "a b", []int{0, 1, 2, 3}},
+		{"collapse unchanged", CollapseWhitespace(), "a b", "a b", nil},
+		{"lowercase", Lowercase(), "AbC", "abc", []int{0, 1, 2, 3}},
+		{"lowercase shrinking", Lowercase(), "İx", "ix", []int{0, 2, 3}},
+		{"lowercase unchanged", Lowercase(), "abc", "abc", nil},
+		{"ansi", StripANSI(), "\x1b[31mred\x1b[0m!", "red!", []int{5, 6, 7, 12, 13}},
+		{"ansi osc", StripANSI(), "a\x1b]0;title\ab", "ab", []int{0, 11, 12}},
+		{"ansi unterminated", StripANSI(), "a\x1b[3", "a", []int{0, 4}},
+		{"ansi unchanged", StripANSI(), "plain", "plain", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, offsets := tc.normalizer.Normalize(tc.input)
+			if normalized != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, normalized)
+			}
+			if !slices.Equal(offsets, tc.offsets) {
+				t.Errorf("Expected offsets %v, got %v", tc.offsets, offsets)
+			}
+		})
+	}
+}
+
+func TestRegexpTable_Normalizers(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`error (\w+)`, "error").
+		AddPattern(`warn`, "warning").
+		WithNormalizers(StripANSI(), TrimSpace(), CollapseWhitespace(), Lowercase()).
+		MustBuild(true, true)
+
+	input := "  \x1b[31mERROR\x1b[0m   Disk \n"
+	value, matches, err := table.Lookup(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "error" || !slices.Equal(matches, []string{"error disk", "disk"}) {
+		t.Errorf("Expected error with [error disk disk], got %q %q", value, matches)
+	}
+
+	result, err := table.LookupResult(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if raw := input[result.Start:result.End]; raw != "ERROR\x1b[0m   Disk" {
+		t.Errorf("Expected the span to cover the raw match, got [%d, %d) %q", result.Start, result.End, raw)
+	}
+	if start, end := result.GroupSpan(1); start < 0 || input[start:end] != "Disk" {
+		t.Errorf("Expected the group's span to cover the raw text, got [%d, %d)", start, end)
+	}
+
+	explanation, err := table.Explain(" WARN ")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Normalized != "warn" || explanation.Winner != 1 {
+		t.Errorf("Expected rule 1 to win for %q, got %d for %q", "warn", explanation.Winner, explanation.Normalized)
+	}
+
+	if _, _, err := table.LookupBytes([]byte("warn")); err == nil {
+		t.Error("Expected LookupBytes to reject a table with normalizers")
+	}
+	if _, err := Tokenize(table, "warn"); err == nil {
+		t.Error("Expected Tokenize to reject a table with normalizers")
+	}
+
+	table.SetNormalizers()
+	if _, _, ok := table.TryLookup("WARN"); ok {
+		t.Error("Expected removing the normalizers to make matching case sensitive")
+	}
+}
@@ -0,0 +1,90 @@
+package regexptable
+
+import "time"
+
+// ChangeKind identifies how a table's patterns changed.
+type ChangeKind int
+
+const (
+	// ChangeAdded reports that a pattern was added to the table.
+	ChangeAdded ChangeKind = iota
+
+	// ChangeRemoved reports that a pattern was removed from the table, with
+	// RemovePattern or because it expired.
+	ChangeRemoved
+)
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change describes a pattern being added to or removed from a table.
+type Change struct {
+	Kind    ChangeKind
+	Pattern string
+	Index   int // The pattern's insertion index, as in Result.Index
+}
+
+// RecompileStats describes a recompilation of a table's union regexp.
+type RecompileStats struct {
+	Patterns    int           // Number of patterns in the table
+	UnionLength int           // Length of the compiled union, 0 if there is none
+	Duration    time.Duration // Time spent recompiling
+	Err         error         // The error Recompile returned, if any
+}
+
+// observers holds the callbacks registered with OnRecompile and OnMutate.
+type observers struct {
+	recompile []func(RecompileStats)
+	mutate    []func(Change)
+}
+
+// OnRecompile registers a callback that is called after every recompilation,
+// whether explicit or triggered by a lookup, and whether or not it succeeded.
+// It lets caches, metrics and the like react to the table changing without
+// polling it. Callbacks are called in the order they were registered, on the
+// goroutine that recompiled the table.
+func (rt *RegexpTable[T]) OnRecompile(callback func(RecompileStats)) {
+	rt.observers.recompile = append(rt.observers.recompile, callback)
+}
+
+// OnMutate registers a callback that is called whenever a pattern is added to
+// or removed from the table, which suits auditing. Expired patterns are reported
+// as removed when they are swept out of the table. Callbacks are called in the
+// order they were registered, after the table has been changed.
+func (rt *RegexpTable[T]) OnMutate(callback func(Change)) {
+	rt.observers.mutate = append(rt.observers.mutate, callback)
+}
+
+// notifyMutate reports a change to an entry to the OnMutate callbacks.
+func (rt *RegexpTable[T]) notifyMutate(kind ChangeKind, entry *ValueAndPattern[T]) {
+	if len(rt.observers.mutate) == 0 {
+		return
+	}
+	change := Change{Kind: kind, Pattern: entry.Pattern, Index: entry.insertionIndex()}
+	for _, callback := range rt.observers.mutate {
+		callback(change)
+	}
+}
+
+// notifyRecompile reports a recompilation that started at start to the
+// OnRecompile callbacks.
+func (rt *RegexpTable[T]) notifyRecompile(start time.Time, err error) {
+	stats := RecompileStats{
+		Patterns:    len(rt.maplets),
+		UnionLength: len(rt.compiledUnion),
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+	for _, callback := range rt.observers.recompile {
+		callback(stats)
+	}
+}
@@ -0,0 +1,67 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRegexpTable_OnMutate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	table := NewRegexpTable[string](true, true)
+	table.now = func() time.Time { return now }
+
+	var changes []Change
+	table.OnMutate(func(change Change) { changes = append(changes, change) })
+
+	table.AddPattern(`a`, "a")
+	table.AddPatternWithTTL(`b`, "b", time.Minute)
+	table.AddPattern(`c`, "c")
+
+	now = now.Add(time.Hour)
+	table.Lookup("c") // Sweeps the expired entry
+
+	expected := []Change{
+		{Kind: ChangeAdded, Pattern: `a`, Index: 0},
+		{Kind: ChangeAdded, Pattern: `b`, Index: 1},
+		{Kind: ChangeAdded, Pattern: `c`, Index: 2},
+		{Kind: ChangeRemoved, Pattern: `b`, Index: 1},
+	}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, changes)
+	}
+}
+
+func TestRegexpTable_OnRecompile(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`a`, "a")
+	table.AddPattern(`b+`, "b")
+
+	var stats []RecompileStats
+	table.OnRecompile(func(s RecompileStats) { stats = append(stats, s) })
+	calls := 0
+	table.OnRecompile(func(RecompileStats) { calls++ })
+
+	table.Lookup("a")
+	table.Lookup("b") // Already compiled
+	if len(stats) != 1 || calls != 1 {
+		t.Fatalf("Expected one recompilation reported to both callbacks, got %d and %d", len(stats), calls)
+	}
+	if stats[0].Patterns != 2 || stats[0].UnionLength != len(table.compiledUnion) || stats[0].Err != nil {
+		t.Errorf("Expected 2 patterns and the union's length, got %+v", stats[0])
+	}
+
+	table.AddPattern(`(`, "bad")
+	if err := table.Recompile(); err == nil {
+		t.Fatal("Expected Recompile to fail")
+	}
+	if len(stats) != 2 || stats[1].Err == nil || stats[1].Patterns != 3 || stats[1].UnionLength != 0 {
+		t.Errorf("Expected the failed recompilation to be reported, got %+v", stats)
+	}
+}
+
+func TestChangeKind_String(t *testing.T) {
+	if ChangeAdded.String() != "added" || ChangeRemoved.String() != "removed" || ChangeKind(9).String() != "unknown" {
+		t.Errorf("Unexpected change kind names %q, %q, %q", ChangeAdded, ChangeRemoved, ChangeKind(9))
+	}
+}
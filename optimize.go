@@ -0,0 +1,217 @@
+package regexptable
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetPrefixFactoring enables or disables the prefix factoring optimizer pass. When
+// enabled, runs of adjacent patterns that share a literal prefix are compiled as a
+// single branch with the prefix hoisted out, e.g. user_a|user_b becomes
+// user_(?:a|b). This shrinks the union and makes failed matches cheaper without
+// changing which pattern wins. The pass requires an engine that implements
+// LiteralAnalyzer and is silently skipped otherwise.
+func (rt *RegexpTable[T]) SetPrefixFactoring(enabled bool) {
+	if rt.prefixFactoring != enabled {
+		rt.prefixFactoring = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// SetSuffixFactoring enables or disables the suffix factoring optimizer pass, the
+// mirror image of prefix factoring: runs of adjacent patterns that share a literal
+// suffix are compiled as a single branch with the suffix hoisted out, e.g.
+// \w+\.tar\.gz|\w+\.gz becomes (?:\w+\.tar|\w+)\.gz. It suits tables anchored at
+// the end, such as file extension rules, where failed matches then stop at the
+// shared suffix. Which pattern wins is unchanged. The pass requires an engine that
+// implements both LiteralAnalyzer and SuffixAnalyzer and is silently skipped
+// otherwise.
+func (rt *RegexpTable[T]) SetSuffixFactoring(enabled bool) {
+	if rt.suffixFactoring != enabled {
+		rt.suffixFactoring = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// SetLiteralOrdering enables or disables the literal ordering compile pass. When
+// enabled, entries are tried in order of descending literal prefix length, so that
+// longer keywords beat their prefixes (>= before >, else if before else) without
+// having to add them in the right order by hand. Entries with equally long prefixes
+// keep their insertion order. Note that this deliberately changes precedence: a
+// pattern with no literal prefix, such as [a-z]+, is tried after every keyword.
+// The pass requires an engine that implements LiteralAnalyzer and is silently
+// skipped otherwise.
+func (rt *RegexpTable[T]) SetLiteralOrdering(enabled bool) {
+	if rt.literalOrdering != enabled {
+		rt.literalOrdering = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// orderEntries puts the maplets into the order in which they should be tried. The
+// union, the disambiguation fallback and the group bookkeeping all follow the
+// order of the maplets slice, so sorting it here keeps them consistent.
+func (rt *RegexpTable[T]) orderEntries() {
+	analyzer, ok := rt.engine.(LiteralAnalyzer)
+	if !rt.literalOrdering || !ok {
+		slices.SortFunc(rt.maplets, func(a, b *ValueAndPattern[T]) int {
+			return cmp.Compare(a.order, b.order)
+		})
+		return
+	}
+
+	lengths := make(map[*ValueAndPattern[T]]int, len(rt.maplets))
+	for _, entry := range rt.maplets {
+		prefix, _, _ := analyzer.LiteralPrefix(rt.effectivePattern(entry))
+		lengths[entry] = len(prefix)
+	}
+	slices.SortFunc(rt.maplets, func(a, b *ValueAndPattern[T]) int {
+		return cmp.Or(cmp.Compare(lengths[b], lengths[a]), cmp.Compare(a.order, b.order))
+	})
+}
+
+// factoredBranch records the literal prefix and suffix analysis of a single entry.
+type factoredBranch[T any] struct {
+	entry        *ValueAndPattern[T]
+	prefix       string // Literal prefix, when prefixOK
+	afterPrefix  string // The pattern for the rest after the prefix
+	prefixOK     bool
+	beforeSuffix string // The pattern for the rest before the suffix
+	suffix       string // Literal suffix, when suffixOK
+	suffixOK     bool
+}
+
+// factoredUnionPattern builds the union like plainUnionPattern but factors the
+// longest common literal prefix or suffix out of each run of adjacent entries,
+// according to the enabled passes. Only adjacent entries are grouped so that the
+// alternation order, and hence precedence, is preserved exactly. Where a run
+// could be factored either way, the longer run wins, preferring prefixes.
+func (rt *RegexpTable[T]) factoredUnionPattern() string {
+	analyzer, ok := rt.engine.(LiteralAnalyzer)
+	if !ok {
+		return rt.plainUnionPattern()
+	}
+	suffixAnalyzer, _ := rt.engine.(SuffixAnalyzer)
+
+	branches := make([]factoredBranch[T], len(rt.maplets))
+	for i, entry := range rt.maplets {
+		entry.factoredPrefix = ""
+		entry.factoredSuffix = ""
+		pattern := rt.unionBranchPattern(entry)
+		branch := factoredBranch[T]{entry: entry}
+		if rt.prefixFactoring {
+			branch.prefix, branch.afterPrefix, branch.prefixOK = analyzer.LiteralPrefix(pattern)
+		}
+		if rt.suffixFactoring && suffixAnalyzer != nil {
+			branch.beforeSuffix, branch.suffix, branch.suffixOK = suffixAnalyzer.LiteralSuffix(pattern)
+		}
+		branches[i] = branch
+	}
+
+	var unionPattern strings.Builder
+	for i := 0; i < len(branches); {
+		prefixEnd, prefix := prefixRun(branches, i)
+		suffixEnd, suffix := suffixRun(branches, i)
+
+		if i > 0 {
+			unionPattern.WriteString("|")
+		}
+		switch {
+		case prefixEnd-i >= 2 && prefixEnd >= suffixEnd:
+			unionPattern.WriteString(analyzer.QuoteLiteral(prefix))
+			unionPattern.WriteString("(?:")
+			for k := i; k < prefixEnd; k++ {
+				if k > i {
+					unionPattern.WriteString("|")
+				}
+				branch := branches[k]
+				remainder := analyzer.QuoteLiteral(branch.prefix[len(prefix):]) + branch.afterPrefix
+				unionPattern.WriteString(rt.formatBranch(branch.entry, remainder))
+				branch.entry.factoredPrefix = prefix
+			}
+			unionPattern.WriteString(")")
+			i = prefixEnd
+		case suffixEnd-i >= 2:
+			unionPattern.WriteString("(?:")
+			for k := i; k < suffixEnd; k++ {
+				if k > i {
+					unionPattern.WriteString("|")
+				}
+				branch := branches[k]
+				remainder := branch.beforeSuffix + analyzer.QuoteLiteral(branch.suffix[:len(branch.suffix)-len(suffix)])
+				unionPattern.WriteString(rt.formatBranch(branch.entry, remainder))
+				branch.entry.factoredSuffix = suffix
+			}
+			unionPattern.WriteString(")")
+			unionPattern.WriteString(analyzer.QuoteLiteral(suffix))
+			i = suffixEnd
+		default:
+			unionPattern.WriteString(rt.branchPattern(branches[i].entry))
+			i++
+		}
+	}
+	return unionPattern.String()
+}
+
+// prefixRun returns the end of the run of branches starting at i that share a
+// non-empty literal prefix, together with that prefix.
+func prefixRun[T any](branches []factoredBranch[T], i int) (int, string) {
+	if !branches[i].prefixOK {
+		return i + 1, ""
+	}
+	common := branches[i].prefix
+	j := i + 1
+	for j < len(branches) && branches[j].prefixOK {
+		shared := commonPrefix(common, branches[j].prefix)
+		if shared == "" {
+			break
+		}
+		common = shared
+		j++
+	}
+	return j, common
+}
+
+// suffixRun is prefixRun for literal suffixes.
+func suffixRun[T any](branches []factoredBranch[T], i int) (int, string) {
+	if !branches[i].suffixOK {
+		return i + 1, ""
+	}
+	common := branches[i].suffix
+	j := i + 1
+	for j < len(branches) && branches[j].suffixOK {
+		shared := commonSuffix(common, branches[j].suffix)
+		if shared == "" {
+			break
+		}
+		common = shared
+		j++
+	}
+	return j, common
+}
+
+// commonPrefix returns the longest common prefix of a and b, cut at a rune boundary.
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	for n > 0 && n < len(a) && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return a[:n]
+}
+
+// commonSuffix returns the longest common suffix of a and b, cut at a rune boundary.
+func commonSuffix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	for n > 0 && !utf8.RuneStart(a[len(a)-n]) {
+		n--
+	}
+	return a[len(a)-n:]
+}
@@ -0,0 +1,262 @@
+package regexptable
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_PrefixFactoringPreservesSemantics(t *testing.T) {
+	patterns := []struct {
+		pattern string
+		value   string
+	}{
+		{`user_admin`, "admin"},
+		{`user_(\d+)`, "numbered"},
+		{`user_\w+`, "named"},
+		{`user`, "bare"},
+		{`group_(\w+)`, "group"},
+		{`[a-z]+`, "word"},
+		{`été`, "summer"},
+		{`étoile`, "star"},
+	}
+	inputs := []string{
+		"user_admin", "user_42", "user_bob", "user", "user_", "group_ops",
+		"group_", "hello", "", "42", "été", "étoile", "user_admin_extra",
+	}
+
+	for _, anchoring := range [][2]bool{{true, false}, {true, true}, {false, false}, {false, true}} {
+		plain := NewRegexpTable[string](anchoring[0], anchoring[1])
+		factored := NewRegexpTable[string](anchoring[0], anchoring[1])
+		factored.SetPrefixFactoring(true)
+		for _, p := range patterns {
+			if err := plain.AddPattern(p.pattern, p.value); err != nil {
+				t.Fatalf("Failed to add pattern: %v", err)
+			}
+			if err := factored.AddPattern(p.pattern, p.value); err != nil {
+				t.Fatalf("Failed to add pattern: %v", err)
+			}
+		}
+
+		for _, input := range inputs {
+			wantValue, wantMatches, wantErr := plain.Lookup(input)
+			gotValue, gotMatches, gotErr := factored.Lookup(input)
+			if (wantErr == nil) != (gotErr == nil) || wantValue != gotValue || !slices.Equal(wantMatches, gotMatches) {
+				t.Errorf("Anchoring %v, input %q: expected (%q, %v, %v), got (%q, %v, %v)",
+					anchoring, input, wantValue, wantMatches, wantErr, gotValue, gotMatches, gotErr)
+			}
+		}
+	}
+}
+
+func TestRegexpTable_PrefixFactoringUnion(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.SetPrefixFactoring(true)
+	for _, p := range []string{`user_a`, `user_b`, `user_c`, `other`} {
+		if err := table.AddPattern(p, p); err != nil {
+			t.Fatalf("Failed to add pattern: %v", err)
+		}
+	}
+
+	union := table.factoredUnionPattern()
+	if strings.Count(union, "user_") != 1 {
+		t.Errorf("Expected the shared prefix to be factored out once, got %s", union)
+	}
+
+	value, matches, err := table.Lookup("user_b")
+	if err != nil || value != "user_b" || matches[0] != "user_b" {
+		t.Errorf("Expected 'user_b' with full match, got %q %v %v", value, matches, err)
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	testCases := []struct {
+		a, b, expected string
+	}{
+		{"user_a", "user_b", "user_"},
+		{"abc", "xyz", ""},
+		{"été", "étoile", "ét"},
+		{"é", "è", ""}, // Same leading byte, different runes
+	}
+	for _, tc := range testCases {
+		if got := commonPrefix(tc.a, tc.b); got != tc.expected {
+			t.Errorf("commonPrefix(%q, %q) = %q, expected %q", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestRegexpTable_SuffixFactoringPreservesSemantics(t *testing.T) {
+	patterns := []struct {
+		pattern string
+		value   string
+	}{
+		{`(\w+)\.tar\.gz`, "tarball"},
+		{`\w+\.gz`, "gzip"},
+		{`report\.txt`, "report"},
+		{`(?P<name>\w+)\.txt`, "text"},
+		{`txt`, "bare"},
+		{`café`, "cafe"},
+		{`thé`, "tea"},
+		{`[a-z]+`, "word"},
+	}
+	inputs := []string{
+		"a.tar.gz", "a.gz", "report.txt", "notes.txt", "txt", ".txt", "café", "thé",
+		"hello", "", "x.tar.gz.txt", "a.gz.bak",
+	}
+
+	for _, anchoring := range [][2]bool{{true, false}, {true, true}, {false, false}, {false, true}} {
+		plain := NewRegexpTable[string](anchoring[0], anchoring[1])
+		factored := NewRegexpTable[string](anchoring[0], anchoring[1])
+		both := NewRegexpTable[string](anchoring[0], anchoring[1])
+		factored.SetSuffixFactoring(true)
+		both.SetSuffixFactoring(true)
+		both.SetPrefixFactoring(true)
+		for _, p := range patterns {
+			plain.AddPattern(p.pattern, p.value)
+			factored.AddPattern(p.pattern, p.value)
+			both.AddPattern(p.pattern, p.value)
+		}
+
+		for _, input := range inputs {
+			want, wantErr := plain.LookupResult(input)
+			for _, table := range []*RegexpTable[string]{factored, both} {
+				got, gotErr := table.LookupResult(input)
+				if (wantErr == nil) != (gotErr == nil) {
+					t.Errorf("Anchoring %v, input %q: expected error %v, got %v", anchoring, input, wantErr, gotErr)
+					continue
+				}
+				if wantErr != nil {
+					continue
+				}
+				if want.Value != got.Value || !slices.Equal(want.Groups, got.Groups) || want.Start != got.Start || want.End != got.End {
+					t.Errorf("Anchoring %v, input %q: expected (%q, %q, %d-%d), got (%q, %q, %d-%d)",
+						anchoring, input, want.Value, want.Groups, want.Start, want.End, got.Value, got.Groups, got.Start, got.End)
+				}
+				_, byteMatches, err := table.LookupBytes([]byte(input))
+				if err != nil || byteMatches[0] != want.Groups[0] {
+					t.Errorf("Anchoring %v, input %q: expected LookupBytes to match %q, got %q, %v", anchoring, input, want.Groups[0], byteMatches, err)
+				}
+			}
+		}
+	}
+}
+
+func TestRegexpTable_SuffixFactoringUnion(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`\w+\.tar\.gz`, "tarball").
+		AddPattern(`\w+\.gz`, "gzip").
+		AddPattern(`\w+\.txt`, "text").
+		WithSuffixFactoring(true).
+		Build(false, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	union := table.factoredUnionPattern()
+	if strings.Count(union, `\.gz`) != 1 {
+		t.Errorf("Expected the shared suffix to be factored out once, got %s", union)
+	}
+
+	value, matches, err := table.Lookup("logs/app.tar.gz")
+	if err != nil || value != "tarball" || matches[0] != "app.tar.gz" {
+		t.Errorf("Expected 'tarball' with full match app.tar.gz, got %q %v %v", value, matches, err)
+	}
+}
+
+func TestCommonSuffix(t *testing.T) {
+	testCases := []struct {
+		a, b, expected string
+	}{
+		{"a.tar.gz", "b.gz", ".gz"},
+		{"abc", "xyz", ""},
+		{"café", "thé", "é"},
+		{"é", "\u01e9", ""}, // Same trailing byte, different runes
+	}
+	for _, tc := range testCases {
+		if got := commonSuffix(tc.a, tc.b); got != tc.expected {
+			t.Errorf("commonSuffix(%q, %q) = %q, expected %q", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestRegexpTable_LiteralOrdering(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`[a-z]+`, "identifier").
+		AddPattern(`>`, "gt").
+		AddPattern(`=`, "assign").
+		AddPattern(`>=`, "ge").
+		AddPattern(`==`, "eq").
+		AddPattern(`if`, "if").
+		WithLiteralOrdering(true).
+		Build(true, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+		lexeme   string
+	}{
+		{">= 1", "ge", ">="},
+		{"> 1", "gt", ">"},
+		{"== 1", "eq", "=="},
+		{"= 1", "assign", "="},
+		{"if x", "if", "if"},
+		{"iffy", "if", "if"}, // The keyword now beats the identifier
+		{"x", "identifier", "x"},
+	}
+	for _, tc := range testCases {
+		value, matches, err := table.Lookup(tc.input)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if value != tc.expected || matches[0] != tc.lexeme {
+			t.Errorf("Lookup(%q): expected (%q, %q), got (%q, %q)", tc.input, tc.expected, tc.lexeme, value, matches[0])
+		}
+	}
+
+	// Turning the pass off restores insertion order.
+	table.SetLiteralOrdering(false)
+	if value, _, _ := table.Lookup("iffy"); value != "identifier" {
+		t.Errorf("Expected insertion order to be restored, got %q", value)
+	}
+	if value, matches, _ := table.Lookup(">= 1"); value != "gt" || matches[0] != ">" {
+		t.Errorf("Expected gt to win in insertion order, got %q", value)
+	}
+}
+
+func TestRegexpTable_LiteralOrderingKeepsGroups(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.SetLiteralOrdering(true)
+	for _, p := range []struct{ pattern, value string }{
+		{`(\d+)`, "number"},
+		{`id-(\d+)`, "id"},
+		{`identifier-(\w+)-(\d+)`, "long"},
+	} {
+		if err := table.AddPattern(p.pattern, p.value); err != nil {
+			t.Fatalf("Failed to add pattern: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+		matches  []string
+	}{
+		{"42", "number", []string{"42", "42"}},
+		{"id-7", "id", []string{"id-7", "7"}},
+		{"identifier-x-9", "long", []string{"identifier-x-9", "x", "9"}},
+	}
+	for _, tc := range testCases {
+		value, matches, err := table.Lookup(tc.input)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if value != tc.expected || !slices.Equal(matches, tc.matches) {
+			t.Errorf("Lookup(%q): expected (%q, %v), got (%q, %v)", tc.input, tc.expected, tc.matches, value, matches)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// PatternError is a compile error of a pattern that has been located within the
+// pattern. Its message gives the engine's description of the problem, its offset
+// and the pattern with a caret beneath the offending text, as in
+//
+//	error parsing regexp: missing closing ] at offset 3:
+//	    abc[a-z
+//	       ^
+//
+// The part of the pattern before the caret is as far as it could be read. Only
+// errors from engines built on Go's regexp/syntax, such as the standard engine,
+// can be located; other errors are returned as the engine reported them.
+type PatternError struct {
+	Pattern string
+	Offset  int   // Byte offset in Pattern of the offending text
+	Err     error // The engine's error
+}
+
+func (e *PatternError) Error() string {
+	message := e.Err.Error()
+	var parseErr *syntax.Error
+	if errors.As(e.Err, &parseErr) {
+		// The snippet shows the offending text better than the engine's quote of
+		// it, which may include anchors or flags the table added.
+		message = "error parsing regexp: " + parseErr.Code.String()
+	}
+	return fmt.Sprintf("%s at offset %d:\n%s", message, e.Offset, caretSnippet(e.Pattern, e.Offset))
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// locatePatternError returns err as a PatternError if it is a regexp/syntax error
+// whose offending text can be found in pattern, and err unchanged otherwise. The
+// pattern is as the user wrote it; the engine may have compiled it with anchors
+// or flags added, which is why the error is located by searching for its text.
+func locatePatternError(pattern string, err error) error {
+	var parseErr *syntax.Error
+	if err == nil || !errors.As(err, &parseErr) {
+		return err
+	}
+	offset := -1
+	switch parseErr.Code {
+	case syntax.ErrMissingParen, syntax.ErrUnexpectedParen:
+		// The error quotes the whole pattern, so find the parenthesis itself.
+		offset = unbalancedParen(pattern)
+	case syntax.ErrMissingBracket:
+		offset = unclosedClass(pattern)
+	default:
+		if parseErr.Expr != "" {
+			offset = strings.Index(pattern, parseErr.Expr)
+		}
+	}
+	if offset < 0 {
+		return err
+	}
+	return &PatternError{Pattern: pattern, Offset: offset, Err: err}
+}
+
+// unbalancedParen returns the offset of the first ) that closes no group in a
+// pattern or, failing that, of the last ( that is never closed, or -1 if the
+// parentheses balance. Like findAnchors, the scan is purely textual.
+func unbalancedParen(pattern string) int {
+	var open []int // Offsets of the groups open so far
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				return i
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) == 0 {
+		return -1
+	}
+	return open[len(open)-1]
+}
+
+// unclosedClass returns the offset of the [ opening a character class that is
+// never closed, or -1 if every class is closed.
+func unclosedClass(pattern string) int {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			end := skipClass(pattern, i)
+			// skipClass also stops at the end of the pattern when the class
+			// runs on to it, or when its only ] is a literal member.
+			first := i + 1
+			if first < len(pattern) && pattern[first] == '^' {
+				first++
+			}
+			if end == len(pattern)-1 && (pattern[end] != ']' || end <= first) {
+				return i
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// caretSnippet returns the pattern indented on one line with a caret under the
+// byte at offset on the next, keeping any tabs so that the caret lines up.
+func caretSnippet(pattern string, offset int) string {
+	var pad strings.Builder
+	for _, r := range pattern[:offset] {
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	return "    " + pattern + "\n    " + pad.String() + "^"
+}
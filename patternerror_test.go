@@ -0,0 +1,84 @@
+package regexptable
+
+import (
+	"errors"
+	"regexp/syntax"
+	"strings"
+	"testing"
+)
+
+func TestLocatePatternError(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+	}{
+		{`abc[a-z`, 3},
+		{`[a]b[]`, 4},
+		{`ab(cd`, 2},
+		{`(a)(b(c)`, 3},
+		{`ab)cd`, 2},
+		{`\(a)`, 3},
+		{`a**`, 1},
+		{`x{2,1}`, 1},
+		{`ab\8`, 2},
+		{`[z-a]`, 1},
+		{`a(?z)`, 1},
+	}
+	for _, test := range tests {
+		_, compileErr := NewStandardRegexpEngine().Compile(AnchorPattern(test.pattern, AnchorBoth))
+		var located *PatternError
+		if !errors.As(locatePatternError(test.pattern, compileErr), &located) {
+			t.Errorf("Expected %q to be located, got %v", test.pattern, compileErr)
+			continue
+		}
+		if located.Offset != test.offset {
+			t.Errorf("Expected offset %d for %q, got %d", test.offset, test.pattern, located.Offset)
+		}
+		var parseErr *syntax.Error
+		if !errors.As(located, &parseErr) {
+			t.Errorf("Expected the syntax error to be wrapped for %q", test.pattern)
+		}
+	}
+}
+
+func TestLocatePatternError_Unlocated(t *testing.T) {
+	plain := errors.New("plain")
+	if err := locatePatternError(`a(`, plain); err != plain {
+		t.Errorf("Expected a non-syntax error unchanged, got %v", err)
+	}
+	if err := locatePatternError(`a`, nil); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestPatternError_Snippet(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		AddPattern(`ok`, "ok").
+		AddPattern("\tabc[a-z", "bad").
+		Build(true, true)
+	if ErrorCodeOf(err) != CodeCompileFailed {
+		t.Fatalf("Expected %s, got %v", CodeCompileFailed, err)
+	}
+	want := "missing closing ] at offset 4:\n    \tabc[a-z\n    \t   ^"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected the error to contain %q, got %q", want, err.Error())
+	}
+
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`ab)cd`, "bad")
+	err = table.Recompile()
+	want = "(pattern: ab)cd): error parsing regexp: unexpected ) at offset 2:\n    ab)cd\n      ^"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected the error to contain %q, got %q", want, err.Error())
+	}
+}
+
+func TestPatternError_Exclusion(t *testing.T) {
+	errs := NewRegexpTableBuilder[string]().
+		AddPatternExcluding(`a`, "a", Exclusion{NotFollowedBy: `ab+*`}).
+		Validate(true, true)
+	var located *PatternError
+	if len(errs) != 1 || !errors.As(errs[0], &located) || located.Offset != 2 {
+		t.Errorf("Expected the exclusion's error at offset 2, got %v", errs)
+	}
+}
@@ -0,0 +1,206 @@
+package regexptable
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ClassifyOptions configures ClassifyWith.
+type ClassifyOptions struct {
+	Workers int  // Number of concurrent lookups, runtime.GOMAXPROCS(0) if zero or less
+	Ordered bool // Whether results are delivered in the order their inputs arrived
+
+	// OnError, if set, is called with each input whose lookup fails for a reason
+	// other than ErrNoMatch, such as an input over the length limit. It may be
+	// called concurrently from several workers.
+	OnError func(input string, err error)
+}
+
+// Classify looks up every input received from in and sends a Result for each to
+// the returned channel, which suits stream-processing applications that feed a
+// table from a channel pipeline. It is ClassifyWith using runtime.GOMAXPROCS(0)
+// workers and delivering the results in order.
+func (rt *RegexpTable[T]) Classify(ctx context.Context, in <-chan string) <-chan Result[T] {
+	return rt.ClassifyWith(ctx, in, ClassifyOptions{Ordered: true})
+}
+
+// ClassifyWith looks up the inputs received from in using opts.Workers concurrent
+// lookups and sends a Result for each input to the returned channel, which is
+// closed once in is closed and every result has been delivered, or once ctx is
+// cancelled. An input that no pattern matches yields a Result whose Index, Start
+// and End are -1 and whose Value is the zero value. An input whose lookup fails
+// for any other reason yields no Result and is passed to opts.OnError instead. In
+// ordered mode the results arrive in the order of their inputs, with at most
+// opts.Workers lookups running ahead of a slow one; otherwise they arrive as the
+// lookups finish.
+//
+// The table is compiled, together with its individual patterns, and its expired
+// entries and schedules are brought up to date before the lookups start. The
+// workers then match without modifying the table, so that they can share it: it
+// must not be modified until the returned channel is closed, and entries that
+// expire or change schedule in the meantime keep their state until the next
+// lookup outside the pipeline. If compilation fails, the inputs are discarded and
+// the channel is closed without results; call Recompile first to detect this.
+func (rt *RegexpTable[T]) ClassifyWith(ctx context.Context, in <-chan string, opts ClassifyOptions) <-chan Result[T] {
+	out := make(chan Result[T])
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	err := rt.ensureCompiled()
+	if err == nil {
+		err = rt.precompileIndividuals()
+	}
+	switch {
+	case err != nil:
+		go func() {
+			defer close(out)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-in:
+					if !ok {
+						return
+					}
+				}
+			}
+		}()
+	case opts.Ordered:
+		go rt.classifyOrdered(ctx, in, out, workers, opts.OnError)
+	default:
+		go rt.classifyUnordered(ctx, in, out, workers, opts.OnError)
+	}
+	return out
+}
+
+// classifyResult looks up input in a table that has already been compiled,
+// without modifying it. It returns nil, after reporting the error to onError,
+// if the lookup fails other than by finding no match.
+func (rt *RegexpTable[T]) classifyResult(input string, onError func(string, error)) *Result[T] {
+	result, err := rt.lookupCompiled(input)
+	switch {
+	case errors.Is(err, ErrNoMatch):
+		return &Result[T]{Index: -1, Start: -1, End: -1}
+	case err != nil:
+		if onError != nil {
+			onError(input, err)
+		}
+		return nil
+	}
+	copied := *result
+	return &copied
+}
+
+// classifyUnordered runs the workers of ClassifyWith in unordered mode, each
+// sending its results straight to out.
+func (rt *RegexpTable[T]) classifyUnordered(ctx context.Context, in <-chan string, out chan<- Result[T], workers int, onError func(string, error)) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var input string
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case input, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+				result := rt.classifyResult(input, onError)
+				if result == nil {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- *result:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(out)
+}
+
+// classifyJob is an input waiting for a worker in ordered mode, together with the
+// channel on which its result is delivered.
+type classifyJob[T any] struct {
+	input  string
+	result chan *Result[T] // Receives nil if the lookup failed
+}
+
+// classifyOrdered runs the workers of ClassifyWith in ordered mode. Each input is
+// queued in arrival order along with a channel for its result, and the results
+// are forwarded to out by working through the queue.
+func (rt *RegexpTable[T]) classifyOrdered(ctx context.Context, in <-chan string, out chan<- Result[T], workers int, onError func(string, error)) {
+	jobs := make(chan classifyJob[T])
+	pending := make(chan chan *Result[T], workers) // Bounds how far the workers run ahead
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.result <- rt.classifyResult(job.input, onError) // Buffered, so never blocks
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for {
+			var input string
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case input, ok = <-in:
+				if !ok {
+					return
+				}
+			}
+			job := classifyJob[T]{input: input, result: make(chan *Result[T], 1)}
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- job.result:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- job:
+			}
+		}
+	}()
+
+	defer close(out)
+	defer wg.Wait()
+	for result := range pending {
+		select {
+		case <-ctx.Done():
+		case r := <-result:
+			if r == nil {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+			case out <- *r:
+				continue
+			}
+		}
+		for range pending {
+			// Let the queueing goroutine see the cancellation.
+		}
+		return
+	}
+}
@@ -0,0 +1,180 @@
+package regexptable
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func feed(inputs ...string) <-chan string {
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, input := range inputs {
+			in <- input
+		}
+	}()
+	return in
+}
+
+func TestRegexpTable_ClassifyOrdered(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+
+	var inputs, want []string
+	for i := range 200 {
+		if i%3 == 0 {
+			inputs, want = append(inputs, "abc"), append(want, "word")
+		} else if i%3 == 1 {
+			inputs, want = append(inputs, strconv.Itoa(i)), append(want, "number")
+		} else {
+			inputs, want = append(inputs, "?"), append(want, "")
+		}
+	}
+
+	var got []string
+	for result := range table.ClassifyWith(context.Background(), feed(inputs...), ClassifyOptions{Workers: 4, Ordered: true}) {
+		if result.Value == "" && result.Index != -1 {
+			t.Errorf("Expected index -1 for no match, got %d", result.Index)
+		}
+		got = append(got, result.Value)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRegexpTable_ClassifyUnordered(t *testing.T) {
+	table := NewRegexpTable[int](true, true)
+	table.AddPattern(`(\d+)`, 0)
+
+	var inputs []string
+	for i := range 100 {
+		inputs = append(inputs, strconv.Itoa(i))
+	}
+	var got []string
+	for result := range table.ClassifyWith(context.Background(), feed(inputs...), ClassifyOptions{Workers: 8}) {
+		got = append(got, result.Groups[1])
+	}
+	slices.SortFunc(got, func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	})
+	if !slices.Equal(got, inputs) {
+		t.Errorf("Expected every input to be classified, got %v", got)
+	}
+}
+
+func TestRegexpTable_ClassifyCancel(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`x`, "x")
+
+	stop := make(chan struct{})
+	defer close(stop)
+	in := make(chan string) // Never closed
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case in <- "x":
+			}
+		}
+	}()
+	for _, ordered := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		results := table.ClassifyWith(ctx, in, ClassifyOptions{Workers: 2, Ordered: ordered})
+		for range 10 {
+			if result := <-results; result.Value != "x" {
+				t.Errorf("Expected x, got %q", result.Value)
+			}
+		}
+		cancel()
+		for range results {
+			// The channel is closed once the workers stop.
+		}
+	}
+}
+
+func TestRegexpTable_ClassifyCompileError(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(`, "bad")
+	for result := range table.Classify(context.Background(), feed("a", "b")) {
+		t.Errorf("Expected no results, got %+v", result)
+	}
+}
+
+func TestRegexpTable_ClassifyExpiring(t *testing.T) {
+	var inputs []string
+	for i := range 500 {
+		inputs = append(inputs, strconv.Itoa(i), "abc")
+	}
+	for _, ordered := range []bool{true, false} {
+		var now atomic.Int64
+		table := NewRegexpTable[string](true, true)
+		table.now = func() time.Time { return time.Unix(now.Load(), 0) }
+		table.AddPatternWithTTL(`\d+`, "number", time.Minute)
+		table.AddPatternWithTTL(`[a-z]+`, "word", time.Hour)
+
+		in := make(chan string)
+		go func() {
+			defer close(in)
+			for i, input := range inputs {
+				if i == len(inputs)/2 {
+					now.Store(120) // The first entry expires while the lookups run
+				}
+				in <- input
+			}
+		}()
+		count := 0
+		for result := range table.ClassifyWith(context.Background(), in, ClassifyOptions{Workers: 4, Ordered: ordered}) {
+			if result.Index < 0 {
+				t.Errorf("Expected every input to be classified, got %+v", result)
+			}
+			count++
+		}
+		if count != len(inputs) {
+			t.Errorf("Expected %d results, got %d", len(inputs), count)
+		}
+		// The next lookup outside the pipeline sweeps the expired entry.
+		if _, _, err := table.Lookup("42"); err == nil {
+			t.Error("Expected the expired entry to be swept")
+		}
+	}
+}
+
+func TestRegexpTable_ClassifyErrors(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`[a-z]+`, "word")
+	table.SetMaxInputLength(5, false)
+
+	for _, ordered := range []bool{true, false} {
+		var mu sync.Mutex
+		var failed []string
+		opts := ClassifyOptions{Workers: 2, Ordered: ordered, OnError: func(input string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if ErrorCodeOf(err) != CodeInputTooLong {
+				t.Errorf("Expected %s for %q, got %v", CodeInputTooLong, input, err)
+			}
+			failed = append(failed, input)
+		}}
+		var got []string
+		for result := range table.ClassifyWith(context.Background(), feed("abc", "toolong", "?", "xyz"), opts) {
+			got = append(got, result.Value)
+		}
+		slices.Sort(got)
+		if want := []string{"", "word", "word"}; !slices.Equal(got, want) {
+			t.Errorf("Expected results %q, got %q", want, got)
+		}
+		if !slices.Equal(failed, []string{"toolong"}) {
+			t.Errorf("Expected the long input to be reported, got %q", failed)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package regexptable
+
+import (
+	"slices"
+	"sync"
+)
+
+// SetPooling enables or disables the recycling of the Results that LookupResult
+// returns, for services whose lookup rate makes garbage collection a bottleneck.
+// With pooling enabled, a caller that is done with a result hands it back with
+// Result.Release, and later lookups reuse the result together with its Groups,
+// Names, Values and spans and the map returned by Fields, so a steady stream of
+// lookups allocates little beyond what the engine itself needs. Results that are
+// never released are simply collected as usual.
+//
+// A released result, and every slice and map obtained from it, must not be used
+// again, since another lookup may already be filling it in; callers that need
+// to keep any of it must copy it first. Lookups other than LookupResult, and
+// results of tables without pooling, are unaffected.
+func (rt *RegexpTable[T]) SetPooling(enabled bool) {
+	switch {
+	case !enabled:
+		rt.results = nil
+	case rt.results == nil:
+		rt.results = &sync.Pool{New: func() any { return new(Result[T]) }}
+	}
+}
+
+// WithPooling enables pooling of lookup results on the built table.
+// See RegexpTable.SetPooling.
+func (b *RegexpTableBuilder[T]) WithPooling(enabled bool) *RegexpTableBuilder[T] {
+	b.pooling = enabled
+	return b
+}
+
+// pooledResult takes a result from the table's pool, returning it with the
+// buffer its submatches may be stored in, see matchEntry. It returns nil and a
+// nil buffer if pooling is disabled.
+func (rt *RegexpTable[T]) pooledResult() (*Result[T], []string) {
+	if rt.results == nil {
+		return nil, nil
+	}
+	result := rt.results.Get().(*Result[T])
+	result.pool = rt.results
+	return result, result.Groups
+}
+
+// reuse fills in a pooled result for a winning entry and its submatches as
+// newResult would, keeping the slices and map the result already has.
+func (r *Result[T]) reuse(entry *ValueAndPattern[T], matches []string, shared bool) {
+	names := slices.Grow(r.Names[:0], len(matches))[:len(matches)]
+	clear(names)
+	copy(names[1:], entry.groupNames)
+	values := append(append(r.Values[:0], entry.Value), entry.moreValues...)
+	*r = Result[T]{
+		Value:        entry.Value,
+		Values:       values,
+		Pattern:      entry.Pattern,
+		Index:        entry.insertionIndex(),
+		RuleName:     entry.name,
+		Groups:       matches,
+		Names:        names,
+		Confidence:   entry.score(),
+		priority:     entry.priority,
+		spans:        r.spans[:0],
+		groupTypes:   entry.groupTypes,
+		fields:       r.fields,
+		pool:         r.pool,
+		sharedGroups: shared,
+	}
+}
+
+// Release returns the result to the pool of the table that produced it, see
+// RegexpTable.SetPooling, after which neither the result nor anything obtained
+// from it may be used. It does nothing for results of tables without pooling.
+func (r *Result[T]) Release() {
+	pool := r.pool
+	if pool == nil {
+		return
+	}
+	// Clear what is kept so that the pool does not hold on to inputs and values.
+	groups := r.Groups[:0]
+	if r.sharedGroups {
+		groups = nil // The memoization cache's own slice, see SetSharedMatches
+	} else {
+		clear(r.Groups)
+	}
+	clear(r.Values)
+	clear(r.fields)
+	*r = Result[T]{
+		Values: r.Values[:0],
+		Groups: groups,
+		Names:  r.Names[:0],
+		spans:  r.spans[:0],
+		fields: r.fields,
+	}
+	pool.Put(r)
+}
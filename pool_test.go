@@ -0,0 +1,130 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func poolingTable(pooling bool) *RegexpTable[string] {
+	return NewRegexpTableBuilder[string]().
+		AddPattern(`(?P<year>\d{4})-(?P<month>\d{2})`, "date").
+		AddPatternValues(`[a-z]+`, "word", "lower").
+		WithPooling(pooling).
+		MustBuild(true, true)
+}
+
+func TestPooling(t *testing.T) {
+	table := poolingTable(true)
+
+	for range 3 {
+		result, err := table.LookupResult("2024-02")
+		if err != nil {
+			t.Fatalf("LookupResult failed: %v", err)
+		}
+		if result.Value != "date" || !slices.Equal(result.Groups, []string{"2024-02", "2024", "02"}) {
+			t.Errorf("Expected date with its groups, got %s", result)
+		}
+		if fields := result.Fields(); fields["year"] != "2024" || fields["month"] != "02" {
+			t.Errorf("Expected the year and month, got %v", fields)
+		}
+		result.Release()
+
+		result, err = table.LookupResult("abc")
+		if err != nil {
+			t.Fatalf("LookupResult failed: %v", err)
+		}
+		if result.Value != "word" || !slices.Equal(result.Values, []string{"word", "lower"}) ||
+			!slices.Equal(result.Groups, []string{"abc"}) || !slices.Equal(result.Names, []string{""}) {
+			t.Errorf("Expected a reused result to be filled in afresh, got %s", result)
+		}
+		if len(result.Fields()) != 0 {
+			t.Errorf("Expected no fields, got %v", result.Fields())
+		}
+		if start, end := result.GroupSpan(0); start != 0 || end != 3 {
+			t.Errorf("Expected a span of [0,3), got [%d,%d)", start, end)
+		}
+		result.Release()
+	}
+
+	if _, err := table.LookupResult("2024"); err == nil {
+		t.Errorf("Expected no match")
+	}
+}
+
+func TestPooling_Disabled(t *testing.T) {
+	table := poolingTable(false)
+	result, err := table.LookupResult("2024-02")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	fields := result.Fields()
+	result.Release()
+	if result.Value != "date" || fields["year"] != "2024" {
+		t.Errorf("Expected Release to leave an unpooled result alone, got %s", result)
+	}
+	fields["year"] = "changed"
+	if other := result.Fields(); other["year"] != "2024" {
+		t.Errorf("Expected Fields to return a fresh map each time, got %v", other)
+	}
+}
+
+func TestPooling_SharedMatches(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)`, "number").
+		WithMemoization(10, false).
+		WithSharedMatches(true).
+		WithPooling(true).
+		MustBuild(true, true)
+
+	result, _ := table.LookupResult("42")
+	result.Release()
+	result, _ = table.LookupResult("42")
+	result.Release()
+	result, _ = table.LookupResult("7")
+	result.Release()
+
+	// Releasing must not clear or reuse the cache's own slices.
+	_, matches, err := table.Lookup("42")
+	if err != nil || !slices.Equal(matches, []string{"42", "42"}) {
+		t.Errorf("Expected the cached submatches intact, got %v, %v", matches, err)
+	}
+}
+
+func TestPooling_Allocations(t *testing.T) {
+	lookup := func(table *RegexpTable[string]) func() {
+		return func() {
+			result, err := table.LookupResult("2024-02")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = result.Fields()
+			result.Release()
+		}
+	}
+	plain := testing.AllocsPerRun(100, lookup(poolingTable(false)))
+	pooled := testing.AllocsPerRun(100, lookup(poolingTable(true)))
+	if pooled >= plain {
+		t.Errorf("Expected pooling to save allocations, got %v pooled and %v without", pooled, plain)
+	}
+}
+
+func BenchmarkLookupResult(b *testing.B) {
+	for _, pooling := range []bool{false, true} {
+		name := "Unpooled"
+		if pooling {
+			name = "Pooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			table := poolingTable(pooling)
+			b.ReportAllocs()
+			for b.Loop() {
+				result, err := table.LookupResult("2024-02")
+				if err != nil {
+					b.Fatal(err)
+				}
+				_ = result.Fields()
+				result.Release()
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package regexptable
+
+// SetPositionalGroups enables or disables positional group tracking. Normally a
+// table wraps each pattern in a named group, see GroupNamer, and finds the
+// pattern's groups among the union's submatches by that name. In positional mode
+// each pattern is wrapped in a plain capture group instead, and the table counts
+// the groups of every pattern, by compiling it on its own, to work out where each
+// pattern's groups lie. This adapts engines that have no named groups, which
+// enable the mode themselves by implementing PositionalGrouper, at the cost of
+// compiling every pattern individually whenever the table is recompiled.
+func (rt *RegexpTable[T]) SetPositionalGroups(enabled bool) {
+	if rt.positionalGroups != enabled {
+		rt.positionalGroups = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// usesPositionalGroups reports whether the table tracks groups by position,
+// because the mode is enabled or because the engine requires it.
+func (rt *RegexpTable[T]) usesPositionalGroups() bool {
+	return rt.positionalGroups || requiresPositionalGroups(rt.engine)
+}
+
+// requiresPositionalGroups reports whether an engine has no named groups.
+func requiresPositionalGroups(engine RegexpEngine) bool {
+	grouper, ok := engine.(PositionalGrouper)
+	return ok && grouper.PositionalGroups()
+}
+
+// formatBranch wraps one of the table's patterns in the group that identifies its
+// entry in the union.
+func (rt *RegexpTable[T]) formatBranch(entry *ValueAndPattern[T], pattern string) string {
+	if rt.usesPositionalGroups() {
+		return formatPositionalBranch(pattern)
+	}
+	return formatBranch(rt.engine, entry.GroupName, pattern)
+}
+
+// formatPositionalBranch wraps a pattern in a plain capture group, which also
+// keeps any top-level alternation inside it.
+func formatPositionalBranch(pattern string) string {
+	return "(" + pattern + ")"
+}
+
+// locateGroupsByPosition records where each entry's groups live among the
+// union's submatches, given the total number of submatches, by counting the
+// groups of each entry's pattern compiled on its own.
+func (rt *RegexpTable[T]) locateGroupsByPosition(submatches int) error {
+	next := 1 // Skip the union's full match
+	for _, entry := range rt.maplets {
+		individual, err := rt.individualRegexp(entry)
+		if err != nil {
+			return codeErrorf(CodeCompileFailed, "failed to compile pattern '%s': %w", entry.Pattern, err)
+		}
+		names := individual.SubexpNames()
+		entry.firstGroup = next
+		entry.groupCount = max(len(names)-1, 0)
+		entry.groupNames = append(entry.groupNames[:0], names[min(1, len(names)):]...)
+		next += 1 + entry.groupCount
+	}
+	if next != submatches {
+		return codeErrorf(CodeEngineUnsupported, "union has %d submatches but its patterns account for %d", submatches, next)
+	}
+	return nil
+}
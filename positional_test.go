@@ -0,0 +1,123 @@
+package regexptable
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// unnamedEngine is the standard engine stripped of named groups, like a minimal
+// engine that only numbers its groups.
+type unnamedEngine struct {
+	StandardRegexpEngine
+}
+
+func (e *unnamedEngine) Compile(pattern string) (CompiledRegexp, error) {
+	if strings.Contains(pattern, "(?P<") {
+		return nil, fmt.Errorf("named groups are not supported: %s", pattern)
+	}
+	compiled, err := e.StandardRegexpEngine.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &unnamedRegexp{compiled}, nil
+}
+
+func (e *unnamedEngine) FormatNamedGroup(groupName, pattern string) string {
+	panic("unnamedEngine has no named groups")
+}
+
+func (e *unnamedEngine) PositionalGroups() bool {
+	return true
+}
+
+// unnamedRegexp reports no group names and hides the optional interfaces.
+type unnamedRegexp struct {
+	compiled CompiledRegexp
+}
+
+func (r *unnamedRegexp) FindStringSubmatch(s string) []string {
+	return r.compiled.FindStringSubmatch(s)
+}
+
+func (r *unnamedRegexp) SubexpNames() []string {
+	return make([]string, len(r.compiled.SubexpNames()))
+}
+
+func TestRegexpTable_PositionalGroupsEngine(t *testing.T) {
+	table, err := NewRegexpTableBuilderWithEngine[string](&unnamedEngine{}).
+		AddPattern(`(\d+)-(\d+)`, "range").
+		AddPattern(`a|b`, "ab"). // Top-level alternation stays inside the plain group
+		AddPattern(`([a-z])(x)?`, "letter").
+		AddPattern(`z*`, "zs").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	tests := []struct {
+		input  string
+		value  string
+		groups []string
+	}{
+		{"10-20", "range", []string{"10-20", "10", "20"}},
+		{"b", "ab", []string{"b"}},
+		{"cx", "letter", []string{"cx", "c", "x"}},
+		{"c", "letter", []string{"c", "c", ""}},
+		{"", "zs", []string{""}},
+	}
+	for _, test := range tests {
+		result, err := table.LookupResult(test.input)
+		if err != nil {
+			t.Errorf("LookupResult(%q) failed: %v", test.input, err)
+			continue
+		}
+		if result.Value != test.value || strings.Join(result.Groups, ",") != strings.Join(test.groups, ",") {
+			t.Errorf("LookupResult(%q): expected %s %q, got %s", test.input, test.value, test.groups, result)
+		}
+	}
+	if errs := NewRegexpTableBuilderWithEngine[string](&unnamedEngine{}).AddPattern(`(a)`, "a").Validate(true, true); errs != nil {
+		t.Errorf("Expected Validate to accept the engine, got %v", errs)
+	}
+}
+
+func TestRegexpTable_SetPositionalGroups(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`(?P<key>\w+)=(\d+)`, "assignment")
+	table.AddPattern(`(?P<__REGEXPTABLE_1__>x)y`, "xy") // Names play no part in positional mode
+	table.SetPositionalGroups(true)
+
+	result, err := table.LookupResult("a b=42")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if key, _ := result.Field("key"); result.Value != "assignment" || key != "b" || result.Groups[2] != "42" {
+		t.Errorf("Expected the assignment with key b, got %s", result)
+	}
+	if strings.Contains(table.compiledUnion, "__REGEXPTABLE_2__") {
+		t.Errorf("Expected no internal group names in the union, got %s", table.compiledUnion)
+	}
+
+	result, err = table.LookupResult("xy")
+	if err != nil || result.Value != "xy" || result.Groups[1] != "x" {
+		t.Errorf("Expected xy with group x, got %s, %v", result, err)
+	}
+
+	table.SetPositionalGroups(false)
+	if value, _, _ := table.Lookup("k=1"); value != "assignment" {
+		t.Errorf("Expected the assignment after leaving positional mode, got %q", value)
+	}
+}
+
+func TestRegexpTable_PositionalGroupsFactoring(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`user_(\w+)`, "user").
+		AddPattern(`user-(\d+)`, "id").
+		WithPrefixFactoring(true).
+		WithPositionalGroups(true).
+		MustBuild(true, true)
+	result, err := table.LookupResult("user-7")
+	if err != nil || result.Value != "id" || result.Groups[0] != "user-7" || result.Groups[1] != "7" {
+		t.Errorf("Expected id with groups [user-7 7], got %s, %v", result, err)
+	}
+}
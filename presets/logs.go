@@ -0,0 +1,103 @@
+package presets
+
+import (
+	"github.com/sfkleach/regexptable"
+)
+
+// LogFormat identifies the format of a log line.
+type LogFormat string
+
+const (
+	LogFormatSyslog       LogFormat = "syslog-rfc5424"
+	LogFormatCombined     LogFormat = "combined"
+	LogFormatCommon       LogFormat = "common"
+	LogFormatJSON         LogFormat = "json"
+	LogFormatGoTestRun    LogFormat = "go-test-run"
+	LogFormatGoTestResult LogFormat = "go-test-result"
+	LogFormatGoTestPkg    LogFormat = "go-test-package"
+)
+
+// LogFormatBuilder returns a builder for a table that recognises the format of a
+// single log line (without its trailing newline). Build it anchored at both ends,
+// e.g. Build(true, true). The patterns expose the interesting parts of each
+// format as named groups, available through Result.Fields:
+//
+//   - syslog-rfc5424: pri, version, timestamp, hostname, appname, procid, msgid,
+//     sd and msg.
+//   - combined and common (Apache/Nginx access logs): remote, ident, user, time,
+//     method, path, protocol, status and bytes; combined adds referer and agent.
+//   - json: no fields, the line is a single JSON object.
+//   - go-test-run, go-test-result and go-test-package (go test -v output): test,
+//     result, duration and package as applicable.
+func LogFormatBuilder() *regexptable.RegexpTableBuilder[LogFormat] {
+	const access = `(?P<remote>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] ` +
+		`"(?P<method>[A-Z]+) (?P<path>\S+) (?P<protocol>[^"]+)" (?P<status>\d{3}) (?P<bytes>\d+|-)`
+
+	return regexptable.NewRegexpTableBuilder[LogFormat]().
+		AddPattern(`<(?P<pri>\d{1,3})>(?P<version>\d{1,2}) (?P<timestamp>\S+) (?P<hostname>\S+) `+
+			`(?P<appname>\S+) (?P<procid>\S+) (?P<msgid>\S+) (?P<sd>-|(?:\[(?:[^\]\\]|\\.)*\])+)(?: (?P<msg>.*))?`,
+			LogFormatSyslog).
+		AddPattern(access+` "(?P<referer>[^"]*)" "(?P<agent>[^"]*)"`, LogFormatCombined).
+		AddPattern(access, LogFormatCommon).
+		AddPattern(`\s*\{.*\}\s*`, LogFormatJSON).
+		AddPattern(`=== (?:RUN|PAUSE|CONT)\s+(?P<test>\S+)`, LogFormatGoTestRun).
+		AddPattern(`\s*--- (?P<result>PASS|FAIL|SKIP): (?P<test>\S+) \((?P<duration>[\d.]+s)\)`, LogFormatGoTestResult).
+		AddPattern(`(?P<result>ok|FAIL|\?)\s+(?P<package>\S+)(?:\s+(?P<duration>[\d.]+s|\(cached\)|\[no test files\]))?.*`, LogFormatGoTestPkg)
+}
+
+// NewLogFormatTable builds the table described by LogFormatBuilder.
+func NewLogFormatTable() (*regexptable.RegexpTable[LogFormat], error) {
+	return LogFormatBuilder().Build(true, true)
+}
+
+// LogLevel is a normalised log severity.
+type LogLevel int
+
+const (
+	LogLevelTrace LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelFatal
+)
+
+// String returns the conventional upper-case name of the level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelTrace:
+		return "TRACE"
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	case LogLevelFatal:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// LogLevelBuilder returns a builder for a table that finds the severity keyword of
+// a log line anywhere in the line, in the spellings used by common logging
+// libraries (case-insensitive, including level=... and "level":"..." forms). The
+// keyword is captured by the named group level. Build it unanchored, e.g.
+// Build(false, false); the leftmost keyword in the line wins.
+func LogLevelBuilder() *regexptable.RegexpTableBuilder[LogLevel] {
+	return regexptable.NewRegexpTableBuilder[LogLevel]().
+		AddPattern(`(?i)\b(?P<level>fatal|panic|crit(?:ical)?|emerg(?:ency)?|alert)\b`, LogLevelFatal).
+		AddPattern(`(?i)\b(?P<level>error|err|severe)\b`, LogLevelError).
+		AddPattern(`(?i)\b(?P<level>warn(?:ing)?)\b`, LogLevelWarn).
+		AddPattern(`(?i)\b(?P<level>info|notice|information)\b`, LogLevelInfo).
+		AddPattern(`(?i)\b(?P<level>debug|dbg|fine)\b`, LogLevelDebug).
+		AddPattern(`(?i)\b(?P<level>trace|finest|finer)\b`, LogLevelTrace)
+}
+
+// NewLogLevelTable builds the table described by LogLevelBuilder.
+func NewLogLevelTable() (*regexptable.RegexpTable[LogLevel], error) {
+	return LogLevelBuilder().Build(false, false)
+}
@@ -0,0 +1,125 @@
+package presets
+
+import (
+	"testing"
+)
+
+func TestLogFormatTable(t *testing.T) {
+	table, err := NewLogFormatTable()
+	if err != nil {
+		t.Fatalf("NewLogFormatTable failed: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		line     string
+		expected LogFormat
+		fields   map[string]string
+	}{
+		{
+			name:     "Syslog",
+			line:     `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8`,
+			expected: LogFormatSyslog,
+			fields:   map[string]string{"pri": "34", "hostname": "mymachine.example.com", "appname": "su", "msgid": "ID47", "sd": "-"},
+		},
+		{
+			name:     "SyslogStructuredData",
+			line:     `<165>1 2003-10-11T22:14:15.003Z host evntslog - ID47 [exampleSDID@32473 iut="3" eventID="1011"] An application event`,
+			expected: LogFormatSyslog,
+			fields:   map[string]string{"sd": `[exampleSDID@32473 iut="3" eventID="1011"]`, "msg": "An application event"},
+		},
+		{
+			name:     "Combined",
+			line:     `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`,
+			expected: LogFormatCombined,
+			fields:   map[string]string{"remote": "127.0.0.1", "user": "frank", "method": "GET", "path": "/apache_pb.gif", "status": "200", "agent": "Mozilla/4.08"},
+		},
+		{
+			name:     "Common",
+			line:     `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "POST /login HTTP/1.1" 302 -`,
+			expected: LogFormatCommon,
+			fields:   map[string]string{"method": "POST", "status": "302", "bytes": "-"},
+		},
+		{
+			name:     "JSON",
+			line:     `{"level":"info","msg":"started"}`,
+			expected: LogFormatJSON,
+		},
+		{
+			name:     "GoTestRun",
+			line:     `=== RUN   TestLogFormatTable/JSON`,
+			expected: LogFormatGoTestRun,
+			fields:   map[string]string{"test": "TestLogFormatTable/JSON"},
+		},
+		{
+			name:     "GoTestResult",
+			line:     `    --- FAIL: TestThing/sub (0.02s)`,
+			expected: LogFormatGoTestResult,
+			fields:   map[string]string{"result": "FAIL", "test": "TestThing/sub", "duration": "0.02s"},
+		},
+		{
+			name:     "GoTestPackage",
+			line:     "ok  \tgithub.com/sfkleach/regexptable\t0.012s",
+			expected: LogFormatGoTestPkg,
+			fields:   map[string]string{"result": "ok", "package": "github.com/sfkleach/regexptable", "duration": "0.012s"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := table.LookupResult(tc.line)
+			if err != nil {
+				t.Fatalf("LookupResult failed: %v", err)
+			}
+			if result.Value != tc.expected {
+				t.Errorf("Expected format %q, got %q", tc.expected, result.Value)
+			}
+			fields := result.Fields()
+			for name, want := range tc.fields {
+				if got := fields[name]; got != want {
+					t.Errorf("Field %q: expected %q, got %q", name, want, got)
+				}
+			}
+		})
+	}
+
+	if _, _, ok := table.TryLookup("just some text"); ok {
+		t.Error("Expected no format for free text")
+	}
+}
+
+func TestLogLevelTable(t *testing.T) {
+	table, err := NewLogLevelTable()
+	if err != nil {
+		t.Fatalf("NewLogLevelTable failed: %v", err)
+	}
+
+	testCases := []struct {
+		line     string
+		expected LogLevel
+		keyword  string
+	}{
+		{`2024-01-01T00:00:00Z ERROR failed to connect`, LogLevelError, "ERROR"},
+		{`time=2024-01-01 level=warn msg="disk almost full"`, LogLevelWarn, "warn"},
+		{`{"level":"debug","msg":"tick"}`, LogLevelDebug, "debug"},
+		{`[INFO] error count is zero`, LogLevelInfo, "INFO"}, // Leftmost keyword wins
+		{`PANIC: runtime error`, LogLevelFatal, "PANIC"},
+	}
+	for _, tc := range testCases {
+		result, err := table.LookupResult(tc.line)
+		if err != nil {
+			t.Errorf("LookupResult(%q) failed: %v", tc.line, err)
+			continue
+		}
+		if result.Value != tc.expected {
+			t.Errorf("LookupResult(%q): expected %v, got %v", tc.line, tc.expected, result.Value)
+		}
+		if keyword, _ := result.Field("level"); keyword != tc.keyword {
+			t.Errorf("LookupResult(%q): expected keyword %q, got %q", tc.line, tc.keyword, keyword)
+		}
+	}
+
+	if _, _, ok := table.TryLookup("nothing to see"); ok {
+		t.Error("Expected no level for a line without a keyword")
+	}
+}
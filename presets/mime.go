@@ -0,0 +1,103 @@
+package presets
+
+import (
+	"path"
+	"sync"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// mimeExtensions maps filename extensions (without the dot, lower case) to MIME
+// types. Entries are tried in order.
+var mimeExtensions = []struct {
+	extensions string // Alternation of extensions
+	mimeType   string
+}{
+	{`html?`, "text/html"},
+	{`css`, "text/css"},
+	{`m?js`, "text/javascript"},
+	{`json`, "application/json"},
+	{`xml`, "application/xml"},
+	{`txt|text|log`, "text/plain"},
+	{`csv`, "text/csv"},
+	{`md|markdown`, "text/markdown"},
+	{`png`, "image/png"},
+	{`jpe?g`, "image/jpeg"},
+	{`gif`, "image/gif"},
+	{`webp`, "image/webp"},
+	{`svg`, "image/svg+xml"},
+	{`ico`, "image/vnd.microsoft.icon"},
+	{`pdf`, "application/pdf"},
+	{`zip`, "application/zip"},
+	{`t?gz`, "application/gzip"},
+	{`tar`, "application/x-tar"},
+	{`mp3`, "audio/mpeg"},
+	{`wav`, "audio/wav"},
+	{`mp4`, "video/mp4"},
+	{`webm`, "video/webm"},
+	{`woff`, "font/woff"},
+	{`woff2`, "font/woff2"},
+	{`wasm`, "application/wasm"},
+}
+
+// MIMEByExtensionBuilder returns a builder for a table that maps filenames to MIME
+// types by extension. The extension is matched case-insensitively. Build the
+// table anchored at the end only, e.g. Build(false, true).
+func MIMEByExtensionBuilder() *regexptable.RegexpTableBuilder[string] {
+	builder := regexptable.NewRegexpTableBuilder[string]()
+	for _, entry := range mimeExtensions {
+		builder.AddPattern(`(?i)\.(?:`+entry.extensions+`)`, entry.mimeType)
+	}
+	return builder
+}
+
+// MIMEBySignatureBuilder returns a builder for a table that recognises file types
+// from their leading bytes ("magic numbers"). Use it with LookupBytes on the
+// first few hundred bytes of a file and build it anchored at the start only, e.g.
+// Build(true, false).
+//
+// Go's regexp engine decodes its input as UTF-8 and sees every invalid byte as
+// U+FFFD, so signatures containing such bytes are matched as "some non-UTF-8
+// byte" followed by their ASCII part. That is precise enough in practice because
+// the ASCII parts are distinctive.
+func MIMEBySignatureBuilder() *regexptable.RegexpTableBuilder[string] {
+	return regexptable.NewRegexpTableBuilder[string]().
+		AddPattern(`\x{FFFD}PNG\r\n\x1a\n`, "image/png").
+		AddPattern(`\x{FFFD}{4}(?s:.{1,2})(?:JFIF|Exif)`, "image/jpeg").
+		AddPattern(`GIF8[79]a`, "image/gif").
+		AddPattern(`RIFF(?s:.{1,4})WEBP`, "image/webp").
+		AddPattern(`%PDF-`, "application/pdf").
+		AddPattern(`PK\x03\x04`, "application/zip").
+		AddPattern(`\x1f\x{FFFD}`, "application/gzip").
+		AddPattern(`\x00asm`, "application/wasm").
+		AddPattern(`(?i)\s*<!doctype html|\s*<html`, "text/html").
+		AddPattern(`<\?xml`, "application/xml")
+}
+
+var (
+	mimeByExtension = sync.OnceValue(func() *regexptable.RegexpTable[string] {
+		return MIMEByExtensionBuilder().MustBuild(false, true)
+	})
+	mimeBySignature = sync.OnceValue(func() *regexptable.RegexpTable[string] {
+		// Configured before building, so that the shared table is never recompiled
+		// by concurrent lookups.
+		return MIMEBySignatureBuilder().WithNonCapturing(true).MustBuild(true, false)
+	})
+)
+
+// DetectMIME infers the MIME type of a file from the leading bytes of its
+// content, falling back on the extension of its name. Either argument may be
+// empty. The boolean is false when the type could not be determined.
+func DetectMIME(name string, head []byte) (string, bool) {
+	if len(head) > 0 {
+		if mimeType, _, ok := mimeBySignature().TryLookupBytes(head); ok {
+			return mimeType, true
+		}
+	}
+	if ext := path.Ext(name); ext != "" {
+		if mimeType, _, ok := mimeByExtension().TryLookup(ext); ok {
+			return mimeType, true
+		}
+	}
+	return "", false
+}
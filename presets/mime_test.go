@@ -0,0 +1,70 @@
+package presets
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDetectMIME(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+		head     []byte
+		expected string
+		ok       bool
+	}{
+		{"PNGSignature", "", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png", true},
+		{"JPEGSignature", "", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), "image/jpeg", true},
+		{"PDFSignature", "upload.bin", []byte("%PDF-1.7\n"), "application/pdf", true},
+		{"GzipSignature", "", []byte{0x1f, 0x8b, 0x08, 0x00}, "application/gzip", true},
+		{"HTMLSignature", "", []byte("  <!DOCTYPE html><html>"), "text/html", true},
+		{"ExtensionFallback", "photo.JPG", []byte("not a real jpeg"), "image/jpeg", true},
+		{"ExtensionOnly", "archive.tar.gz", nil, "application/gzip", true},
+		{"WOFF", "font.woff", nil, "font/woff", true},
+		{"WOFF2", "font.woff2", nil, "font/woff2", true},
+		{"NestedDirectory", "dir.png/readme", nil, "", false},
+		{"Unknown", "data.xyz", []byte("plain data"), "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mimeType, ok := DetectMIME(tc.filename, tc.head)
+			if ok != tc.ok || mimeType != tc.expected {
+				t.Errorf("DetectMIME(%q, %q) = %q, %v; expected %q, %v", tc.filename, tc.head, mimeType, ok, tc.expected, tc.ok)
+			}
+		})
+	}
+}
+
+// TestDetectMIME_Concurrent exercises the shared tables from several goroutines,
+// which go test -race checks for unsynchronised writes.
+func TestDetectMIME_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if mimeType, ok := DetectMIME("x.bin", []byte("%PDF-1.7\n")); !ok || mimeType != "application/pdf" {
+				t.Errorf("Expected application/pdf, got %q, %v", mimeType, ok)
+			}
+			if mimeType, ok := DetectMIME("x.css", nil); !ok || mimeType != "text/css" {
+				t.Errorf("Expected text/css, got %q, %v", mimeType, ok)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMIMEByExtensionBuilder_Extend(t *testing.T) {
+	table, err := MIMEByExtensionBuilder().
+		AddPattern(`(?i)\.rs`, "text/x-rust").
+		Build(false, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if value, _, ok := table.TryLookup("main.rs"); !ok || value != "text/x-rust" {
+		t.Errorf("Expected 'text/x-rust' for main.rs, got %q", value)
+	}
+	if value, _, ok := table.TryLookup("index.HTML"); !ok || value != "text/html" {
+		t.Errorf("Expected 'text/html' for index.HTML, got %q", value)
+	}
+}
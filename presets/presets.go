@@ -0,0 +1,4 @@
+// Package presets provides ready-made regexp tables for common classification
+// tasks. Each preset is available as a builder, so that callers can extend or
+// override the rules before building, and as a convenience constructor.
+package presets
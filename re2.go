@@ -0,0 +1,77 @@
+package regexptable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"strings"
+)
+
+// CheckRE2Syntax reports an error if the pattern is not in strict RE2 syntax, the
+// dialect understood by the RE2 library and the systems built on it, such as
+// Envoy. Go's syntax is RE2's with a few extensions, so a pattern passes if Go
+// accepts it and it does not use those extensions:
+//
+//   - named groups written (?<name>...), which Go accepts as an alternative to
+//     RE2's (?P<name>...).
+//
+// Syntax that neither Go nor RE2 supports, such as lookaround, backreferences and
+// possessive quantifiers, is rejected as a parse error.
+func CheckRE2Syntax(pattern string) error {
+	if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+		return fmt.Errorf("pattern '%s' is not valid RE2 syntax: %w", pattern, err)
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if strings.HasPrefix(pattern[i:], `\Q`) {
+				// Skip quoted text up to \E, which RE2 also supports.
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					return nil
+				}
+				i += end + 3
+				continue
+			}
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			if strings.HasPrefix(pattern[i:], "(?<") {
+				return fmt.Errorf("pattern '%s' names a group with (?<name>...) at offset %d, a Go extension; RE2 requires (?P<name>...)", pattern, i)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRE2 checks every entry's pattern, with its flags applied, against strict
+// RE2 syntax (see CheckRE2Syntax) and returns all the problems found.
+func (s *Spec) CheckRE2() []error {
+	var problems []error
+	for i, entry := range s.Entries {
+		if err := CheckRE2Syntax(entry.flaggedPattern()); err != nil {
+			problems = append(problems, fmt.Errorf("entry %d: %w", i, err))
+		}
+	}
+	return problems
+}
+
+// ExportRE2 writes the spec to w as indented JSON for sharing with systems that
+// consume RE2-compatible rules. Nothing is written unless the spec is valid and
+// every pattern passes CheckRE2, so an exported spec can always be loaded back
+// by a Loader with WithStrictRE2 enabled.
+func (s *Spec) ExportRE2(w io.Writer) error {
+	problems := append(s.Validate(), s.CheckRE2()...)
+	if len(problems) > 0 {
+		return codeErrorf(CodeUntranslatable, "spec cannot be exported as RE2: %w", errors.Join(problems...))
+	}
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,84 @@
+package regexptable
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckRE2Syntax(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{`\d+`, true},
+		{`(?P<year>\d{4})-(\d\d)`, true},
+		{`(?i:hello)|[[:alpha:]]+`, true},
+		{`[(?<]x`, true},    // Inside a character class
+		{`\(?<x`, true},     // Escaped parenthesis
+		{`\Qa(?<b\E`, true}, // Quoted text is checked by the parser, not the scan
+		{`(?<year>\d{4})`, false},
+		{`a(?:b(?<c>d))`, false},
+		{`(?<=a)b`, false}, // Lookbehind
+		{`(a)\1`, false},   // Backreference
+		{`a++`, false},     // Possessive quantifier
+	}
+	for _, test := range tests {
+		err := CheckRE2Syntax(test.pattern)
+		if test.valid && err != nil {
+			t.Errorf("Expected %q to be valid RE2, got %v", test.pattern, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("Expected %q to be rejected", test.pattern)
+		}
+	}
+}
+
+func newRE2Spec(patterns ...string) *Spec {
+	spec := &Spec{Version: SpecVersion, AnchorStart: true, AnchorEnd: true}
+	for _, pattern := range patterns {
+		spec.Entries = append(spec.Entries, SpecEntry{Pattern: pattern, Value: json.RawMessage(`"v"`)})
+	}
+	return spec
+}
+
+func TestSpec_ExportRE2Roundtrip(t *testing.T) {
+	spec := newRE2Spec(`(?P<n>\d+)`, `[a-z]+`)
+	spec.Entries[1].Flags = "i"
+
+	var buffer bytes.Buffer
+	if err := spec.ExportRE2(&buffer); err != nil {
+		t.Fatalf("ExportRE2 failed: %v", err)
+	}
+
+	loader := NewLoader[string](LoadStrict).WithStrictRE2(true)
+	loaded, err := loader.ParseSpec(&buffer)
+	if err != nil {
+		t.Fatalf("Expected the exported spec to load, got %v", err)
+	}
+	if len(loaded.Entries) != 2 || loaded.Entries[0].Pattern != `(?P<n>\d+)` || loaded.Entries[1].Flags != "i" {
+		t.Errorf("Expected the entries to survive the roundtrip, got %+v", loaded.Entries)
+	}
+}
+
+func TestSpec_ExportRE2Rejects(t *testing.T) {
+	spec := newRE2Spec(`ok`, `(?<n>\d+)`)
+
+	var buffer bytes.Buffer
+	err := spec.ExportRE2(&buffer)
+	if err == nil || !strings.Contains(err.Error(), "entry 1") {
+		t.Errorf("Expected an error for entry 1, got %v", err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("Expected nothing to be written, got %q", buffer.String())
+	}
+
+	data, _ := json.Marshal(spec)
+	if _, err := NewLoader[string](LoadLenient).WithStrictRE2(true).LoadBytes(data); err == nil {
+		t.Error("Expected a strict RE2 loader to reject the spec")
+	}
+	if _, err := NewLoader[string](LoadLenient).LoadBytes(data); err != nil {
+		t.Errorf("Expected the default loader to accept the spec, got %v", err)
+	}
+}
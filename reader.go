@@ -0,0 +1,82 @@
+package regexptable
+
+import (
+	"io"
+)
+
+// LookupReader matches the text read from r against all registered patterns,
+// without loading it into memory first. It returns the value of the winning
+// pattern and pairs of byte offsets (relative to the start of the reader) for the
+// full match and the pattern's own capture groups, with -1 for groups that did not
+// participate. The reader is consumed up to the end of the match or beyond.
+// The engine's compiled regexps must implement ReaderMatcher. If a pattern
+// removed by RemovePattern wins the match, LookupReader fails rather than
+// modifying the table; call Compact after removing patterns to avoid this.
+func (rt *RegexpTable[T]) LookupReader(r io.RuneReader) (T, []int, error) {
+	var zero T
+
+	err := rt.ensureCompiled()
+	if err != nil {
+		return zero, nil, err
+	}
+	if err := rt.checkNoNormalizers("LookupReader"); err != nil {
+		return zero, nil, err
+	}
+
+	if rt.compiled == nil {
+		return zero, nil, ErrNoPatterns
+	}
+
+	matcher, ok := rt.compiled.(ReaderMatcher)
+	if !ok {
+		return zero, nil, codeErrorf(CodeEngineUnsupported, "regexp engine does not support matching against a reader")
+	}
+
+	loc := matcher.FindReaderSubmatchIndex(r)
+	if loc == nil {
+		return zero, nil, ErrNoMatch
+	}
+
+	entry, indexes, ok := rt.groupIndexes(loc)
+	if !ok && rt.tombstones > 0 {
+		// A reader cannot be matched again to find the live winner.
+		return zero, nil, errTombstoneUnsupported("a reader")
+	}
+	if !ok {
+		return zero, nil, codeErrorf(CodeInternal, "internal error: match found but no capture group matched")
+	}
+	if entry.exclusion != nil {
+		return zero, nil, codeErrorf(CodeTableUnsupported, "pattern '%s' has an exclusion, which cannot be checked against a reader", entry.Pattern)
+	}
+	return entry.Value, indexes, nil
+}
+
+// groupIndexes converts the submatch index pairs of a union match into the winning
+// entry and its own index pairs. Unlike string submatches, index pairs tell
+// us unambiguously which group participated, even for empty matches.
+func (rt *RegexpTable[T]) groupIndexes(loc []int) (*ValueAndPattern[T], []int, bool) {
+	for _, entry := range rt.maplets {
+		first := entry.firstGroup
+		// Defensive check: pluggable engines may return fewer pairs than expected.
+		if 2*first+1 >= len(loc) || loc[2*first] < 0 {
+			continue
+		}
+
+		count := entry.groupCount
+		if rt.nonCapturing {
+			count = 0
+		}
+		indexes := make([]int, 2*(1+count))
+		indexes[0] = loc[2*first] - len(entry.factoredPrefix)
+		indexes[1] = loc[2*first+1] + len(entry.factoredSuffix)
+		for k := 1; k <= count; k++ {
+			if 2*(first+k)+1 < len(loc) {
+				indexes[2*k], indexes[2*k+1] = loc[2*(first+k)], loc[2*(first+k)+1]
+			} else {
+				indexes[2*k], indexes[2*k+1] = -1, -1
+			}
+		}
+		return entry, indexes, true
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,71 @@
+package regexptable
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_LookupReader(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)-(\d+)`, "range").
+		AddPattern(`(\w+)=(\w+)`, "assignment").
+		MustBuild(true, false)
+
+	value, indexes, err := table.LookupReader(strings.NewReader("key=value and more"))
+	if err != nil {
+		t.Fatalf("LookupReader failed: %v", err)
+	}
+	if value != "assignment" {
+		t.Errorf("Expected 'assignment', got %q", value)
+	}
+	if expected := []int{0, 9, 0, 3, 4, 9}; !slices.Equal(indexes, expected) {
+		t.Errorf("Expected indexes %v, got %v", expected, indexes)
+	}
+
+	if _, _, err := table.LookupReader(strings.NewReader("!nothing")); err == nil {
+		t.Error("Expected no match for '!nothing'")
+	}
+}
+
+func TestRegexpTable_LookupReaderLargeInput(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`header:(\w+)`, "header").
+		MustBuild(true, false)
+
+	input := "header:big" + strings.Repeat(" padding", 100000)
+	value, indexes, err := table.LookupReader(bufio.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("LookupReader failed: %v", err)
+	}
+	if value != "header" || input[indexes[2]:indexes[3]] != "big" {
+		t.Errorf("Expected 'header' with group 'big', got %q %v", value, indexes)
+	}
+}
+
+func TestRegexpTable_LookupReaderEmptyMatch(t *testing.T) {
+	// String submatches cannot tell which empty group matched, index pairs can.
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`x*`, "xs").
+		AddPattern(`y*`, "ys").
+		MustBuild(true, false)
+
+	value, indexes, err := table.LookupReader(strings.NewReader("zzz"))
+	if err != nil {
+		t.Fatalf("LookupReader failed: %v", err)
+	}
+	if value != "xs" || !slices.Equal(indexes, []int{0, 0}) {
+		t.Errorf("Expected 'xs' with an empty match, got %q %v", value, indexes)
+	}
+}
+
+func TestRegexpTable_LookupReaderUnsupportedEngine(t *testing.T) {
+	table := NewRegexpTableWithEngine[string](NewMockRegexpEngine("(?<%s>%s)"), true, false)
+	if err := table.AddPattern("hello", "greeting"); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if _, _, err := table.LookupReader(strings.NewReader("hello")); err == nil {
+		t.Error("Expected an error for an engine without reader support")
+	}
+}
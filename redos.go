@@ -0,0 +1,232 @@
+package regexptable
+
+import (
+	"errors"
+	"regexp/syntax"
+	"unicode"
+)
+
+// ReDoSRisk describes part of a pattern whose shape can make a backtracking
+// engine take exponential time on inputs that almost match, which is a
+// denial-of-service risk when the inputs are untrusted.
+type ReDoSRisk struct {
+	Fragment string // The repeated subexpression at fault, in Go's syntax
+	Reason   string // Why the subexpression is risky
+}
+
+// ReDoSRisks analyses a pattern for the classic shapes of catastrophic
+// backtracking: an unbounded repetition whose body ends with a quantified
+// subexpression that can also start the next iteration, as in (a+)+ or
+// (\w+\s?)*, and an unbounded repetition of alternatives that can start with the
+// same character, as in (\w+|x\d)*. The analysis is a heuristic: it does not
+// find every risky pattern and may flag some that are harmless in practice.
+//
+// Go's standard engine cannot backtrack and needs no such analysis. Patterns are
+// parsed with Go's syntax, so patterns using features Go lacks, such as
+// lookaround or backreferences, cannot be analysed and yield no risks.
+func ReDoSRisks(pattern string) []ReDoSRisk {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	var risks []ReDoSRisk
+	walkReDoS(re, &risks)
+	return risks
+}
+
+// walkReDoS appends the risks found in re and its subexpressions.
+func walkReDoS(re *syntax.Regexp, risks *[]ReDoSRisk) {
+	if isUnboundedRepeat(re) {
+		body := uncapture(re.Sub[0])
+		if reason, ok := repeatedBodyRisk(body); ok {
+			*risks = append(*risks, ReDoSRisk{Fragment: re.String(), Reason: reason})
+		}
+	}
+	for _, sub := range re.Sub {
+		walkReDoS(sub, risks)
+	}
+}
+
+// repeatedBodyRisk reports whether the body of an unbounded repetition lets the
+// engine split the same text between iterations in many ways.
+func repeatedBodyRisk(body *syntax.Regexp) (string, bool) {
+	first := firstRunes(body)
+
+	if body.Op == syntax.OpAlternate {
+		for i, a := range body.Sub {
+			for _, b := range body.Sub[i+1:] {
+				if runesOverlap(firstRunes(a), firstRunes(b)) {
+					return "repeated alternatives can start with the same character", true
+				}
+			}
+		}
+	}
+
+	elements := []*syntax.Regexp{body}
+	if body.Op == syntax.OpConcat {
+		elements = body.Sub
+	}
+	// Walk back over the elements that can end an iteration.
+	for i := len(elements) - 1; i >= 0; i-- {
+		element := uncapture(elements[i])
+		if isQuantifier(element) && runesOverlap(firstRunes(element), first) {
+			return "nested quantifier can also match the start of the next repetition", true
+		}
+		if !nullable(element) {
+			break
+		}
+	}
+	return "", false
+}
+
+// isUnboundedRepeat reports whether re repeats its body without an upper limit.
+func isUnboundedRepeat(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		return re.Max == -1
+	}
+	return false
+}
+
+// isQuantifier reports whether re is a quantified subexpression.
+func isQuantifier(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		return true
+	case syntax.OpRepeat:
+		return re.Max == -1 || re.Max > re.Min
+	}
+	return false
+}
+
+// uncapture strips capture groups, which do not affect what is matched.
+func uncapture(re *syntax.Regexp) *syntax.Regexp {
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	return re
+}
+
+// nullable reports whether re can match the empty string.
+func nullable(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral, syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL, syntax.OpNoMatch:
+		return false
+	case syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpRepeat:
+		return re.Min == 0 || nullable(re.Sub[0])
+	case syntax.OpPlus, syntax.OpCapture:
+		return nullable(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !nullable(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if nullable(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return true // Empty matches and zero-width assertions
+}
+
+// firstRunes returns the characters that can start a match of re, as a list of
+// inclusive ranges in the style of syntax.Regexp.Rune.
+func firstRunes(re *syntax.Regexp) []rune {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return nil
+		}
+		r := re.Rune[0]
+		ranges := []rune{r, r}
+		if re.Flags&syntax.FoldCase != 0 {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				ranges = append(ranges, f, f)
+			}
+		}
+		return ranges
+	case syntax.OpCharClass:
+		return re.Rune
+	case syntax.OpAnyChar:
+		return []rune{0, unicode.MaxRune}
+	case syntax.OpAnyCharNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}
+	case syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return firstRunes(re.Sub[0])
+	case syntax.OpConcat:
+		var ranges []rune
+		for _, sub := range re.Sub {
+			ranges = append(ranges, firstRunes(sub)...)
+			if !nullable(sub) {
+				break
+			}
+		}
+		return ranges
+	case syntax.OpAlternate:
+		var ranges []rune
+		for _, sub := range re.Sub {
+			ranges = append(ranges, firstRunes(sub)...)
+		}
+		return ranges
+	}
+	return nil
+}
+
+// runesOverlap reports whether two lists of rune ranges have a rune in common.
+func runesOverlap(a, b []rune) bool {
+	for i := 0; i+1 < len(a); i += 2 {
+		for j := 0; j+1 < len(b); j += 2 {
+			if a[i] <= b[j+1] && b[j] <= a[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reDoSErrors reports the risky patterns among patterns, for engines that backtrack.
+func reDoSErrors(engine RegexpEngine, patterns []string) []error {
+	if backtracker, ok := engine.(Backtracker); !ok || !backtracker.Backtracks() {
+		return nil
+	}
+	var errs []error
+	for _, pattern := range patterns {
+		for _, risk := range ReDoSRisks(pattern) {
+			errs = append(errs, codeErrorf(CodeReDoSRisk, "pattern '%s' risks catastrophic backtracking in %s: %s", pattern, risk.Fragment, risk.Reason))
+		}
+	}
+	return errs
+}
+
+// SetAllowReDoS controls whether Recompile accepts patterns that ReDoSRisks flags
+// when the engine backtracks (see Backtracker). By default such patterns are
+// rejected, so that they are found when the rules are loaded rather than when an
+// unlucky input stalls a lookup. Engines that do not backtrack, such as Go's
+// standard engine, are unaffected.
+func (rt *RegexpTable[T]) SetAllowReDoS(allowed bool) {
+	if rt.allowReDoS != allowed {
+		rt.allowReDoS = allowed
+		rt.needsRecompile = true
+	}
+}
+
+// checkReDoS rejects risky patterns as described by SetAllowReDoS.
+func (rt *RegexpTable[T]) checkReDoS() error {
+	if rt.allowReDoS {
+		return nil
+	}
+	errs := reDoSErrors(rt.engine, rt.Patterns())
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+// backtrackingEngine is the standard engine posing as a backtracking engine.
+type backtrackingEngine struct {
+	StandardRegexpEngine
+}
+
+func (e *backtrackingEngine) Backtracks() bool {
+	return true
+}
+
+func TestReDoSRisks(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		risky   bool
+	}{
+		{`(a+)+`, true},
+		{`(a*)*b`, true},
+		{`^(\w+\s?)*$`, true},
+		{`(?:aa?)+`, true},
+		{`(\w+|x\d)*`, true},
+		{`(\w|\d\.)+`, true},
+		{`(a|a\d)+`, false}, // Factored into (?:a\d?)+, which is harmless
+		{`((ab)+)+`, true},
+		{`(\d+,)+`, false},
+		{`(ab?)+`, false},
+		{`(a+b)+`, false},
+		{`\d+\.\d+`, false},
+		{`(foo|bar)+`, false},
+		{`(a+){2,5}`, false},
+		{`(?<=x)y`, false}, // Not Go syntax, so not analysed
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			risks := ReDoSRisks(tc.pattern)
+			if (len(risks) > 0) != tc.risky {
+				t.Errorf("Expected risky=%v for %q, got %v", tc.risky, tc.pattern, risks)
+			}
+		})
+	}
+}
+
+func TestRegexpTable_ReDoSCheck(t *testing.T) {
+	table := NewRegexpTableWithEngine[string](&backtrackingEngine{}, true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`(\w+\s?)*`, "words")
+	err := table.Recompile()
+	if err == nil || !strings.Contains(err.Error(), `(\w+\s?)*`) {
+		t.Fatalf("Expected the risky pattern to be rejected, got %v", err)
+	}
+
+	table.SetAllowReDoS(true)
+	if err := table.Recompile(); err != nil {
+		t.Errorf("Expected SetAllowReDoS to accept the pattern: %v", err)
+	}
+
+	// Engines that cannot backtrack need no check.
+	standard := NewRegexpTable[string](true, true)
+	standard.AddPattern(`(a+)+`, "as")
+	if err := standard.Recompile(); err != nil {
+		t.Errorf("Expected the standard engine to accept the pattern: %v", err)
+	}
+
+	builder := NewRegexpTableBuilder[string]().AddPattern(`(\w|x\d)+`, "a")
+	if errs := builder.Validate(true, true); errs != nil {
+		t.Errorf("Expected no problems with the standard engine, got %v", errs)
+	}
+	if errs := builder.ValidateAgainst(&backtrackingEngine{}, true, true); len(errs) != 1 {
+		t.Errorf("Expected a backtracking engine to report the risk, got %v", errs)
+	}
+	if errs := builder.Clone().WithAllowReDoS(true).ValidateAgainst(&backtrackingEngine{}, true, true); errs != nil {
+		t.Errorf("Expected WithAllowReDoS to accept the pattern, got %v", errs)
+	}
+}
@@ -1,5 +1,9 @@
 package regexptable
 
+import (
+	"io"
+)
+
 // RegexpEngine defines the minimal interface needed by RegexpTable for regexp operations.
 // This abstraction allows different regexp engines to be used with RegexpTable.
 type RegexpEngine interface {
@@ -44,3 +48,122 @@ type CompiledRegexp interface {
 	// - Index 3: "" (third group, unnamed)
 	SubexpNames() []string
 }
+
+// CaptureStripper is an optional interface that a RegexpEngine may implement to
+// support the non-capturing table mode. StripCaptures rewrites a pattern so that
+// every capture group becomes non-capturing while matching exactly the same strings.
+type CaptureStripper interface {
+	StripCaptures(pattern string) (string, error)
+}
+
+// LiteralAnalyzer is an optional interface that a RegexpEngine may implement to
+// enable optimizer passes that reason about literal text in patterns.
+type LiteralAnalyzer interface {
+
+	// LiteralPrefix splits a pattern into a case-sensitive literal prefix and a
+	// pattern for the remainder, such that prefix followed by rest matches exactly
+	// the same strings as the original pattern. ok is false when there is no such
+	// prefix or the pattern cannot be analysed.
+	LiteralPrefix(pattern string) (prefix, rest string, ok bool)
+
+	// QuoteLiteral returns a pattern that matches the literal text exactly.
+	QuoteLiteral(literal string) string
+}
+
+// SuffixAnalyzer is an optional interface that a RegexpEngine implementing
+// LiteralAnalyzer may also implement to enable suffix factoring. See
+// RegexpTable.SetSuffixFactoring.
+type SuffixAnalyzer interface {
+
+	// LiteralSuffix splits a pattern into a pattern for its beginning and a
+	// case-sensitive literal suffix, such that rest followed by suffix matches
+	// exactly the same strings as the original pattern. ok is false when there is
+	// no such suffix or the pattern cannot be analysed.
+	LiteralSuffix(pattern string) (rest, suffix string, ok bool)
+}
+
+// ReaderMatcher is an optional interface that a CompiledRegexp may implement to
+// support matching against an io.RuneReader, as used by RegexpTable.LookupReader.
+type ReaderMatcher interface {
+
+	// FindReaderSubmatchIndex behaves like Go's regexp.FindReaderSubmatchIndex: it
+	// returns pairs of byte offsets for the full match and each capture group, with
+	// -1 for groups that did not participate, or nil if there is no match.
+	FindReaderSubmatchIndex(r io.RuneReader) []int
+}
+
+// ByteMatcher is an optional interface that a CompiledRegexp may implement to match
+// byte slices directly, avoiding conversions to string.
+type ByteMatcher interface {
+
+	// FindSubmatchIndex behaves like Go's regexp.FindSubmatchIndex: it returns pairs
+	// of byte offsets for the full match and each capture group, with -1 for groups
+	// that did not participate, or nil if there is no match.
+	FindSubmatchIndex(b []byte) []int
+}
+
+// IndexMatcher is an optional interface that a CompiledRegexp may implement to
+// report where each group matched. Lookups use it to tell a group that matched the
+// empty string apart from one that did not participate, which FindStringSubmatch
+// cannot express.
+type IndexMatcher interface {
+
+	// FindStringSubmatchIndex behaves like Go's regexp.FindStringSubmatchIndex: it
+	// returns pairs of byte offsets for the full match and each capture group, with
+	// -1 for groups that did not participate, or nil if there is no match.
+	FindStringSubmatchIndex(s string) []int
+}
+
+// FlagFormatter is an optional interface that a RegexpEngine may implement to apply
+// inline flags to a pattern, as needed by table modes such as SetUngreedy.
+type FlagFormatter interface {
+
+	// FormatFlags returns a pattern that matches like pattern with the given
+	// flags set, using the engine's syntax; for Go this is (?flags:pattern). The
+	// flags use Go's letters (i, m, s and U). ok is false if the engine does not
+	// support one of them.
+	FormatFlags(flags, pattern string) (result string, ok bool)
+}
+
+// GroupNamer is an optional interface that a RegexpEngine may implement to choose
+// the names of the capture groups a table wraps around each of its patterns, for
+// engines that restrict group names, for instance in length or in the characters
+// allowed. Without it tables use names of the form __REGEXPTABLE_1__.
+type GroupNamer interface {
+
+	// GroupName returns the internal group name for the i-th pattern added to a
+	// table, counting from 1. Distinct values of i must give distinct names, which
+	// should be unlikely to clash with the names of groups in user patterns.
+	GroupName(i int) string
+}
+
+// Backtracker is an optional interface that a RegexpEngine may implement to report
+// that it matches by backtracking, as PCRE-style engines do. Such engines can take
+// exponential time on some patterns, so tables check their patterns with
+// ReDoSRisks when they are compiled; see RegexpTable.SetAllowReDoS.
+type Backtracker interface {
+	Backtracks() bool
+}
+
+// Backreferencer is an optional interface that a RegexpEngine may implement to
+// report that it supports backreferences such as \1 and \k<name>. Tables wrap each
+// pattern in a group of their own in the union, which shifts the numbers of the
+// pattern's groups, so they rewrite every backreference to refer to its group by
+// its number in the union; named references are resolved within their own
+// pattern, so that patterns may reuse group names. Groups must be numbered in the
+// order they open, as in Perl. Tables reject patterns with backreferences when
+// the engine does not implement this interface.
+type Backreferencer interface {
+
+	// FormatBackreference returns a backreference to the i-th capture group in the
+	// engine's syntax, for example \g{12} for PCRE.
+	FormatBackreference(i int) string
+}
+
+// PositionalGrouper is an optional interface that a RegexpEngine may implement to
+// report that it has no named groups at all. Tables then track each pattern's
+// groups by position instead of by name, and never call FormatNamedGroup; see
+// RegexpTable.SetPositionalGroups.
+type PositionalGrouper interface {
+	PositionalGroups() bool
+}
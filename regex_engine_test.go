@@ -137,3 +137,64 @@ func TestStandardRegexpEngine_FormatNamedGroup(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, result)
 	}
 }
+
+// shortNameEngine is the standard engine with a group naming scheme of the kind
+// required by engines that restrict group names.
+type shortNameEngine struct {
+	StandardRegexpEngine
+}
+
+func (e *shortNameEngine) GroupName(i int) string {
+	return fmt.Sprintf("rt%d", i)
+}
+
+func TestRegexpTable_GroupNamer(t *testing.T) {
+	table := NewRegexpTableBuilderWithEngine[string](&shortNameEngine{}).
+		AddPattern(`(\d+)-(\d+)`, "range").
+		AddPattern(`(?P<word>[a-z]+)`, "word").
+		AddPattern(`x*`, "xs").
+		MustBuild(true, true)
+
+	if name := table.maplets[1].GroupName; name != "rt2" {
+		t.Errorf("Expected group name %q, got %q", "rt2", name)
+	}
+
+	result, err := table.LookupResult("10-20")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Value != "range" || len(result.Groups) != 3 || result.Groups[2] != "20" {
+		t.Errorf("Expected range with groups [10-20 10 20], got %q %v", result.Value, result.Groups)
+	}
+
+	result, err = table.LookupResult("abc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if word, _ := result.Field("word"); result.Value != "word" || word != "abc" {
+		t.Errorf("Expected word 'abc', got %q %q", result.Value, word)
+	}
+
+	if value, _, ok := table.TryLookup(""); !ok || value != "xs" {
+		t.Errorf("Expected 'xs' for the empty string, got %q", value)
+	}
+}
+
+func TestRegexpTable_UserGroupWithReservedPrefix(t *testing.T) {
+	// A user group whose name merely looks like an internal one must not be
+	// mistaken for the start of another entry.
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(?P<__REGEXPTABLE_9__>a)b`, "ab")
+	table.AddPattern(`c`, "c")
+
+	result, err := table.LookupResult("ab")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Value != "ab" || len(result.Groups) != 2 || result.Groups[1] != "a" {
+		t.Errorf("Expected 'ab' with groups [ab a], got %q %v", result.Value, result.Groups)
+	}
+	if value, _, ok := table.TryLookup("c"); !ok || value != "c" {
+		t.Errorf("Expected 'c', got %q", value)
+	}
+}
@@ -0,0 +1,171 @@
+// Package regexp2engine adapts Doug Clark's regexp2 package, a backtracking
+// engine with .NET regular expression syntax and semantics, for use with
+// regexptable. It offers what Go's regexp package does not, such as lookaround,
+// atomic groups and backreferences within a pattern, at the cost of matching
+// time that is not linear in the input.
+//
+// The adapter lives in a module of its own so that regexptable itself keeps no
+// dependencies beyond the standard library.
+//
+// Patterns use .NET syntax: named groups are written (?<name>x) or (?'name'x),
+// and $ at the end of a pattern also matches before a final newline, so an
+// anchored table accepts "abc\n" where Go's engine would not. .NET numbers the
+// unnamed groups of a pattern before the named ones; the adapter reports groups
+// in the order they open in the pattern, as Go does, so Result.Groups and the
+// group numbers used with a table mean the same with either engine.
+package regexp2engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dlclark/regexp2"
+	"github.com/sfkleach/regexptable"
+)
+
+// Engine implements regexptable.RegexpEngine using regexp2.
+type Engine struct {
+	options regexp2.RegexOptions
+}
+
+// New returns an engine that compiles patterns with .NET's default options.
+func New() *Engine {
+	return &Engine{options: regexp2.None}
+}
+
+// NewWithOptions returns an engine that compiles patterns with the given
+// options, such as regexp2.IgnoreCase or regexp2.ExplicitCapture.
+func NewWithOptions(options regexp2.RegexOptions) *Engine {
+	return &Engine{options: options}
+}
+
+// Compile compiles a pattern with regexp2.
+func (e *Engine) Compile(pattern string) (regexptable.CompiledRegexp, error) {
+	compiled, err := regexp2.Compile(pattern, e.options)
+	if err != nil {
+		return nil, err
+	}
+	return newCompiledRegexp(compiled, pattern, e.options), nil
+}
+
+// FormatNamedGroup formats a named group using .NET's (?<name>pattern) syntax.
+func (e *Engine) FormatNamedGroup(groupName, pattern string) string {
+	return fmt.Sprintf("(?<%s>%s)", groupName, pattern)
+}
+
+// FormatFlags applies inline flags using .NET's (?flags:pattern) syntax. .NET has
+// no ungreedy flag, so U is not supported.
+func (e *Engine) FormatFlags(flags, pattern string) (string, bool) {
+	if strings.ContainsFunc(flags, func(r rune) bool { return !strings.ContainsRune("ims", r) }) {
+		return "", false
+	}
+	return "(?" + flags + ":" + pattern + ")", true
+}
+
+// Name returns "regexp2".
+func (e *Engine) Name() string {
+	return "regexp2"
+}
+
+// Backtracks reports that regexp2 matches by backtracking, so that tables reject
+// patterns prone to catastrophic backtracking unless told otherwise.
+func (e *Engine) Backtracks() bool {
+	return true
+}
+
+// CompiledRegexp implements regexptable.CompiledRegexp, and IndexMatcher, for a
+// regexp compiled by regexp2. A match that fails, for instance because it timed
+// out, is reported as no match.
+type CompiledRegexp struct {
+	regexp *regexp2.Regexp
+	order  []int    // regexp2's numbers for the groups, in the order they open in the pattern
+	names  []string // The names of the groups in that order, "" for unnamed ones
+}
+
+// newCompiledRegexp wraps a compiled pattern, working out the order of its groups.
+func newCompiledRegexp(compiled *regexp2.Regexp, pattern string, options regexp2.RegexOptions) *CompiledRegexp {
+	r := &CompiledRegexp{regexp: compiled, order: []int{0}, names: []string{""}}
+	seen := map[int]bool{0: true}
+	add := func(number int, name string) {
+		if number > 0 && !seen[number] {
+			seen[number] = true
+			r.order = append(r.order, number)
+			r.names = append(r.names, name)
+		}
+	}
+	unnamed := 0
+	for _, group := range scanGroups(pattern, options&regexp2.ExplicitCapture != 0) {
+		if group.name == "" {
+			unnamed++
+			add(unnamed, "")
+		} else {
+			add(compiled.GroupNumberFromName(group.name), group.name)
+		}
+	}
+	// Groups the scan missed, if any, still get reported, after the others.
+	for _, number := range compiled.GetGroupNumbers() {
+		name := compiled.GroupNameFromNumber(number)
+		if name == strconv.Itoa(number) {
+			name = ""
+		}
+		add(number, name)
+	}
+	return r
+}
+
+// FindStringSubmatch returns the text of the match and its groups, with "" for
+// groups that did not participate.
+func (r *CompiledRegexp) FindStringSubmatch(s string) []string {
+	loc := r.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil
+	}
+	matches := make([]string, len(loc)/2)
+	for i := range matches {
+		if loc[2*i] >= 0 {
+			matches[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return matches
+}
+
+// FindStringSubmatchIndex returns the byte offsets of the match and its groups,
+// with -1 for groups that did not participate, or nil if there is no match.
+// regexp2 reports offsets in runes, which are converted to bytes.
+func (r *CompiledRegexp) FindStringSubmatchIndex(s string) []int {
+	match, err := r.regexp.FindStringMatch(s)
+	if err != nil || match == nil {
+		return nil
+	}
+	offsets := runeOffsets(s)
+	loc := make([]int, 2*len(r.order))
+	for i, number := range r.order {
+		group := match.GroupByNumber(number)
+		if group == nil || len(group.Captures) == 0 {
+			loc[2*i], loc[2*i+1] = -1, -1
+			continue
+		}
+		loc[2*i], loc[2*i+1] = offsets[group.Index], offsets[group.Index+group.Length]
+	}
+	return loc
+}
+
+// SubexpNames returns the names of the groups, "" for the full match and for
+// unnamed groups, in the order they open in the pattern.
+func (r *CompiledRegexp) SubexpNames() []string {
+	return r.names
+}
+
+// runeOffsets returns the byte offset in s of each rune regexp2 sees, followed by
+// len(s). Like regexp2, it treats each byte of invalid UTF-8 as a rune.
+func runeOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); {
+		offsets = append(offsets, i)
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return append(offsets, len(s))
+}
@@ -0,0 +1,124 @@
+package regexp2engine
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dlclark/regexp2"
+	"github.com/sfkleach/regexptable"
+	"github.com/sfkleach/regexptable/enginetest"
+	"github.com/sfkleach/regexptable/regexptabletest"
+)
+
+func TestCompiledRegexp_GroupOrder(t *testing.T) {
+	// .NET numbers the unnamed groups first: (y) is 1, (w) is 2, then a and b.
+	compiled, err := New().Compile(`(?<a>x)(y)(?<b>z)(w)?`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if names := compiled.SubexpNames(); !slices.Equal(names, []string{"", "a", "", "b", ""}) {
+		t.Errorf("Expected the groups in pattern order, got %q", names)
+	}
+	if matches := compiled.FindStringSubmatch("xyz"); !slices.Equal(matches, []string{"xyz", "x", "y", "z", ""}) {
+		t.Errorf("Expected the submatches in pattern order, got %q", matches)
+	}
+	loc := compiled.(regexptable.IndexMatcher).FindStringSubmatchIndex("éxyz")
+	if !slices.Equal(loc, []int{2, 5, 2, 3, 3, 4, 4, 5, -1, -1}) {
+		t.Errorf("Expected byte offsets, with -1 for the missing group, got %v", loc)
+	}
+	if compiled.FindStringSubmatch("nothing") != nil {
+		t.Error("Expected no match")
+	}
+}
+
+func TestEngine_EngineTest(t *testing.T) {
+	enginetest.Run(t, New())
+}
+
+func TestEngine_Table(t *testing.T) {
+	table, err := regexptable.NewRegexpTableBuilderWithEngine[string](New()).
+		AddPattern(`(?<year>\d{4})-(\d{2})-(?<day>\d{2})`, "date").
+		AddPattern(`(?<=\$)(?<amount>\d+)`, "price"). // Lookbehind, which Go lacks
+		AddPattern(`(?<word>\w+)`, "word").
+		Build(false, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if name := table.EngineName(); name != "regexp2" {
+		t.Errorf("Expected regexp2, got %q", name)
+	}
+
+	result, err := table.LookupResult("2024-05-17 onwards")
+	if err != nil || result.Value != "date" {
+		t.Fatalf("Expected date, got %v, %v", result, err)
+	}
+	if !slices.Equal(result.Groups, []string{"2024-05-17", "2024", "05", "17"}) {
+		t.Errorf("Expected the groups in pattern order, got %q", result.Groups)
+	}
+	if day, _ := result.Field("day"); day != "17" {
+		t.Errorf("Expected day 17, got %q", day)
+	}
+	if result.Start != 0 || result.End != 10 {
+		t.Errorf("Expected the match at 0-10, got %d-%d", result.Start, result.End)
+	}
+
+	if value, matches, _ := table.Lookup("costs $42"); value != "word" || matches[0] != "costs" {
+		t.Errorf("Expected the leftmost match, a word, got %s %q", value, matches)
+	}
+	if value, matches, _ := table.Lookup("$42"); value != "price" || matches[1] != "42" {
+		t.Errorf("Expected price, got %s %q", value, matches)
+	}
+}
+
+func TestEngine_ReDoS(t *testing.T) {
+	_, err := regexptable.NewRegexpTableBuilderWithEngine[string](New()).
+		AddPattern(`(a+)+b`, "risky").
+		Build(true, true)
+	if regexptable.ErrorCodeOf(err) != regexptable.CodeReDoSRisk {
+		t.Errorf("Expected the backtracking engine to reject the pattern, got %v", err)
+	}
+}
+
+func TestEngine_Flags(t *testing.T) {
+	table := regexptable.NewRegexpTableWithEngine[string](NewWithOptions(regexp2.None), true, true)
+	table.SetCaseInsensitive(true)
+	table.AddPattern(`hello`, "greeting")
+	if value, _, err := table.Lookup("HeLLo"); err != nil || value != "greeting" {
+		t.Errorf("Expected greeting, got %q, %v", value, err)
+	}
+	if _, ok := New().FormatFlags("U", "x"); ok {
+		t.Error("Expected the ungreedy flag to be unsupported")
+	}
+}
+
+// TestEngine_Conformance classifies a corpus with both engines, which must agree
+// except where .NET's semantics differ from Go's.
+func TestEngine_Conformance(t *testing.T) {
+	spec := &regexptable.Spec{Version: 1, AnchorStart: true, AnchorEnd: true}
+	for i, pattern := range []string{
+		`(?<key>[a-z]+)=(?<value>\d+)`, // Go has accepted .NET's named group syntax since 1.22
+		`(\d+)\.(\d+)(?:\.(\d+))?`,
+		`(?<user>\w+)@(\w+)\.com`,
+		`(a|ab)(c|bcd)(d*)`,
+		`[^\n]*`,
+	} {
+		value, _ := json.Marshal(i)
+		spec.Entries = append(spec.Entries, regexptable.SpecEntry{Pattern: pattern, Value: value})
+	}
+	corpus := []string{"x=1", "1.2", "1.2.3", "joe@example.com", "abcd", "héllo wörld", "", "tail\n"}
+
+	divergences, err := regexptabletest.CompareEngines(spec, corpus, regexptable.NewStandardRegexpEngine(), New())
+	if err != nil {
+		t.Fatalf("CompareEngines failed: %v", err)
+	}
+	// .NET's $ also matches before a final newline.
+	if len(divergences) != 1 || divergences[0].Input != "tail\n" {
+		var report []string
+		for _, divergence := range divergences {
+			report = append(report, divergence.String())
+		}
+		t.Errorf("Expected the engines to differ only on the final newline, got:\n%s", strings.Join(report, "\n"))
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/sfkleach/regexptable/regexp2engine
+
+go 1.24.2
+
+require github.com/sfkleach/regexptable v0.0.0
+
+require github.com/dlclark/regexp2 v1.12.0
+
+replace github.com/sfkleach/regexptable => ../
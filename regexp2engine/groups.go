@@ -0,0 +1,98 @@
+package regexp2engine
+
+import "strings"
+
+// group is a capture group found by scanGroups.
+type group struct {
+	name string // "" for an unnamed group
+}
+
+// scanGroups returns the capture groups of a pattern in .NET syntax in the order
+// they open. Unnamed groups are left out if explicitCapture is set, as with the
+// ExplicitCapture option. Comment groups, bracket expressions and escapes are
+// skipped, as is the condition of a conditional group such as (?(name)yes|no).
+// Inline options, such as (?n) or (?x), are not taken into account.
+func scanGroups(pattern string, explicitCapture bool) []group {
+	var groups []group
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = bracketEnd(pattern, i)
+		case '(':
+			rest := pattern[i+1:]
+			switch {
+			case !strings.HasPrefix(rest, "?"):
+				if !explicitCapture {
+					groups = append(groups, group{})
+				}
+			case strings.HasPrefix(rest, "?#"):
+				if end := strings.IndexByte(rest, ')'); end >= 0 {
+					i += end + 1
+				}
+			case strings.HasPrefix(rest, "?("):
+				// The condition is not a group, unless it is a lookaround.
+				if !strings.HasPrefix(rest, "?(?") {
+					if end := strings.IndexByte(rest, ')'); end >= 0 {
+						i += end + 1
+					}
+				}
+			default:
+				if name, ok := groupName(rest[1:]); ok {
+					groups = append(groups, group{name: name})
+				}
+			}
+		}
+	}
+	return groups
+}
+
+// groupName returns the name of a named group, given the text following "(?",
+// such as <name>x) or 'name'x). For a balancing group, (?<name1-name2>x), it
+// returns name1, which may be empty, since (?<-name2>x) captures nothing.
+func groupName(text string) (string, bool) {
+	if text == "" || (text[0] != '<' && text[0] != '\'') {
+		return "", false
+	}
+	closing := ">"
+	if text[0] == '\'' {
+		closing = "'"
+	}
+	name, _, found := strings.Cut(text[1:], closing)
+	if !found {
+		return "", false
+	}
+	if name == "" || name[0] == '=' || name[0] == '!' {
+		return "", false // Lookbehind
+	}
+	name, _, _ = strings.Cut(name, "-")
+	return name, name != ""
+}
+
+// bracketEnd returns the index of the ] that closes the bracket expression
+// starting at pattern[start], or the end of the pattern if there is none. A ]
+// straight after [ or [^ is literal, and a nested [ follows - in a character
+// class subtraction such as [a-z-[aeiou]].
+func bracketEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			if pattern[i-1] == '-' {
+				i = bracketEnd(pattern, i)
+			}
+		case ']':
+			return i
+		}
+	}
+	return len(pattern)
+}
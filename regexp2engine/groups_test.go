@@ -0,0 +1,34 @@
+package regexp2engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScanGroups(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		explicit bool
+		want     []string // "" for an unnamed group
+	}{
+		{`(a)(?<x>b)(c)`, false, []string{"", "x", ""}},
+		{`(a)(?<x>b)(c)`, true, []string{"x"}},
+		{`(?'x'a)(?<y>b)`, false, []string{"x", "y"}},
+		{`(?:a)(?=b)(?!c)(?<=d)(?<!e)(?>f)(?i)g`, false, nil},
+		{`\(a\)[(](b)`, false, []string{""}},
+		{`[a-z-[(]](x)[]()]`, false, []string{""}},
+		{`(?#(not a group))(a)`, false, []string{""}},
+		{`(?<open>\()(?<close-open>\))(?<-open>x)`, false, []string{"open", "close"}},
+		{`(?(x)(a)|(b))`, false, []string{"", ""}},
+		{`(?((?=a))a|b)`, false, nil},
+	}
+	for _, tc := range testCases {
+		var got []string
+		for _, group := range scanGroups(tc.pattern, tc.explicit) {
+			got = append(got, group.name)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("Expected groups %q for %s, got %q", tc.want, tc.pattern, got)
+		}
+	}
+}
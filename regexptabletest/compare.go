@@ -0,0 +1,118 @@
+package regexptabletest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// EngineOutcome is how one engine classified an input, as reported by
+// CompareEngines.
+type EngineOutcome struct {
+	Engine  string   // The engine's name, see regexptable.EngineNamer
+	Matched bool     // Whether any pattern matched
+	Pattern string   // The winning pattern, "" if nothing matched
+	Index   int      // The winning pattern's insertion index, -1 if nothing matched
+	Groups  []string // The submatches, nil if nothing matched
+}
+
+// String describes the outcome briefly, e.g. go-regexp: pattern 2 `\d+` ["42"].
+func (o EngineOutcome) String() string {
+	if !o.Matched {
+		return o.Engine + ": no match"
+	}
+	return fmt.Sprintf("%s: pattern %d `%s` %q", o.Engine, o.Index, o.Pattern, o.Groups)
+}
+
+// Divergence is an input that two or more engines classify differently.
+type Divergence struct {
+	Input    string
+	Outcomes []EngineOutcome // One per engine, in the order the engines were given
+}
+
+// String describes the divergence on one line per engine.
+func (d Divergence) String() string {
+	lines := []string{fmt.Sprintf("input %q:", d.Input)}
+	for _, outcome := range d.Outcomes {
+		lines = append(lines, "  "+outcome.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CompareEngines builds a table from spec with each of the engines and
+// classifies every input of the corpus with each table, returning the inputs on
+// which they disagree about the winning pattern or its submatches. It is meant
+// to be run before moving a production table to a different engine, whose
+// syntax and matching semantics may differ subtly from the current one.
+//
+// An error is returned if fewer than two engines are given, if the spec cannot be
+// built with one of them or if a lookup fails other than by not matching.
+func CompareEngines(spec *regexptable.Spec, corpus []string, engines ...regexptable.RegexpEngine) ([]Divergence, error) {
+	if len(engines) < 2 {
+		return nil, fmt.Errorf("CompareEngines needs at least two engines, got %d", len(engines))
+	}
+
+	names := make([]string, len(engines))
+	tables := make([]*regexptable.RegexpTable[json.RawMessage], len(engines))
+	for i, engine := range engines {
+		names[i] = engineName(engine)
+		table, err := regexptable.NewLoader[json.RawMessage](regexptable.LoadLenient).WithEngine(engine).Build(spec)
+		if err != nil {
+			return nil, fmt.Errorf("engine %s: %w", names[i], err)
+		}
+		tables[i] = table
+	}
+
+	var divergences []Divergence
+	for _, input := range corpus {
+		outcomes := make([]EngineOutcome, len(tables))
+		for i, table := range tables {
+			outcome, err := classify(table, input)
+			if err != nil {
+				return nil, fmt.Errorf("engine %s: input %q: %w", names[i], input, err)
+			}
+			outcome.Engine = names[i]
+			outcomes[i] = outcome
+		}
+		if !outcomesAgree(outcomes) {
+			divergences = append(divergences, Divergence{Input: input, Outcomes: outcomes})
+		}
+	}
+	return divergences, nil
+}
+
+// classify looks up an input in a table, reporting a failure to match as an
+// outcome rather than an error.
+func classify(table *regexptable.RegexpTable[json.RawMessage], input string) (EngineOutcome, error) {
+	result, err := table.LookupResult(input)
+	if errors.Is(err, regexptable.ErrNoMatch) {
+		return EngineOutcome{Index: -1}, nil
+	}
+	if err != nil {
+		return EngineOutcome{}, err
+	}
+	return EngineOutcome{Matched: true, Pattern: result.Pattern, Index: result.Index, Groups: result.Groups}, nil
+}
+
+// outcomesAgree reports whether every engine classified an input the same way.
+func outcomesAgree(outcomes []EngineOutcome) bool {
+	first := outcomes[0]
+	for _, outcome := range outcomes[1:] {
+		if outcome.Matched != first.Matched || outcome.Index != first.Index || !slices.Equal(outcome.Groups, first.Groups) {
+			return false
+		}
+	}
+	return true
+}
+
+// engineName names an engine the way table fingerprints do.
+func engineName(engine regexptable.RegexpEngine) string {
+	if namer, ok := engine.(regexptable.EngineNamer); ok {
+		return namer.Name()
+	}
+	return fmt.Sprintf("%T", engine)
+}
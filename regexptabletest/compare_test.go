@@ -0,0 +1,85 @@
+package regexptabletest
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// longestEngine is the standard engine with leftmost-longest semantics, which
+// picks a different winner whenever an earlier alternative matches less text.
+type longestEngine struct {
+	regexptable.StandardRegexpEngine
+}
+
+func (e *longestEngine) Compile(pattern string) (regexptable.CompiledRegexp, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Longest()
+	return regexptable.NewStandardCompiledRegexp(compiled), nil
+}
+
+func (e *longestEngine) Name() string {
+	return "go-regexp-longest"
+}
+
+func newCompareSpec() *regexptable.Spec {
+	return &regexptable.Spec{
+		Version: regexptable.SpecVersion,
+		Entries: []regexptable.SpecEntry{
+			{Pattern: `a`, Value: json.RawMessage(`"a"`)},
+			{Pattern: `ab`, Value: json.RawMessage(`"ab"`)},
+			{Pattern: `(\d+?)`, Value: json.RawMessage(`"number"`)},
+		},
+	}
+}
+
+func TestCompareEngines(t *testing.T) {
+	divergences, err := CompareEngines(newCompareSpec(), []string{"xa", "ab", "12", "-"},
+		regexptable.NewStandardRegexpEngine(), &longestEngine{})
+	if err != nil {
+		t.Fatalf("CompareEngines failed: %v", err)
+	}
+	if len(divergences) != 2 {
+		t.Fatalf("Expected 2 divergences, got %d: %v", len(divergences), divergences)
+	}
+
+	// A different winner.
+	ab := divergences[0]
+	if ab.Input != "ab" {
+		t.Errorf("Expected the first divergence to be for \"ab\", got %q", ab.Input)
+	}
+	if ab.Outcomes[0].Index != 0 || ab.Outcomes[1].Index != 1 {
+		t.Errorf("Expected winners 0 and 1, got %d and %d", ab.Outcomes[0].Index, ab.Outcomes[1].Index)
+	}
+	if ab.Outcomes[1].Engine != "go-regexp-longest" {
+		t.Errorf("Expected the second engine's name, got %q", ab.Outcomes[1].Engine)
+	}
+
+	// The same winner with different groups.
+	number := divergences[1]
+	if number.Input != "12" || number.Outcomes[0].Index != number.Outcomes[1].Index {
+		t.Errorf("Expected \"12\" to diverge in its groups only, got %v", number)
+	}
+	if !strings.Contains(number.String(), `["1" "1"]`) || !strings.Contains(number.String(), `["12" "12"]`) {
+		t.Errorf("Expected the description to show both groups, got %s", number)
+	}
+}
+
+func TestCompareEngines_Errors(t *testing.T) {
+	if _, err := CompareEngines(newCompareSpec(), nil, regexptable.NewStandardRegexpEngine()); err == nil {
+		t.Error("Expected an error for a single engine")
+	}
+
+	spec := newCompareSpec()
+	spec.Entries = append(spec.Entries, regexptable.SpecEntry{Pattern: `(`, Value: json.RawMessage(`"bad"`)})
+	_, err := CompareEngines(spec, nil, regexptable.NewStandardRegexpEngine(), &longestEngine{})
+	if err == nil || !strings.Contains(err.Error(), "go-regexp") {
+		t.Errorf("Expected a build error naming the engine, got %v", err)
+	}
+}
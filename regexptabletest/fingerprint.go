@@ -0,0 +1,52 @@
+package regexptabletest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertFingerprintGolden
+// rewrite golden files instead of comparing against them, e.g.
+//
+//	REGEXPTABLE_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "REGEXPTABLE_UPDATE_GOLDEN"
+
+// AssertFingerprint reports an error if the table's fingerprint differs from want.
+func AssertFingerprint[T any](t testing.TB, table *regexptable.RegexpTable[T], want string) {
+	t.Helper()
+	if got := table.Fingerprint(); got != want {
+		t.Errorf("Table fingerprint changed: expected %s, got %s", want, got)
+	}
+}
+
+// AssertFingerprintGolden compares the table's fingerprint with the one recorded in
+// the golden file at path, typically under testdata. When UpdateGoldenEnv is set,
+// or the file does not exist yet, the file is written instead so that intended
+// changes can be accepted by re-running the tests.
+func AssertFingerprintGolden[T any](t testing.TB, table *regexptable.RegexpTable[T], path string) {
+	t.Helper()
+	got := table.Fingerprint()
+
+	data, err := os.ReadFile(path)
+	if os.Getenv(UpdateGoldenEnv) != "" || errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := strings.TrimSpace(string(data)); got != want {
+		t.Errorf("Table fingerprint changed: %s records %s, got %s (set %s=1 to accept the change)", path, want, got, UpdateGoldenEnv)
+	}
+}
@@ -0,0 +1,42 @@
+package regexptabletest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssertFingerprint(t *testing.T) {
+	table := newSampleTable(true, true)
+	AssertFingerprint(t, table, table.Fingerprint())
+
+	recorder := &testing.T{}
+	AssertFingerprint(recorder, table, "0000")
+	if !recorder.Failed() {
+		t.Error("Expected a mismatched fingerprint to fail")
+	}
+}
+
+func TestAssertFingerprintGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "table.fingerprint")
+	table := newSampleTable(true, true)
+
+	// The first run records the fingerprint.
+	AssertFingerprintGolden(t, table, path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the golden file to be written: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != table.Fingerprint() {
+		t.Errorf("Expected the golden file to hold the fingerprint, got %q", data)
+	}
+
+	// Later runs compare against it.
+	AssertFingerprintGolden(t, table, path)
+	recorder := &testing.T{}
+	AssertFingerprintGolden(recorder, newSampleTable(true, false), path)
+	if !recorder.Failed() {
+		t.Error("Expected a changed table to fail the golden comparison")
+	}
+}
@@ -0,0 +1,130 @@
+// Package regexptabletest provides helpers for testing and fuzzing code built on
+// regexptable. It is kept separate from the main package so that programs using
+// tables do not link in the testing package.
+//
+// The simplest use is a fuzz target for an application's own table:
+//
+//	func FuzzClassifier(f *testing.F) {
+//		regexptabletest.FuzzLookup(f, newClassifierTable())
+//	}
+package regexptabletest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+// AdversarialInputs returns inputs that commonly expose bugs in matching code:
+// empty and whitespace-only strings, invalid UTF-8, NUL bytes, combining marks,
+// very long runs and strings that look like regexp syntax.
+func AdversarialInputs() []string {
+	return []string{
+		"",
+		" ",
+		"\n",
+		"\r\n",
+		"\x00",
+		"\xff",
+		"a\xffb",
+		"\xe2\x82",
+		"é",
+		"é",
+		" ",
+		"\U0001F600",
+		strings.Repeat("a", 1024),
+		strings.Repeat("ab", 512),
+		"(?P<__REGEXPTABLE_1__>x)",
+		`\`,
+		"^$",
+		"[]",
+	}
+}
+
+// SeedCorpus returns seed inputs for fuzzing a table: up to n generated examples
+// of each of its patterns (see regexptable.Examples) followed by
+// AdversarialInputs.
+func SeedCorpus[T any](table *regexptable.RegexpTable[T], n int) []string {
+	var seeds []string
+	for _, pattern := range table.Patterns() {
+		seeds = append(seeds, regexptable.Examples(pattern, n)...)
+	}
+	return append(seeds, AdversarialInputs()...)
+}
+
+// FuzzLookup seeds f with SeedCorpus and fuzzes the table's lookups, checking
+// every input with CheckLookup.
+func FuzzLookup[T any](f *testing.F, table *regexptable.RegexpTable[T]) {
+	f.Helper()
+	for _, seed := range SeedCorpus(table, 3) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		CheckLookup(t, table, input)
+	})
+}
+
+// CheckLookup looks up input in table through each of the lookup entry points and
+// reports an error if they disagree or if the result breaks the table's
+// contract: the full match must be part of the input, respect the table's
+// anchoring and be reported consistently by Lookup, TryLookup, LookupResult and
+// LookupBytes, with LookupResult locating it in the input. Repeating the lookup
+// must give the same answer. Tables that normalize their inputs are not
+// supported, since their matches need not be part of the input.
+func CheckLookup[T any](t testing.TB, table *regexptable.RegexpTable[T], input string) {
+	t.Helper()
+
+	value, matches, err := table.Lookup(input)
+	tryValue, tryMatches, ok := table.TryLookup(input)
+	if ok != (err == nil) {
+		t.Fatalf("Lookup(%q) error %v but TryLookup ok=%v", input, err, ok)
+	}
+	if err != nil {
+		if _, _, err := table.LookupBytes([]byte(input)); err == nil {
+			t.Errorf("Lookup(%q) failed but LookupBytes matched", input)
+		}
+		return
+	}
+	if !reflect.DeepEqual(value, tryValue) || !reflect.DeepEqual(matches, tryMatches) {
+		t.Errorf("Lookup(%q) = (%v, %q) but TryLookup = (%v, %q)", input, value, matches, tryValue, tryMatches)
+	}
+
+	if len(matches) == 0 {
+		t.Fatalf("Lookup(%q) returned no full match", input)
+	}
+	full := matches[0]
+	anchoring := table.Anchoring()
+	switch {
+	case !strings.Contains(input, full):
+		t.Errorf("Lookup(%q): full match %q is not part of the input", input, full)
+	case anchoring.AnchorsStart() && !strings.HasPrefix(input, full):
+		t.Errorf("Lookup(%q): full match %q does not start the input", input, full)
+	case anchoring.AnchorsEnd() && !strings.HasSuffix(input, full):
+		t.Errorf("Lookup(%q): full match %q does not end the input", input, full)
+	}
+
+	result, err := table.LookupResult(input)
+	if err != nil {
+		t.Fatalf("Lookup(%q) matched but LookupResult failed: %v", input, err)
+	}
+	if !reflect.DeepEqual(result.Value, value) || !reflect.DeepEqual(result.Groups, matches) {
+		t.Errorf("Lookup(%q) = (%v, %q) but LookupResult = (%v, %q)", input, value, matches, result.Value, result.Groups)
+	}
+	if result.Start >= 0 && (result.End < result.Start || result.End > len(input) || input[result.Start:result.End] != full) {
+		t.Errorf("Lookup(%q): LookupResult span [%d, %d) does not locate the full match %q", input, result.Start, result.End, full)
+	}
+
+	byteValue, byteMatches, err := table.LookupBytes([]byte(input))
+	if err != nil {
+		t.Errorf("Lookup(%q) matched but LookupBytes failed: %v", input, err)
+	} else if !reflect.DeepEqual(byteValue, value) || byteMatches[0] != full {
+		t.Errorf("Lookup(%q) = (%v, %q) but LookupBytes = (%v, %q)", input, value, full, byteValue, byteMatches[0])
+	}
+
+	againValue, againMatches, err := table.Lookup(input)
+	if err != nil || !reflect.DeepEqual(againValue, value) || !reflect.DeepEqual(againMatches, matches) {
+		t.Errorf("Repeated Lookup(%q) = (%v, %q, %v), first gave (%v, %q)", input, againValue, againMatches, err, value, matches)
+	}
+}
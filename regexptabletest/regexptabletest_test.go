@@ -0,0 +1,46 @@
+package regexptabletest
+
+import (
+	"testing"
+
+	"github.com/sfkleach/regexptable"
+)
+
+func newSampleTable(anchorStart, anchorEnd bool) *regexptable.RegexpTable[string] {
+	return regexptable.NewRegexpTableBuilder[string]().
+		AddPattern(`\d+(\.\d+)?`, "number").
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`(?P<quote>["'])[^"']*["']`, "string").
+		AddPattern(`x*`, "xs").
+		AddPattern(`\s+`, "space").
+		MustBuild(anchorStart, anchorEnd)
+}
+
+func FuzzLookupAnchored(f *testing.F) {
+	FuzzLookup(f, newSampleTable(true, true))
+}
+
+func FuzzLookupUnanchored(f *testing.F) {
+	FuzzLookup(f, newSampleTable(false, false))
+}
+
+func FuzzLookupStartAnchored(f *testing.F) {
+	FuzzLookup(f, newSampleTable(true, false))
+}
+
+func TestSeedCorpus(t *testing.T) {
+	table := newSampleTable(true, true)
+	seeds := SeedCorpus(table, 2)
+	if len(seeds) <= len(AdversarialInputs()) {
+		t.Fatalf("Expected generated examples in the corpus, got %q", seeds)
+	}
+	matched := 0
+	for _, seed := range seeds {
+		if _, _, ok := table.TryLookup(seed); ok {
+			matched++
+		}
+	}
+	if matched < len(table.Patterns()) {
+		t.Errorf("Expected at least %d matching seeds, got %d", len(table.Patterns()), matched)
+	}
+}
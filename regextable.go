@@ -25,30 +25,94 @@
 package regexptable
 
 import (
+	"errors"
 	"fmt"
+	"iter"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by lookups that do not find a match. They can be tested for with
+// errors.Is.
+var (
+	ErrNoPatterns = &CodedError{Code: CodeNoPatterns, Err: errors.New("no patterns configured")}
+	ErrNoMatch    = &CodedError{Code: CodeNoMatch, Err: errors.New("no pattern matched")}
 )
 
 // ValueAndPattern holds both the value and original pattern for a regexp group.
 type ValueAndPattern[T any] struct {
-	GroupName       string // e.g. __REGEXPTABLE_1
-	namedPattern    string // e.g. (?P<__REGEXPTABLE_1>pattern)
+	GroupName       string // e.g. __REGEXPTABLE_1__, see GroupNamer
+	namedPattern    string // e.g. (?P<__REGEXPTABLE_1__>pattern)
 	Value           T
 	Pattern         string         // e.g. pattern
 	compiledPattern CompiledRegexp // Cached compiled pattern for disambiguation
+	factoredPrefix  string         // Literal prefix hoisted out of the named group by prefix factoring
+	factoredSuffix  string         // Literal suffix hoisted out of the named group by suffix factoring
+	firstGroup      int            // Index of the entry's named group among the union's submatches
+	groupCount      int            // Number of capture groups inside the entry's own pattern
+	groupNames      []string       // Names of those capture groups, "" for unnamed groups
+	order           int            // Insertion sequence number, used to undo literal ordering
+	source          int            // One-based position among the builder's patterns, 0 if added directly
+	strippedPattern string         // Cached result of stripping the pattern's captures, "" until needed
+	moreValues      []T            // Further values after Value, for entries with several
+	name            string         // The name given with AddNamedPattern, "" if none
+	hits            *patternHits   // Hit statistics, nil unless enabled with SetHitStats
+	unionGroup      int            // Expected index of the named group when backreferences are renumbered, 0 otherwise
+	*entryAttrs                    // Attributes most entries leave unset, see ownAttrs
 }
 
 // RegexpTable provides efficient multi-pattern regexp classification using a pluggable regexp engine.
 // It compiles multiple regexp patterns into a single automaton for optimal performance.
 type RegexpTable[T any] struct {
-	engine         RegexpEngine
-	compiled       CompiledRegexp
-	lookup         []*ValueAndPattern[T]
-	maplets        []*ValueAndPattern[T]
-	nextGroupID    int
-	needsRecompile bool
-	anchorStart    bool // Whether to anchor patterns to start of string with ^
-	anchorEnd      bool // Whether to anchor patterns to end of string with $
+	engine           RegexpEngine
+	compiled         CompiledRegexp
+	maplets          []*ValueAndPattern[T]
+	nextGroupID      int
+	needsRecompile   bool
+	anchorStart      bool                           // Whether to anchor patterns to start of string with ^
+	anchorEnd        bool                           // Whether to anchor patterns to end of string with $
+	nonCapturing     bool                           // Whether user capture groups are discarded for faster matching
+	prefixFactoring  bool                           // Whether shared literal prefixes are factored out of the union
+	suffixFactoring  bool                           // Whether shared literal suffixes are factored out of the union
+	literalOrdering  bool                           // Whether entries are ordered by descending literal prefix length
+	ungreedy         bool                           // Whether repetitions match as little as possible by default
+	caseInsensitive  bool                           // Whether letters match regardless of case
+	precompile       bool                           // Whether Recompile also compiles every entry's individual pattern
+	allowReDoS       bool                           // Whether patterns prone to catastrophic backtracking are accepted
+	memo             *memoCache[T]                  // Optional cache of lookup results, nil when disabled
+	sharedMatches    bool                           // Whether cached submatch slices are returned without copying
+	unionPattern     string                         // The unanchored union, kept for per-call anchoring overrides
+	unionEntries     []*ValueAndPattern[T]          // The entries unionPattern was built from, nil if it must be rebuilt
+	unionStripped    bool                           // Whether unionPattern was built in non-capturing mode
+	unionFlags       string                         // The inline flags unionPattern was built with
+	compiledUnion    string                         // The anchored union text that compiled was compiled from
+	diagnostics      *diagnostics                   // Optional reporting of slow lookup paths, nil when disabled
+	normalizers      []Normalizer                   // Applied in order to inputs before they are matched
+	hitStats         bool                           // Whether per-pattern hit statistics are kept
+	hitExamples      int                            // How many recent inputs each pattern's statistics keep
+	adaptive         bool                           // Whether recompilation adapts the table to its hits, see SetAdaptive
+	adaptivePromote  int                            // The most literals adaptive mode promotes
+	hotLiterals      map[string]*ValueAndPattern[T] // Promoted literals and their entries, nil if none
+	reordered        []int                          // Insertion indexes of the entries adaptive mode last reordered
+	variants         *anchoredVariants[T]           // Lazily compiled unions for other anchorings
+	nextExpiry       time.Time                      // Earliest expiry time of any entry, zero if none expire
+	nextTransition   time.Time                      // Earliest time any entry's schedule may change, zero if none can
+	now              func() time.Time               // Clock used for expiry, defaults to time.Now
+	observers        observers                      // Callbacks registered with OnRecompile and OnMutate
+	matchCallbacks   []matchCallback[T]             // Callbacks registered with OnMatch and OnRuleMatch
+	matchPanic       func(Match[T], any)            // Handler for panics in match callbacks, see OnMatchPanic
+	maxInputLength   int                            // Longest input matched in bytes, 0 for no limit
+	truncateInput    bool                           // Whether longer inputs are truncated rather than rejected
+	positionalGroups bool                           // Whether entries' groups are tracked by position rather than by name
+	twoPhase         bool                           // Whether lookups prefilter candidates and match them individually
+	omitWrapper      bool                           // Whether the non-capturing wrapper is left out where it makes no difference
+	prefilter        *firstBytePrefilter            // The two-phase prefilter, nil unless enabled and compiled
+	tombstones       int                            // Removed entries whose branches remain in the compiled union, see RemovePattern
+	prefixDispatch   bool                           // Whether lookups dispatch on the patterns' literal prefixes
+	dispatch         *radixNode                     // The prefix dispatch tree, nil unless enabled, applicable and compiled
+	results          *sync.Pool                     // Pool of results for LookupResult, nil unless enabled, see SetPooling
 }
 
 // NewRegexpTable creates a new empty RegexpTable using the standard regexp engine.
@@ -71,25 +135,69 @@ func NewRegexpTableWithEngine[T any](engine RegexpEngine, anchorStart, anchorEnd
 // AddPattern adds a new regexp pattern with its associated value to the table.
 // This method defers recompilation until Lookup is called for better performance.
 func (rt *RegexpTable[T]) AddPattern(pattern string, value T) error {
-	// Auto-generate a unique internal name
-	groupName := fmt.Sprintf("__REGEXPTABLE_%d__", rt.nextGroupID)
+	rt.addPattern("", pattern, value)
+	return nil
+}
+
+// addPattern adds a pattern with an optional name, see AddNamedPattern.
+func (rt *RegexpTable[T]) addPattern(name, pattern string, value T) {
+	// Choose a unique internal name, from the pattern's name if it has one
+	order := rt.nextGroupID
+	groupName := entryGroupName(rt.engine, order, name)
 	rt.nextGroupID++
 
-	// Create a unique capture group name using the engine's syntax
-	namedPattern := rt.engine.FormatNamedGroup(groupName, pattern)
+	// Create a unique capture group name using the engine's syntax, unless the
+	// engine has no named groups
+	namedPattern := ""
+	if !requiresPositionalGroups(rt.engine) {
+		namedPattern = formatBranch(rt.engine, groupName, pattern)
+	}
 
-	rt.maplets = append(rt.maplets,
-		&ValueAndPattern[T]{
-			GroupName:    groupName,
-			namedPattern: namedPattern,
-			Value:        value,
-			Pattern:      pattern,
-		},
-	)
+	entry := &ValueAndPattern[T]{
+		GroupName:    groupName,
+		namedPattern: namedPattern,
+		Value:        value,
+		Pattern:      pattern,
+		name:         name,
+		order:        order,
+		hits:         rt.newHits(),
+		entryAttrs:   noAttrs,
+	}
+	rt.maplets = append(rt.maplets, entry)
 
 	rt.needsRecompile = true
+	rt.notifyMutate(ChangeAdded, entry)
+}
 
-	return nil
+// internalGroupName returns the name of the group that wraps the i-th pattern added
+// to a table using the engine.
+func internalGroupName(engine RegexpEngine, i int) string {
+	if namer, ok := engine.(GroupNamer); ok {
+		return namer.GroupName(i)
+	}
+	return fmt.Sprintf("__REGEXPTABLE_%d__", i)
+}
+
+// ValidateAgainst reports the problems another engine would have with the table's
+// patterns, with the table's modes and anchoring, as RegexpTableBuilder.Validate
+// does. A nil result means the table could switch to that engine.
+func (rt *RegexpTable[T]) ValidateAgainst(engine RegexpEngine) []error {
+	builder := NewRegexpTableBuilderWithEngine[T](engine).
+		WithNonCapturing(rt.nonCapturing).
+		WithUngreedy(rt.ungreedy).
+		WithCaseInsensitive(rt.caseInsensitive).
+		WithPositionalGroups(rt.positionalGroups)
+	for _, entry := range rt.maplets {
+		switch {
+		case entry.exclusion != nil:
+			builder.AddPatternExcluding(entry.Pattern, entry.Value, entry.exclusion.spec)
+		case entry.name != "":
+			builder.AddNamedPattern(entry.name, entry.Pattern, entry.Value)
+		default:
+			builder.AddPattern(entry.Pattern, entry.Value)
+		}
+	}
+	return builder.Validate(rt.anchorStart, rt.anchorEnd)
 }
 
 // AddAndCheckPattern is like AddPattern but immediately recompiles the regexp.
@@ -108,18 +216,129 @@ func (rt *RegexpTable[T]) AddAndCheckPattern(pattern string, value T) error {
 	return nil
 }
 
-// anchorPattern applies start/end anchoring to a pattern based on the table's settings.
-func (rt *RegexpTable[T]) anchorPattern(pattern string) string {
-	result := pattern
-	if rt.anchorStart {
-		result = "^(?:" + result + ")"
-	} else {
-		result = "(?:" + result + ")"
+// Patterns returns the patterns in the table, in the order in which they are tried
+// as of the last compilation (patterns added since then come last).
+func (rt *RegexpTable[T]) Patterns() []string {
+	patterns := make([]string, len(rt.maplets))
+	for i, entry := range rt.maplets {
+		patterns[i] = entry.Pattern
+	}
+	return patterns
+}
+
+// Len returns the number of patterns in the table.
+func (rt *RegexpTable[T]) Len() int {
+	return len(rt.maplets)
+}
+
+// PatternAt returns the i-th pattern of the table, counting from 0 in the order
+// used by Patterns: the order in which patterns are added, unless the literal
+// ordering pass has reordered them. It panics if i is out of range, like indexing
+// a slice.
+func (rt *RegexpTable[T]) PatternAt(i int) string {
+	return rt.maplets[i].Pattern
+}
+
+// ValueAt returns the value of the i-th pattern of the table, in the order used
+// by PatternAt. It panics if i is out of range.
+func (rt *RegexpTable[T]) ValueAt(i int) T {
+	return rt.maplets[i].Value
+}
+
+// IndexAt returns the insertion index (see Result.Index) of the i-th pattern of
+// the table, in the order used by PatternAt. It panics if i is out of range.
+func (rt *RegexpTable[T]) IndexAt(i int) int {
+	return rt.maplets[i].insertionIndex()
+}
+
+// All returns an iterator over the patterns of the table and their values, in
+// the order used by PatternAt. The table must not be modified during iteration.
+func (rt *RegexpTable[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		for _, entry := range rt.maplets {
+			if !yield(entry.Pattern, entry.Value) {
+				return
+			}
+		}
+	}
+}
+
+// SetNonCapturing switches the table into (or out of) non-capturing mode. In this
+// mode every capture group in the registered patterns is rewritten as non-capturing
+// before compilation (when the engine implements CaptureStripper) and lookups return
+// only the full match, i.e. a submatch slice of length one. This is the fastest way
+// to use a table when only the classification is needed.
+func (rt *RegexpTable[T]) SetNonCapturing(enabled bool) {
+	if rt.nonCapturing != enabled {
+		rt.nonCapturing = enabled
+		rt.needsRecompile = true
+		for _, entry := range rt.maplets {
+			entry.compiledPattern = nil
+		}
+	}
+}
+
+// SetUngreedy switches the table into (or out of) ungreedy mode, in which every
+// pattern is compiled as if it started with the (?U) flag: x* and x+ match as
+// little as possible and x*? and x+? as much as possible. This suits extraction
+// rules that use .* between fields, where greedy matching steals text across field
+// boundaries. The mode requires an engine that implements FlagFormatter;
+// Recompile reports an error otherwise.
+func (rt *RegexpTable[T]) SetUngreedy(enabled bool) {
+	if rt.ungreedy != enabled {
+		rt.ungreedy = enabled
+		rt.needsRecompile = true
+		for _, entry := range rt.maplets {
+			entry.compiledPattern = nil
+		}
+	}
+}
+
+// effectivePattern returns the pattern text that is actually compiled for an entry,
+// taking the non-capturing, case-insensitive and ungreedy modes into account.
+func (rt *RegexpTable[T]) effectivePattern(entry *ValueAndPattern[T]) string {
+	pattern := rt.capturePattern(entry)
+	if entry.dormant {
+		pattern = neverMatches + WrapNonCapturing(pattern)
+	}
+	if flags := rt.patternFlags(); flags != "" {
+		if formatter, ok := rt.engine.(FlagFormatter); ok {
+			if flagged, ok := formatter.FormatFlags(flags, pattern); ok {
+				return flagged
+			}
+		}
 	}
-	if rt.anchorEnd {
-		result = result + "$"
+	return pattern
+}
+
+// patternFlags returns the inline flags that the table's modes apply to every
+// pattern, "" if none.
+func (rt *RegexpTable[T]) patternFlags() string {
+	return modeFlags(rt.caseInsensitive, rt.ungreedy)
+}
+
+// capturePattern returns the entry's pattern with its capture groups stripped in
+// non-capturing mode.
+func (rt *RegexpTable[T]) capturePattern(entry *ValueAndPattern[T]) string {
+	if rt.nonCapturing {
+		if entry.strippedPattern != "" {
+			return entry.strippedPattern
+		}
+		if stripper, ok := rt.engine.(CaptureStripper); ok {
+			stripped, err := stripper.StripCaptures(entry.Pattern)
+			if err == nil {
+				entry.strippedPattern = stripped
+				return stripped
+			}
+			// Leave invalid patterns alone so that compilation reports the real error.
+		}
 	}
-	return result
+	return entry.Pattern
+}
+
+// anchorPattern applies start/end anchoring to a pattern based on the table's settings.
+func (rt *RegexpTable[T]) anchorPattern(pattern string) string {
+	return rt.anchorPatternAs(pattern, rt.Anchoring())
 }
 
 // validatePatterns checks each pattern individually and returns details about any invalid patterns.
@@ -128,34 +347,149 @@ func (rt *RegexpTable[T]) validatePatterns() []string {
 
 	for _, valueAndPattern := range rt.maplets {
 		// Try to compile this pattern individually with proper anchoring
-		anchoredPattern := rt.anchorPattern(valueAndPattern.Pattern)
+		anchoredPattern := rt.anchorPattern(rt.effectivePattern(valueAndPattern))
 		_, err := rt.engine.Compile(anchoredPattern)
 		if err != nil {
-			invalidPatterns = append(invalidPatterns, fmt.Sprintf("group %s (pattern: %s): %v", valueAndPattern.GroupName, valueAndPattern.Pattern, err))
+			invalidPatterns = append(invalidPatterns, fmt.Sprintf("group %s (pattern: %s): %v", valueAndPattern.label(), valueAndPattern.Pattern, locatePatternError(valueAndPattern.Pattern, err)))
 		}
 	}
 
 	return invalidPatterns
 }
 
+// branchPattern returns the named capture group that represents an entry in the union.
+func (rt *RegexpTable[T]) branchPattern(entry *ValueAndPattern[T]) string {
+	if rt.nonCapturing || rt.patternFlags() != "" || rt.usesPositionalGroups() || entry.unionGroup != 0 || entry.dormant {
+		return rt.formatBranch(entry, rt.unionBranchPattern(entry))
+	}
+	return entry.namedPattern
+}
+
+// plainUnionPattern joins the branches of all entries with alternation, in order.
+func (rt *RegexpTable[T]) plainUnionPattern() string {
+	var unionPattern strings.Builder
+	for i, entry := range rt.maplets {
+		if i > 0 {
+			unionPattern.WriteString("|")
+		}
+		entry.factoredPrefix = ""
+		entry.factoredSuffix = ""
+		unionPattern.WriteString(rt.branchPattern(entry))
+	}
+	return unionPattern.String()
+}
+
+// buildUnionPattern returns the unanchored union of all entries. When entries have
+// only been appended since the previous union was built, as is typical while
+// rules are being edited interactively, the previous union is extended rather
+// than joined again from scratch.
+func (rt *RegexpTable[T]) buildUnionPattern() string {
+	if rt.prefixFactoring || rt.suffixFactoring {
+		// Factoring looks at runs of neighbouring entries, so appending an entry
+		// can change how earlier ones are written.
+		rt.unionEntries = nil
+		return rt.factoredUnionPattern()
+	}
+
+	start := 0
+	var unionPattern strings.Builder
+	reusable := rt.unionEntries != nil && rt.unionStripped == rt.nonCapturing && rt.unionFlags == rt.patternFlags() &&
+		len(rt.unionEntries) <= len(rt.maplets)
+	if reusable && slices.Equal(rt.unionEntries, rt.maplets[:len(rt.unionEntries)]) {
+		start = len(rt.unionEntries)
+		unionPattern.WriteString(rt.unionPattern)
+	}
+	for i, entry := range rt.maplets[start:] {
+		if start+i > 0 {
+			unionPattern.WriteString("|")
+		}
+		entry.factoredPrefix = ""
+		entry.factoredSuffix = ""
+		unionPattern.WriteString(rt.branchPattern(entry))
+	}
+
+	rt.unionEntries = slices.Clone(rt.maplets)
+	rt.unionStripped = rt.nonCapturing
+	rt.unionFlags = rt.patternFlags()
+	return unionPattern.String()
+}
+
 // Recompile rebuilds the union regexp from all registered patterns.
 // This is exposed to allow manual control over when recompilation occurs.
 func (rt *RegexpTable[T]) Recompile() error {
+	if len(rt.observers.recompile) == 0 {
+		return rt.recompile()
+	}
+	start := time.Now()
+	err := rt.recompile()
+	rt.notifyRecompile(start, err)
+	return err
+}
+
+// recompile does the work of Recompile.
+func (rt *RegexpTable[T]) recompile() error {
+	rt.SweepExpired()
+	if err := checkModeFlags(rt.engine, rt.patternFlags()); err != nil {
+		return err
+	}
+	if err := rt.checkReDoS(); err != nil {
+		return err
+	}
+	if err := rt.checkBackreferences(); err != nil {
+		return err
+	}
 	if len(rt.maplets) == 0 {
 		rt.compiled = nil
+		rt.compiledUnion = ""
+		rt.unionPattern = ""
+		rt.unionEntries = nil
+		rt.variants = new(anchoredVariants[T])
+		rt.hotLiterals = nil
+		rt.reordered = nil
+		if rt.memo != nil {
+			rt.memo.clear()
+		}
+		rt.tombstones = 0
 		rt.needsRecompile = false
 		return nil
 	}
+	rt.orderEntries()
+	rt.reorderByHits()
+	rt.prefilter = nil
+	if rt.twoPhase {
+		rt.prefilter = rt.newFirstBytePrefilter()
+	}
+	rt.dispatch = nil
+	if rt.prefixDispatch {
+		rt.dispatch = rt.newPrefixDispatch()
+	}
 
 	// Create union pattern with proper anchoring
-	var unionPattern strings.Builder
-	for i, entry := range rt.maplets {
-		if i > 0 {
-			unionPattern.WriteString("|")
+	rt.numberUnionGroups()
+	unionPattern := rt.buildUnionPattern()
+	rt.unionPattern = unionPattern
+	anchoredUnionPattern := rt.anchorPattern(unionPattern)
+
+	if rt.compiled != nil && anchoredUnionPattern == rt.compiledUnion {
+		// Nothing that affects the regexp has changed, for instance an option was
+		// toggled back, so the compiled union and its group bookkeeping still hold.
+		if rt.memo != nil {
+			rt.memo.clear()
+		}
+		if rt.precompile {
+			// Toggling a mode discards the individual regexps even when the union
+			// ends up unchanged.
+			if err := rt.precompileIndividuals(); err != nil {
+				return codeErrorf(CodeCompileFailed, "failed to compile individual pattern: %w", err)
+			}
 		}
-		unionPattern.WriteString(entry.namedPattern)
+		rt.tombstones = 0
+		rt.promoteHotLiterals()
+		rt.needsRecompile = false
+		return nil
 	}
-	anchoredUnionPattern := rt.anchorPattern(unionPattern.String())
+	rt.variants = new(anchoredVariants[T])
+	rt.compiledUnion = ""
 
 	var err error
 	rt.compiled, err = rt.engine.Compile(anchoredUnionPattern)
@@ -163,40 +497,70 @@ func (rt *RegexpTable[T]) Recompile() error {
 		// Try to identify which specific patterns are invalid
 		invalidPatterns := rt.validatePatterns()
 		if len(invalidPatterns) > 0 {
-			return fmt.Errorf("failed to compile union regexp due to invalid patterns:\n%s", strings.Join(invalidPatterns, "\n"))
+			return codeErrorf(CodeCompileFailed, "failed to compile union regexp due to invalid patterns:\n%s", strings.Join(invalidPatterns, "\n"))
 		}
 		// Fallback to original error if we can't identify specific patterns
-		return fmt.Errorf("failed to compile union regexp: %w", err)
+		return codeErrorf(CodeCompileFailed, "failed to compile union regexp: %w", err)
 	}
 
-	// We now need to build the lookup slice. For each name in the SubexpNames
-	// we use the corresponding ValueAndPattern from the maplets slice OR nil
-	// if the name is "". The result is congruent to the strings returned by a match.
+	// We now record where each entry's groups live among the union's submatches.
+	// The SubexpNames include the internal names in the same order as the maplets
+	// slice, so we can rely on simply walking it, and every unnamed or user-named
+	// group up to the next internal name belongs to the same entry.
 	names := rt.compiled.SubexpNames()
-	n := 0
-	rt.lookup = make([]*ValueAndPattern[T], 0)
-	for _, name := range names {
-		// Note that the SubexpNames will include the prefixed names in
-		// the set order they were generated in. So we can rely on simply
-		// walking the maplets slice.
-		if strings.HasPrefix(name, "__REGEXPTABLE_") {
-			rt.lookup = append(rt.lookup, rt.maplets[n]) // Skip the first empty name
-			n++
-		} else {
-			rt.lookup = append(rt.lookup, nil)
+	if rt.usesPositionalGroups() {
+		if err := rt.locateGroupsByPosition(len(names)); err != nil {
+			rt.compiled = nil
+			return err
+		}
+	} else {
+		n := 0
+		var current *ValueAndPattern[T]
+		for i, name := range names {
+			// Defensive check: a pluggable engine could report more internal names than
+			// there are entries, which must not make us index past the maplets slice.
+			if n < len(rt.maplets) && name == rt.maplets[n].GroupName {
+				current = rt.maplets[n]
+				current.firstGroup = i
+				current.groupCount = 0
+				current.groupNames = current.groupNames[:0]
+				n++
+			} else if current != nil {
+				current.groupCount++
+				current.groupNames = append(current.groupNames, name)
+			}
+		}
+	}
+	if err := rt.checkUnionGroups(); err != nil {
+		rt.compiled = nil
+		return err
+	}
+
+	if rt.memo != nil {
+		rt.memo.clear()
+	}
+
+	if rt.precompile {
+		if err := rt.precompileIndividuals(); err != nil {
+			return codeErrorf(CodeCompileFailed, "failed to compile individual pattern: %w", err)
 		}
 	}
-	// for x, name := range names {
-	// 	fmt.Println("subexpnames", x, name)
-	// }
-	// fmt.Println("lookup", len(rt.lookup), rt.lookup) // Debugging output to see lookup
 
+	rt.compiledUnion = anchoredUnionPattern
+	rt.tombstones = 0
+	rt.promoteHotLiterals()
 	rt.needsRecompile = false
 	return nil
 }
 
 // ensureCompiled ensures the regexp is compiled before use, recompiling if necessary.
 func (rt *RegexpTable[T]) ensureCompiled() error {
+	if rt.hasExpiredEntries() {
+		rt.SweepExpired()
+	}
+	if rt.hasScheduleTransition() {
+		rt.applySchedules()
+	}
 	if rt.needsRecompile || rt.compiled == nil {
 		return rt.Recompile()
 	}
@@ -204,74 +568,227 @@ func (rt *RegexpTable[T]) ensureCompiled() error {
 }
 
 // Lookup attempts to match the input string against all registered patterns.
-// Returns the value, submatch slice, and error. If no patterns match, returns zero value, nil, error.
+// Returns the value, submatch slice, and error. In non-capturing mode the submatch
+// slice holds only the full match.
+// If no patterns match, returns zero value, nil, error.
+// The submatch slice belongs to the caller unless SetSharedMatches is enabled.
 // This method automatically recompiles the regexp if patterns have been added/removed since last compilation.
 func (rt *RegexpTable[T]) Lookup(input string) (T, []string, error) {
 	var zero T
 
-	err := rt.ensureCompiled()
+	entry, matches, err := rt.find(input)
 	if err != nil {
 		return zero, nil, err
 	}
+	return entry.Value, matches, nil
+}
 
-	if rt.compiled == nil {
-		return zero, nil, fmt.Errorf("no patterns configured")
+// find locates the entry that classifies the input, recompiling if necessary,
+// normalizing the input and consulting the memoization cache when it is enabled.
+func (rt *RegexpTable[T]) find(input string) (*ValueAndPattern[T], []string, error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, nil, err
+	}
+	input, err = rt.limitInput(input)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	matches := rt.compiled.FindStringSubmatch(input)
+	normalized := input
+	if rt.normalizers != nil {
+		normalized, _ = rt.normalize(input)
+	}
+	found, err := rt.findNormalized(normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches := found.submatches(nil)
+	rt.recordHit(found.entry, input, matches)
+	return found.entry, matches, nil
+}
+
+// findNormalized is find for an input that has already been normalized. The
+// caller must ensure the table has been compiled.
+func (rt *RegexpTable[T]) findNormalized(input string) (located[T], error) {
+	if rt.memo != nil {
+		entry, matches, err := rt.memoizedFind(input)
+		return textual(entry, matches), err
+	}
+	return rt.locateEntry(input)
+}
+
+// matchEntry performs the actual match of an input against the compiled union and
+// returns the winning entry with its submatches. The caller must ensure the table
+// has been compiled.
+func (rt *RegexpTable[T]) matchEntry(input string) (*ValueAndPattern[T], []string, error) {
+	found, err := rt.locateEntry(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return found.entry, found.submatches(nil), nil
+}
+
+// locateEntry is matchEntry returning the match as found, see located.
+func (rt *RegexpTable[T]) locateEntry(input string) (located[T], error) {
+	if entry, ok := rt.hotLiterals[input]; ok {
+		return indexed(entry, input, []int{0, len(input)}), nil
+	}
+	if rt.dispatch != nil {
+		return rt.locateDispatch(input)
+	}
+	if rt.prefilter != nil {
+		return rt.locateTwoPhase(input)
+	}
+	return rt.locateUnion(input, rt.compiled, rt.individualRegexp)
+}
+
+// locateUnion matches an input against a compiled union of the table's entries,
+// using individual to obtain the stand-alone regexps needed for disambiguation.
+// The union and the individual regexps must share the same anchoring.
+func (rt *RegexpTable[T]) locateUnion(input string, compiled CompiledRegexp, individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (located[T], error) {
+	found, err := rt.locateUnionOnce(input, compiled, individual)
+	if err == errTombstoneWon {
+		return rt.traceFallback(DiagnosticTombstone, input, func() (located[T], error) {
+			return rt.locateExcluding(input, individual)
+		})
+	}
+	if err == nil && found.entry.exclusion != nil {
+		// The union cannot check exclusions, so when the winner has one the
+		// entries are matched one at a time instead.
+		return rt.traceFallback(DiagnosticExclusion, input, func() (located[T], error) {
+			return rt.locateExcluding(input, individual)
+		})
+	}
+	return found, err
+}
+
+// locateUnionOnce performs a single match of the union, ignoring exclusions.
+func (rt *RegexpTable[T]) locateUnionOnce(input string, compiled CompiledRegexp, individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (located[T], error) {
+	if compiled == nil {
+		return located[T]{}, ErrNoPatterns
+	}
+
+	if matcher, ok := compiled.(IndexMatcher); ok {
+		return rt.locateUnionIndex(input, matcher)
+	}
+
+	matches := compiled.FindStringSubmatch(input)
 	if matches == nil {
-		return zero, nil, fmt.Errorf("no pattern matched")
-	}
-	// for x, m := range matches {
-	// 	fmt.Println("match", x, m)
-	// }
-
-	// Note that rt.lookup and matches will be congruent (we force this in Recompile).
-	for i, valueAndPattern := range rt.lookup {
-		// fmt.Println("valueAndPattern", i, valueAndPattern) // Debugging output to see lookup and matches
-		if valueAndPattern != nil && i < len(matches) && matches[i] != "" {
-			// Now find the set of matches that applies for this lookup.
-			our_matches := make([]string, 1)
-			our_matches[0] = matches[i]
-			for j := i + 1; j < len(rt.lookup); j++ {
-				if rt.lookup[j] != nil {
-					// Stop at the next __REGEXPTABLE capture group.
-					break
-				}
-				// This must be a capture group that is part of the matching key.
-				our_matches = append(our_matches, matches[j])
-			}
-			return valueAndPattern.Value, our_matches, nil
+		return located[T]{}, ErrNoMatch
+	}
+
+	// The entry whose named group captured some text is the winner.
+	for _, entry := range rt.maplets {
+		// Defensive check: ensure we don't exceed matches slice bounds
+		// (SubexpNames and matches should have same length, but we use pluggable engines)
+		if entry.firstGroup < len(matches) && matches[entry.firstGroup] != "" {
+			return textual(entry, rt.entrySubmatches(entry, matches)), nil
 		}
 	}
 
 	// If all matches are empty strings, we need to disambiguate by testing individual patterns
 	// This handles the case where multiple patterns could match empty strings or when alternation
 	// makes it impossible to distinguish which group actually matched.
+	return rt.traceFallback(DiagnosticDisambiguation, input, func() (located[T], error) {
+		return rt.disambiguate(input, matches[0], individual)
+	})
+}
+
+// disambiguate finds the first entry whose individual pattern finds the same full
+// match as the union.
+func (rt *RegexpTable[T]) disambiguate(input, match string, individual func(*ValueAndPattern[T]) (CompiledRegexp, error)) (located[T], error) {
 	for _, valueAndPattern := range rt.maplets {
-		// Use cached compiled pattern or compile on-demand
-		var individualRegexp CompiledRegexp
-		if valueAndPattern.compiledPattern != nil {
-			individualRegexp = valueAndPattern.compiledPattern
-		} else {
-			// Compile and cache the pattern
-			individualPattern := rt.anchorPattern(valueAndPattern.Pattern)
-			compiledRegexp, err := rt.engine.Compile(individualPattern)
-			if err != nil {
-				continue // Skip invalid patterns (should never happen)
-			}
-			// Cache the compiled pattern (note: this modifies the map entry)
-			valueAndPattern.compiledPattern = compiledRegexp
-			individualRegexp = compiledRegexp
+		individualRegexp, err := individual(valueAndPattern)
+		if err != nil {
+			continue // Skip invalid patterns (should never happen)
 		}
 
 		// Test if this individual pattern matches
-		if individualMatches := individualRegexp.FindStringSubmatch(input); individualMatches != nil {
-			return valueAndPattern.Value, individualMatches, nil
+		// The individual pattern must find the same match as the union. Otherwise an
+		// unanchored pattern could claim a match elsewhere in the input.
+		if individualMatches := individualRegexp.FindStringSubmatch(input); individualMatches != nil && individualMatches[0] == match {
+			if rt.nonCapturing {
+				individualMatches = individualMatches[:1]
+			}
+			return textual(valueAndPattern, individualMatches), nil
 		}
 	}
 
-	return zero, nil, fmt.Errorf("internal error: match found but no capture group matched")
+	return located[T]{}, codeErrorf(CodeInternal, "internal error: match found but no capture group matched")
+}
+
+// locateUnionIndex is locateUnion for engines that report group offsets. Group
+// participation identifies the winner directly, even when it matched the empty
+// string, so no disambiguation is needed.
+func (rt *RegexpTable[T]) locateUnionIndex(input string, matcher IndexMatcher) (located[T], error) {
+	loc := matcher.FindStringSubmatchIndex(input)
+	if loc == nil {
+		return located[T]{}, ErrNoMatch
+	}
+	entry, indexes, ok := rt.groupIndexes(loc)
+	if !ok && rt.tombstones > 0 {
+		return located[T]{}, errTombstoneWon
+	}
+	if !ok {
+		return located[T]{}, codeErrorf(CodeInternal, "internal error: match found but no capture group matched")
+	}
+	return indexed(entry, input, indexes), nil
+}
+
+// entrySubmatches extracts an entry's own submatches from the union's submatches,
+// so that they are numbered exactly as if the entry's pattern had matched alone.
+func (rt *RegexpTable[T]) entrySubmatches(entry *ValueAndPattern[T], matches []string) []string {
+	count := entry.groupCount
+	if rt.nonCapturing {
+		count = 0
+	}
+	ours := make([]string, 1+count)
+	ours[0] = entry.factoredPrefix + matches[entry.firstGroup] + entry.factoredSuffix
+	for k := 1; k <= count && entry.firstGroup+k < len(matches); k++ {
+		ours[k] = matches[entry.firstGroup+k]
+	}
+	return ours
+}
+
+// individualRegexp returns the entry's pattern compiled on its own with the table's
+// anchoring, compiling and caching it on first use.
+func (rt *RegexpTable[T]) individualRegexp(entry *ValueAndPattern[T]) (CompiledRegexp, error) {
+	if entry.compiledPattern != nil {
+		return entry.compiledPattern, nil
+	}
+	compiledRegexp, err := rt.engine.Compile(rt.anchorPattern(rt.effectivePattern(entry)))
+	if err != nil {
+		return nil, err
+	}
+	// Cache the compiled pattern (note: this modifies the shared entry)
+	entry.compiledPattern = compiledRegexp
+	return compiledRegexp, nil
+}
+
+// SetPrecompileIndividuals controls whether Recompile also compiles the individual
+// pattern of every entry. Lookups fall back to the individual patterns to
+// disambiguate some matches and to check exclusions; by default these are compiled
+// lazily on first use, which modifies the table during a lookup and adds latency
+// to that lookup. Precompiling trades a slower Recompile for lookups that never
+// modify the table, which makes it safe to share a compiled table between
+// goroutines.
+func (rt *RegexpTable[T]) SetPrecompileIndividuals(enabled bool) {
+	if rt.precompile != enabled {
+		rt.precompile = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// precompileIndividuals compiles the individual pattern of every entry up front so
+// that lookups never need to modify the table.
+func (rt *RegexpTable[T]) precompileIndividuals() error {
+	for _, entry := range rt.maplets {
+		if _, err := rt.individualRegexp(entry); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (rt *RegexpTable[T]) TryLookup(input string) (T, []string, bool) {
@@ -1,21 +1,68 @@
 package regexptable
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
 // RegexpTableBuilder provides a convenient builder pattern for creating RegexpTable instances.
 // It accumulates patterns and builds the final RegexpTable with a single compilation step.
 type RegexpTableBuilder[T any] struct {
-	patterns []patternEntry[T]
-	engine   RegexpEngine
+	patterns        []patternEntry[T]
+	engine          RegexpEngine
+	nonCapturing    bool
+	prefixFactoring bool
+	suffixFactoring bool
+	literalOrdering bool
+	ungreedy        bool
+	caseInsensitive bool
+	positional      bool
+	twoPhase        bool
+	prefixDispatch  bool
+	omitWrapper     bool
+	precompile      bool
+	allowReDoS      bool
+	memoCapacity    int
+	memoComputed    bool
+	sharedMatches   bool
+	maxInputLength  int
+	truncateInput   bool
+	valueFolding    bool
+	anchorPolicy    AnchorPolicy
+	hitStats        bool
+	hitExamples     int
+	adaptive        bool
+	adaptivePromote int
+	pooling         bool
+	diagnosticHook  func(Diagnostic)
+	normalizers     []Normalizer
+	sampler         *Sampler
+	classes         map[string]string // Named character classes, see Class
+	grok            *GrokLibrary      // Library for AddGrokPattern, nil until needed
+	cache           *TableCache       // Optional cache of built tables, see WithTableCache
+	errs            []error           // Problems detected while adding patterns, reported by Build
 }
 
 // patternEntry holds a pattern and its associated value during building
 type patternEntry[T any] struct {
-	pattern string
-	value   T
+	pattern    string
+	value      T
+	exclusion  *Exclusion           // Optional exclusion, see RegexpTable.AddPatternExcluding
+	moreValues []T                  // Further values, see RegexpTable.AddPatternValues
+	priority   int                  // Higher priorities take precedence, see AddPatternWithPriority
+	tags       []string             // Labels recorded with the entry, see AddPatternWithTags
+	name       string               // Optional unique name, see AddNamedPattern
+	confidence float64              // Weight of the value in LookupScored, 0 if unset, see AddPatternWithConfidence
+	groupTypes map[string]GroupType // Types of named groups, see AddTypedPattern
+	schedule   *Schedule            // When the entry is active, nil if always, see AddPatternWithSchedule
+	doc        string               // What the entry is for, see Entry.Doc
+	examples   []string             // Inputs the entry must classify, see Entry.Examples
+	position   int                  // Zero-based position among the builder's patterns, see Result.Index
 }
 
 // RegexpTableSubBuilder provides a type-safe fluent interface for building alternation patterns.
@@ -51,57 +98,463 @@ func (b *RegexpTableBuilder[T]) AddPattern(pattern string, value T) *RegexpTable
 	return b
 }
 
-// AddPatterns adds multiple patterns as a single alternation pattern with a shared value.
+// AddPatternExcluding adds a pattern whose matches are rejected when the exclusion
+// applies. See RegexpTable.AddPatternExcluding.
+func (b *RegexpTableBuilder[T]) AddPatternExcluding(pattern string, value T, exclusion Exclusion) *RegexpTableBuilder[T] {
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern:   pattern,
+		value:     value,
+		exclusion: &exclusion,
+	})
+	return b
+}
+
+// AddPatternValues adds a pattern that maps to several values. Build reports an
+// error if no values are given. See RegexpTable.AddPatternValues.
+func (b *RegexpTableBuilder[T]) AddPatternValues(pattern string, values ...T) *RegexpTableBuilder[T] {
+	if len(values) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("pattern '%s' has no values", pattern))
+		return b
+	}
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern:    pattern,
+		value:      values[0],
+		moreValues: slices.Clone(values[1:]),
+	})
+	return b
+}
+
+// AddNamedPattern adds a pattern with a name that identifies it in error messages
+// and results. Build reports an error if the name is invalid or used twice. See
+// RegexpTable.AddNamedPattern.
+func (b *RegexpTableBuilder[T]) AddNamedPattern(name, pattern string, value T) *RegexpTableBuilder[T] {
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern: pattern,
+		value:   value,
+		name:    name,
+	})
+	return b
+}
+
+// AddPatternWithTags adds a pattern labelled with tags, such as the rule set or
+// ticket it came from. Tags do not affect matching; they are recorded with the
+// pattern for audits, see RegexpTable.ExportAudit.
+func (b *RegexpTableBuilder[T]) AddPatternWithTags(pattern string, value T, tags ...string) *RegexpTableBuilder[T] {
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern: pattern,
+		value:   value,
+		tags:    slices.Clone(tags),
+	})
+	return b
+}
+
+// AddSubPatterns adds multiple patterns as a single alternation pattern with a shared value.
 // The patterns are combined using alternation syntax (?:pattern1|pattern2|...) and
-// treated as a single regexp key that maps to the given value. Note that anchoring
-// does not apply to this construction, as it is simply a longhand way to add
-// a single pattern entry.
+// treated as a single regexp key that maps to the given value. Anchoring is applied
+// by the table to the alternation as a whole, so explicit anchors (^, $, \A, \z)
+// inside the sub-patterns are rejected: Build reports an error for them.
 func (b *RegexpTableBuilder[T]) AddSubPatterns(patterns []string, value T) *RegexpTableBuilder[T] {
 	if len(patterns) == 0 {
 		return b // No patterns to add, return unchanged
 	}
 
+	for _, pattern := range patterns {
+		if offsets := findAnchors(pattern); len(offsets) > 0 {
+			b.errs = append(b.errs, fmt.Errorf("sub-pattern '%s' contains an explicit anchor at offset %d; anchoring is controlled by the table", pattern, offsets[0]))
+		}
+	}
+
 	if len(patterns) == 1 {
 		// Single pattern, no need for alternation syntax
 		return b.AddPattern(patterns[0], value)
 	}
 
 	// Create alternation pattern with proper grouping
-	var alternation strings.Builder
-	alternation.WriteString("(?:")
-	for i, pattern := range patterns {
-		if i > 0 {
-			alternation.WriteString("|")
-		}
-		alternation.WriteString(pattern)
-	}
-	alternation.WriteString(")")
+	return b.AddPattern(WrapNonCapturing(strings.Join(patterns, "|")), value)
+}
+
+// WithNonCapturing requests that the built table runs in non-capturing mode, where
+// user capture groups are discarded and lookups return only the full match.
+// See RegexpTable.SetNonCapturing.
+func (b *RegexpTableBuilder[T]) WithNonCapturing(enabled bool) *RegexpTableBuilder[T] {
+	b.nonCapturing = enabled
+	return b
+}
+
+// WithPrefixFactoring enables the prefix factoring optimizer pass on the built table.
+// See RegexpTable.SetPrefixFactoring.
+func (b *RegexpTableBuilder[T]) WithPrefixFactoring(enabled bool) *RegexpTableBuilder[T] {
+	b.prefixFactoring = enabled
+	return b
+}
+
+// WithSuffixFactoring enables the suffix factoring optimizer pass on the built table.
+// See RegexpTable.SetSuffixFactoring.
+func (b *RegexpTableBuilder[T]) WithSuffixFactoring(enabled bool) *RegexpTableBuilder[T] {
+	b.suffixFactoring = enabled
+	return b
+}
+
+// WithLiteralOrdering enables the literal ordering compile pass on the built table.
+// See RegexpTable.SetLiteralOrdering.
+func (b *RegexpTableBuilder[T]) WithLiteralOrdering(enabled bool) *RegexpTableBuilder[T] {
+	b.literalOrdering = enabled
+	return b
+}
+
+// WithUngreedy requests that the built table runs in ungreedy mode.
+// See RegexpTable.SetUngreedy.
+func (b *RegexpTableBuilder[T]) WithUngreedy(enabled bool) *RegexpTableBuilder[T] {
+	b.ungreedy = enabled
+	return b
+}
+
+// WithPositionalGroups requests that the built table tracks each pattern's groups
+// by position rather than by name. See RegexpTable.SetPositionalGroups.
+func (b *RegexpTableBuilder[T]) WithPositionalGroups(enabled bool) *RegexpTableBuilder[T] {
+	b.positional = enabled
+	return b
+}
+
+// WithTwoPhaseLookup requests that the built table prefilters candidate patterns
+// and matches them individually. See RegexpTable.SetTwoPhaseLookup.
+func (b *RegexpTableBuilder[T]) WithTwoPhaseLookup(enabled bool) *RegexpTableBuilder[T] {
+	b.twoPhase = enabled
+	return b
+}
+
+// WithOmitRedundantWrapper requests that the built table leaves out the
+// non-capturing wrapper where it makes no difference. See
+// RegexpTable.SetOmitRedundantWrapper.
+func (b *RegexpTableBuilder[T]) WithOmitRedundantWrapper(enabled bool) *RegexpTableBuilder[T] {
+	b.omitWrapper = enabled
+	return b
+}
+
+// WithPrecompileIndividuals makes the built table compile every entry's individual
+// pattern when it is compiled. See RegexpTable.SetPrecompileIndividuals.
+func (b *RegexpTableBuilder[T]) WithPrecompileIndividuals(enabled bool) *RegexpTableBuilder[T] {
+	b.precompile = enabled
+	return b
+}
+
+// WithAllowReDoS makes the built table accept patterns prone to catastrophic
+// backtracking. See RegexpTable.SetAllowReDoS.
+func (b *RegexpTableBuilder[T]) WithAllowReDoS(allowed bool) *RegexpTableBuilder[T] {
+	b.allowReDoS = allowed
+	return b
+}
+
+// WithCaseInsensitive requests that the built table matches letters regardless of
+// case. See RegexpTable.SetCaseInsensitive.
+func (b *RegexpTableBuilder[T]) WithCaseInsensitive(enabled bool) *RegexpTableBuilder[T] {
+	b.caseInsensitive = enabled
+	return b
+}
+
+// WithMemoization enables the lookup result cache on the built table.
+// See RegexpTable.SetMemoization.
+func (b *RegexpTableBuilder[T]) WithMemoization(capacity int, recordComputed bool) *RegexpTableBuilder[T] {
+	b.memoCapacity = capacity
+	b.memoComputed = recordComputed
+	return b
+}
+
+// WithSharedMatches lets the built table return cached submatch slices without
+// copying them. See RegexpTable.SetSharedMatches.
+func (b *RegexpTableBuilder[T]) WithSharedMatches(shared bool) *RegexpTableBuilder[T] {
+	b.sharedMatches = shared
+	return b
+}
+
+// WithAdaptive enables adaptive mode on the built table, promoting up to promote
+// literals. See RegexpTable.SetAdaptive.
+func (b *RegexpTableBuilder[T]) WithAdaptive(enabled bool, promote int) *RegexpTableBuilder[T] {
+	b.adaptive = enabled
+	b.adaptivePromote = promote
+	return b
+}
+
+// WithHitStats enables per-pattern hit statistics on the built table.
+// See RegexpTable.SetHitStats.
+func (b *RegexpTableBuilder[T]) WithHitStats(enabled bool, examples int) *RegexpTableBuilder[T] {
+	b.hitStats = enabled
+	b.hitExamples = examples
+	return b
+}
+
+// WithDiagnostics installs a diagnostic hook on the built table.
+// See RegexpTable.SetDiagnostics.
+func (b *RegexpTableBuilder[T]) WithDiagnostics(hook func(Diagnostic), sampler *Sampler) *RegexpTableBuilder[T] {
+	b.diagnosticHook = hook
+	b.sampler = sampler
+	return b
+}
 
-	return b.AddPattern(alternation.String(), value)
+// WithNormalizers installs a pipeline of input normalizers on the built table.
+// See RegexpTable.SetNormalizers.
+func (b *RegexpTableBuilder[T]) WithNormalizers(normalizers ...Normalizer) *RegexpTableBuilder[T] {
+	b.normalizers = slices.Clone(normalizers)
+	return b
 }
 
 // Build creates the final RegexpTable with all accumulated patterns.
 // This is when compilation and validation occur.
 func (b *RegexpTableBuilder[T]) Build(anchorStart, anchorEnd bool) (*RegexpTable[T], error) {
-	table := NewRegexpTableWithEngine[T](b.engine, anchorStart, anchorEnd)
+	return b.build(context.Background(), anchorStart, anchorEnd)
+}
+
+// build implements Build and BuildContext.
+func (b *RegexpTableBuilder[T]) build(ctx context.Context, anchorStart, anchorEnd bool) (*RegexpTable[T], error) {
+	if len(b.errs) > 0 {
+		return nil, codeErrorf(CodeCompileFailed, "invalid patterns: %w", errors.Join(b.errs...))
+	}
+
+	entries, err := b.orderedPatterns(NewAnchoring(anchorStart, anchorEnd))
+	if err != nil {
+		return nil, codeErrorf(CodeCompileFailed, "invalid patterns: %w", err)
+	}
+	key, cacheable := b.cacheKey(entries, anchorStart, anchorEnd)
+	if cacheable {
+		if cached, ok := b.cache.get(key); ok {
+			return cached.(*RegexpTable[T]), nil
+		}
+	}
+	table := b.newTable(anchorStart, anchorEnd)
 
 	// Add all patterns to the table (using lazy compilation)
-	for _, entry := range b.patterns {
-		err := table.AddPattern(entry.pattern, entry.value)
-		if err != nil {
-			return nil, fmt.Errorf("invalid pattern '%s': %w", entry.pattern, err)
+	for _, entry := range entries {
+		if err := b.addEntry(table, entry); err != nil {
+			return nil, err
 		}
 	}
+	b.reservePositions(table)
+	added := slices.Clone(table.maplets) // Literal ordering may reorder the table's own
 
 	// Trigger compilation once at the end
-	err := table.Recompile()
+	err = table.RecompileContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile regexp table: %w", err)
+		return nil, codeErrorf(CodeCompileFailed, "failed to compile regexp table: %w", err)
+	}
+	if err := checkExamples(entries, added, table.classify); err != nil {
+		return nil, err
 	}
 
+	if cacheable {
+		return b.cache.put(key, table).(*RegexpTable[T]), nil
+	}
 	return table, nil
 }
 
+// newTable creates an empty table with the builder's engine and options.
+func (b *RegexpTableBuilder[T]) newTable(anchorStart, anchorEnd bool) *RegexpTable[T] {
+	table := NewRegexpTableWithEngine[T](b.engine, anchorStart, anchorEnd)
+	table.SetNonCapturing(b.nonCapturing)
+	table.SetPrefixFactoring(b.prefixFactoring)
+	table.SetSuffixFactoring(b.suffixFactoring)
+	table.SetLiteralOrdering(b.literalOrdering)
+	table.SetUngreedy(b.ungreedy)
+	table.SetCaseInsensitive(b.caseInsensitive)
+	table.SetPositionalGroups(b.positional)
+	table.SetTwoPhaseLookup(b.twoPhase)
+	table.SetPrefixDispatch(b.prefixDispatch)
+	table.SetOmitRedundantWrapper(b.omitWrapper)
+	table.SetPrecompileIndividuals(b.precompile)
+	table.SetAllowReDoS(b.allowReDoS)
+	table.SetMemoization(b.memoCapacity, b.memoComputed)
+	table.SetSharedMatches(b.sharedMatches)
+	table.SetHitStats(b.hitStats, b.hitExamples)
+	if b.adaptive {
+		table.SetAdaptive(true, b.adaptivePromote)
+	}
+	table.SetPooling(b.pooling)
+	table.SetDiagnostics(b.diagnosticHook, b.sampler)
+	table.SetNormalizers(b.normalizers...)
+	table.SetMaxInputLength(b.maxInputLength, b.truncateInput)
+	return table
+}
+
+// orderedPatterns returns the pattern entries in the order they are added to a
+// table: by descending priority, preserving the order of addition among equal
+// priorities, and folded if WithValueFolding is enabled.
+func (b *RegexpTableBuilder[T]) orderedPatterns(anchoring Anchoring) ([]patternEntry[T], error) {
+	entries, errs := b.expandedPatterns(anchoring)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for i := range entries {
+		entries[i].position = i
+	}
+	slices.SortStableFunc(entries, func(x, y patternEntry[T]) int {
+		return cmp.Compare(y.priority, x.priority)
+	})
+	if b.valueFolding {
+		entries = b.foldEntries(entries)
+	}
+	return entries, nil
+}
+
+// expandedPatterns returns the pattern entries with their class references
+// expanded (see Class) and the AnchorPolicy applied for the given anchoring,
+// together with the problems found doing so.
+func (b *RegexpTableBuilder[T]) expandedPatterns(anchoring Anchoring) ([]patternEntry[T], []error) {
+	entries := slices.Clone(b.patterns)
+	var errs []error
+	for i, entry := range entries {
+		expanded, err := b.expandClasses(entry.pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pattern '%s': %w", entry.pattern, err))
+			continue
+		}
+		entries[i].pattern = expanded
+	}
+	errs = append(errs, b.applyAnchorPolicy(entries, anchoring)...)
+	return entries, errs
+}
+
+// addEntry adds a pattern entry to a table being built.
+func (b *RegexpTableBuilder[T]) addEntry(table *RegexpTable[T], entry patternEntry[T]) error {
+	var excluded *exclusion
+	var err error
+	if entry.exclusion != nil {
+		excluded, err = table.compileExclusion(*entry.exclusion)
+	}
+	switch {
+	case err != nil:
+	case entry.name != "":
+		err = table.AddNamedPattern(entry.name, entry.pattern, entry.value)
+	default:
+		err = table.AddPattern(entry.pattern, entry.value)
+	}
+	if err != nil {
+		return codeErrorf(CodeCompileFailed, "invalid pattern '%s'%s: %w", entry.pattern, entry.describeName(), err)
+	}
+	added := table.maplets[len(table.maplets)-1]
+	added.moreValues = entry.moreValues
+	if excluded != nil || entry.priority != 0 || entry.tags != nil || entry.confidence != 0 || entry.groupTypes != nil || entry.doc != "" {
+		attrs := added.ownAttrs()
+		attrs.exclusion = excluded
+		attrs.priority = entry.priority
+		attrs.tags = entry.tags
+		attrs.confidence = entry.confidence
+		attrs.groupTypes = entry.groupTypes
+		attrs.doc = entry.doc
+	}
+	added.source = entry.position + 1
+	if entry.schedule != nil {
+		table.schedule(added, *entry.schedule)
+	}
+	return nil
+}
+
+// reservePositions ensures that patterns added to a built table later are numbered
+// after all of the builder's patterns, even those that folding merged away, so
+// that their insertion indexes do not repeat the builder's positions.
+func (b *RegexpTableBuilder[T]) reservePositions(table *RegexpTable[T]) {
+	table.nextGroupID = max(table.nextGroupID, len(b.patterns)+1)
+}
+
+// Validate reports the problems Build would report, without building a table: it
+// compiles every pattern on its own and then the union, with the builder's modes
+// and the given anchoring. A nil result means Build would succeed. Unlike Build,
+// Validate reports every invalid pattern rather than stopping at the first, which
+// suits linting rule files and admission checks.
+func (b *RegexpTableBuilder[T]) Validate(anchorStart, anchorEnd bool) []error {
+	errs := slices.Clone(b.errs)
+	anchoring := NewAnchoring(anchorStart, anchorEnd)
+
+	flags := modeFlags(b.caseInsensitive, b.ungreedy)
+	if err := checkModeFlags(b.engine, flags); err != nil {
+		return append(errs, err)
+	}
+
+	entries, expansionErrs := b.expandedPatterns(anchoring)
+	errs = append(errs, expansionErrs...)
+
+	if !b.allowReDoS {
+		patterns := make([]string, len(entries))
+		for i, entry := range entries {
+			patterns[i] = entry.pattern
+		}
+		errs = append(errs, reDoSErrors(b.engine, patterns)...)
+	}
+
+	names := make(map[string]bool)
+	branches := make([]string, len(entries))
+	for i, entry := range entries {
+		if entry.name != "" {
+			if err := checkRuleName(entry.name); err != nil {
+				errs = append(errs, err)
+			} else if names[entry.name] {
+				errs = append(errs, fmt.Errorf("pattern name %q is already in use", entry.name))
+			}
+			names[entry.name] = true
+		}
+		pattern := entry.pattern
+		if b.nonCapturing {
+			if stripper, ok := b.engine.(CaptureStripper); ok {
+				if stripped, err := stripper.StripCaptures(pattern); err == nil {
+					pattern = stripped
+				}
+			}
+		}
+		refErrs := backreferenceErrors(b.engine, entry.pattern, pattern)
+		errs = append(errs, refErrs...)
+		if flags != "" {
+			if flagged, ok := b.engine.(FlagFormatter).FormatFlags(flags, pattern); ok {
+				pattern = flagged
+			}
+		}
+		// The engine's own error would only repeat a backreference problem less clearly.
+		if _, err := b.engine.Compile(AnchorPattern(pattern, anchoring)); err != nil && len(refErrs) == 0 {
+			errs = append(errs, codeErrorf(CodeCompileFailed, "invalid pattern '%s'%s: %w", entry.pattern, entry.describeName(), locatePatternError(entry.pattern, err)))
+		}
+		if entry.exclusion != nil {
+			errs = append(errs, b.validateExclusion(*entry.exclusion)...)
+		}
+		if b.positional || requiresPositionalGroups(b.engine) {
+			branches[i] = formatPositionalBranch(pattern)
+		} else {
+			branches[i] = formatBranch(b.engine, entryGroupName(b.engine, i+1, entry.name), pattern)
+		}
+	}
+
+	// The union is only worth compiling when every pattern compiles on its own,
+	// otherwise it would just report one of the same problems again.
+	if len(errs) == 0 && len(branches) > 0 {
+		if _, err := b.engine.Compile(AnchorPattern(strings.Join(branches, "|"), anchoring)); err != nil {
+			errs = append(errs, codeErrorf(CodeCompileFailed, "failed to compile union regexp: %w", err))
+		}
+	}
+	return errs
+}
+
+// ValidateAgainst is like Validate but compiles the patterns with another engine,
+// reporting the patterns that would stop the builder's rules working if its engine
+// were replaced. This is meant to be run before migrating a rule set between engines,
+// since a rule set can be valid for one engine and not for another.
+func (b *RegexpTableBuilder[T]) ValidateAgainst(engine RegexpEngine, anchorStart, anchorEnd bool) []error {
+	other := *b
+	other.engine = engine
+	return other.Validate(anchorStart, anchorEnd)
+}
+
+// validateExclusion compiles the patterns of an exclusion, as AddPatternExcluding does.
+func (b *RegexpTableBuilder[T]) validateExclusion(spec Exclusion) []error {
+	var errs []error
+	if spec.NotFollowedBy != "" {
+		if _, err := b.engine.Compile(AnchorPattern(spec.NotFollowedBy, AnchorStart)); err != nil {
+			errs = append(errs, codeErrorf(CodeCompileFailed, "invalid NotFollowedBy pattern '%s': %w", spec.NotFollowedBy, locatePatternError(spec.NotFollowedBy, err)))
+		}
+	}
+	if spec.NotMatching != "" {
+		if _, err := b.engine.Compile(AnchorPattern(spec.NotMatching, AnchorBoth)); err != nil {
+			errs = append(errs, codeErrorf(CodeCompileFailed, "invalid NotMatching pattern '%s': %w", spec.NotMatching, locatePatternError(spec.NotMatching, err)))
+		}
+	}
+	return errs
+}
+
 // MustBuild is like Build but panics on error. Useful for static configurations
 // where patterns are known to be valid.
 func (b *RegexpTableBuilder[T]) MustBuild(anchorStart, anchorEnd bool) *RegexpTable[T] {
@@ -115,14 +568,56 @@ func (b *RegexpTableBuilder[T]) MustBuild(anchorStart, anchorEnd bool) *RegexpTa
 // Clear removes all patterns from the builder, allowing it to be reused.
 func (b *RegexpTableBuilder[T]) Clear() *RegexpTableBuilder[T] {
 	b.patterns = b.patterns[:0] // Reset slice but keep capacity
+	b.errs = nil
 	return b
 }
 
+// RemovePattern removes every pattern entry whose pattern text equals pattern and
+// returns the number of entries removed.
+func (b *RegexpTableBuilder[T]) RemovePattern(pattern string) int {
+	before := len(b.patterns)
+	b.patterns = slices.DeleteFunc(b.patterns, func(entry patternEntry[T]) bool {
+		return entry.pattern == pattern
+	})
+	return before - len(b.patterns)
+}
+
 // Clone creates a copy of the builder with the same patterns and engine.
 func (b *RegexpTableBuilder[T]) Clone() *RegexpTableBuilder[T] {
 	clone := NewRegexpTableBuilderWithEngine[T](b.engine)
 	clone.patterns = make([]patternEntry[T], len(b.patterns))
 	copy(clone.patterns, b.patterns)
+	clone.nonCapturing = b.nonCapturing
+	clone.prefixFactoring = b.prefixFactoring
+	clone.suffixFactoring = b.suffixFactoring
+	clone.literalOrdering = b.literalOrdering
+	clone.ungreedy = b.ungreedy
+	clone.caseInsensitive = b.caseInsensitive
+	clone.positional = b.positional
+	clone.twoPhase = b.twoPhase
+	clone.prefixDispatch = b.prefixDispatch
+	clone.omitWrapper = b.omitWrapper
+	clone.precompile = b.precompile
+	clone.allowReDoS = b.allowReDoS
+	clone.memoCapacity = b.memoCapacity
+	clone.memoComputed = b.memoComputed
+	clone.sharedMatches = b.sharedMatches
+	clone.maxInputLength = b.maxInputLength
+	clone.truncateInput = b.truncateInput
+	clone.valueFolding = b.valueFolding
+	clone.adaptive = b.adaptive
+	clone.adaptivePromote = b.adaptivePromote
+	clone.pooling = b.pooling
+	clone.anchorPolicy = b.anchorPolicy
+	clone.hitStats = b.hitStats
+	clone.hitExamples = b.hitExamples
+	clone.diagnosticHook = b.diagnosticHook
+	clone.sampler = b.sampler
+	clone.normalizers = b.normalizers
+	clone.classes = maps.Clone(b.classes)
+	clone.grok = b.grok
+	clone.cache = b.cache
+	clone.errs = slices.Clone(b.errs)
 	return clone
 }
 
@@ -1,6 +1,7 @@
 package regexptable
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -776,3 +777,139 @@ func TestRegexpTableBuilder_Clear(t *testing.T) {
 		t.Errorf("New table should not match old pattern 'hello'")
 	}
 }
+
+func TestRegexpTableBuilder_WithNonCapturing(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)-(\d+)`, "range").
+		WithNonCapturing(true)
+
+	table, err := builder.Clone().Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+
+	value, matches, ok := table.TryLookup("10-20")
+	if !ok || value != "range" {
+		t.Fatalf("Expected 'range' for '10-20', got %v (ok=%v)", value, ok)
+	}
+	if len(matches) != 1 || matches[0] != "10-20" {
+		t.Errorf("Expected only the full match, got %v", matches)
+	}
+}
+
+func TestRegexpTableBuilder_SubPatternAnchorsRejected(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		AddSubPatterns([]string{`\d+`, `^0x[0-9a-f]+`}, "number").
+		Build(false, false)
+	if err == nil {
+		t.Fatal("Expected Build to reject an anchored sub-pattern")
+	}
+
+	_, err = NewRegexpTableBuilder[string]().
+		BeginAddSubPatterns().
+		AddSubPattern(`yes`).
+		AddSubPattern(`no$`).
+		EndAddSubPatterns("answer").
+		Build(true, false)
+	if err == nil {
+		t.Fatal("Expected Build to reject an anchored sub-pattern from the fluent interface")
+	}
+
+	// Character-class carets and escaped anchors are fine.
+	table, err := NewRegexpTableBuilder[string]().
+		AddSubPatterns([]string{`[^a-z]+`, `\$\d+`}, "other").
+		Build(true, false)
+	if err != nil {
+		t.Fatalf("Expected unanchored sub-patterns to build: %v", err)
+	}
+	if value, _, ok := table.TryLookup("$10"); !ok || value != "other" {
+		t.Errorf("Expected 'other' for '$10', got %q", value)
+	}
+
+	// Clearing the builder also clears the recorded problems.
+	builder := NewRegexpTableBuilder[string]().AddSubPatterns([]string{`^a`, `b`}, "x")
+	if _, err := builder.Clear().AddPattern(`a`, "a").Build(true, true); err != nil {
+		t.Errorf("Expected Clear to discard earlier problems: %v", err)
+	}
+}
+
+func TestRegexpTableBuilder_Validate(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z`, "broken").
+		AddPatternExcluding(`\w+`, "word", Exclusion{NotMatching: `(if`}).
+		AddPattern(`(?P<x>a)(`, "also broken")
+
+	errs := builder.Validate(true, true)
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 problems, got %d: %v", len(errs), errs)
+	}
+	for i, want := range []string{"[a-z", "(if", "(?P<x>a)("} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("Expected problem %d to mention %q, got %v", i, want, errs[i])
+		}
+	}
+	if _, err := builder.Build(true, true); err == nil {
+		t.Error("Expected Build to fail where Validate found problems")
+	}
+
+	valid := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddSubPatterns([]string{`yes`, `no`}, "answer")
+	if errs := valid.Validate(true, false); errs != nil {
+		t.Errorf("Expected no problems, got %v", errs)
+	}
+	if errs := NewRegexpTableBuilder[string]().Validate(false, false); errs != nil {
+		t.Errorf("Expected no problems for an empty builder, got %v", errs)
+	}
+
+	// Problems recorded while adding patterns are reported too.
+	anchored := NewRegexpTableBuilder[string]().AddSubPatterns([]string{`^a`, `b`}, "x")
+	if errs := anchored.Validate(false, false); len(errs) != 1 {
+		t.Errorf("Expected the anchored sub-pattern to be reported, got %v", errs)
+	}
+
+	// Ungreedy mode needs an engine that can format flags.
+	ungreedy := NewRegexpTableBuilderWithEngine[string](NewMockRegexpEngine("(?<%s>%s)")).
+		AddPattern(`a`, "a").
+		WithUngreedy(true)
+	if errs := ungreedy.Validate(false, false); len(errs) != 1 {
+		t.Errorf("Expected ungreedy mode to be rejected, got %v", errs)
+	}
+}
+
+// asciiOnlyEngine is the standard engine restricted in the way of engines that
+// lack Unicode character classes.
+type asciiOnlyEngine struct {
+	StandardRegexpEngine
+}
+
+func (e *asciiOnlyEngine) Compile(pattern string) (CompiledRegexp, error) {
+	if strings.Contains(pattern, `\p{`) {
+		return nil, fmt.Errorf("unicode classes are not supported")
+	}
+	return e.StandardRegexpEngine.Compile(pattern)
+}
+
+func TestRegexpTableBuilder_ValidateAgainst(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`\p{Greek}+`, "greek").
+		AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `\p{Lu}`})
+
+	if errs := builder.Validate(true, true); errs != nil {
+		t.Fatalf("Expected the rules to be valid for their own engine, got %v", errs)
+	}
+	errs := builder.ValidateAgainst(&asciiOnlyEngine{}, true, true)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 portability problems, got %d: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), `\p{Greek}+`) || !strings.Contains(errs[1].Error(), `\p{Lu}`) {
+		t.Errorf("Expected the problems to name the patterns, got %v", errs)
+	}
+
+	// The builder keeps its own engine.
+	if _, err := builder.Build(true, true); err != nil {
+		t.Errorf("Expected Build to use the builder's engine: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package regexptable
 
 import (
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -417,3 +420,308 @@ func TestRegexpTable_WithCaptureGroups(t *testing.T) {
 		}
 	}
 }
+
+func TestRegexpTable_NonCapturing(t *testing.T) {
+	table := NewRegexpTable[string](true, false) // Start anchoring, no end anchoring
+	table.SetNonCapturing(true)
+
+	err := table.AddPattern(`(\w+)=(\d+)`, "assignment")
+	if err != nil {
+		t.Fatalf("Failed to add assignment pattern: %v", err)
+	}
+	err = table.AddPattern(`func\s+(\w+)\(([^)]*)\)`, "function")
+	if err != nil {
+		t.Fatalf("Failed to add function pattern: %v", err)
+	}
+
+	value, matches, err := table.Lookup("func foo(x)")
+	if err != nil {
+		t.Fatalf("Expected match for 'func foo(x)', but got error: %v", err)
+	}
+	if value != "function" {
+		t.Errorf("Expected 'function', got %v", value)
+	}
+	if len(matches) != 1 || matches[0] != "func foo(x)" {
+		t.Errorf("Expected only the full match, got %v", matches)
+	}
+
+	// The union should contain no capture groups other than the internal ones.
+	if names := table.compiled.SubexpNames(); len(names) != 3 {
+		t.Errorf("Expected user groups to be stripped, got subexp names %v", names)
+	}
+
+	// Switching the mode off restores the capture groups.
+	table.SetNonCapturing(false)
+	_, matches, err = table.Lookup("x=42")
+	if err != nil {
+		t.Fatalf("Expected match for 'x=42', but got error: %v", err)
+	}
+	if len(matches) != 3 || matches[1] != "x" || matches[2] != "42" {
+		t.Errorf("Expected capture groups after disabling non-capturing mode, got %v", matches)
+	}
+}
+
+// stringOnlyEngine wraps the standard engine but hides the optional index-based
+// matching interfaces, forcing lookups down the FindStringSubmatch path.
+type stringOnlyEngine struct {
+	StandardRegexpEngine
+}
+
+func (e *stringOnlyEngine) Compile(pattern string) (CompiledRegexp, error) {
+	compiled, err := e.StandardRegexpEngine.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ CompiledRegexp }{compiled}, nil
+}
+
+func TestRegexpTable_EmptyMatchDisambiguation(t *testing.T) {
+	// x* matches the empty string at the start of the input, which is the leftmost
+	// match, even though \d+ matches later on. The digits must not win.
+	engines := map[string]RegexpEngine{
+		"standard":    NewStandardRegexpEngine(),
+		"string only": &stringOnlyEngine{},
+	}
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			table := NewRegexpTableWithEngine[string](engine, false, false)
+			for _, p := range []struct{ pattern, value string }{{`\d+`, "number"}, {`x*`, "xs"}} {
+				if err := table.AddPattern(p.pattern, p.value); err != nil {
+					t.Fatalf("Failed to add pattern: %v", err)
+				}
+			}
+			value, matches, err := table.Lookup("(1)")
+			if err != nil {
+				t.Fatalf("Lookup failed: %v", err)
+			}
+			if value != "xs" || matches[0] != "" {
+				t.Errorf("Expected (xs, \"\"), got (%q, %q)", value, matches[0])
+			}
+		})
+	}
+}
+
+func FuzzRecompileLookup(f *testing.F) {
+	f.Add(`a+`, `a*`, "aaa")
+	f.Add(`x*`, `\d+`, "(1)")
+	f.Add(`(a)|b`, `(?:)`, "")
+	f.Add(`\b`, `^`, "word")
+	f.Add(`(?i)é+`, `[^a]`, "É")
+	f.Add(`(`, `a`, "a")
+	f.Add(`a{2,3}`, `(a)(a)?`, "aa")
+	f.Fuzz(func(t *testing.T, first, second, input string) {
+		table := NewRegexpTable[int](true, true)
+		table.AddPattern(first, 0)
+		table.AddPattern(second, 1)
+		if err := table.Recompile(); err != nil {
+			return // Invalid patterns must be reported, not panic.
+		}
+
+		// With both ends anchored the winner is the first pattern that matches the
+		// whole input on its own.
+		expected := -1
+		for i, pattern := range []string{first, second} {
+			if regexp.MustCompile(`^(?:` + pattern + `)$`).MatchString(input) {
+				expected = i
+				break
+			}
+		}
+		value, _, ok := table.TryLookup(input)
+		if expected < 0 && ok {
+			t.Errorf("Expected no match for %q, got %d", input, value)
+		} else if expected >= 0 && (!ok || value != expected) {
+			t.Errorf("Expected %d for %q, got %d (ok=%v)", expected, input, value, ok)
+		}
+
+		// The other entry points must not panic either.
+		table.TryLookupAnchored(input, AnchorNone)
+		table.TryLookupBytes([]byte(input))
+		table.SetNonCapturing(true)
+		table.TryLookup(input)
+	})
+}
+
+func TestRegexpTable_IncrementalRecompile(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	for _, p := range []struct{ pattern, value string }{{`\d+`, "number"}, {`[a-z]+`, "word"}} {
+		if err := table.AddPattern(p.pattern, p.value); err != nil {
+			t.Fatalf("Failed to add pattern: %v", err)
+		}
+	}
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	before := table.unionPattern
+
+	// Recompiling an unchanged table keeps the compiled union.
+	compiled := table.compiled
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	if table.compiled != compiled {
+		t.Error("Expected an unchanged table to keep its compiled union")
+	}
+
+	// Appending extends the previous union.
+	if err := table.AddAndCheckPattern(`(\s+)`, "space"); err != nil {
+		t.Fatalf("AddAndCheckPattern failed: %v", err)
+	}
+	expected := before + "|" + table.maplets[2].namedPattern
+	if table.unionPattern != expected {
+		t.Errorf("Expected union %q, got %q", expected, table.unionPattern)
+	}
+	if value, matches, _ := table.TryLookup("  "); value != "space" || len(matches) != 2 {
+		t.Errorf("Expected space with one group, got %q, %v", value, matches)
+	}
+
+	// Toggling an option that rewrites the branches rebuilds the union in full.
+	table.SetNonCapturing(true)
+	if value, matches, _ := table.TryLookup("  "); value != "space" || len(matches) != 1 {
+		t.Errorf("Expected space with no groups, got %q, %v", value, matches)
+	}
+	if strings.Contains(table.unionPattern, `(\s+)`) {
+		t.Errorf("Expected captures to be stripped from the union, got %q", table.unionPattern)
+	}
+	table.SetNonCapturing(false)
+	if value, matches, _ := table.TryLookup("  "); value != "space" || len(matches) != 2 {
+		t.Errorf("Expected space with one group, got %q, %v", value, matches)
+	}
+}
+
+func TestRegexpTable_Ungreedy(t *testing.T) {
+	build := func(ungreedy bool) *RegexpTable[string] {
+		return NewRegexpTableBuilder[string]().
+			AddPattern(`(\w+)=(.*);`, "assignment").
+			AddPattern(`"(.+)"`, "quoted").
+			WithUngreedy(ungreedy).
+			MustBuild(true, false)
+	}
+	greedy, ungreedy := build(false), build(true)
+
+	testCases := []struct {
+		input            string
+		greedy, ungreedy string
+	}{
+		{"a=1;b=2;", "1;b=2", "1"},
+		{`"x" and "y"`, `x" and "y`, "x"},
+	}
+	for _, tc := range testCases {
+		_, matches, err := greedy.Lookup(tc.input)
+		if err != nil {
+			t.Fatalf("Lookup(%q) failed: %v", tc.input, err)
+		}
+		if got := matches[len(matches)-1]; got != tc.greedy {
+			t.Errorf("Greedy lookup of %q: expected %q, got %q", tc.input, tc.greedy, got)
+		}
+		_, matches, err = ungreedy.Lookup(tc.input)
+		if err != nil {
+			t.Fatalf("Lookup(%q) failed: %v", tc.input, err)
+		}
+		if got := matches[len(matches)-1]; got != tc.ungreedy {
+			t.Errorf("Ungreedy lookup of %q: expected %q, got %q", tc.input, tc.ungreedy, got)
+		}
+	}
+
+	// The engine must be able to express the flag.
+	table := NewRegexpTableWithEngine[string](NewMockRegexpEngine("(?<%s>%s)"), true, false)
+	table.SetUngreedy(true)
+	if err := table.AddAndCheckPattern("a", "a"); err == nil {
+		t.Error("Expected an error for an engine without FlagFormatter")
+	}
+}
+
+func TestRegexpTable_PrecompileIndividuals(t *testing.T) {
+	allCompiled := func(table *RegexpTable[string]) bool {
+		for _, entry := range table.maplets {
+			if entry.compiledPattern == nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`a*`, "as")
+	table.AddPattern(`b*`, "bs")
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if allCompiled(table) {
+		t.Fatal("Expected individual patterns to be compiled lazily by default")
+	}
+
+	table.SetPrecompileIndividuals(true)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !allCompiled(table) {
+		t.Error("Expected Recompile to compile every individual pattern")
+	}
+
+	// Toggling a mode back and forth leaves the union unchanged but discards the
+	// individual patterns, which must be compiled again.
+	table.SetNonCapturing(true)
+	table.SetNonCapturing(false)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !allCompiled(table) {
+		t.Error("Expected individual patterns to be compiled after toggling a mode")
+	}
+	if value, _, ok := table.TryLookup(""); !ok || value != "as" {
+		t.Errorf("Expected 'as' for the empty string, got %q", value)
+	}
+
+	built := NewRegexpTableBuilder[string]().
+		AddPattern(`x`, "x").
+		WithPrecompileIndividuals(true).
+		MustBuild(false, false)
+	if !allCompiled(built) {
+		t.Error("Expected WithPrecompileIndividuals to carry over to the built table")
+	}
+}
+
+func TestRegexpTable_ValidateAgainst(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	table.AddPattern(`\w+`, "word")
+	table.AddPatternExcluding(`\d+`, "number", Exclusion{NotFollowedBy: `\p{L}`})
+	if errs := table.ValidateAgainst(NewStandardRegexpEngine()); errs != nil {
+		t.Errorf("Expected no problems with the standard engine, got %v", errs)
+	}
+	if errs := table.ValidateAgainst(&asciiOnlyEngine{}); len(errs) != 1 {
+		t.Errorf("Expected the exclusion to be reported, got %v", errs)
+	}
+	if errs := table.ValidateAgainst(NewMockRegexpEngine("(?<%s>%s)")); errs != nil {
+		t.Errorf("Expected no problems with an engine that accepts everything, got %v", errs)
+	}
+}
+
+func TestRegexpTable_OrderedAccessors(t *testing.T) {
+	table := NewRegexpTable[int](true, true)
+	if table.Len() != 0 {
+		t.Errorf("Expected an empty table, got length %d", table.Len())
+	}
+	table.AddPattern(`one`, 1)
+	table.AddPattern(`two`, 2)
+	table.AddPattern(`three`, 3)
+
+	if table.Len() != 3 {
+		t.Fatalf("Expected length 3, got %d", table.Len())
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if table.PatternAt(i) != want || table.ValueAt(i) != i+1 {
+			t.Errorf("Expected %q -> %d at %d, got %q -> %d", want, i+1, i, table.PatternAt(i), table.ValueAt(i))
+		}
+	}
+
+	var patterns []string
+	for pattern, value := range table.All() {
+		patterns = append(patterns, pattern)
+		if value == 2 {
+			break
+		}
+	}
+	if !slices.Equal(patterns, []string{"one", "two"}) {
+		t.Errorf("Expected iteration to stop after 'two', got %v", patterns)
+	}
+}
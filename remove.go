@@ -0,0 +1,107 @@
+package regexptable
+
+import (
+	"errors"
+	"slices"
+)
+
+// errTombstoneWon reports that the union's winner was an entry removed since the
+// table was compiled, so the winner must be found among the live entries instead.
+var errTombstoneWon = errors.New("a removed pattern won the union match")
+
+// errTombstoneUnsupported reports that a tombstone won a match against input that
+// cannot be matched again, such as a reader, to find the live winner.
+func errTombstoneUnsupported(what string) error {
+	return codeErrorf(CodeTableUnsupported, "a removed pattern won the match, which cannot be matched again against %s; call Compact after RemovePattern", what)
+}
+
+// RemovePattern removes every entry whose pattern text equals pattern and returns
+// the number of entries removed.
+//
+// Removal is cheap: rather than recompiling, the table keeps its compiled union,
+// in which the removed entries' branches remain as tombstones. A lookup that a
+// tombstone wins matches the live entries one at a time instead, as for an
+// exclusion, so this needs an engine whose compiled regexps implement
+// IndexMatcher; with other engines the table is recompiled on the next lookup.
+// Once the tombstones outnumber the live entries, the next lookup recompiles the
+// table, which drops them; Compact does so at once.
+func (rt *RegexpTable[T]) RemovePattern(pattern string) int {
+	var removed []*ValueAndPattern[T]
+	live := rt.maplets[:0]
+	for _, entry := range rt.maplets {
+		if entry.Pattern == pattern {
+			removed = append(removed, entry)
+			continue
+		}
+		live = append(live, entry)
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+	clear(rt.maplets[len(live):]) // Release references to the removed entries
+	rt.maplets = live
+
+	rt.bury(len(removed))
+	for _, entry := range removed {
+		rt.notifyMutate(ChangeRemoved, entry)
+	}
+	return len(removed)
+}
+
+// bury records that n entries have just been removed from the table, leaving
+// tombstones in the compiled union where that is safe and scheduling a
+// recompilation otherwise.
+func (rt *RegexpTable[T]) bury(n int) {
+	if rt.needsRecompile || rt.compiled == nil {
+		return // The next recompilation drops the entries anyway
+	}
+	if _, ok := rt.compiled.(IndexMatcher); !ok || rt.tombstones+n > len(rt.maplets) {
+		rt.needsRecompile = true
+		return
+	}
+	rt.tombstones += n
+	if rt.memo != nil {
+		rt.memo.clear()
+	}
+	// The prefilter and the dispatch tree refer to entries by their position in
+	// the table.
+	if rt.prefilter != nil {
+		rt.prefilter = rt.newFirstBytePrefilter()
+	}
+	if rt.dispatch != nil {
+		rt.dispatch = rt.newPrefixDispatch()
+	}
+	if rt.hotLiterals != nil {
+		rt.forgetRemovedLiterals()
+	}
+}
+
+// Tombstones returns the number of removed entries whose branches remain in the
+// compiled union, see RemovePattern.
+func (rt *RegexpTable[T]) Tombstones() int {
+	return rt.tombstones
+}
+
+// Compact rebuilds the table's internal state from its live entries and
+// recompiles it. This drops the tombstones left by RemovePattern and renumbers
+// the internal groups wrapping the entries, whose numbers otherwise keep growing
+// as patterns are added and removed; as a result Result.Index and the other
+// insertion indexes count only the live entries, in the order they were added.
+func (rt *RegexpTable[T]) Compact() error {
+	byInsertion := slices.Clone(rt.maplets)
+	slices.SortFunc(byInsertion, func(a, b *ValueAndPattern[T]) int {
+		return a.order - b.order
+	})
+	for i, entry := range byInsertion {
+		entry.order = i + 1
+		entry.source = 0
+		entry.GroupName = entryGroupName(rt.engine, entry.order, entry.name)
+		if !requiresPositionalGroups(rt.engine) {
+			entry.namedPattern = formatBranch(rt.engine, entry.GroupName, entry.Pattern)
+		}
+	}
+	rt.nextGroupID = len(rt.maplets) + 1
+	rt.unionEntries = nil
+	rt.needsRecompile = true
+	return rt.Recompile()
+}
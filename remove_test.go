@@ -0,0 +1,156 @@
+package regexptable
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegexpTable_RemovePattern(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`\w+`, "any")
+	table.AddPattern(`[A-Z]+`, "upper")
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	union := table.compiledUnion
+
+	var diagnostics []Diagnostic
+	table.SetDiagnostics(func(d Diagnostic) { diagnostics = append(diagnostics, d) }, nil)
+	if n := table.RemovePattern(`[a-z]+`); n != 1 {
+		t.Fatalf("Expected 1 pattern removed, got %d", n)
+	}
+	if table.needsRecompile || table.compiledUnion != union || table.Tombstones() != 1 {
+		t.Errorf("Expected the union to be kept with a tombstone, got %d tombstones", table.Tombstones())
+	}
+	if table.Len() != 3 || slices.Contains(table.Patterns(), `[a-z]+`) {
+		t.Errorf("Expected the pattern to be gone, got %v", table.Patterns())
+	}
+	if value, _, _ := table.Lookup("abc"); value != "any" {
+		t.Errorf("Expected any, got %q", value)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Kind != DiagnosticTombstone {
+		t.Errorf("Expected a tombstone diagnostic, got %+v", diagnostics)
+	}
+	if value, _, _ := table.Lookup("42"); value != "number" {
+		t.Errorf("Expected number, got %q", value)
+	}
+	if n := table.RemovePattern(`nothing`); n != 0 {
+		t.Errorf("Expected nothing removed, got %d", n)
+	}
+
+	// Once the tombstones outnumber the live entries the table is recompiled.
+	table.RemovePattern(`\w+`)
+	if table.needsRecompile {
+		t.Error("Expected two tombstones among two live entries to be kept")
+	}
+	table.RemovePattern(`[A-Z]+`)
+	if !table.needsRecompile {
+		t.Error("Expected three tombstones to schedule a recompilation")
+	}
+	if _, _, err := table.Lookup("abc"); err != ErrNoMatch || table.Tombstones() != 0 {
+		t.Errorf("Expected no match without tombstones, got %v with %d", err, table.Tombstones())
+	}
+}
+
+func TestRegexpTable_RemovePatternStringOnly(t *testing.T) {
+	table := NewRegexpTableWithEngine[string](&stringOnlyEngine{}, true, true)
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`\w+`, "any")
+	table.Recompile()
+
+	table.RemovePattern(`[a-z]+`)
+	if !table.needsRecompile || table.Tombstones() != 0 {
+		t.Error("Expected engines without IndexMatcher to recompile instead of keeping tombstones")
+	}
+	if value, _, _ := table.Lookup("abc"); value != "any" {
+		t.Errorf("Expected any, got %q", value)
+	}
+}
+
+// TestRegexpTable_RemovePatternConcurrentReads reads a table with tombstones from
+// several goroutines at once, which go test -race checks: streaming reads must
+// report a tombstone win rather than recompile the table.
+func TestRegexpTable_RemovePatternConcurrentReads(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`\w+`, "any")
+	table.AddPattern(`[A-Z]+`, "upper")
+	table.SetPrecompileIndividuals(true)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+	table.RemovePattern(`[a-z]+`)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, _, err := table.Lookup("abc"); err != nil || value != "any" {
+				t.Errorf("Expected any, got %q, %v", value, err)
+			}
+			var values []string
+			err := table.ScanReader(strings.NewReader("42 7"), func(m Match[string]) error {
+				values = append(values, m.Result.Value)
+				return nil
+			})
+			if err != nil || !slices.Equal(values, []string{"number", "number"}) {
+				t.Errorf("Expected two numbers, got %v, %v", values, err)
+			}
+			err = table.ScanReader(strings.NewReader("abc"), func(Match[string]) error { return nil })
+			if ErrorCodeOf(err) != CodeTableUnsupported {
+				t.Errorf("Expected a tombstone win to be unsupported, got %v", err)
+			}
+			if _, _, err := table.LookupReader(strings.NewReader("abc")); ErrorCodeOf(err) != CodeTableUnsupported {
+				t.Errorf("Expected a tombstone win to be unsupported, got %v", err)
+			}
+			if value, _, err := table.LookupReader(strings.NewReader("42")); err != nil || value != "number" {
+				t.Errorf("Expected number, got %q, %v", value, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if table.Tombstones() != 1 {
+		t.Errorf("Expected reads to leave the tombstone, got %d", table.Tombstones())
+	}
+
+	if err := table.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if value, _, err := table.LookupReader(strings.NewReader("abc")); err != nil || value != "any" {
+		t.Errorf("Expected any after Compact, got %q, %v", value, err)
+	}
+}
+
+func TestRegexpTable_Compact(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	for range 10 {
+		table.AddPattern(`[a-z]+`, "word")
+		table.AddPattern(`\d+`, "number")
+		table.Lookup("x")
+		table.RemovePattern(`[a-z]+`)
+	}
+	table.AddPattern(`[a-z]+`, "word")
+	if table.nextGroupID != 22 {
+		t.Fatalf("Expected 21 groups to have been used, got %d", table.nextGroupID-1)
+	}
+
+	if err := table.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if table.nextGroupID != 12 || table.Tombstones() != 0 || table.needsRecompile {
+		t.Errorf("Expected the group numbers to restart, got %d with %d tombstones", table.nextGroupID, table.Tombstones())
+	}
+	result, err := table.LookupResult("abc")
+	if err != nil || result.Value != "word" || result.Index != 10 {
+		t.Errorf("Expected the word rule at index 10, got %v, %v", result, err)
+	}
+	if value, _, _ := table.Lookup("42"); value != "number" {
+		t.Errorf("Expected number, got %q", value)
+	}
+}
@@ -0,0 +1,325 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Result is the rich outcome of a successful lookup. Groups are numbered exactly
+// as in the winning pattern written on its own: Groups[0] is the full match and
+// Groups[i] is the text of the pattern's i-th capture group, independent of where
+// the pattern sits in the table's union.
+//
+// Index counts every pattern added to the table, including any that have since
+// expired, and is unaffected by reordering passes such as literal ordering. For a
+// table built with a builder it is the pattern's position among those added to the
+// builder, whatever their priorities, so for a table loaded from a spec it is the
+// rule's position in the spec.
+//
+// Start and End locate the match in the input as it was given, even when the table
+// normalizes its inputs (see SetNormalizers) and Groups hold normalized text. Text
+// removed by normalization immediately after the match, such as an escape
+// sequence, falls inside the span. GroupSpan locates the capture groups in the
+// same way.
+type Result[T any] struct {
+	Value      T
+	Values     []T      // All values of the winning pattern, starting with Value
+	Pattern    string   // The winning pattern as it was added to the table
+	Index      int      // Zero-based position of the winning pattern in insertion order
+	RuleName   string   // The name the winning pattern was added with, see AddNamedPattern
+	Groups     []string // The full match followed by the pattern's capture groups
+	Names      []string // Group names parallel to Groups, "" for the full match and unnamed groups
+	Start      int      // Byte offset of the full match in the input, -1 if the engine cannot tell
+	End        int      // Byte offset of the end of the full match in the input, -1 if unknown
+	Confidence float64  // The winning pattern's confidence, see RegexpTableBuilder.AddPatternWithConfidence
+
+	priority int   // The winning pattern's priority, see RegexpTableBuilder.AddPatternWithPriority
+	complete bool  // Whether the full match is the whole input, see Complete
+	spans    []int // Offsets of the groups in the input, paired as Start and End, see GroupSpan
+
+	groupTypes map[string]GroupType // Types of the named groups, see TypedFields
+
+	pool         *sync.Pool        // The pool the result returns to on Release, nil unless pooled
+	fields       map[string]string // The map Fields reuses when pooled
+	sharedGroups bool              // Whether Groups belongs to the memoization cache
+}
+
+// Complete reports whether the match consumed the whole input, after any
+// normalization, as it always does in a table anchored at both ends. In a table
+// anchored only at the start, a result that is not complete matched just a prefix
+// of the input, such as a partial token. For a Word it reports whether the whole
+// word matched. It does not depend on the engine reporting offsets.
+func (r *Result[T]) Complete() bool {
+	return r.complete
+}
+
+// GroupByIndex returns the text of capture group i of the winning pattern, where
+// i is the group number in the standalone pattern (0 is the full match). The
+// boolean is false if the pattern has no such group. In non-capturing mode only
+// group 0 is available.
+func (r *Result[T]) GroupByIndex(i int) (string, bool) {
+	if i < 0 || i >= len(r.Groups) {
+		return "", false
+	}
+	return r.Groups[i], true
+}
+
+// GroupSpan returns the byte offsets in the input of capture group i of the
+// winning pattern, numbered as in GroupByIndex. Like Start and End, they refer to
+// the input as it was given rather than to the normalized text the group holds,
+// so that callers can highlight or redact the raw input. It returns -1, -1 if the
+// group did not take part in the match or its offsets are unknown, as they are
+// when the engine's compiled regexps do not implement IndexMatcher.
+func (r *Result[T]) GroupSpan(i int) (start, end int) {
+	switch {
+	case i == 0:
+		return r.Start, r.End
+	case i < 0 || i >= len(r.Groups) || 2*i+1 >= len(r.spans):
+		return -1, -1
+	}
+	return r.spans[2*i], r.spans[2*i+1]
+}
+
+// locate sets the spans of the result and its groups from loc, the offsets of the
+// match in the normalized input as returned by FindStringSubmatchIndex, mapping
+// them to offsets in the input unless offsets is nil.
+func (r *Result[T]) locate(loc, offsets []int) {
+	spans := append(r.spans[:0], loc...)
+	if offsets != nil {
+		for i, offset := range spans {
+			if offset >= 0 {
+				spans[i] = offsets[offset]
+			}
+		}
+	}
+	r.Start, r.End = spans[0], spans[1]
+	r.spans = spans
+}
+
+// shift moves the spans of the result and its groups by n bytes, for a match in a
+// part of the input starting at offset n.
+func (r *Result[T]) shift(n int) {
+	if r.Start >= 0 {
+		r.Start += n
+		r.End += n
+	}
+	for i, offset := range r.spans {
+		if offset >= 0 {
+			r.spans[i] = offset + n
+		}
+	}
+}
+
+// Field returns the text captured by the named group of the winning pattern. The
+// boolean is false if the pattern has no group with that name.
+func (r *Result[T]) Field(name string) (string, bool) {
+	for i, groupName := range r.Names {
+		if groupName == name && name != "" && i < len(r.Groups) {
+			return r.Groups[i], true
+		}
+	}
+	return "", false
+}
+
+// Fields returns the text captured by every named group of the winning pattern,
+// keyed by group name. If a name occurs more than once the first group wins.
+//
+// With pooling, see RegexpTable.SetPooling, the map is the result's own and is
+// only valid until the result is released.
+func (r *Result[T]) Fields() map[string]string {
+	fields := r.fields
+	switch {
+	case fields != nil:
+		clear(fields)
+	case r.pool != nil:
+		fields = make(map[string]string)
+		r.fields = fields
+	default:
+		fields = make(map[string]string)
+	}
+	for i, name := range r.Names {
+		if _, seen := fields[name]; name != "" && !seen && i < len(r.Groups) {
+			fields[name] = r.Groups[i]
+		}
+	}
+	return fields
+}
+
+// String renders the result on one line in a stable, readable form, for example
+//
+//	value=date index=1 pattern=`(?P<year>\d{4})-(\d{2})` span=[0,7) groups=[0:"2024-02" 1<year>:"2024" 2:"02"]
+//
+// The value is formatted with %v, followed by values=[...] when the pattern has
+// several, and the index by rule=name when the pattern has a name. The pattern
+// is quoted with backquotes unless it cannot be, and the span is ? when it is
+// unknown. Groups are numbered as in Result.Groups, with their names in angle
+// brackets.
+func (r *Result[T]) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "value=%v", r.Value)
+	if len(r.Values) > 1 {
+		fmt.Fprintf(&b, " values=%v", r.Values)
+	}
+	fmt.Fprintf(&b, " index=%d", r.Index)
+	if r.RuleName != "" {
+		fmt.Fprintf(&b, " rule=%s", r.RuleName)
+	}
+	fmt.Fprintf(&b, " pattern=%s", quotePattern(r.Pattern))
+	if r.Start >= 0 {
+		fmt.Fprintf(&b, " span=[%d,%d)", r.Start, r.End)
+	} else {
+		b.WriteString(" span=?")
+	}
+	b.WriteString(" groups=[")
+	for i, group := range r.Groups {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Itoa(i))
+		if i < len(r.Names) && r.Names[i] != "" {
+			fmt.Fprintf(&b, "<%s>", r.Names[i])
+		}
+		fmt.Fprintf(&b, ":%q", group)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// quotePattern quotes a pattern with backquotes if possible, so that its
+// backslashes are not doubled, and as a Go string otherwise.
+func quotePattern(pattern string) string {
+	if strconv.CanBackquote(pattern) {
+		return "`" + pattern + "`"
+	}
+	return strconv.Quote(pattern)
+}
+
+// FormatResult renders the outcome of a lookup, as returned by LookupResult and
+// the other methods returning a Result, in the form used by Result.String. A
+// lookup that failed with ErrNoMatch is rendered as "no match" and any other
+// error as "error: " followed by the error, so that the outcome of a lookup can
+// be printed or compared without checking the error first:
+//
+//	fmt.Println(regexptable.FormatResult(table.LookupResult(input)))
+func FormatResult[T any](result *Result[T], err error) string {
+	switch {
+	case errors.Is(err, ErrNoMatch):
+		return "no match"
+	case err != nil:
+		return "error: " + err.Error()
+	case result == nil:
+		return "no match"
+	}
+	return result.String()
+}
+
+// LookupResult is like Lookup but returns a Result describing the match.
+func (rt *RegexpTable[T]) LookupResult(input string) (*Result[T], error) {
+	err := rt.ensureCompiled()
+	if err != nil {
+		return nil, err
+	}
+	return rt.lookupCompiled(input)
+}
+
+// lookupCompiled is LookupResult for a table that has already been compiled. It
+// neither sweeps expired entries nor applies schedules, so it does not modify the
+// table and concurrent calls may share it.
+func (rt *RegexpTable[T]) lookupCompiled(input string) (*Result[T], error) {
+	input, err := rt.limitInput(input)
+	if err != nil {
+		return nil, err
+	}
+	normalized, offsets := input, []int(nil)
+	if rt.normalizers != nil {
+		normalized, offsets = rt.normalize(input)
+	}
+
+	result, buf := rt.pooledResult()
+	found, err := rt.findNormalized(normalized)
+	if err != nil {
+		if result != nil {
+			result.Release()
+		}
+		return nil, err
+	}
+	entry, matches := found.entry, found.submatches(buf)
+	rt.recordHit(entry, input, matches)
+	if result != nil {
+		result.reuse(entry, matches, rt.memo != nil && rt.sharedMatches)
+	} else {
+		result = rt.newResult(entry, matches)
+	}
+	loc := found.loc
+	if loc == nil {
+		loc = rt.matchIndexes(entry, normalized, matches)
+	}
+	result.locate(loc, offsets)
+	result.complete = len(matches[0]) == len(normalized)
+	return result, nil
+}
+
+// matchIndexes returns the offsets of the winning entry's match and its groups in
+// the input, given its submatches, with -1 for those that cannot be determined.
+func (rt *RegexpTable[T]) matchIndexes(entry *ValueAndPattern[T], input string, matches []string) []int {
+	match := matches[0]
+	// An anchored match is located by its length alone, and matching the winner
+	// again is only worthwhile for its groups.
+	if len(matches) == 1 {
+		switch {
+		case rt.anchorStart:
+			return []int{0, len(match)}
+		case rt.anchorEnd:
+			return []int{len(input) - len(match), len(input)}
+		}
+	}
+	// The winner cannot match anywhere to the left of the union's match, so its
+	// own leftmost match is the one the union found.
+	if individual, err := rt.individualRegexp(entry); err == nil {
+		if matcher, ok := individual.(IndexMatcher); ok {
+			if loc := matcher.FindStringSubmatchIndex(input); loc != nil {
+				return loc
+			}
+		}
+	}
+	loc := slices.Repeat([]int{-1}, 2*len(matches))
+	switch {
+	case rt.anchorStart:
+		loc[0], loc[1] = 0, len(match)
+	case rt.anchorEnd:
+		loc[0], loc[1] = len(input)-len(match), len(input)
+	}
+	return loc
+}
+
+// newResult builds the Result for a winning entry and its submatches.
+func (rt *RegexpTable[T]) newResult(entry *ValueAndPattern[T], matches []string) *Result[T] {
+	names := make([]string, len(matches))
+	copy(names[1:], entry.groupNames)
+	return &Result[T]{
+		Value:      entry.Value,
+		Values:     entry.values(),
+		Pattern:    entry.Pattern,
+		Index:      entry.insertionIndex(),
+		RuleName:   entry.name,
+		Groups:     matches,
+		Names:      names,
+		Confidence: entry.score(),
+		priority:   entry.priority,
+		groupTypes: entry.groupTypes,
+	}
+}
+
+// insertionIndex returns the zero-based position of the entry among all the
+// patterns added to its table, or among the patterns of the builder it was built
+// by. See Result.Index.
+func (vp *ValueAndPattern[T]) insertionIndex() int {
+	if vp.source > 0 {
+		return vp.source - 1
+	}
+	return vp.order - 1
+}
@@ -0,0 +1,275 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_LookupResultGroupByIndex(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(\w+)=(\d+)`, "assignment").
+		AddPattern(`(\d{4})-(\d{2})-(\d{2})`, "date").
+		AddPattern(`user_(\w+)`, "user").
+		AddPattern(`user_(x)(y)`, "shadowed").
+		WithPrefixFactoring(true).
+		MustBuild(true, true)
+
+	result, err := table.LookupResult("2024-02-29")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if result.Value != "date" || result.Pattern != `(\d{4})-(\d{2})-(\d{2})` {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	for i, expected := range []string{"2024-02-29", "2024", "02", "29"} {
+		if group, ok := result.GroupByIndex(i); !ok || group != expected {
+			t.Errorf("GroupByIndex(%d) = %q, %v; expected %q", i, group, ok, expected)
+		}
+	}
+	if _, ok := result.GroupByIndex(4); ok {
+		t.Error("Expected no group 4 for the date pattern")
+	}
+
+	// The factored prefix is restored in the full match and group numbering is unaffected.
+	result, err = table.LookupResult("user_bob")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if group, _ := result.GroupByIndex(0); group != "user_bob" {
+		t.Errorf("Expected full match 'user_bob', got %q", group)
+	}
+	if group, _ := result.GroupByIndex(1); group != "bob" {
+		t.Errorf("Expected group 1 'bob', got %q", group)
+	}
+
+	if _, err := table.LookupResult("nothing here"); err == nil {
+		t.Error("Expected an error when nothing matches")
+	}
+}
+
+func TestRegexpTable_GroupBookkeeping(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	for _, pattern := range []string{`a(b)(c)`, `d`, `(?P<name>e)(f(g))`} {
+		if err := table.AddPattern(pattern, pattern); err != nil {
+			t.Fatalf("Failed to add pattern: %v", err)
+		}
+	}
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+
+	expected := []struct{ first, count int }{{1, 2}, {4, 0}, {5, 3}}
+	for i, want := range expected {
+		entry := table.maplets[i]
+		if entry.firstGroup != want.first || entry.groupCount != want.count {
+			t.Errorf("Entry %d: expected first=%d count=%d, got first=%d count=%d",
+				i, want.first, want.count, entry.firstGroup, entry.groupCount)
+		}
+	}
+}
+
+func TestResult_Fields(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`(?P<key>\w+)=(?P<value>\w+)`, "pair").
+		AddPattern(`(?P<host>[\w.]+):(\d+)`, "address").
+		MustBuild(true, true)
+
+	result, err := table.LookupResult("example.com:8080")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	fields := result.Fields()
+	if len(fields) != 1 || fields["host"] != "example.com" {
+		t.Errorf("Expected only the host field, got %v", fields)
+	}
+	if _, ok := result.Field("key"); ok {
+		t.Error("Expected no 'key' field for the address pattern")
+	}
+
+	result, err = table.LookupResult("colour=red")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if value, ok := result.Field("value"); !ok || value != "red" {
+		t.Errorf("Expected field value 'red', got %q", value)
+	}
+}
+
+func TestRegexpTable_LookupResultIndex(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`\d+`, "number").
+		AddPattern(`item-\d+`, "item").
+		WithLiteralOrdering(true).
+		MustBuild(true, true)
+
+	// Literal ordering tries the item rule first, but the index still refers to
+	// the order in which the rules were added.
+	for input, want := range map[string]int{"abc": 0, "42": 1, "item-7": 2} {
+		result, err := table.LookupResult(input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", input, err)
+		}
+		if result.Index != want {
+			t.Errorf("Expected index %d for %q, got %d", want, input, result.Index)
+		}
+	}
+
+	explanation, err := table.Explain("item-7")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	winner := explanation.Rules[explanation.Winner]
+	if winner.Index != 0 || winner.InsertionIndex != 2 {
+		t.Errorf("Expected the winner to be tried first and inserted third, got %d and %d", winner.Index, winner.InsertionIndex)
+	}
+}
+
+func TestRegexpTable_LookupResultSpan(t *testing.T) {
+	testCases := []struct {
+		anchorStart, anchorEnd bool
+		input                  string
+		start, end             int
+	}{
+		{false, false, "xx 42 yy 7", 3, 5},
+		{true, false, "42 yy", 0, 2},
+		{false, true, "yy 42", 3, 5},
+		{true, true, "42", 0, 2},
+	}
+	for _, tc := range testCases {
+		table := NewRegexpTable[string](tc.anchorStart, tc.anchorEnd)
+		table.AddPattern(`[a-z]+\d`, "tagged")
+		table.AddPattern(`\d+`, "number")
+		result, err := table.LookupResult(tc.input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tc.input, err)
+		}
+		if result.Start != tc.start || result.End != tc.end {
+			t.Errorf("Expected span [%d, %d) for %q, got [%d, %d)", tc.start, tc.end, tc.input, result.Start, result.End)
+		}
+	}
+}
+
+func TestResult_GroupSpan(t *testing.T) {
+	testCases := []struct {
+		anchorStart, anchorEnd bool
+		input                  string
+	}{
+		{false, false, "see key=42 here"},
+		{true, false, "key=42 here"},
+		{false, true, "see key=42"},
+		{true, true, "key=42"},
+	}
+	for _, tc := range testCases {
+		table := NewRegexpTable[string](tc.anchorStart, tc.anchorEnd)
+		table.AddPattern(`(\w+)=(\d+)(?:;(\w+))?`, "assignment")
+		result, err := table.LookupResult(tc.input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tc.input, err)
+		}
+		for i, want := range []string{"key=42", "key", "42"} {
+			if start, end := result.GroupSpan(i); start < 0 || tc.input[start:end] != want {
+				t.Errorf("Expected group %d to span %q in %q, got [%d, %d)", i, want, tc.input, start, end)
+			}
+		}
+		for _, i := range []int{3, 4, -1} {
+			if start, end := result.GroupSpan(i); start != -1 || end != -1 {
+				t.Errorf("Expected no span for group %d of %q, got [%d, %d)", i, tc.input, start, end)
+			}
+		}
+	}
+
+	words, err := NewRegexpTableBuilder[string]().
+		AddPattern(`(\d+)px`, "pixels").
+		MustBuild(true, true).
+		LookupWords("width 12px")
+	if err != nil {
+		t.Fatalf("LookupWords failed: %v", err)
+	}
+	if start, end := words[1].Result.GroupSpan(1); start != 6 || end != 8 {
+		t.Errorf("Expected the group span [6, 8) in the whole input, got [%d, %d)", start, end)
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`x`, "x")
+	table.AddPattern(`(?P<year>\d{4})-(\d{2})`, "date")
+	table.AddPatternValues("\"", "quote", "mark")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"on 2024-02", "value=date index=1 pattern=`(?P<year>\\d{4})-(\\d{2})` span=[3,10) groups=[0:\"2024-02\" 1<year>:\"2024\" 2:\"02\"]"},
+		{"say \"", "value=quote values=[quote mark] index=2 pattern=`\"` span=[4,5) groups=[0:\"\\\"\"]"},
+		{"none", "no match"},
+	}
+	for _, test := range tests {
+		if got := FormatResult(table.LookupResult(test.input)); got != test.expected {
+			t.Errorf("FormatResult(%q): expected %s, got %s", test.input, test.expected, got)
+		}
+	}
+
+	result := &Result[int]{Value: 1, Pattern: "a`b", Groups: []string{"a`b"}, Start: -1, End: -1}
+	if expected := "value=1 index=0 pattern=\"a`b\" span=? groups=[0:\"a`b\"]"; result.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, result.String())
+	}
+}
+
+func TestMatch_String(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`(\d+)`, "number")
+	var matches []string
+	OnMatch(table, "number", func(m Match[string]) { matches = append(matches, m.String()) })
+	table.Lookup("a 42")
+	if expected := "input=\"a 42\" value=number index=0 pattern=`(\\d+)` span=? groups=[0:\"42\" 1:\"42\"]"; len(matches) != 1 || matches[0] != expected {
+		t.Errorf("Expected %s, got %q", expected, matches)
+	}
+	if got := (Match[string]{Input: "x"}).String(); got != `input="x" no match` {
+		t.Errorf("Expected no match, got %s", got)
+	}
+}
+
+func TestFormatResult_Error(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(`, "bad")
+	if got := FormatResult(table.LookupResult("x")); !strings.HasPrefix(got, "error: failed to compile") {
+		t.Errorf("Expected the compilation error, got %s", got)
+	}
+}
+
+func TestResult_Complete(t *testing.T) {
+	table := NewRegexpTable[string](true, false)
+	table.AddPattern(`[a-z]+`, "word")
+	table.AddPattern(`\d+`, "number")
+	table.SetNormalizers(TrimSpace())
+
+	tests := []struct {
+		input    string
+		complete bool
+	}{
+		{"abc", true},
+		{"abc1", false},
+		{"42", true},
+		{"  abc ", true}, // The normalized input is matched in full
+		{"a bc", false},
+	}
+	for _, test := range tests {
+		result, err := table.LookupResult(test.input)
+		if err != nil {
+			t.Errorf("LookupResult(%q) failed: %v", test.input, err)
+			continue
+		}
+		if result.Complete() != test.complete {
+			t.Errorf("LookupResult(%q): expected Complete %v, got %v", test.input, test.complete, result.Complete())
+		}
+	}
+
+	if result, err := table.LookupCandidates("abc1", []int{0}); err != nil || result.Complete() {
+		t.Errorf("Expected an incomplete candidate match, got %v, %v", result, err)
+	}
+	if result, err := table.LookupCandidates("42", []int{1}); err != nil || !result.Complete() {
+		t.Errorf("Expected a complete candidate match, got %v, %v", result, err)
+	}
+}
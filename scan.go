@@ -0,0 +1,168 @@
+package regexptable
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// Defaults for ScanOptions.
+const (
+	defaultScanBufferSize     = 64 << 10
+	defaultScanMaxMatchLength = 4 << 10
+)
+
+// ScanOptions configures ScanReaderWith.
+type ScanOptions struct {
+	BufferSize     int // Bytes of the stream held in memory at a time, 64 KiB if zero or less
+	MaxMatchLength int // Length of the longest match found in full, 4 KiB if zero or less
+}
+
+// ScanReader is ScanReaderWith using the default options.
+func (rt *RegexpTable[T]) ScanReader(r io.Reader, emit func(Match[T]) error) error {
+	return rt.ScanReaderWith(r, ScanOptions{}, emit)
+}
+
+// ScanReaderWith finds every match of the table's patterns in the text read from
+// r, in order and without overlaps, and passes each to emit, which suits
+// classifying files far too large to load into memory. Whatever the table's
+// anchoring, matches are searched for anywhere in the text. For each match, the
+// Input of the Match is the text matched and its Result's Start, End and group
+// spans are byte offsets from the start of the reader. Scanning stops at the end
+// of the reader or as soon as emit returns an error, which ScanReaderWith then
+// returns, so emit can apply backpressure by blocking and cancel by failing.
+//
+// The text is read opts.BufferSize bytes at a time. A match that could extend
+// beyond the text read so far is only searched for once more has been read,
+// provided it is at most opts.MaxMatchLength bytes long, which must be less than
+// the buffer size; longer matches may be cut short or missed. Like a Tokenizer,
+// ScanReaderWith matches from the end of each match onwards as if the text
+// started there, so assertions such as \b see no text before that point; empty
+// matches immediately after a match are skipped, as in Go's FindAll methods.
+//
+// The table must not have normalizers, and a match won by a pattern with an
+// exclusion is reported as an error, since neither can be applied to a stream.
+// So is a match won by a pattern removed by RemovePattern; call Compact after
+// removing patterns to avoid this.
+func (rt *RegexpTable[T]) ScanReaderWith(r io.Reader, opts ScanOptions, emit func(Match[T]) error) error {
+	size, maxMatch := opts.BufferSize, opts.MaxMatchLength
+	if size <= 0 {
+		size = defaultScanBufferSize
+	}
+	if maxMatch <= 0 {
+		maxMatch = defaultScanMaxMatchLength
+	}
+	if maxMatch >= size {
+		return codeErrorf(CodeInvalidArgument, "maximum match length %d is not less than the buffer size %d", maxMatch, size)
+	}
+
+	err := rt.ensureCompiled()
+	if err != nil {
+		return err
+	}
+	if err := rt.checkNoNormalizers("ScanReader"); err != nil {
+		return err
+	}
+	if rt.compiled == nil {
+		return ErrNoPatterns
+	}
+	compiled := rt.compiled
+	if rt.Anchoring() != AnchorNone {
+		variant, err := rt.anchoredVariant(AnchorNone)
+		if err != nil {
+			return err
+		}
+		compiled = variant.compiled
+	}
+	var find func([]byte) []int
+	if matcher, ok := compiled.(ByteMatcher); ok {
+		find = matcher.FindSubmatchIndex
+	} else if matcher, ok := compiled.(ReaderMatcher); ok {
+		find = func(b []byte) []int { return matcher.FindReaderSubmatchIndex(bytes.NewReader(b)) }
+	} else {
+		return codeErrorf(CodeEngineUnsupported, "regexp engine does not support index-based matching")
+	}
+
+	buf := make([]byte, 0, size)
+	base := 0     // Offset in the stream of buf[0]
+	pos := 0      // Offset in buf from which to search
+	lastEnd := -1 // Offset in the stream where the last match ended
+	eof := false
+	for {
+		for !eof && len(buf) < size {
+			n, err := r.Read(buf[len(buf):size])
+			buf = buf[:len(buf)+n]
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+		// Before the end of the stream, only a match starting this far into the
+		// buffer is sure to be complete.
+		safe := len(buf)
+		if !eof {
+			safe -= maxMatch
+		}
+
+		for pos <= len(buf) {
+			loc := find(buf[pos:])
+			if loc == nil || !eof && pos+loc[0] >= safe {
+				break
+			}
+			entry, indexes, ok := rt.groupIndexes(loc)
+			if !ok && rt.tombstones > 0 {
+				return errTombstoneUnsupported("a stream")
+			}
+			if !ok {
+				return codeErrorf(CodeInternal, "internal error: match found but no capture group matched")
+			}
+			if entry.exclusion != nil {
+				return codeErrorf(CodeTableUnsupported, "pattern '%s' has an exclusion, which cannot be checked against a stream", entry.Pattern)
+			}
+			from := pos
+			start, end := from+indexes[0], from+indexes[1]
+			if start == end {
+				_, width := utf8.DecodeRune(buf[start:])
+				pos = start + max(width, 1)
+				if base+start == lastEnd {
+					continue // An empty match abutting the previous match
+				}
+			} else {
+				pos = end
+			}
+			lastEnd = base + end
+
+			if err := emit(rt.scannedMatch(entry, buf[from:], indexes, base+from)); err != nil {
+				return err
+			}
+		}
+		if eof {
+			return nil
+		}
+		// Keep the text in which a match may yet start.
+		keep := max(pos, safe)
+		buf = buf[:copy(buf, buf[keep:])]
+		base += keep
+		pos = 0
+	}
+}
+
+// scannedMatch builds the Match for an entry that matched text, given the index
+// pairs of its submatches in text and the offset of text in the stream.
+func (rt *RegexpTable[T]) scannedMatch(entry *ValueAndPattern[T], text []byte, indexes []int, offset int) Match[T] {
+	matches := make([]string, len(indexes)/2)
+	loc := make([]int, len(indexes))
+	for i := range matches {
+		if indexes[2*i] < 0 {
+			loc[2*i], loc[2*i+1] = -1, -1
+			continue
+		}
+		matches[i] = string(text[indexes[2*i]:indexes[2*i+1]])
+		loc[2*i], loc[2*i+1] = indexes[2*i]+offset, indexes[2*i+1]+offset
+	}
+	rt.recordHit(entry, matches[0], matches)
+	result := rt.newResult(entry, matches)
+	result.locate(loc, nil)
+	return Match[T]{Input: matches[0], Result: result}
+}
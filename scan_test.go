@@ -0,0 +1,94 @@
+package regexptable
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// scanAll scans input with the table and returns the spans of the matches.
+func scanAll[T any](t *testing.T, table *RegexpTable[T], input string, opts ScanOptions) [][]int {
+	t.Helper()
+	var spans [][]int
+	err := table.ScanReaderWith(iotest.OneByteReader(strings.NewReader(input)), opts, func(m Match[T]) error {
+		if input[m.Result.Start:m.Result.End] != m.Input {
+			t.Errorf("Expected the span [%d, %d) to hold %q", m.Result.Start, m.Result.End, m.Input)
+		}
+		spans = append(spans, []int{m.Result.Start, m.Result.End})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanReaderWith failed: %v", err)
+	}
+	return spans
+}
+
+func TestRegexpTable_ScanReader(t *testing.T) {
+	table := NewRegexpTable[string](true, true) // Scanning ignores the anchoring
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-zé]+`, "word")
+	reference := regexp.MustCompile(`\d+|[a-zé]+`)
+
+	input := strings.Repeat("abc 12 café, 345678 x9 ", 50)
+	want := reference.FindAllStringIndex(input, -1)
+	for _, opts := range []ScanOptions{{}, {BufferSize: 16, MaxMatchLength: 8}, {BufferSize: 7, MaxMatchLength: 6}} {
+		if got := scanAll(t, table, input, opts); !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("Expected the matches of FindAll with %+v, got %v", opts, got)
+		}
+	}
+
+	var values []string
+	var groups []string
+	table2 := NewRegexpTable[string](false, false)
+	table2.AddPattern(`(\w+)=(\d+)`, "assignment")
+	err := table2.ScanReader(strings.NewReader("a=1, bb=22"), func(m Match[string]) error {
+		values = append(values, m.Result.Value)
+		start, end := m.Result.GroupSpan(2)
+		groups = append(groups, "a=1, bb=22"[start:end])
+		return nil
+	})
+	if err != nil || !slices.Equal(values, []string{"assignment", "assignment"}) || !slices.Equal(groups, []string{"1", "22"}) {
+		t.Errorf("Expected two assignments with their groups, got %v %v, %v", values, groups, err)
+	}
+}
+
+func TestRegexpTable_ScanReaderEmptyMatches(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`x*`, "x")
+	input := "axxbxé"
+	want := regexp.MustCompile(`x*`).FindAllStringIndex(input, -1)
+	if got := scanAll(t, table, input, ScanOptions{BufferSize: 4, MaxMatchLength: 2}); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRegexpTable_ScanReaderStops(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`\d`, "digit")
+	stop := errors.New("stop")
+	count := 0
+	err := table.ScanReader(strings.NewReader("1 2 3 4"), func(m Match[string]) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 2 {
+		t.Errorf("Expected scanning to stop after two matches with the emit error, got %d, %v", count, err)
+	}
+
+	err = table.ScanReaderWith(strings.NewReader("1"), ScanOptions{BufferSize: 8, MaxMatchLength: 8}, nil)
+	if ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected an invalid argument error, got %v", err)
+	}
+
+	table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: "no"})
+	err = table.ScanReader(strings.NewReader("no"), func(Match[string]) error { return nil })
+	if ErrorCodeOf(err) != CodeTableUnsupported {
+		t.Errorf("Expected exclusions to be unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package regexptable
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Schedule says when a pattern is active, see AddPatternWithSchedule. A pattern
+// is active within its date range, on its days of the week and within its daily
+// window, all of which are optional; the zero Schedule is always active. For
+// example, a rule for a campaign running on weekday mornings in March:
+//
+//	regexptable.Schedule{
+//	    From:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+//	    Until: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+//	    Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+//	    Start: 9 * time.Hour,
+//	    End:   12 * time.Hour,
+//	}
+type Schedule struct {
+	From     time.Time      // When the pattern becomes active, zero for no start
+	Until    time.Time      // When the pattern stops being active, zero for no end
+	Days     []time.Weekday // The days on which the daily window opens, every day if empty
+	Start    time.Duration  // Time of day at which the daily window opens
+	End      time.Duration  // Time of day at which it closes, the next day if not after Start
+	Location *time.Location // The zone of Days, Start and End, time.Local if nil
+}
+
+// neverMatches is a character class that matches nothing, which prefixes the
+// patterns of dormant entries so that they keep their groups but cannot win.
+const neverMatches = `[^\s\S]`
+
+// Validate reports an error if the schedule cannot be satisfied as written: if
+// a time of day is outside [0, 24h) or the date range is empty.
+func (s Schedule) Validate() error {
+	if s.Start < 0 || s.Start >= 24*time.Hour || s.End < 0 || s.End >= 24*time.Hour {
+		return errors.New("schedule times of day must be at least 0 and less than 24h")
+	}
+	if !s.From.IsZero() && !s.Until.IsZero() && !s.From.Before(s.Until) {
+		return errors.New("schedule must start before it ends")
+	}
+	return nil
+}
+
+// Active reports whether the schedule is active at time t. When the daily window
+// ends no later than it starts, as from 22:00 to 06:00, it runs past midnight,
+// and Days refers to the day it opens. A window whose Start equals its End lasts
+// the whole day.
+func (s Schedule) Active(t time.Time) bool {
+	if !s.From.IsZero() && t.Before(s.From) || !s.Until.IsZero() && !t.Before(s.Until) {
+		return false
+	}
+	if !s.daily() {
+		return true
+	}
+	year, month, day := t.In(s.location()).Date()
+	// A window that includes t opened today or yesterday.
+	for _, opened := range []int{day, day - 1} {
+		start := s.timeOfDay(year, month, opened, s.Start)
+		end := s.timeOfDay(year, month, opened, s.End)
+		if s.End <= s.Start {
+			end = s.timeOfDay(year, month, opened+1, s.End)
+		}
+		if (len(s.Days) == 0 || slices.Contains(s.Days, start.Weekday())) && !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the earliest time after now at which the schedule may change
+// between active and inactive, or the zero time if it never will again.
+func (s Schedule) next(now time.Time) time.Time {
+	if !s.Until.IsZero() && !now.Before(s.Until) {
+		return time.Time{}
+	}
+	var next time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	consider(s.From)
+	consider(s.Until)
+	if s.daily() {
+		year, month, day := now.In(s.location()).Date()
+		for _, offset := range []time.Duration{s.Start, s.End} {
+			consider(s.timeOfDay(year, month, day, offset))
+			consider(s.timeOfDay(year, month, day+1, offset))
+		}
+	}
+	return next
+}
+
+// daily reports whether the schedule has a daily window or days of the week.
+func (s Schedule) daily() bool {
+	return s.Start != s.End || len(s.Days) > 0
+}
+
+// location returns the zone of the schedule's days and times of day.
+func (s Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
+
+// timeOfDay returns the given time of day on the given date, as a wall clock
+// would show it, so that daily windows keep their times across daylight saving
+// changes.
+func (s Schedule) timeOfDay(year int, month time.Month, day int, offset time.Duration) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, int(offset), s.location())
+}
+
+// AddPatternWithSchedule is like AddPattern but the entry is only active when the
+// schedule says so, which suits temporary campaign rules or classifications that
+// apply during maintenance windows, without redeploying the table. Outside its
+// schedule the entry stays in the table, and in listings such as Patterns, but
+// no lookup matches it.
+//
+// The table works out when the next of its schedules changes, so lookups only
+// compare the time with that; the first lookup after it updates the entries and
+// recompiles the table, and so modifies the table, as sweeping expired entries
+// does. It returns an error if the schedule is invalid, see Schedule.Validate.
+func (rt *RegexpTable[T]) AddPatternWithSchedule(pattern string, value T, schedule Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return codeErrorf(CodeInvalidArgument, "pattern '%s': %w", pattern, err)
+	}
+	err := rt.AddPattern(pattern, value)
+	if err != nil {
+		return err
+	}
+	rt.schedule(rt.maplets[len(rt.maplets)-1], schedule)
+	return nil
+}
+
+// AddPatternWithSchedule adds a pattern that is only active when the schedule
+// says so. See RegexpTable.AddPatternWithSchedule.
+func (b *RegexpTableBuilder[T]) AddPatternWithSchedule(pattern string, value T, schedule Schedule) *RegexpTableBuilder[T] {
+	if err := schedule.Validate(); err != nil {
+		b.errs = append(b.errs, codeErrorf(CodeInvalidArgument, "pattern '%s': %w", pattern, err))
+	}
+	b.patterns = append(b.patterns, patternEntry[T]{pattern: pattern, value: value, schedule: &schedule})
+	return b
+}
+
+// schedule gives an entry of the table a schedule, setting whether it is
+// dormant now.
+func (rt *RegexpTable[T]) schedule(entry *ValueAndPattern[T], schedule Schedule) {
+	now := rt.clock()
+	schedule.Days = slices.Clone(schedule.Days)
+	attrs := entry.ownAttrs()
+	attrs.schedule = &schedule
+	attrs.dormant = !schedule.Active(now)
+	if next := schedule.next(now); !next.IsZero() && (rt.nextTransition.IsZero() || next.Before(rt.nextTransition)) {
+		rt.nextTransition = next
+	}
+	rt.needsRecompile = true
+}
+
+// hasScheduleTransition reports whether a schedule may have changed since the
+// entries were last updated.
+func (rt *RegexpTable[T]) hasScheduleTransition() bool {
+	return !rt.nextTransition.IsZero() && !rt.clock().Before(rt.nextTransition)
+}
+
+// applySchedules updates whether each scheduled entry is dormant, scheduling a
+// recompilation if any changed, and works out the next transition.
+func (rt *RegexpTable[T]) applySchedules() {
+	now := rt.clock()
+	rt.nextTransition = time.Time{}
+	for _, entry := range rt.maplets {
+		if entry.schedule == nil {
+			continue
+		}
+		if dormant := !entry.schedule.Active(now); dormant != entry.dormant {
+			entry.ownAttrs().dormant = dormant
+			entry.compiledPattern = nil
+			rt.unionEntries = nil // The entry's branch changes, so the union cannot be extended
+			rt.needsRecompile = true
+		}
+		if next := entry.schedule.next(now); !next.IsZero() && (rt.nextTransition.IsZero() || next.Before(rt.nextTransition)) {
+			rt.nextTransition = next
+		}
+	}
+}
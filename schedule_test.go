@@ -0,0 +1,153 @@
+package regexptable
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Active(t *testing.T) {
+	march := Schedule{
+		From:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Days:     []time.Weekday{time.Monday, time.Friday},
+		Start:    9 * time.Hour,
+		End:      12 * time.Hour,
+		Location: time.UTC,
+	}
+	nights := Schedule{Start: 22 * time.Hour, End: 6 * time.Hour, Days: []time.Weekday{time.Friday}, Location: time.UTC}
+	tests := []struct {
+		schedule Schedule
+		at       time.Time
+		active   bool
+	}{
+		{Schedule{}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{march, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), true},    // Monday
+		{march, time.Date(2026, 3, 2, 11, 59, 0, 0, time.UTC), true},  // Monday
+		{march, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), false},  // Monday, closed
+		{march, time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC), false},  // Tuesday
+		{march, time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC), false}, // Friday before From
+		{march, time.Date(2026, 4, 3, 10, 0, 0, 0, time.UTC), false},  // Friday after Until
+		{nights, time.Date(2026, 3, 6, 23, 0, 0, 0, time.UTC), true},  // Friday night
+		{nights, time.Date(2026, 3, 7, 5, 0, 0, 0, time.UTC), true},   // Into Saturday
+		{nights, time.Date(2026, 3, 7, 23, 0, 0, 0, time.UTC), false}, // Saturday night
+		{nights, time.Date(2026, 3, 6, 5, 0, 0, 0, time.UTC), false},  // Friday morning
+		{Schedule{Days: []time.Weekday{time.Sunday}}, time.Date(2026, 3, 8, 12, 0, 0, 0, time.Local), true},
+	}
+	for _, test := range tests {
+		if active := test.schedule.Active(test.at); active != test.active {
+			t.Errorf("Expected Active(%v) to be %v for %+v", test.at, test.active, test.schedule)
+		}
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	invalid := []Schedule{
+		{Start: -time.Hour},
+		{End: 24 * time.Hour},
+		{From: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, schedule := range invalid {
+		if schedule.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", schedule)
+		}
+	}
+	if err := (Schedule{Start: 22 * time.Hour, End: 6 * time.Hour}).Validate(); err != nil {
+		t.Errorf("Expected a window past midnight to be valid, got %v", err)
+	}
+}
+
+func TestRegexpTable_AddPatternWithSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC) // A Monday
+	table := NewRegexpTable[string](true, true)
+	table.now = func() time.Time { return now }
+	mornings := Schedule{Start: 9 * time.Hour, End: 12 * time.Hour, Location: time.UTC}
+	if err := table.AddPatternWithSchedule(`(\w+)-promo`, "campaign", mornings); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	table.AddPattern(`\w+-\w+`, "pair")
+
+	lookup := func() string {
+		value, matches, err := table.Lookup("spring-promo")
+		if err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+		if value == "campaign" && matches[1] != "spring" {
+			t.Errorf("Expected the campaign's group, got %v", matches)
+		}
+		return value
+	}
+	if value := lookup(); value != "pair" {
+		t.Errorf("Expected the campaign to be dormant before 9:00, got %s", value)
+	}
+	if len(table.Patterns()) != 2 {
+		t.Errorf("Expected the dormant pattern to stay in the table, got %v", table.Patterns())
+	}
+
+	now = now.Add(time.Hour)
+	if value := lookup(); value != "campaign" {
+		t.Errorf("Expected the campaign to be active at 9:00, got %s", value)
+	}
+	if result, err := table.LookupResult("spring-promo"); err != nil || result.Fields() == nil || result.Groups[1] != "spring" {
+		t.Errorf("Expected the campaign's result, got %v, %v", result, err)
+	}
+
+	now = now.Add(3 * time.Hour)
+	if value := lookup(); value != "pair" {
+		t.Errorf("Expected the campaign to be dormant at 12:00, got %s", value)
+	}
+	now = now.Add(21 * time.Hour)
+	if value := lookup(); value != "campaign" {
+		t.Errorf("Expected the campaign to be active again the next morning, got %s", value)
+	}
+
+	if err := table.AddPatternWithSchedule(`x`, "x", Schedule{Start: 25 * time.Hour}); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected an invalid schedule to be rejected, got %v", err)
+	}
+}
+
+func TestRegexpTable_ScheduleOtherPaths(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	configure := map[string]func(*RegexpTableBuilder[string]) *RegexpTableBuilder[string]{
+		"two-phase": func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithTwoPhaseLookup(true) },
+		"dispatch":  func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithPrefixDispatch(true) },
+		"memo":      func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithMemoization(10, false) },
+		"factoring": func(b *RegexpTableBuilder[string]) *RegexpTableBuilder[string] { return b.WithPrefixFactoring(true) },
+	}
+	for name, with := range configure {
+		table := with(NewRegexpTableBuilder[string]().
+			AddPatternWithSchedule(`sale-\d+`, "sale", Schedule{From: now.Add(time.Hour)}).
+			AddPattern(`sale-\w+`, "other")).
+			MustBuild(true, true)
+		table.now = func() time.Time { return now }
+		table.applySchedules() // The builder scheduled the entries by the real clock
+
+		if value, _, _ := table.Lookup("sale-42"); value != "other" {
+			t.Errorf("%s: expected the sale to be dormant, got %s", name, value)
+		}
+		now = now.Add(time.Hour)
+		if value, _, _ := table.Lookup("sale-42"); value != "sale" {
+			t.Errorf("%s: expected the sale to be active, got %s", name, value)
+		}
+		if results, err := table.LookupAll("sale-42"); err != nil || len(results) != 2 {
+			t.Errorf("%s: expected both patterns to match, got %v, %v", name, results, err)
+		}
+		now = now.Add(-time.Hour)
+	}
+}
+
+func TestBuilder_AddPatternWithSchedule(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		AddPatternWithSchedule(`x`, "x", Schedule{End: -time.Minute}).
+		Build(true, true)
+	if ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Expected an invalid schedule to fail the build, got %v", err)
+	}
+
+	table := NewRegexpTableBuilder[string]().
+		AddPatternWithSchedule(`x`, "later", Schedule{From: time.Now().Add(time.Hour)}).
+		AddPattern(`x`, "now").
+		MustBuild(true, true)
+	if value, _, _ := table.Lookup("x"); value != "now" {
+		t.Errorf("Expected the scheduled pattern to be dormant, got %s", value)
+	}
+}
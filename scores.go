@@ -0,0 +1,89 @@
+package regexptable
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// AddPatternWithConfidence adds a pattern together with the confidence, between 0
+// and 1, with which a match of the pattern implies its value. Confidence does not
+// affect which pattern wins a lookup; it weights the pattern's value in
+// LookupScored. Patterns added without a confidence have a confidence of 1. Build
+// reports an error if the confidence is out of range.
+func (b *RegexpTableBuilder[T]) AddPatternWithConfidence(pattern string, value T, confidence float64) *RegexpTableBuilder[T] {
+	if !(confidence > 0 && confidence <= 1) {
+		b.errs = append(b.errs, fmt.Errorf("pattern '%s' has confidence %v, which is not in (0, 1]", pattern, confidence))
+		return b
+	}
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern:    pattern,
+		value:      value,
+		confidence: confidence,
+	})
+	return b
+}
+
+// score returns the entry's confidence, see AddPatternWithConfidence.
+func (vp *ValueAndPattern[T]) score() float64 {
+	if vp.confidence == 0 {
+		return 1
+	}
+	return vp.confidence
+}
+
+// ScoredValue is a value found by LookupScored with its combined score.
+type ScoredValue[T any] struct {
+	Value   T
+	Score   float64 // The combined confidence of the patterns giving the value
+	Indexes []int   // The insertion indexes of those patterns, as in Result.Index
+}
+
+// LookupScored classifies the input with every value of every pattern that
+// matches it, as found by LookupAll, rather than with the winner's value alone,
+// which suits multi-label classification. Each value is scored by combining the
+// confidences of the patterns giving it as independent evidence: a value given
+// by patterns with confidences c1, c2, ... scores 1 - (1-c1)(1-c2)..., so that
+// agreeing patterns raise the score without it exceeding 1. Values scoring less
+// than threshold are left out, and the rest are returned by descending score, in
+// the order the table tries the patterns among equal scores.
+//
+// It returns ErrNoMatch if no pattern matches, and an empty slice if patterns
+// match but every value scores below the threshold. Values are compared with ==,
+// hence the comparable constraint, so this is a function rather than a method.
+func LookupScored[T comparable](rt *RegexpTable[T], input string, threshold float64) ([]ScoredValue[T], error) {
+	results, _, err := rt.lookupAll(input)
+	if err != nil {
+		return nil, err
+	}
+	var scored []ScoredValue[T]
+	var misses []float64 // The product of 1 - confidence for each scored value
+	positions := make(map[T]int)
+	for _, result := range results {
+		for _, value := range result.Values {
+			i, seen := positions[value]
+			if !seen {
+				i = len(scored)
+				positions[value] = i
+				scored = append(scored, ScoredValue[T]{Value: value})
+				misses = append(misses, 1)
+			}
+			if !slices.Contains(scored[i].Indexes, result.Index) {
+				scored[i].Indexes = append(scored[i].Indexes, result.Index)
+				misses[i] *= 1 - result.Confidence
+			}
+		}
+	}
+
+	kept := make([]ScoredValue[T], 0, len(scored))
+	for i, s := range scored {
+		s.Score = 1 - misses[i]
+		if s.Score >= threshold {
+			kept = append(kept, s)
+		}
+	}
+	slices.SortStableFunc(kept, func(x, y ScoredValue[T]) int {
+		return cmp.Compare(y.Score, x.Score)
+	})
+	return kept, nil
+}
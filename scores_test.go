@@ -0,0 +1,110 @@
+package regexptable
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupScored(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPatternWithConfidence(`.*\bidiot\b.*`, "insult", 0.6).
+		AddPatternWithConfidence(`.*\bstupid\b.*`, "insult", 0.5).
+		AddPatternWithConfidence(`.*\bbuy now\b.*`, "spam", 0.3).
+		AddPattern(`.*https?://.*`, "link").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	scores, err := LookupScored(table, "buy now, stupid idiot: http://x", 0)
+	if err != nil {
+		t.Fatalf("LookupScored failed: %v", err)
+	}
+	want := []struct {
+		value   string
+		score   float64
+		indexes []int
+	}{
+		{"link", 1, []int{3}},
+		{"insult", 0.8, []int{0, 1}},
+		{"spam", 0.3, []int{2}},
+	}
+	if len(scores) != len(want) {
+		t.Fatalf("Expected %d scores, got %+v", len(want), scores)
+	}
+	for i, w := range want {
+		got := scores[i]
+		if got.Value != w.value || math.Abs(got.Score-w.score) > 1e-9 || !slices.Equal(got.Indexes, w.indexes) {
+			t.Errorf("Expected %s scoring %v from %v, got %+v", w.value, w.score, w.indexes, got)
+		}
+	}
+
+	if scores, err := LookupScored(table, "buy now", 0.5); err != nil || len(scores) != 0 {
+		t.Errorf("Expected the threshold to drop spam, got %+v, %v", scores, err)
+	}
+	if _, err := LookupScored(table, "hello", 0); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+
+	result, _ := table.LookupResult("you idiot")
+	if result.Confidence != 0.6 {
+		t.Errorf("Expected the result to carry confidence 0.6, got %v", result.Confidence)
+	}
+}
+
+func TestLookupScored_Values(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPatternValues(`\d+`, "number", "number", "digits")
+	scores, err := LookupScored(table, "42", 0)
+	if err != nil || len(scores) != 2 || scores[0].Value != "number" || !slices.Equal(scores[0].Indexes, []int{0}) {
+		t.Errorf("Expected a pattern to count once per value, got %+v, %v", scores, err)
+	}
+}
+
+func TestRegexpTableBuilder_AddPatternWithConfidenceRange(t *testing.T) {
+	for _, confidence := range []float64{0, -0.5, 1.5, math.NaN()} {
+		_, err := NewRegexpTableBuilder[string]().AddPatternWithConfidence(`a`, "a", confidence).Build(true, true)
+		if err == nil || !strings.Contains(err.Error(), "confidence") {
+			t.Errorf("Expected confidence %v to be rejected, got %v", confidence, err)
+		}
+	}
+}
+
+func TestLoader_Confidence(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(`{"version": 1, "anchorStart": true, "anchorEnd": true, "entries": [
+		{"pattern": "a+", "value": "a", "confidence": 0.25}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if scores, err := LookupScored(table, "aa", 0); err != nil || scores[0].Score != 0.25 {
+		t.Errorf("Expected the spec's confidence, got %+v, %v", scores, err)
+	}
+
+	// A confidence of 0 is the same as none, as the schema says.
+	spec, err = loader.ParseSpec(strings.NewReader(`{"version": 1, "anchorStart": true, "entries": [{"pattern": "a", "value": "a", "confidence": 0}]}`))
+	if err != nil {
+		t.Fatalf("Expected a confidence of 0 to be accepted, got %v", err)
+	}
+	if table, err := loader.Build(spec); err != nil {
+		t.Errorf("Build failed: %v", err)
+	} else if scores, err := LookupScored(table, "a", 0); err != nil || scores[0].Score != 1 {
+		t.Errorf("Expected a confidence of 0 to count as certain, got %+v, %v", scores, err)
+	}
+
+	if _, err := loader.ParseSpec(strings.NewReader(`{"version": 1, "entries": [{"pattern": "a", "value": "a", "confidence": 2}]}`)); err == nil {
+		t.Error("Expected a confidence above 1 to be rejected")
+	}
+	spec, err = loader.ParseCSV(strings.NewReader("pattern,value,confidence\nb+,b,0.5\n"))
+	if err != nil || spec.Entries[0].Confidence != 0.5 {
+		t.Errorf("Expected a confidence column, got %+v, %v", spec, err)
+	}
+}
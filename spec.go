@@ -0,0 +1,108 @@
+package regexptable
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SpecVersion is the version of the specification format written by this package.
+const SpecVersion = 1
+
+// SpecSchema is the JSON Schema describing the specification format. Teams that
+// exchange rule files can validate them against this contract with any JSON
+// Schema tooling.
+//
+//go:embed spec.schema.json
+var SpecSchema string
+
+// Spec is a versioned, serialisable description of a regexp table. Values are
+// kept as raw JSON and decoded into the table's value type by a Loader.
+type Spec struct {
+	Version     int         `json:"version"`
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Engine      string      `json:"engine,omitempty"`  // The engine the patterns are written for, see RegexpTable.EngineName
+	Include     []string    `json:"include,omitempty"` // Specs whose entries this one extends, see Loader.ParseSpecFile
+	AnchorStart bool        `json:"anchorStart"`
+	AnchorEnd   bool        `json:"anchorEnd"`
+	Entries     []SpecEntry `json:"entries"`
+}
+
+// SpecEntry describes a single pattern of a Spec.
+type SpecEntry struct {
+	Pattern    string               `json:"pattern"`
+	Value      json.RawMessage      `json:"value"`
+	Flags      string               `json:"flags,omitempty"`      // Any of i, m, s and U
+	Priority   int                  `json:"priority,omitempty"`   // Higher priorities take precedence
+	Confidence float64              `json:"confidence,omitempty"` // Between 0 and 1, 0 meaning 1, see RegexpTableBuilder.AddPatternWithConfidence
+	Tags       []string             `json:"tags,omitempty"`
+	Groups     map[string]GroupType `json:"groups,omitempty"` // Types of named groups, see RegexpTableBuilder.AddTypedPattern
+	Doc        string               `json:"doc,omitempty"`
+	Tests      []SpecTest           `json:"tests,omitempty"`
+}
+
+// SpecTest is an example input for a SpecEntry. By default the input must be
+// classified by the entry; with Reject it must not be.
+type SpecTest struct {
+	Input  string `json:"input"`
+	Reject bool   `json:"reject,omitempty"`
+}
+
+// specFlags lists the flags a SpecEntry may carry.
+const specFlags = "imsU"
+
+// Validate checks the spec against the rules of the schema that cannot be
+// expressed by the Go types alone and returns all the problems found.
+func (s *Spec) Validate() []error {
+	var problems []error
+	if s.Version != SpecVersion {
+		problems = append(problems, fmt.Errorf("unsupported spec version %d (expected %d)", s.Version, SpecVersion))
+	}
+	for i, entry := range s.Entries {
+		if entry.Pattern == "" {
+			problems = append(problems, fmt.Errorf("entry %d: missing pattern", i))
+		}
+		if len(entry.Value) == 0 {
+			problems = append(problems, fmt.Errorf("entry %d (pattern: %s): missing value", i, entry.Pattern))
+		}
+		if entry.Confidence < 0 || entry.Confidence > 1 {
+			problems = append(problems, fmt.Errorf("entry %d (pattern: %s): confidence %v is not between 0 and 1", i, entry.Pattern, entry.Confidence))
+		}
+		for _, name := range slices.Sorted(maps.Keys(entry.Groups)) {
+			if err := entry.Groups[name].Validate(); err != nil {
+				problems = append(problems, fmt.Errorf("entry %d (pattern: %s), group %s: %w", i, entry.Pattern, name, err))
+			}
+		}
+		for _, flag := range entry.Flags {
+			if !strings.ContainsRune(specFlags, flag) {
+				problems = append(problems, fmt.Errorf("entry %d (pattern: %s): unknown flag %q", i, entry.Pattern, flag))
+			}
+		}
+	}
+	return problems
+}
+
+// Examples returns up to n strings matched by the entry's pattern, taking its flags
+// into account. See the package-level Examples.
+func (e *SpecEntry) Examples(n int) []string {
+	return Examples(e.flaggedPattern(), n)
+}
+
+// flaggedPattern applies the entry's flags to its pattern using the (?flags:...)
+// syntax shared by Go and RE2. Unknown flags are ignored.
+func (e *SpecEntry) flaggedPattern() string {
+	var flags strings.Builder
+	for _, flag := range e.Flags {
+		if strings.ContainsRune(specFlags, flag) && !strings.ContainsRune(flags.String(), flag) {
+			flags.WriteRune(flag)
+		}
+	}
+	if flags.Len() == 0 {
+		return e.Pattern
+	}
+	return "(?" + flags.String() + ":" + e.Pattern + ")"
+}
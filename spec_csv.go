@@ -0,0 +1,148 @@
+package regexptable
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tableColumns lists the columns a CSV or TSV rule file may have.
+var tableColumns = []string{"pattern", "value", "priority", "confidence", "flags", "tags", "doc"}
+
+// tableRow is a record of a CSV or TSV rule file with the line it started on.
+type tableRow struct {
+	line   int
+	fields []string
+}
+
+// ParseCSV reads a rule list in comma-separated values format, as exported by
+// spreadsheets, and returns it as a Spec. The first record is a header naming the
+// columns, in any order: pattern and value are required, while priority,
+// confidence, flags, tags (separated by spaces) and doc are optional. Lines
+// starting with # are comments, so a first field that begins with # must be
+// quoted, as in "#[0-9a-f]{6}". Values are read as JSON strings, which suits the
+// StringValue and EnumValue decoders. CSV files carry no anchoring, so the spec is
+// unanchored until AnchorStart and AnchorEnd are set. In strict mode unknown
+// columns and malformed priorities and confidences are rejected; in lenient mode
+// they are ignored.
+func (l *Loader[T]) ParseCSV(r io.Reader) (*Spec, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1 // Short rows are reported with better messages below
+
+	var rows []tableRow
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, codeErrorf(CodeInvalidSpec, "failed to parse CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, tableRow{line: line, fields: fields})
+	}
+	return l.specFromRows(rows)
+}
+
+// ParseTSV is like ParseCSV for tab-separated values. Fields are split on tabs and
+// never quoted, so patterns can contain quotes freely; use \t in a pattern to
+// match a tab. Since fields cannot be quoted, a pattern in the first column that
+// begins with # must escape it as \#, which matches the same text, or the line
+// is taken for a comment.
+func (l *Loader[T]) ParseTSV(r io.Reader) (*Spec, error) {
+	reader := bufio.NewReader(r)
+	var rows []tableRow
+	for line := 1; ; line++ {
+		text, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, codeErrorf(CodeInvalidSpec, "failed to parse TSV: %w", err)
+		}
+		text = strings.TrimRight(text, "\r\n")
+		if text != "" && !strings.HasPrefix(text, "#") {
+			rows = append(rows, tableRow{line: line, fields: strings.Split(text, "\t")})
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return l.specFromRows(rows)
+}
+
+// specFromRows turns the header and rows of a CSV or TSV rule file into a Spec.
+func (l *Loader[T]) specFromRows(rows []tableRow) (*Spec, error) {
+	if len(rows) == 0 {
+		return nil, codeErrorf(CodeInvalidSpec, "invalid rule table: missing header")
+	}
+
+	var problems []error
+	columns := make(map[string]int)
+	for i, name := range rows[0].fields {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(tableColumns, name) {
+			if l.mode == LoadStrict {
+				problems = append(problems, fmt.Errorf("line %d: unknown column %q", rows[0].line, name))
+			}
+			continue
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"pattern", "value"} {
+		if _, ok := columns[required]; !ok {
+			problems = append(problems, fmt.Errorf("line %d: missing %s column", rows[0].line, required))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, codeErrorf(CodeInvalidSpec, "invalid rule table: %w", errors.Join(problems...))
+	}
+
+	spec := &Spec{Version: SpecVersion}
+	for _, row := range rows[1:] {
+		cell := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(row.fields) {
+				return ""
+			}
+			return row.fields[i]
+		}
+
+		entry := SpecEntry{
+			Pattern: cell("pattern"),
+			Flags:   strings.TrimSpace(cell("flags")),
+			Tags:    strings.Fields(cell("tags")),
+			Doc:     cell("doc"),
+		}
+		if value := cell("value"); value != "" {
+			entry.Value, _ = json.Marshal(value) // Marshalling a string cannot fail
+		}
+		if priority := strings.TrimSpace(cell("priority")); priority != "" {
+			n, err := strconv.Atoi(priority)
+			if err != nil && l.mode == LoadStrict {
+				problems = append(problems, fmt.Errorf("line %d: invalid priority %q", row.line, priority))
+			}
+			entry.Priority = n
+		}
+		if confidence := strings.TrimSpace(cell("confidence")); confidence != "" {
+			c, err := strconv.ParseFloat(confidence, 64)
+			if err != nil && l.mode == LoadStrict {
+				problems = append(problems, fmt.Errorf("line %d: invalid confidence %q", row.line, confidence))
+			}
+			entry.Confidence = c
+		}
+		spec.Entries = append(spec.Entries, entry)
+	}
+	if len(problems) > 0 {
+		return nil, codeErrorf(CodeInvalidSpec, "invalid rule table: %w", errors.Join(problems...))
+	}
+
+	if err := l.checkSpec(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
@@ -0,0 +1,127 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoader_ParseCSV(t *testing.T) {
+	input := `# Keywords beat identifiers
+pattern,value,priority,flags,tags,doc
+"if|else",keyword,10,,control flow,Control keywords
+[a-z]+,identifier,,i,,
+"""[^""]*""",string,5,,,"Double-quoted, no escapes"
+`
+	loader := NewLoader[string](LoadStrict).WithValueDecoder(StringValue)
+	spec, err := loader.ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(spec.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(spec.Entries))
+	}
+	first := spec.Entries[0]
+	if first.Pattern != "if|else" || first.Priority != 10 || first.Doc != "Control keywords" || len(first.Tags) != 2 {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	if pattern := spec.Entries[2].Pattern; pattern != `"[^"]*"` {
+		t.Errorf("Expected the quoted pattern to be unescaped, got %q", pattern)
+	}
+
+	spec.AnchorStart, spec.AnchorEnd = true, true
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	testCases := []struct{ input, expected string }{
+		{"if", "keyword"},
+		{"IFFY", "identifier"},
+		{`"hi"`, "string"},
+	}
+	for _, tc := range testCases {
+		if value, _, _ := table.TryLookup(tc.input); value != tc.expected {
+			t.Errorf("Lookup(%q): expected %q, got %q", tc.input, tc.expected, value)
+		}
+	}
+}
+
+func TestLoader_ParseTSV(t *testing.T) {
+	input := "value\tpattern\n" +
+		"1\t\"[^\"]*\"\n" +
+		"\n" +
+		"2\t\\d+\r\n"
+	loader := NewLoader[int](LoadStrict).WithValueDecoder(IntValue)
+	spec, err := loader.ParseTSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTSV failed: %v", err)
+	}
+	spec.AnchorStart, spec.AnchorEnd = true, true
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if value, _, _ := table.TryLookup(`"quoted"`); value != 1 {
+		t.Errorf("Expected 1, got %d", value)
+	}
+	if value, _, _ := table.TryLookup("42"); value != 2 {
+		t.Errorf("Expected 2, got %d", value)
+	}
+}
+
+func TestLoader_ParseHashPatterns(t *testing.T) {
+	loader := NewLoader[string](LoadStrict).WithValueDecoder(StringValue)
+	csvSpec, err := loader.ParseCSV(strings.NewReader("pattern,value\n# Colours\n\"#[0-9a-f]{6}\",colour\n"))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	tsvSpec, err := loader.ParseTSV(strings.NewReader("pattern\tvalue\n# Colours\n\\#[0-9a-f]{6}\tcolour\n"))
+	if err != nil {
+		t.Fatalf("ParseTSV failed: %v", err)
+	}
+	for name, spec := range map[string]*Spec{"CSV": csvSpec, "TSV": tsvSpec} {
+		if len(spec.Entries) != 1 {
+			t.Fatalf("%s: expected the comment to be skipped and the pattern kept, got %+v", name, spec.Entries)
+		}
+		spec.AnchorStart, spec.AnchorEnd = true, true
+		table, err := loader.Build(spec)
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", name, err)
+		}
+		if value, _, _ := table.TryLookup("#a0b1c2"); value != "colour" {
+			t.Errorf("%s: expected colour, got %q", name, value)
+		}
+	}
+}
+
+func TestLoader_ParseCSVErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mode    LoadMode
+		input   string
+		problem string
+	}{
+		{"Empty", LoadStrict, "", "missing header"},
+		{"MissingValueColumn", LoadStrict, "pattern\na\n", "missing value column"},
+		{"UnknownColumn", LoadStrict, "pattern,value,colour\na,x,red\n", `line 1: unknown column "colour"`},
+		{"BadPriority", LoadStrict, "pattern,value,priority\na,x,high\n", `line 2: invalid priority "high"`},
+		{"MissingValue", LoadStrict, "pattern,value\na,\n", "missing value"},
+		{"UnknownFlag", LoadStrict, "pattern,value,flags\na,x,q\n", "unknown flag"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLoader[string](tc.mode).ParseCSV(strings.NewReader(tc.input))
+			if err == nil || !strings.Contains(err.Error(), tc.problem) {
+				t.Errorf("Expected an error containing %q, got %v", tc.problem, err)
+			}
+		})
+	}
+
+	// Lenient mode ignores what it does not understand and skips incomplete rows.
+	spec, err := NewLoader[string](LoadLenient).ParseCSV(strings.NewReader("pattern,value,colour,priority\na,x,red,high\nb,,,\n"))
+	if err != nil {
+		t.Fatalf("Lenient ParseCSV failed: %v", err)
+	}
+	if len(spec.Entries) != 1 || spec.Entries[0].Pattern != "a" {
+		t.Errorf("Expected only the complete row, got %+v", spec.Entries)
+	}
+}
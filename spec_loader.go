@@ -0,0 +1,221 @@
+package regexptable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// LoadMode selects how strictly a Loader interprets a spec.
+type LoadMode int
+
+const (
+	// LoadStrict rejects unknown fields, unsupported versions, unknown flags,
+	// entries without a pattern or value and specs written for another engine.
+	LoadStrict LoadMode = iota
+
+	// LoadLenient accepts whatever it can make sense of: unknown fields and flags
+	// are ignored, a missing version is taken to be the current one and entries
+	// without a pattern or value are skipped.
+	LoadLenient
+)
+
+// Loader reads specs and turns them into regexp tables with values of type T.
+type Loader[T any] struct {
+	mode      LoadMode
+	engine    RegexpEngine
+	decoder   ValueDecoder[T]
+	strictRE2 bool // Whether patterns must be in strict RE2 syntax, see WithStrictRE2
+}
+
+// NewLoader creates a Loader with the given mode that builds tables with the
+// standard regexp engine and decodes values with JSONValue.
+func NewLoader[T any](mode LoadMode) *Loader[T] {
+	return &Loader[T]{
+		mode:    mode,
+		engine:  NewStandardRegexpEngine(),
+		decoder: JSONValue[T],
+	}
+}
+
+// WithEngine sets the regexp engine used for the tables the loader builds.
+func (l *Loader[T]) WithEngine(engine RegexpEngine) *Loader[T] {
+	l.engine = engine
+	return l
+}
+
+// WithValueDecoder sets how the loader turns the values of spec entries into T.
+func (l *Loader[T]) WithValueDecoder(decoder ValueDecoder[T]) *Loader[T] {
+	l.decoder = decoder
+	return l
+}
+
+// WithStrictRE2 makes the loader reject specs whose patterns are not in strict RE2
+// syntax (see CheckRE2Syntax), in either mode. Use it for rule files that are
+// shared with systems built on RE2, so that a rule using a Go extension is caught
+// before it reaches them.
+func (l *Loader[T]) WithStrictRE2(enabled bool) *Loader[T] {
+	l.strictRE2 = enabled
+	return l
+}
+
+// ParseSpec reads a JSON spec from r, validating it according to the loader's mode.
+// A spec that includes others must be read with ParseSpecFile or ParseSpecFS
+// instead, which can find them.
+func (l *Loader[T]) ParseSpec(r io.Reader) (*Spec, error) {
+	spec, err := l.decodeSpec(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Include) > 0 {
+		return nil, codeErrorf(CodeInvalidSpec, "spec includes %q, which can only be resolved when reading it from a file", spec.Include)
+	}
+	if err := l.checkSpec(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// decodeSpec reads a JSON spec from r without validating it.
+func (l *Loader[T]) decodeSpec(r io.Reader) (*Spec, error) {
+	decoder := json.NewDecoder(r)
+	if l.mode == LoadStrict {
+		decoder.DisallowUnknownFields()
+	}
+
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, codeErrorf(CodeInvalidSpec, "failed to parse spec: %w", err)
+	}
+	if l.mode == LoadStrict && decoder.More() {
+		return nil, codeErrorf(CodeInvalidSpec, "failed to parse spec: unexpected data after the spec")
+	}
+	return &spec, nil
+}
+
+// checkSpec validates a parsed spec according to the loader's mode. In lenient mode
+// it also fills in a missing version and drops incomplete entries.
+func (l *Loader[T]) checkSpec(spec *Spec) error {
+	if l.mode == LoadLenient {
+		if spec.Version == 0 {
+			spec.Version = SpecVersion
+		}
+		spec.Entries = slices.DeleteFunc(spec.Entries, func(entry SpecEntry) bool {
+			return entry.Pattern == "" || len(entry.Value) == 0
+		})
+	}
+
+	problems := spec.Validate()
+	if l.mode == LoadLenient {
+		// Unknown flags are ignored when the patterns are built.
+		problems = nil
+		if spec.Version > SpecVersion {
+			problems = append(problems, fmt.Errorf("unsupported spec version %d (expected %d)", spec.Version, SpecVersion))
+		}
+	}
+	if l.mode == LoadStrict && spec.Engine != "" && spec.Engine != engineName(l.engine) {
+		problems = append(problems, codeErrorf(CodeEngineUnsupported, "spec is written for the %s engine, not %s", spec.Engine, engineName(l.engine)))
+	}
+	if l.strictRE2 {
+		problems = append(problems, spec.CheckRE2()...)
+	}
+	if len(problems) > 0 {
+		return codeErrorf(CodeInvalidSpec, "invalid spec: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// Builder converts a spec into a RegexpTableBuilder. Entries keep their priorities
+// (see RegexpTableBuilder.AddPatternWithPriority), so the table is ordered by
+// descending priority, preserving the spec order among equal priorities, and the
+// values are decoded into T by the loader's ValueDecoder.
+func (l *Loader[T]) Builder(spec *Spec) (*RegexpTableBuilder[T], error) {
+	builder := NewRegexpTableBuilderWithEngine[T](l.engine)
+	for _, entry := range spec.Entries {
+		value, err := l.decoder(entry.Value)
+		if err != nil {
+			return nil, codeErrorf(CodeInvalidSpec, "invalid value for pattern '%s': %w", entry.Pattern, err)
+		}
+		builder.AddPatternWithPriority(entry.flaggedPattern(), value, entry.Priority)
+		builder.patterns[len(builder.patterns)-1].tags = slices.Clone(entry.Tags)
+		builder.patterns[len(builder.patterns)-1].doc = entry.Doc
+		if entry.Confidence > 0 && entry.Confidence <= 1 { // Lenient loaders ignore others
+			builder.patterns[len(builder.patterns)-1].confidence = entry.Confidence
+		}
+		types := maps.Clone(entry.Groups)
+		maps.DeleteFunc(types, func(_ string, groupType GroupType) bool {
+			return groupType.Validate() != nil // Lenient loaders ignore unknown types
+		})
+		builder.setGroupTypes(len(builder.patterns)-1, types)
+	}
+	return builder, nil
+}
+
+// Build converts a spec into a compiled RegexpTable using the spec's anchoring.
+func (l *Loader[T]) Build(spec *Spec) (*RegexpTable[T], error) {
+	builder, err := l.Builder(spec)
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(spec.AnchorStart, spec.AnchorEnd)
+}
+
+// BuildTiered converts a spec into a chain of tables, one per priority, using the
+// spec's anchoring. See RegexpTableBuilder.BuildTiered.
+func (l *Loader[T]) BuildTiered(spec *Spec) (*TableChain[T], error) {
+	builder, err := l.Builder(spec)
+	if err != nil {
+		return nil, err
+	}
+	return builder.BuildTiered(spec.AnchorStart, spec.AnchorEnd)
+}
+
+// Load reads a JSON spec from r and builds the table it describes.
+func (l *Loader[T]) Load(r io.Reader) (*RegexpTable[T], error) {
+	spec, err := l.ParseSpec(r)
+	if err != nil {
+		return nil, err
+	}
+	return l.Build(spec)
+}
+
+// LoadBytes is a convenience wrapper around Load for in-memory specs.
+func (l *Loader[T]) LoadBytes(data []byte) (*RegexpTable[T], error) {
+	return l.Load(bytes.NewReader(data))
+}
+
+// CheckTests runs the example inputs of every spec entry against a table built
+// from the spec by this loader and returns a description of each failure. The
+// inputs are looked up with LookupResult, so they go through the table's
+// normalizers and input length limit as any other input would.
+func (l *Loader[T]) CheckTests(spec *Spec, table *RegexpTable[T]) []error {
+	var failures []error
+	if err := table.ensureCompiled(); err != nil {
+		return []error{err}
+	}
+
+	// The insertion index of the winner is its position in the spec, whatever order
+	// the table keeps the entries in.
+	for i, entry := range spec.Entries {
+		for _, test := range entry.Tests {
+			result, err := table.LookupResult(test.Input)
+			matched := err == nil && result.Index == i
+			if err == nil {
+				result.Release()
+			}
+			switch {
+			case matched && test.Reject:
+				failures = append(failures, fmt.Errorf("pattern '%s': input %q should not be classified by this entry", entry.Pattern, test.Input))
+			case !matched && !test.Reject && err != nil && !errors.Is(err, ErrNoMatch):
+				failures = append(failures, fmt.Errorf("pattern '%s': input %q should be classified by this entry: %w", entry.Pattern, test.Input, err))
+			case !matched && !test.Reject:
+				failures = append(failures, fmt.Errorf("pattern '%s': input %q should be classified by this entry", entry.Pattern, test.Input))
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,297 @@
+package regexptable
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+const testSpec = `{
+	"version": 1,
+	"name": "tokens",
+	"anchorStart": true,
+	"anchorEnd": true,
+	"entries": [
+		{"pattern": "[a-z]+", "value": "word", "tests": [{"input": "world"}, {"input": "if", "reject": true}]},
+		{"pattern": "if|else", "value": "keyword", "priority": 10, "tests": [{"input": "if"}]},
+		{"pattern": "hello", "value": "greeting", "flags": "i", "priority": 10, "tests": [{"input": "HELLO"}]},
+		{"pattern": "\\d+", "value": "number", "tags": ["numeric"], "doc": "Decimal integers."}
+	]
+}`
+
+func TestLoader_Load(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"if", "keyword"},     // Priority beats the earlier word entry
+		{"HeLLo", "greeting"}, // Case-insensitive flag
+		{"world", "word"},
+		{"42", "number"},
+	}
+	for _, tc := range testCases {
+		value, _, err := table.Lookup(tc.input)
+		if err != nil || value != tc.expected {
+			t.Errorf("Lookup(%q): expected %q, got %q (err: %v)", tc.input, tc.expected, value, err)
+		}
+	}
+
+	if failures := loader.CheckTests(spec, table); len(failures) != 0 {
+		t.Errorf("Expected spec tests to pass, got %v", failures)
+	}
+}
+
+func TestLoader_BuildTiered(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	chain, err := loader.BuildTiered(spec)
+	if err != nil {
+		t.Fatalf("BuildTiered failed: %v", err)
+	}
+	if tiers := len(chain.Tables()); tiers != 2 {
+		t.Fatalf("Expected 2 tiers, got %d", tiers)
+	}
+	for input, expected := range map[string]string{"if": "keyword", "HeLLo": "greeting", "world": "word", "42": "number"} {
+		value, _, err := chain.Lookup(input)
+		if err != nil || value != expected {
+			t.Errorf("Lookup(%q): expected %q, got %q (err: %v)", input, expected, value, err)
+		}
+	}
+}
+
+func TestLoader_CheckTestsReportsFailures(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(`{
+		"version": 1,
+		"anchorStart": true,
+		"entries": [
+			{"pattern": "[a-z]+", "value": "word"},
+			{"pattern": "if", "value": "keyword", "tests": [{"input": "if"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if failures := loader.CheckTests(spec, table); len(failures) != 1 {
+		t.Errorf("Expected 1 failure for the shadowed keyword, got %v", failures)
+	}
+}
+
+func TestLoader_CheckTestsReorderedTable(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(`{
+		"version": 1,
+		"anchorStart": true,
+		"anchorEnd": true,
+		"entries": [
+			{"pattern": "[a-z]+", "value": "word", "tests": [{"input": "abc"}]},
+			{"pattern": "else", "value": "keyword", "tests": [{"input": "else"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	// Literal ordering puts the keyword first in the table.
+	builder, err := loader.Builder(spec)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+	table, err := builder.WithLiteralOrdering(true).Build(spec.AnchorStart, spec.AnchorEnd)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if failures := loader.CheckTests(spec, table); len(failures) != 0 {
+		t.Errorf("Expected spec tests to pass, got %v", failures)
+	}
+}
+
+func TestLoader_CheckTestsUsesLookupPath(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec, err := loader.ParseSpec(strings.NewReader(`{
+		"version": 1,
+		"anchorStart": true,
+		"anchorEnd": true,
+		"entries": [
+			{"pattern": "abc", "value": "abc", "tests": [{"input": "  ABC "}, {"input": "abcabc", "reject": true}]},
+			{"pattern": "[a-z]+", "value": "word", "tests": [{"input": "toolong"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	builder, err := loader.Builder(spec)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+	table, err := builder.WithNormalizers(TrimSpace(), Lowercase()).WithMaxInputLength(6, false).Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	// The normalized input passes; the one over the length limit fails as it
+	// would in a lookup.
+	failures := loader.CheckTests(spec, table)
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), `"toolong"`) || ErrorCodeOf(failures[0]) != CodeInputTooLong {
+		t.Errorf("Expected only the over-long input to fail, got %v", failures)
+	}
+}
+
+func TestLoader_ExtremePriorities(t *testing.T) {
+	loader := NewLoader[string](LoadStrict)
+	spec := &Spec{Version: SpecVersion, Entries: []SpecEntry{
+		{Pattern: "a", Value: json.RawMessage(`"lowest"`), Priority: math.MinInt},
+		{Pattern: "a", Value: json.RawMessage(`"highest"`), Priority: math.MaxInt},
+		{Pattern: "a", Value: json.RawMessage(`"zero"`)},
+	}}
+	table, err := loader.Build(spec)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var values []string
+	for i := range table.Len() {
+		values = append(values, string(spec.Entries[table.IndexAt(i)].Value))
+	}
+	if got := strings.Join(values, " "); got != `"highest" "zero" "lowest"` {
+		t.Errorf("Expected descending priorities, got %s", got)
+	}
+}
+
+func TestLoader_StrictAndLenient(t *testing.T) {
+	testCases := []struct {
+		name          string
+		spec          string
+		strictFails   bool
+		lenientFails  bool
+		lenientLength int
+	}{
+		{
+			name:          "UnknownField",
+			spec:          `{"version": 1, "entries": [{"pattern": "a", "value": "x", "colour": "red"}]}`,
+			strictFails:   true,
+			lenientLength: 1,
+		},
+		{
+			name:          "MissingVersion",
+			spec:          `{"entries": [{"pattern": "a", "value": "x"}]}`,
+			strictFails:   true,
+			lenientLength: 1,
+		},
+		{
+			name:         "FutureVersion",
+			spec:         `{"version": 99, "entries": []}`,
+			strictFails:  true,
+			lenientFails: true,
+		},
+		{
+			name:          "UnknownFlag",
+			spec:          `{"version": 1, "entries": [{"pattern": "a", "value": "x", "flags": "iz"}]}`,
+			strictFails:   true,
+			lenientLength: 1,
+		},
+		{
+			name:          "MissingValue",
+			spec:          `{"version": 1, "entries": [{"pattern": "a"}, {"pattern": "b", "value": "y"}]}`,
+			strictFails:   true,
+			lenientLength: 1,
+		},
+		{
+			name:          "Valid",
+			spec:          `{"version": 1, "entries": [{"pattern": "a", "value": "x"}]}`,
+			lenientLength: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLoader[string](LoadStrict).ParseSpec(strings.NewReader(tc.spec))
+			if (err != nil) != tc.strictFails {
+				t.Errorf("Strict: expected failure %v, got %v", tc.strictFails, err)
+			}
+			spec, err := NewLoader[string](LoadLenient).ParseSpec(strings.NewReader(tc.spec))
+			if (err != nil) != tc.lenientFails {
+				t.Errorf("Lenient: expected failure %v, got %v", tc.lenientFails, err)
+			}
+			if err == nil && len(spec.Entries) != tc.lenientLength {
+				t.Errorf("Lenient: expected %d entries, got %d", tc.lenientLength, len(spec.Entries))
+			}
+		})
+	}
+}
+
+func TestLoader_InvalidValue(t *testing.T) {
+	_, err := NewLoader[int](LoadStrict).LoadBytes([]byte(`{"version": 1, "entries": [{"pattern": "a", "value": "not a number"}]}`))
+	if err == nil {
+		t.Error("Expected an error decoding a string value into an int table")
+	}
+}
+
+func TestSpecSchema(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(SpecSchema), &schema); err != nil {
+		t.Fatalf("SpecSchema is not valid JSON: %v", err)
+	}
+	if schema["title"] != "RegexpTable specification" {
+		t.Errorf("Unexpected schema title: %v", schema["title"])
+	}
+}
+
+func TestLoader_Tags(t *testing.T) {
+	spec := `{"version": 1, "entries": [{"pattern": "a", "value": "x", "tags": ["pci"], "priority": 2}]}`
+	table, err := NewLoader[string](LoadStrict).Load(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if entry := table.maplets[0]; strings.Join(entry.tags, ",") != "pci" || entry.priority != 2 {
+		t.Errorf("Expected the spec's tags and priority on the entry, got %v, %d", entry.tags, entry.priority)
+	}
+}
+
+func TestLoader_Doc(t *testing.T) {
+	spec := `{"version": 1, "entries": [{"pattern": "a", "value": "x", "doc": "The letter a."}]}`
+	table, err := NewLoader[string](LoadStrict).Load(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var out bytes.Buffer
+	if err := table.ExportAudit(&out, nil); err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	audit, err := ReadAudit(&out)
+	if err != nil || len(audit.Rules) != 1 || audit.Rules[0].Doc != "The letter a." {
+		t.Errorf("Expected the spec's doc in the audit, got %+v, %v", audit, err)
+	}
+}
+
+func TestLoader_Engine(t *testing.T) {
+	spec := []byte(`{"version": 1, "engine": "go-regexp", "entries": [{"pattern": "a", "value": "x"}]}`)
+	if _, err := NewLoader[string](LoadStrict).LoadBytes(spec); err != nil {
+		t.Errorf("Expected a spec for the loader's engine to load, got %v", err)
+	}
+
+	spec = []byte(`{"version": 1, "engine": "pcre2", "entries": [{"pattern": "a", "value": "x"}]}`)
+	_, err := NewLoader[string](LoadStrict).LoadBytes(spec)
+	if err == nil || ErrorCodeOf(err) != CodeEngineUnsupported || !strings.Contains(err.Error(), "pcre2") {
+		t.Errorf("Expected a spec for another engine to be rejected, got %v", err)
+	}
+	if _, err := NewLoader[string](LoadLenient).LoadBytes(spec); err != nil {
+		t.Errorf("Expected the lenient loader to ignore the engine, got %v", err)
+	}
+}
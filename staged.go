@@ -0,0 +1,104 @@
+package regexptable
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// StagedTable supports updating a table while it is being read concurrently. Writers
+// add and remove patterns on a private staging copy; Commit compiles the staged
+// patterns into a brand new table and atomically publishes it. Readers always see a
+// complete, compiled table and are never disturbed by in-progress edits.
+type StagedTable[T any] struct {
+	mu          sync.Mutex // Serialises writers
+	staging     *RegexpTableBuilder[T]
+	committed   *RegexpTableBuilder[T]
+	anchorStart bool
+	anchorEnd   bool
+	published   atomic.Pointer[RegexpTable[T]]
+}
+
+// NewStagedTable builds and publishes an initial table from the builder's patterns.
+// The builder is copied, so later changes to it do not affect the staged table.
+func NewStagedTable[T any](builder *RegexpTableBuilder[T], anchorStart, anchorEnd bool) (*StagedTable[T], error) {
+	st := &StagedTable[T]{
+		staging:     builder.Clone(),
+		committed:   builder.Clone(),
+		anchorStart: anchorStart,
+		anchorEnd:   anchorEnd,
+	}
+	table, err := st.buildContext(context.Background(), st.committed)
+	if err != nil {
+		return nil, err
+	}
+	st.published.Store(table)
+	return st, nil
+}
+
+// AddPattern stages a new pattern. It has no effect on readers until Commit.
+func (st *StagedTable[T]) AddPattern(pattern string, value T) *StagedTable[T] {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.staging.AddPattern(pattern, value)
+	return st
+}
+
+// RemovePattern stages the removal of every entry with the given pattern text and
+// returns the number of staged entries removed.
+func (st *StagedTable[T]) RemovePattern(pattern string) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.staging.RemovePattern(pattern)
+}
+
+// Commit compiles the staged patterns and publishes the resulting table. If
+// compilation fails the error is returned, the previously published table stays in
+// place and the staged changes are kept so they can be corrected.
+func (st *StagedTable[T]) Commit() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	table, err := st.buildContext(context.Background(), st.staging)
+	if err != nil {
+		return err
+	}
+	st.committed = st.staging.Clone()
+	st.published.Store(table)
+	return nil
+}
+
+// Discard throws away all staged changes since the last successful Commit.
+func (st *StagedTable[T]) Discard() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.staging = st.committed.Clone()
+}
+
+// Table returns the currently published table. The result must be treated as
+// read-only; use the StagedTable methods to make changes.
+func (st *StagedTable[T]) Table() *RegexpTable[T] {
+	return st.published.Load()
+}
+
+// Lookup looks up input in the currently published table.
+func (st *StagedTable[T]) Lookup(input string) (T, []string, error) {
+	return st.Table().Lookup(input)
+}
+
+// TryLookup looks up input in the currently published table.
+func (st *StagedTable[T]) TryLookup(input string) (T, []string, bool) {
+	return st.Table().TryLookup(input)
+}
+
+// buildContext compiles a builder into a table that is ready to be shared between
+// goroutines, i.e. nothing is compiled lazily by later lookups.
+func (st *StagedTable[T]) buildContext(ctx context.Context, builder *RegexpTableBuilder[T]) (*RegexpTable[T], error) {
+	table, err := builder.BuildContext(ctx, st.anchorStart, st.anchorEnd)
+	if err != nil {
+		return nil, err
+	}
+	if err := table.precompileIndividuals(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
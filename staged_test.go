@@ -0,0 +1,85 @@
+package regexptable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStagedTable_CommitAndDiscard(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().AddPattern(`\d+`, "number")
+	staged, err := NewStagedTable(builder, true, true)
+	if err != nil {
+		t.Fatalf("NewStagedTable failed: %v", err)
+	}
+
+	staged.AddPattern(`[a-z]+`, "word")
+	if _, _, ok := staged.TryLookup("abc"); ok {
+		t.Error("Expected staged pattern to be invisible before Commit")
+	}
+
+	if err := staged.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if value, _, ok := staged.TryLookup("abc"); !ok || value != "word" {
+		t.Errorf("Expected 'word' after Commit, got %q", value)
+	}
+
+	if removed := staged.RemovePattern(`\d+`); removed != 1 {
+		t.Errorf("Expected 1 staged removal, got %d", removed)
+	}
+	staged.Discard()
+	if err := staged.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, _, ok := staged.TryLookup("42"); !ok {
+		t.Error("Expected discarded removal to have no effect")
+	}
+}
+
+func TestStagedTable_FailedCommitKeepsPublishedTable(t *testing.T) {
+	staged, err := NewStagedTable(NewRegexpTableBuilder[string]().AddPattern(`a`, "a"), true, true)
+	if err != nil {
+		t.Fatalf("NewStagedTable failed: %v", err)
+	}
+	before := staged.Table()
+
+	staged.AddPattern(`[invalid`, "broken")
+	if err := staged.Commit(); err == nil {
+		t.Fatal("Expected Commit to fail for an invalid pattern")
+	}
+	if staged.Table() != before {
+		t.Error("Expected the published table to be unchanged after a failed Commit")
+	}
+	if _, _, ok := staged.TryLookup("a"); !ok {
+		t.Error("Expected the published table to keep working")
+	}
+}
+
+func TestStagedTable_ConcurrentReaders(t *testing.T) {
+	staged, err := NewStagedTable(NewRegexpTableBuilder[int]().AddPattern(`x*`, 0), true, true)
+	if err != nil {
+		t.Fatalf("NewStagedTable failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				// The empty input exercises the disambiguation path.
+				if _, _, ok := staged.TryLookup(""); !ok {
+					t.Error("Expected the empty input to match")
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 20; i++ {
+		staged.AddPattern(`y*`, i)
+		if err := staged.Commit(); err != nil {
+			t.Errorf("Commit failed: %v", err)
+		}
+	}
+	wg.Wait()
+}
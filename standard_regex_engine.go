@@ -2,7 +2,10 @@ package regexptable
 
 import (
 	"fmt"
+	"io"
 	"regexp"
+	"regexp/syntax"
+	"strings"
 )
 
 // StandardRegexpEngine implements RegexpEngine using Go's built-in regexp package.
@@ -27,6 +30,85 @@ func (e *StandardRegexpEngine) FormatNamedGroup(groupName, pattern string) strin
 	return fmt.Sprintf("(?P<%s>%s)", groupName, pattern)
 }
 
+// FormatFlags applies inline flags using Go's (?flags:pattern) syntax.
+func (e *StandardRegexpEngine) FormatFlags(flags, pattern string) (string, bool) {
+	for _, flag := range flags {
+		if !strings.ContainsRune("imsU", flag) {
+			return "", false
+		}
+	}
+	if flags == "" {
+		return pattern, true
+	}
+	return "(?" + flags + ":" + pattern + ")", true
+}
+
+// StripCaptures rewrites every capture group in the pattern as a non-capturing group.
+// The pattern is parsed with regexp/syntax so the result is in Go's canonical syntax.
+func (e *StandardRegexpEngine) StripCaptures(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	return stripCaptures(re).String(), nil
+}
+
+// stripCaptures replaces each OpCapture node with its single sub-expression.
+func stripCaptures(re *syntax.Regexp) *syntax.Regexp {
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	for i, sub := range re.Sub {
+		re.Sub[i] = stripCaptures(sub)
+	}
+	return re
+}
+
+// LiteralPrefix returns the leading case-sensitive literal of the pattern and the
+// remainder of the pattern in Go's canonical syntax.
+func (e *StandardRegexpEngine) LiteralPrefix(pattern string) (string, string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", "", false
+	}
+	switch {
+	case isPlainLiteral(re):
+		return string(re.Rune), "", true
+	case re.Op == syntax.OpConcat && len(re.Sub) > 0 && isPlainLiteral(re.Sub[0]):
+		rest := &syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags, Sub: re.Sub[1:]}
+		return string(re.Sub[0].Rune), rest.String(), true
+	}
+	return "", "", false
+}
+
+// LiteralSuffix returns the trailing case-sensitive literal of the pattern and the
+// beginning of the pattern in Go's canonical syntax.
+func (e *StandardRegexpEngine) LiteralSuffix(pattern string) (string, string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", "", false
+	}
+	switch {
+	case isPlainLiteral(re):
+		return "", string(re.Rune), true
+	case re.Op == syntax.OpConcat && len(re.Sub) > 0 && isPlainLiteral(re.Sub[len(re.Sub)-1]):
+		last := len(re.Sub) - 1
+		rest := &syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags, Sub: re.Sub[:last]}
+		return rest.String(), string(re.Sub[last].Rune), true
+	}
+	return "", "", false
+}
+
+// isPlainLiteral reports whether re is a non-empty literal without case folding.
+func isPlainLiteral(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase == 0 && len(re.Rune) > 0
+}
+
+// QuoteLiteral escapes all regexp metacharacters using regexp.QuoteMeta.
+func (e *StandardRegexpEngine) QuoteLiteral(literal string) string {
+	return regexp.QuoteMeta(literal)
+}
+
 // StandardCompiledRegexp wraps a Go *regexp.Regexp to implement CompiledRegexp.
 type StandardCompiledRegexp struct {
 	regexp *regexp.Regexp
@@ -46,3 +128,18 @@ func (r *StandardCompiledRegexp) FindStringSubmatch(s string) []string {
 func (r *StandardCompiledRegexp) SubexpNames() []string {
 	return r.regexp.SubexpNames()
 }
+
+// FindReaderSubmatchIndex delegates to the wrapped regexp.
+func (r *StandardCompiledRegexp) FindReaderSubmatchIndex(reader io.RuneReader) []int {
+	return r.regexp.FindReaderSubmatchIndex(reader)
+}
+
+// FindSubmatchIndex delegates to the wrapped regexp.
+func (r *StandardCompiledRegexp) FindSubmatchIndex(b []byte) []int {
+	return r.regexp.FindSubmatchIndex(b)
+}
+
+// FindStringSubmatchIndex delegates to the wrapped regexp.
+func (r *StandardCompiledRegexp) FindStringSubmatchIndex(s string) []int {
+	return r.regexp.FindStringSubmatchIndex(s)
+}
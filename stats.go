@@ -0,0 +1,112 @@
+package regexptable
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// patternHits counts the lookups a pattern has won and keeps a ring buffer of
+// the most recent inputs. It is updated by lookups, which may run concurrently.
+type patternHits struct {
+	count    atomic.Uint64
+	mu       sync.Mutex
+	examples []string // Ring buffer of recent inputs, in use up to its capacity
+	next     int      // Where the next example goes once the buffer is full
+}
+
+func newPatternHits(examples int) *patternHits {
+	return &patternHits{examples: make([]string, 0, examples)}
+}
+
+// record counts a hit and, when examples are kept, remembers its input.
+func (h *patternHits) record(input string) {
+	h.count.Add(1)
+	if cap(h.examples) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.examples) < cap(h.examples) {
+		h.examples = append(h.examples, input)
+		return
+	}
+	h.examples[h.next] = input
+	h.next = (h.next + 1) % len(h.examples)
+}
+
+// recent returns the remembered inputs, oldest first.
+func (h *patternHits) recent() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recent := make([]string, 0, len(h.examples))
+	recent = append(recent, h.examples[h.next:]...)
+	return append(recent, h.examples[:h.next]...)
+}
+
+// SetHitStats enables or disables per-pattern hit statistics. When enabled, the
+// table counts how many lookups each pattern wins and, if examples is positive,
+// keeps the most recent examples inputs that each pattern matched, which is a
+// convenient way to audit what a rule is really catching. Enabling the statistics
+// starts them afresh.
+//
+// Hits are recorded by the string and byte slice lookup methods, the tokenizers
+// and LookupAnchored, including lookups answered from the memoization cache. Examples are the inputs as given,
+// before any normalization. See HitCount and RecentExamples.
+func (rt *RegexpTable[T]) SetHitStats(enabled bool, examples int) {
+	rt.hitStats = enabled
+	rt.hitExamples = max(examples, 0)
+	for _, entry := range rt.maplets {
+		entry.hits = rt.newHits()
+	}
+}
+
+// newHits returns fresh statistics for an entry, or nil when they are disabled.
+func (rt *RegexpTable[T]) newHits() *patternHits {
+	if !rt.hitStats {
+		return nil
+	}
+	return newPatternHits(rt.hitExamples)
+}
+
+// recordHit updates the winning entry's statistics, if they are enabled, and runs
+// any match callbacks that apply to it.
+func (rt *RegexpTable[T]) recordHit(entry *ValueAndPattern[T], input string, matches []string) {
+	if entry.hits != nil {
+		entry.hits.record(input)
+	}
+	if rt.matchCallbacks != nil {
+		rt.notifyMatch(entry, input, matches)
+	}
+}
+
+// HitCount returns how many lookups the pattern with the given insertion index
+// (see Result.Index) has won since hit statistics were enabled. It returns 0 if
+// statistics are disabled or there is no such pattern.
+func (rt *RegexpTable[T]) HitCount(index int) uint64 {
+	if hits := rt.hitsAt(index); hits != nil {
+		return hits.count.Load()
+	}
+	return 0
+}
+
+// RecentExamples returns up to the configured number of the most recent inputs
+// matched by the pattern with the given insertion index (see Result.Index),
+// oldest first. It returns nil if statistics are disabled, no examples are kept
+// or there is no such pattern.
+func (rt *RegexpTable[T]) RecentExamples(index int) []string {
+	hits := rt.hitsAt(index)
+	if hits == nil || cap(hits.examples) == 0 {
+		return nil
+	}
+	return hits.recent()
+}
+
+// hitsAt returns the statistics of the entry with the given insertion index.
+func (rt *RegexpTable[T]) hitsAt(index int) *patternHits {
+	for _, entry := range rt.maplets {
+		if entry.insertionIndex() == index {
+			return entry.hits
+		}
+	}
+	return nil
+}
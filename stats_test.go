@@ -0,0 +1,116 @@
+package regexptable
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRegexpTable_HitStats(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+	table.SetHitStats(true, 2)
+
+	for _, input := range []string{"1", "abc", "22", "333", "!"} {
+		table.Lookup(input)
+	}
+
+	if count := table.HitCount(0); count != 3 {
+		t.Errorf("Expected 3 hits for the number pattern, got %d", count)
+	}
+	if count := table.HitCount(1); count != 1 {
+		t.Errorf("Expected 1 hit for the word pattern, got %d", count)
+	}
+	if examples := table.RecentExamples(0); !slices.Equal(examples, []string{"22", "333"}) {
+		t.Errorf("Expected the two most recent numbers, got %v", examples)
+	}
+	if examples := table.RecentExamples(1); !slices.Equal(examples, []string{"abc"}) {
+		t.Errorf("Expected [abc], got %v", examples)
+	}
+	if count := table.HitCount(2); count != 0 {
+		t.Errorf("Expected 0 hits for a missing pattern, got %d", count)
+	}
+
+	// Patterns added later are counted too, and re-enabling starts afresh.
+	table.AddPattern(`!`, "bang")
+	table.Lookup("!")
+	if count := table.HitCount(2); count != 1 {
+		t.Errorf("Expected 1 hit for the new pattern, got %d", count)
+	}
+	table.SetHitStats(true, 0)
+	if count := table.HitCount(0); count != 0 {
+		t.Errorf("Expected statistics to restart, got %d", count)
+	}
+	table.Lookup("4")
+	if examples := table.RecentExamples(0); examples != nil {
+		t.Errorf("Expected no examples to be kept, got %v", examples)
+	}
+}
+
+func TestRegexpTable_HitStatsDisabled(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.Lookup("1")
+	if count := table.HitCount(0); count != 0 {
+		t.Errorf("Expected 0 hits when disabled, got %d", count)
+	}
+	if examples := table.RecentExamples(0); examples != nil {
+		t.Errorf("Expected no examples when disabled, got %v", examples)
+	}
+}
+
+func TestRegexpTable_HitStatsOriginalInput(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		AddPattern(`abc`, "abc").
+		WithNormalizers(TrimSpace(), Lowercase()).
+		WithMemoization(10, false).
+		WithHitStats(true, 5).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	table.Lookup(" ABC ")
+	table.Lookup(" ABC ") // Answered from the cache
+	if _, err := table.LookupResult("abc"); err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	if _, _, err := table.LookupAnchored("xabc", AnchorEnd); err != nil {
+		t.Fatalf("LookupAnchored failed: %v", err)
+	}
+
+	expected := []string{" ABC ", " ABC ", "abc", "xabc"}
+	if examples := table.RecentExamples(0); !slices.Equal(examples, expected) {
+		t.Errorf("Expected %v, got %v", expected, examples)
+	}
+}
+
+func TestRegexpTable_HitStatsConcurrent(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.SetHitStats(true, 3)
+	table.SetPrecompileIndividuals(true)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				table.Lookup("42")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count := table.HitCount(0); count != 800 {
+		t.Errorf("Expected 800 hits, got %d", count)
+	}
+	if examples := table.RecentExamples(0); len(examples) != 3 {
+		t.Errorf("Expected 3 examples, got %v", examples)
+	}
+}
@@ -0,0 +1,27 @@
+package regexptable
+
+// LookupSuffix finds the longest suffix of the input that one of the table's
+// patterns matches in full, for scanners that work from the right, such as ones
+// that split a file extension or a unit off the end of a value. It returns the
+// value of the pattern that matched and the length of the suffix in bytes, which
+// leaves input[:len(input)-consumed] for the scanner to carry on with.
+//
+// The table's own anchoring is ignored: the lookup is LookupAnchored with
+// AnchorEnd, whose leftmost match is the longest suffix, and when several
+// patterns match that suffix the table's order decides between them as usual.
+// With normalizers, consumed is measured in the input as given.
+func (rt *RegexpTable[T]) LookupSuffix(input string) (T, int, error) {
+	var zero T
+	value, matches, err := rt.LookupAnchored(input, AnchorEnd)
+	if err != nil {
+		return zero, 0, err
+	}
+	consumed := len(matches[0])
+	if rt.normalizers != nil {
+		normalized, offsets := rt.normalize(input)
+		if offsets != nil {
+			consumed = len(input) - offsets[len(normalized)-consumed]
+		}
+	}
+	return value, consumed, nil
+}
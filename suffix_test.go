@@ -0,0 +1,49 @@
+package regexptable
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegexpTable_LookupSuffix(t *testing.T) {
+	// Built anchored at the start, to show that the table's anchoring is ignored.
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\.tar\.gz`, "tarball").
+		AddPattern(`\.gz`, "gzip").
+		AddPattern(`\.[a-z]+`, "extension").
+		AddPattern(`[0-9]+(?:\.[0-9]+)?(?P<unit>kg|g)`, "weight").
+		MustBuild(true, false)
+
+	testCases := []struct {
+		input    string
+		value    string
+		consumed int
+	}{
+		{"archive.tar.gz", "tarball", 7},
+		{"notes.gz", "gzip", 3},
+		{"notes.txt", "extension", 4},
+		{"a.b.c", "extension", 2},
+		{"12.5kg", "weight", 6},
+		{"net 3g", "weight", 2},
+	}
+	for _, tc := range testCases {
+		value, consumed, err := table.LookupSuffix(tc.input)
+		if err != nil || value != tc.value || consumed != tc.consumed {
+			t.Errorf("LookupSuffix(%q): expected %s, %d, got %s, %d, %v", tc.input, tc.value, tc.consumed, value, consumed, err)
+		}
+	}
+	if _, _, err := table.LookupSuffix("README"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+}
+
+func TestRegexpTable_LookupSuffixNormalized(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		WithNormalizers(TrimSpace(), Lowercase()).
+		AddPattern(`\.txt`, "text").
+		MustBuild(false, true)
+	value, consumed, err := table.LookupSuffix("NOTES.TXT  ")
+	if err != nil || value != "text" || consumed != 6 {
+		t.Errorf("Expected text, 6, got %s, %d, %v", value, consumed, err)
+	}
+}
@@ -0,0 +1,255 @@
+package regexptable
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// approxBytesPerPatternByte is a rough estimate of the memory a compiled table uses
+// per byte of pattern text, covering the compiled union, the individual regexps
+// used for disambiguation and the entries themselves.
+const approxBytesPerPatternByte = 64
+
+// TableSet manages many named tables, such as one per tenant or locale. Tables are
+// defined by builders, which may be derived from shared templates, and compiled
+// lazily on first use. Compiled tables can be evicted when they have been idle for
+// a while or when the set exceeds a memory budget; an evicted table is rebuilt
+// from its definition the next time it is needed. A TableSet is safe for
+// concurrent use.
+type TableSet[T any] struct {
+	mu          sync.Mutex
+	anchorStart bool
+	anchorEnd   bool
+	templates   map[string]*RegexpTableBuilder[T]
+	members     map[string]*tableSetMember[T]
+	idleTimeout time.Duration    // Zero disables idle eviction
+	memoryLimit int              // Approximate bytes, zero disables the limit
+	now         func() time.Time // Clock used for idle tracking, defaults to time.Now
+}
+
+// tableSetMember is a table definition together with its compiled table, if loaded.
+type tableSetMember[T any] struct {
+	builder  *RegexpTableBuilder[T]
+	table    *RegexpTable[T] // nil until first use and after eviction
+	size     int             // Approximate bytes used by table
+	lastUsed time.Time
+}
+
+// TableSetStats summarises the state of a TableSet.
+type TableSetStats struct {
+	Defined     int // Number of defined tables
+	Loaded      int // Number of tables currently compiled
+	ApproxBytes int // Approximate memory used by the compiled tables
+}
+
+// NewTableSet creates an empty set whose tables all use the given anchoring.
+func NewTableSet[T any](anchorStart, anchorEnd bool) *TableSet[T] {
+	return &TableSet[T]{
+		anchorStart: anchorStart,
+		anchorEnd:   anchorEnd,
+		templates:   make(map[string]*RegexpTableBuilder[T]),
+		members:     make(map[string]*tableSetMember[T]),
+	}
+}
+
+// DefineTemplate registers a builder that table definitions can start from. The
+// builder is copied, so later changes to it have no effect.
+func (ts *TableSet[T]) DefineTemplate(name string, builder *RegexpTableBuilder[T]) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.templates[name] = builder.Clone()
+}
+
+// Define registers the table called name, replacing any previous definition and
+// unloading its compiled table. The builder is copied, so later changes to it have
+// no effect. The table is compiled on first use.
+func (ts *TableSet[T]) Define(name string, builder *RegexpTableBuilder[T]) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.members[name] = &tableSetMember[T]{builder: builder.Clone()}
+}
+
+// DefineFromTemplate registers the table called name as a copy of a template,
+// which customize may then extend, e.g. with tenant-specific patterns. customize
+// may be nil.
+func (ts *TableSet[T]) DefineFromTemplate(name, template string, customize func(*RegexpTableBuilder[T])) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	base, ok := ts.templates[template]
+	if !ok {
+		return codeErrorf(CodeInvalidArgument, "unknown template %q", template)
+	}
+	builder := base.Clone()
+	if customize != nil {
+		customize(builder)
+	}
+	ts.members[name] = &tableSetMember[T]{builder: builder}
+	return nil
+}
+
+// Remove deletes the definition of the table called name and reports whether it
+// existed.
+func (ts *TableSet[T]) Remove(name string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	_, ok := ts.members[name]
+	delete(ts.members, name)
+	return ok
+}
+
+// Names returns the names of the defined tables in sorted order.
+func (ts *TableSet[T]) Names() []string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	names := make([]string, 0, len(ts.members))
+	for name := range ts.members {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get returns the compiled table called name, compiling it if necessary. The
+// returned table is fully compiled and must be treated as read-only; it remains
+// usable after being evicted from the set.
+func (ts *TableSet[T]) Get(name string) (*RegexpTable[T], error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	member, ok := ts.members[name]
+	if !ok {
+		return nil, codeErrorf(CodeInvalidArgument, "unknown table %q", name)
+	}
+	member.lastUsed = ts.clock()
+	if member.table != nil {
+		return member.table, nil
+	}
+
+	table, err := member.builder.Build(ts.anchorStart, ts.anchorEnd)
+	if err != nil {
+		return nil, fmt.Errorf("table %q: %w", name, err)
+	}
+	if err := table.precompileIndividuals(); err != nil {
+		return nil, fmt.Errorf("table %q: %w", name, err)
+	}
+	member.table = table
+	member.size = approxTableBytes(table)
+	ts.enforceMemoryLimit(member)
+	return table, nil
+}
+
+// Lookup looks up input in the table called name.
+func (ts *TableSet[T]) Lookup(name, input string) (T, []string, error) {
+	var zero T
+	table, err := ts.Get(name)
+	if err != nil {
+		return zero, nil, err
+	}
+	return table.Lookup(input)
+}
+
+// SetIdleTimeout sets how long a compiled table may go unused before EvictIdle
+// unloads it. Zero disables idle eviction.
+func (ts *TableSet[T]) SetIdleTimeout(timeout time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.idleTimeout = timeout
+}
+
+// SetMemoryLimit sets an approximate budget, in bytes, for the compiled tables.
+// Whenever compiling a table takes the set over budget, the least recently used
+// other tables are unloaded until it is back within budget. Zero disables the
+// limit. Memory use is estimated from the size of the patterns, so the limit is
+// only a guide.
+func (ts *TableSet[T]) SetMemoryLimit(bytes int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.memoryLimit = bytes
+}
+
+// EvictIdle unloads every compiled table that has not been used for longer than
+// the idle timeout and returns how many were unloaded. Services typically call it
+// from a periodic housekeeping task.
+func (ts *TableSet[T]) EvictIdle() int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.idleTimeout <= 0 {
+		return 0
+	}
+	now := ts.clock()
+	evicted := 0
+	for _, member := range ts.members {
+		if member.table != nil && now.Sub(member.lastUsed) > ts.idleTimeout {
+			member.unload()
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Stats reports how many tables are defined and loaded and their approximate
+// memory use.
+func (ts *TableSet[T]) Stats() TableSetStats {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	stats := TableSetStats{Defined: len(ts.members)}
+	for _, member := range ts.members {
+		if member.table != nil {
+			stats.Loaded++
+			stats.ApproxBytes += member.size
+		}
+	}
+	return stats
+}
+
+// enforceMemoryLimit unloads least recently used tables other than keep until the
+// loaded tables fit the memory limit. The caller must hold the lock.
+func (ts *TableSet[T]) enforceMemoryLimit(keep *tableSetMember[T]) {
+	if ts.memoryLimit <= 0 {
+		return
+	}
+	var loaded []*tableSetMember[T]
+	total := 0
+	for _, member := range ts.members {
+		if member.table != nil {
+			total += member.size
+			if member != keep {
+				loaded = append(loaded, member)
+			}
+		}
+	}
+	slices.SortFunc(loaded, func(a, b *tableSetMember[T]) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+	for _, member := range loaded {
+		if total <= ts.memoryLimit {
+			break
+		}
+		total -= member.size
+		member.unload()
+	}
+}
+
+// unload discards the compiled table, keeping the definition.
+func (m *tableSetMember[T]) unload() {
+	m.table = nil
+	m.size = 0
+}
+
+// clock returns the current time, using the set's injected clock if any.
+func (ts *TableSet[T]) clock() time.Time {
+	if ts.now != nil {
+		return ts.now()
+	}
+	return time.Now()
+}
+
+// approxTableBytes estimates the memory used by a compiled table.
+func approxTableBytes[T any](table *RegexpTable[T]) int {
+	size := 0
+	for _, entry := range table.maplets {
+		size += len(entry.Pattern)
+	}
+	return size * approxBytesPerPatternByte
+}
@@ -0,0 +1,129 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestTableSet() (*TableSet[string], *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := NewTableSet[string](true, true)
+	ts.now = func() time.Time { return now }
+	ts.DefineTemplate("base", NewRegexpTableBuilder[string]().AddPattern(`\d+`, "number"))
+	return ts, &now
+}
+
+func TestTableSet_TemplatesAndLazyCompilation(t *testing.T) {
+	ts, _ := newTestTableSet()
+	if err := ts.DefineFromTemplate("en", "base", func(b *RegexpTableBuilder[string]) {
+		b.AddPattern(`yes|no`, "answer")
+	}); err != nil {
+		t.Fatalf("DefineFromTemplate failed: %v", err)
+	}
+	if err := ts.DefineFromTemplate("fr", "base", func(b *RegexpTableBuilder[string]) {
+		b.AddPattern(`oui|non`, "answer")
+	}); err != nil {
+		t.Fatalf("DefineFromTemplate failed: %v", err)
+	}
+	if err := ts.DefineFromTemplate("de", "missing", nil); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+
+	if stats := ts.Stats(); stats.Defined != 2 || stats.Loaded != 0 {
+		t.Errorf("Expected 2 defined and 0 loaded tables, got %+v", stats)
+	}
+
+	if value, _, err := ts.Lookup("fr", "oui"); err != nil || value != "answer" {
+		t.Errorf("Expected answer from fr, got %q, %v", value, err)
+	}
+	if _, _, err := ts.Lookup("en", "oui"); err == nil {
+		t.Error("Expected en not to know oui")
+	}
+	if value, _, err := ts.Lookup("en", "42"); err != nil || value != "number" {
+		t.Errorf("Expected the template's pattern in en, got %q, %v", value, err)
+	}
+	if _, _, err := ts.Lookup("xx", "42"); err == nil {
+		t.Error("Expected an error for an unknown table")
+	}
+
+	stats := ts.Stats()
+	if stats.Loaded != 2 || stats.ApproxBytes <= 0 {
+		t.Errorf("Expected 2 loaded tables with a size, got %+v", stats)
+	}
+	if names := ts.Names(); !slices.Equal(names, []string{"en", "fr"}) {
+		t.Errorf("Expected [en fr], got %v", names)
+	}
+
+	if !ts.Remove("en") || ts.Remove("en") {
+		t.Error("Expected Remove to report whether the table existed")
+	}
+}
+
+func TestTableSet_EvictIdle(t *testing.T) {
+	ts, now := newTestTableSet()
+	ts.SetIdleTimeout(time.Minute)
+	for _, name := range []string{"a", "b"} {
+		if err := ts.DefineFromTemplate(name, "base", nil); err != nil {
+			t.Fatalf("DefineFromTemplate failed: %v", err)
+		}
+		if _, err := ts.Get(name); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+
+	*now = now.Add(45 * time.Second)
+	if _, err := ts.Get("b"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	*now = now.Add(30 * time.Second)
+	if evicted := ts.EvictIdle(); evicted != 1 {
+		t.Errorf("Expected 1 idle table to be evicted, got %d", evicted)
+	}
+	if stats := ts.Stats(); stats.Loaded != 1 || stats.Defined != 2 {
+		t.Errorf("Expected 1 loaded of 2 defined tables, got %+v", stats)
+	}
+
+	// An evicted table is rebuilt on demand.
+	if value, _, err := ts.Lookup("a", "7"); err != nil || value != "number" {
+		t.Errorf("Expected the evicted table to be rebuilt, got %q, %v", value, err)
+	}
+}
+
+func TestTableSet_MemoryLimit(t *testing.T) {
+	ts, now := newTestTableSet()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := ts.DefineFromTemplate(name, "base", nil); err != nil {
+			t.Fatalf("DefineFromTemplate failed: %v", err)
+		}
+	}
+	one := len(`\d+`) * approxBytesPerPatternByte
+	ts.SetMemoryLimit(2 * one)
+
+	for _, name := range []string{"a", "b", "a", "c"} {
+		*now = now.Add(time.Second)
+		if _, err := ts.Get(name); err != nil {
+			t.Fatalf("Get(%q) failed: %v", name, err)
+		}
+	}
+
+	// b was the least recently used table when c was loaded.
+	stats := ts.Stats()
+	if stats.Loaded != 2 || stats.ApproxBytes != 2*one {
+		t.Errorf("Expected 2 loaded tables within the limit, got %+v", stats)
+	}
+	if ts.members["b"].table != nil {
+		t.Error("Expected b to have been evicted")
+	}
+}
+
+func TestTableSet_InvalidDefinition(t *testing.T) {
+	ts, _ := newTestTableSet()
+	ts.Define("broken", NewRegexpTableBuilder[string]().AddPattern(`(`, "broken"))
+	if _, err := ts.Get("broken"); err == nil {
+		t.Error("Expected an error for a table that does not compile")
+	}
+	if stats := ts.Stats(); stats.Loaded != 0 {
+		t.Errorf("Expected no loaded tables, got %+v", stats)
+	}
+}
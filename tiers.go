@@ -0,0 +1,78 @@
+package regexptable
+
+import "errors"
+
+// AddPatternWithPriority adds a pattern with a priority; patterns added with
+// AddPattern have priority 0. Higher priorities take precedence, but how strictly
+// depends on how the table is built:
+//
+//   - Build puts the patterns into a single union in order of descending priority.
+//     A higher priority pattern then wins over lower priority ones that match at
+//     the same position, but an unanchored lower priority pattern that matches
+//     earlier in the input still wins, as with any alternation.
+//   - BuildTiered compiles one union per priority and tries them in order of
+//     descending priority, so a higher priority pattern wins whenever it matches.
+func (b *RegexpTableBuilder[T]) AddPatternWithPriority(pattern string, value T, priority int) *RegexpTableBuilder[T] {
+	b.patterns = append(b.patterns, patternEntry[T]{
+		pattern:  pattern,
+		value:    value,
+		priority: priority,
+	})
+	return b
+}
+
+// BuildTiered builds one table per distinct priority, each compiled as its own
+// union, and returns them as a TableChain ordered by descending priority. A lookup
+// tries the tiers in turn and stops at the first tier with a match, which gives
+// deterministic precedence between priorities without relying on alternation
+// order, and keeps each union smaller. Every tier has the builder's options.
+//
+// The Result.Index reported by any tier is the pattern's position among those
+// added to the builder, as it is for Build.
+func (b *RegexpTableBuilder[T]) BuildTiered(anchorStart, anchorEnd bool) (*TableChain[T], error) {
+	if len(b.errs) > 0 {
+		return nil, codeErrorf(CodeCompileFailed, "invalid patterns: %w", errors.Join(b.errs...))
+	}
+
+	entries, err := b.orderedPatterns(NewAnchoring(anchorStart, anchorEnd))
+	if err != nil {
+		return nil, codeErrorf(CodeCompileFailed, "invalid patterns: %w", err)
+	}
+
+	chain := NewTableChain[T]()
+	var priorities []int // The priority of each tier
+	var tier *RegexpTable[T]
+	added := make([]*ValueAndPattern[T], len(entries))
+	for i, entry := range entries {
+		if tier == nil || entry.priority != priorities[len(priorities)-1] {
+			tier = b.newTable(anchorStart, anchorEnd)
+			chain.Append(tier)
+			priorities = append(priorities, entry.priority)
+		}
+		// Number the entry as Build would, so that the tiers keep the union's order.
+		tier.nextGroupID = i + 1
+		if err := b.addEntry(tier, entry); err != nil {
+			return nil, err
+		}
+		added[i] = tier.maplets[len(tier.maplets)-1]
+	}
+
+	for i, tier := range chain.Tables() {
+		b.reservePositions(tier)
+		if err := tier.Recompile(); err != nil {
+			return nil, codeErrorf(CodeCompileFailed, "failed to compile regexp table tier with priority %d: %w", priorities[i], err)
+		}
+	}
+	err = checkExamples(entries, added, func(input string) *ValueAndPattern[T] {
+		for _, tier := range chain.Tables() {
+			if winner := tier.classify(input); winner != nil {
+				return winner
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
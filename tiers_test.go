@@ -0,0 +1,95 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func newPriorityBuilder() *RegexpTableBuilder[string] {
+	return NewRegexpTableBuilder[string]().
+		AddPattern(`[a-z]+`, "word").
+		AddPatternWithPriority(`error`, "error", 10).
+		AddPatternWithPriority(`\d+`, "number", 5).
+		AddPatternWithPriority(`warn`, "warning", 10)
+}
+
+func TestRegexpTableBuilder_BuildOrdersByPriority(t *testing.T) {
+	table, err := newPriorityBuilder().Build(false, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := []string{`error`, `warn`, `\d+`, `[a-z]+`}
+	if patterns := table.Patterns(); strings.Join(patterns, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected patterns %v, got %v", expected, patterns)
+	}
+
+	// In a single union a lower priority pattern that matches earlier still wins.
+	value, _, err := table.Lookup("an error")
+	if err != nil || value != "word" {
+		t.Errorf("Expected the union to pick word, got %q, %v", value, err)
+	}
+
+	// Result.Index is the position among the builder's patterns, not in the union.
+	for input, index := range map[string]int{"abc": 0, "error": 1, "42": 2, "warn": 3} {
+		if result, err := table.LookupResult(input); err != nil || result.Index != index {
+			t.Errorf("Expected %q to report index %d, got %v, %v", input, index, result, err)
+		}
+	}
+	table.AddPattern(`!`, "bang")
+	if result, err := table.LookupResult("!"); err != nil || result.Index != 4 {
+		t.Errorf("Expected a pattern added later to report index 4, got %v, %v", result, err)
+	}
+}
+
+func TestRegexpTableBuilder_BuildTiered(t *testing.T) {
+	chain, err := newPriorityBuilder().BuildTiered(false, false)
+	if err != nil {
+		t.Fatalf("BuildTiered failed: %v", err)
+	}
+	if tiers := len(chain.Tables()); tiers != 3 {
+		t.Fatalf("Expected 3 tiers, got %d", tiers)
+	}
+	if patterns := chain.Tables()[0].Patterns(); len(patterns) != 2 || patterns[0] != `error` || patterns[1] != `warn` {
+		t.Errorf("Expected the first tier to hold error and warn, got %v", patterns)
+	}
+
+	tests := []struct {
+		input string
+		value string
+		tier  int
+		index int
+	}{
+		{"an error", "error", 0, 1},
+		{"warn 42", "warning", 0, 3},
+		{"abc 42", "number", 1, 2},
+		{"abc", "word", 2, 0},
+	}
+	for _, test := range tests {
+		result, tier, err := chain.LookupResult(test.input)
+		if err != nil {
+			t.Errorf("LookupResult(%q) failed: %v", test.input, err)
+			continue
+		}
+		if result.Value != test.value || tier != test.tier {
+			t.Errorf("Expected %q to match %q in tier %d, got %q in tier %d", test.input, test.value, test.tier, result.Value, tier)
+		}
+		if result.Index != test.index {
+			t.Errorf("Expected %q to report index %d, got %d", test.input, test.index, result.Index)
+		}
+	}
+}
+
+func TestRegexpTableBuilder_BuildTieredErrors(t *testing.T) {
+	_, err := NewRegexpTableBuilder[string]().
+		AddPattern(`ok`, "ok").
+		AddPatternWithPriority(`(`, "bad", 3).
+		BuildTiered(true, true)
+	if err == nil || !strings.Contains(err.Error(), "priority 3") {
+		t.Errorf("Expected an error naming the tier, got %v", err)
+	}
+
+	chain, err := NewRegexpTableBuilder[string]().BuildTiered(true, true)
+	if err != nil || len(chain.Tables()) != 0 {
+		t.Errorf("Expected an empty chain, got %v, %v", chain, err)
+	}
+}
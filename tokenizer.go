@@ -0,0 +1,166 @@
+package regexptable
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Token is a single lexeme recognised by a Tokenizer. Value is the value of the
+// pattern that matched (typically a token kind), Lexeme is the matched text and
+// Start/End are byte offsets of the lexeme within the tokenized input.
+type Token[T any] struct {
+	Value     T
+	Lexeme    string
+	Start     int
+	End       int
+	Matches   []string // Submatches as returned by Lookup, Matches[0] == Lexeme
+	Unmatched bool     // Whether the token is a run of input that no pattern matched
+}
+
+// Tokenizer splits an input string into consecutive tokens by repeatedly looking
+// up the remaining input in a start-anchored RegexpTable. Every byte of the input
+// must be covered by some pattern, so tables usually include a pattern for
+// whitespace.
+type Tokenizer[T any] struct {
+	table      *RegexpTable[T]
+	input      string
+	pos        int
+	err        error
+	unknown    T    // Value of unmatched runs, when hasUnknown is set
+	hasUnknown bool // Whether unmatched runs become tokens rather than errors
+	longest    bool // Whether tokens are the longest match, see SetLongestMatch
+	tieBreak   TieBreak[T]
+}
+
+// NewTokenizer creates a Tokenizer that scans input using the given table.
+// The table must be anchored at the start, otherwise Next reports an error.
+func NewTokenizer[T any](table *RegexpTable[T], input string) *Tokenizer[T] {
+	return &Tokenizer[T]{table: table, input: input}
+}
+
+// SetUnknownValue makes the tokenizer emit runs of input that no pattern matches
+// as tokens with the given value, with Unmatched set, instead of stopping with an
+// error. The tokens then partition the whole input, as pretty-printers and
+// highlighters need. A run extends up to the next offset where some pattern
+// matches a non-empty token.
+func (tk *Tokenizer[T]) SetUnknownValue(value T) {
+	tk.unknown = value
+	tk.hasUnknown = true
+}
+
+// Next returns the next token and true, or a zero token and false when the input
+// is exhausted or an error occurred. Use Err to distinguish the two cases.
+func (tk *Tokenizer[T]) Next() (Token[T], bool) {
+	var zero Token[T]
+	if tk.err != nil || tk.pos >= len(tk.input) {
+		return zero, false
+	}
+	if !tk.table.anchorStart {
+		tk.err = codeErrorf(CodeTableUnsupported, "tokenizer requires a start-anchored table")
+		return zero, false
+	}
+	if err := tk.table.checkNoNormalizers("Tokenizer"); err != nil {
+		tk.err = err
+		return zero, false
+	}
+
+	lookup := tk.table.Lookup
+	if tk.longest {
+		lookup = tk.lookupLongest
+	}
+	value, matches, err := lookup(tk.input[tk.pos:])
+	if tk.hasUnknown && (errors.Is(err, ErrNoMatch) || (err == nil && len(matches[0]) == 0)) {
+		return tk.unmatchedRun()
+	}
+	if err != nil {
+		tk.err = fmt.Errorf("no token at offset %d: %w", tk.pos, err)
+		return zero, false
+	}
+	if len(matches[0]) == 0 {
+		tk.err = fmt.Errorf("empty token at offset %d", tk.pos)
+		return zero, false
+	}
+
+	token := Token[T]{
+		Value:   value,
+		Lexeme:  matches[0],
+		Start:   tk.pos,
+		End:     tk.pos + len(matches[0]),
+		Matches: matches,
+	}
+	tk.pos = token.End
+	return token, true
+}
+
+// unmatchedRun returns the run of input from the current offset that no pattern
+// matches as a token with the unknown value.
+func (tk *Tokenizer[T]) unmatchedRun() (Token[T], bool) {
+	end := tk.pos
+	for {
+		_, width := utf8.DecodeRuneInString(tk.input[end:])
+		end += width
+		if end >= len(tk.input) {
+			break
+		}
+		_, matches, err := tk.table.Lookup(tk.input[end:])
+		if err == nil && len(matches[0]) > 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, ErrNoMatch) {
+			tk.err = fmt.Errorf("no token at offset %d: %w", end, err)
+			return Token[T]{}, false
+		}
+	}
+
+	lexeme := tk.input[tk.pos:end]
+	token := Token[T]{
+		Value:     tk.unknown,
+		Lexeme:    lexeme,
+		Start:     tk.pos,
+		End:       end,
+		Matches:   []string{lexeme},
+		Unmatched: true,
+	}
+	tk.pos = end
+	return token, true
+}
+
+// Offset returns the byte offset of the next token to be scanned.
+func (tk *Tokenizer[T]) Offset() int {
+	return tk.pos
+}
+
+// Err returns the error that stopped the tokenizer, or nil if the input was
+// consumed completely (or has not been consumed yet).
+func (tk *Tokenizer[T]) Err() error {
+	return tk.err
+}
+
+// Tokenize is the eager counterpart of Tokenizer: it scans the whole input and
+// returns all tokens. On failure it returns the tokens recognised so far together
+// with the error.
+func Tokenize[T any](table *RegexpTable[T], input string) ([]Token[T], error) {
+	return collectTokens(NewTokenizer(table, input))
+}
+
+// TokenizeWithUnknown is like Tokenize but emits runs of input that no pattern
+// matches as tokens with the unknown value, see Tokenizer.SetUnknownValue.
+func TokenizeWithUnknown[T any](table *RegexpTable[T], input string, unknown T) ([]Token[T], error) {
+	tokenizer := NewTokenizer(table, input)
+	tokenizer.SetUnknownValue(unknown)
+	return collectTokens(tokenizer)
+}
+
+// collectTokens runs a tokenizer to completion.
+func collectTokens[T any](tokenizer *Tokenizer[T]) ([]Token[T], error) {
+	var tokens []Token[T]
+	for {
+		token, ok := tokenizer.Next()
+		if !ok {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, tokenizer.Err()
+}
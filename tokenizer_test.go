@@ -0,0 +1,113 @@
+package regexptable
+
+import (
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\s+`, "space").
+		AddPattern(`\d+`, "number").
+		AddPattern(`[a-z]+`, "word").
+		AddPattern(`[+*/-]`, "operator").
+		MustBuild(true, false)
+
+	tokens, err := Tokenize(table, "x + 42")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+
+	expected := []Token[string]{
+		{Value: "word", Lexeme: "x", Start: 0, End: 1},
+		{Value: "space", Lexeme: " ", Start: 1, End: 2},
+		{Value: "operator", Lexeme: "+", Start: 2, End: 3},
+		{Value: "space", Lexeme: " ", Start: 3, End: 4},
+		{Value: "number", Lexeme: "42", Start: 4, End: 6},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i, want := range expected {
+		got := tokens[i]
+		if got.Value != want.Value || got.Lexeme != want.Lexeme || got.Start != want.Start || got.End != want.End {
+			t.Errorf("Token %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestTokenize_Errors(t *testing.T) {
+	t.Run("UnmatchedInput", func(t *testing.T) {
+		table := NewRegexpTableBuilder[string]().
+			AddPattern(`[a-z]+`, "word").
+			MustBuild(true, false)
+
+		tokens, err := Tokenize(table, "abc!")
+		if err == nil {
+			t.Fatal("Expected an error for unmatched input")
+		}
+		if len(tokens) != 1 || tokens[0].Lexeme != "abc" {
+			t.Errorf("Expected the tokens before the error, got %v", tokens)
+		}
+	})
+
+	t.Run("EmptyMatch", func(t *testing.T) {
+		table := NewRegexpTableBuilder[string]().
+			AddPattern(`[a-z]*`, "word").
+			MustBuild(true, false)
+
+		if _, err := Tokenize(table, "123"); err == nil {
+			t.Error("Expected an error for an empty token")
+		}
+	})
+
+	t.Run("UnanchoredTable", func(t *testing.T) {
+		table := NewRegexpTableBuilder[string]().
+			AddPattern(`[a-z]+`, "word").
+			MustBuild(false, false)
+
+		if _, err := Tokenize(table, "abc"); err == nil {
+			t.Error("Expected an error for a table that is not start-anchored")
+		}
+	})
+}
+
+func TestTokenizeWithUnknown(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\s+`, "space").
+		AddPattern(`\d+`, "number").
+		AddPattern(`x*`, "xs"). // Matches the empty string everywhere
+		MustBuild(true, false)
+
+	tokens, err := TokenizeWithUnknown(table, "12 é?! 3", "unknown")
+	if err != nil {
+		t.Fatalf("TokenizeWithUnknown failed: %v", err)
+	}
+
+	expected := []Token[string]{
+		{Value: "number", Lexeme: "12", Start: 0, End: 2},
+		{Value: "space", Lexeme: " ", Start: 2, End: 3},
+		{Value: "unknown", Lexeme: "é?!", Start: 3, End: 7, Unmatched: true},
+		{Value: "space", Lexeme: " ", Start: 7, End: 8},
+		{Value: "number", Lexeme: "3", Start: 8, End: 9},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i, want := range expected {
+		got := tokens[i]
+		if got.Value != want.Value || got.Lexeme != want.Lexeme || got.Start != want.Start || got.End != want.End || got.Unmatched != want.Unmatched {
+			t.Errorf("Token %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// A trailing run extends to the end of the input.
+	tokens, err = TokenizeWithUnknown(table, "1??", "unknown")
+	if err != nil || len(tokens) != 2 || tokens[1].Lexeme != "??" {
+		t.Errorf("Expected a trailing unknown run, got %v, %v", tokens, err)
+	}
+
+	// Without an unknown value the tokenizer still stops at unmatched input.
+	if _, err := Tokenize(table, "1?"); err == nil {
+		t.Error("Expected Tokenize to fail on unmatched input")
+	}
+}
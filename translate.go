@@ -0,0 +1,249 @@
+package regexptable
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UntranslatableRule describes a rule that an exporter such as ExportGrok could
+// not express in the target system.
+type UntranslatableRule struct {
+	Index   int    // The rule's insertion index, as in Result.Index
+	Pattern string // The rule's pattern
+	Reason  string // What could not be translated
+}
+
+// TranslationError is returned by the exporters when some rules could not be
+// translated. The other rules are still written, so the error is a warning
+// about an incomplete export rather than a failed one; I/O errors are returned
+// as they are.
+type TranslationError struct {
+	Rules []UntranslatableRule
+}
+
+func (e *TranslationError) Error() string {
+	reasons := make([]string, len(e.Rules))
+	for i, rule := range e.Rules {
+		reasons[i] = fmt.Sprintf("rule %d (%s): %s", rule.Index, rule.Pattern, rule.Reason)
+	}
+	return fmt.Sprintf("%d rules could not be translated: %s", len(e.Rules), strings.Join(reasons, "; "))
+}
+
+// ErrorCode returns CodeUntranslatable.
+func (e *TranslationError) ErrorCode() ErrorCode {
+	return CodeUntranslatable
+}
+
+// dialect describes how a regexp syntax other than Go's spells the constructs
+// whose spelling or meaning differs.
+type dialect struct {
+	anyChar        string // Any character including newline
+	anyCharNotNL   string // Any character except newline
+	beginLine      string
+	endLine        string
+	wordBoundary   string // An ASCII word boundary, as Go's \b
+	noWordBoundary string // Not an ASCII word boundary, as Go's \B
+	groupPrefix    string // Opens a named group, followed by the name and ">"
+}
+
+// asciiWord matches the characters that Go's \b counts as word characters.
+const asciiWord = `[0-9A-Za-z_]`
+
+// onigurumaDialect is the Ruby syntax of Oniguruma, used by grok. Ruby's . never
+// matches newline unless the m flag is set, its ^ and $ always match at line
+// boundaries, and its \b is Unicode-aware, so Go's ASCII word boundaries are
+// spelled out with lookaround.
+var onigurumaDialect = &dialect{
+	anyChar:        `(?m:.)`,
+	anyCharNotNL:   `.`,
+	beginLine:      `^`,
+	endLine:        `$`,
+	wordBoundary:   `(?:(?<=` + asciiWord + `)(?!` + asciiWord + `)|(?<!` + asciiWord + `)(?=` + asciiWord + `))`,
+	noWordBoundary: `(?:(?<=` + asciiWord + `)(?=` + asciiWord + `)|(?<!` + asciiWord + `)(?!` + asciiWord + `))`,
+	groupPrefix:    `(?<`,
+}
+
+// rustDialect is the syntax of Rust's regex crate, used by VRL. It is close to
+// Go's, except that \b is Unicode-aware unless Unicode mode is turned off.
+var rustDialect = &dialect{
+	anyChar:        `(?s:.)`,
+	anyCharNotNL:   `.`,
+	beginLine:      `(?m:^)`,
+	endLine:        `(?m:$)`,
+	wordBoundary:   `(?-u:\b)`,
+	noWordBoundary: `(?-u:\B)`,
+	groupPrefix:    `(?P<`,
+}
+
+// translatePattern rewrites a pattern in Go syntax into the dialect, anchored as
+// given. Flags are resolved while parsing, so the result never depends on how
+// the dialect treats inline flags.
+func (d *dialect) translatePattern(pattern string, anchoring Anchoring) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if anchoring.AnchorsStart() {
+		b.WriteString(`\A`)
+	}
+	b.WriteString("(?:")
+	if err := d.write(&b, re); err != nil {
+		return "", err
+	}
+	b.WriteString(")")
+	if anchoring.AnchorsEnd() {
+		b.WriteString(`\z`)
+	}
+	return b.String(), nil
+}
+
+// write appends the translation of re to b.
+func (d *dialect) write(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch, syntax.OpEmptyMatch, syntax.OpLiteral, syntax.OpCharClass:
+		b.WriteString(re.String()) // Spelled the same way in every dialect
+	case syntax.OpAnyChar:
+		b.WriteString(d.anyChar)
+	case syntax.OpAnyCharNotNL:
+		b.WriteString(d.anyCharNotNL)
+	case syntax.OpBeginLine:
+		b.WriteString(d.beginLine)
+	case syntax.OpEndLine:
+		b.WriteString(d.endLine)
+	case syntax.OpBeginText:
+		b.WriteString(`\A`)
+	case syntax.OpEndText:
+		b.WriteString(`\z`)
+	case syntax.OpWordBoundary:
+		b.WriteString(d.wordBoundary)
+	case syntax.OpNoWordBoundary:
+		b.WriteString(d.noWordBoundary)
+	case syntax.OpCapture:
+		if re.Name == "" {
+			b.WriteString("(")
+		} else if unicode.IsDigit(rune(re.Name[0])) {
+			return fmt.Errorf("group name %q starts with a digit", re.Name)
+		} else {
+			b.WriteString(d.groupPrefix + re.Name + ">")
+		}
+		if err := d.write(b, re.Sub[0]); err != nil {
+			return err
+		}
+		b.WriteString(")")
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := d.writeAtom(b, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteString("*")
+		case syntax.OpPlus:
+			b.WriteString("+")
+		case syntax.OpQuest:
+			b.WriteString("?")
+		default:
+			b.WriteString("{" + strconv.Itoa(re.Min))
+			if re.Max != re.Min {
+				b.WriteString(",")
+				if re.Max >= 0 {
+					b.WriteString(strconv.Itoa(re.Max))
+				}
+			}
+			b.WriteString("}")
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			b.WriteString("?")
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				if err := d.writeGroup(b, sub); err != nil {
+					return err
+				}
+			} else if err := d.write(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			if err := d.write(b, sub); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported construct %s", re)
+	}
+	return nil
+}
+
+// writeAtom appends the translation of re to b as a single unit that a
+// repetition operator can follow.
+func (d *dialect) writeAtom(b *strings.Builder, re *syntax.Regexp) error {
+	switch {
+	case re.Op == syntax.OpCharClass, re.Op == syntax.OpAnyChar, re.Op == syntax.OpAnyCharNotNL,
+		re.Op == syntax.OpCapture, re.Op == syntax.OpLiteral && len(re.Rune) == 1:
+		return d.write(b, re)
+	}
+	return d.writeGroup(b, re)
+}
+
+// writeGroup appends the translation of re to b in a non-capturing group.
+func (d *dialect) writeGroup(b *strings.Builder, re *syntax.Regexp) error {
+	b.WriteString("(?:")
+	if err := d.write(b, re); err != nil {
+		return err
+	}
+	b.WriteString(")")
+	return nil
+}
+
+// translatedRule is one rule of a table rewritten by an exporter.
+type translatedRule struct {
+	index      int
+	name       string   // The name given with AddNamedPattern, "" if none
+	expression string   // The rule's pattern in the target dialect, with the table's anchoring
+	values     []string // The rule's values, formatted
+}
+
+// translateRules rewrites every rule of the table into the dialect in match
+// order, returning the rules that were translated and a TranslationError
+// listing those that were not, if any.
+func (rt *RegexpTable[T]) translateRules(d *dialect, formatValue func(T) string) ([]translatedRule, error) {
+	if err := rt.ensureCompiled(); err != nil {
+		return nil, err
+	}
+	if formatValue == nil {
+		formatValue = func(value T) string { return fmt.Sprint(value) }
+	}
+
+	var rules []translatedRule
+	var problems []UntranslatableRule
+	for _, entry := range rt.maplets {
+		index := entry.insertionIndex()
+		if entry.exclusion != nil {
+			problems = append(problems, UntranslatableRule{index, entry.Pattern, "exclusions cannot be translated"})
+			continue
+		}
+		expression, err := d.translatePattern(rt.effectivePattern(entry), rt.Anchoring())
+		if err != nil {
+			problems = append(problems, UntranslatableRule{index, entry.Pattern, err.Error()})
+			continue
+		}
+		rule := translatedRule{index: index, name: entry.name, expression: expression}
+		for _, value := range entry.values() {
+			rule.values = append(rule.values, formatValue(value))
+		}
+		rules = append(rules, rule)
+	}
+	if len(problems) > 0 {
+		return rules, &TranslationError{Rules: problems}
+	}
+	return rules, nil
+}
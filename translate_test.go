@@ -0,0 +1,64 @@
+package regexptable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDialect_TranslatePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		grok    string
+		rust    string
+	}{
+		{`\d+`, `[0-9]+`, `[0-9]+`},
+		{`(?i)ab`, `(?i:AB)`, `(?i:AB)`},
+		{`a.b`, `a.b`, `a.b`},
+		{`(?s)a.`, `a(?m:.)`, `a(?s:.)`},
+		{`(?m)^a$`, `^a$`, `(?m:^)a(?m:$)`},
+		{`^a$`, `\Aa\z`, `\Aa\z`},
+		{`\bx`, `(?:(?<=[0-9A-Za-z_])(?![0-9A-Za-z_])|(?<![0-9A-Za-z_])(?=[0-9A-Za-z_]))x`, `(?-u:\b)x`},
+		{`(?P<n>ab|cd){2,}?`, `(?<n>ab|cd){2,}?`, `(?P<n>ab|cd){2,}?`},
+		{`x(?:ab)*|y{3}`, `x(?:ab)*|y{3}`, `x(?:ab)*|y{3}`},
+		{`a(b|cd)e`, `a(b|cd)e`, `a(b|cd)e`},
+		{`a(?:bc|de)f`, `a(?:bc|de)f`, `a(?:bc|de)f`},
+	}
+	for _, tt := range tests {
+		grok, err := onigurumaDialect.translatePattern(tt.pattern, AnchorNone)
+		if err != nil || grok != "(?:"+tt.grok+")" {
+			t.Errorf("Expected %s to become (?:%s) in grok, got %s, %v", tt.pattern, tt.grok, grok, err)
+		}
+		rust, err := rustDialect.translatePattern(tt.pattern, AnchorNone)
+		if err != nil || rust != "(?:"+tt.rust+")" {
+			t.Errorf("Expected %s to become (?:%s) in Rust, got %s, %v", tt.pattern, tt.rust, rust, err)
+		}
+	}
+
+	if anchored, _ := rustDialect.translatePattern(`a`, AnchorBoth); anchored != `\A(?:a)\z` {
+		t.Errorf("Expected the anchoring to be applied, got %s", anchored)
+	}
+	if _, err := rustDialect.translatePattern(`(?P<1st>a)`, AnchorNone); err == nil {
+		t.Error("Expected a group name starting with a digit to be rejected")
+	}
+	if _, err := rustDialect.translatePattern(`(?<=a)b`, AnchorNone); err == nil {
+		t.Error("Expected a pattern Go cannot parse to be rejected")
+	}
+}
+
+func TestTranslationError(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`a`, "a")
+	table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `if`})
+	rules, err := table.translateRules(rustDialect, nil)
+	var incomplete *TranslationError
+	if !errors.As(err, &incomplete) || len(incomplete.Rules) != 1 || incomplete.Rules[0].Index != 1 {
+		t.Fatalf("Expected the excluding rule to be reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "rule 1 ([a-z]+): exclusions cannot be translated") {
+		t.Errorf("Unexpected message %q", err)
+	}
+	if len(rules) != 1 || rules[0].expression != `\A(?:a)\z` {
+		t.Errorf("Expected the other rule to be translated, got %+v", rules)
+	}
+}
@@ -0,0 +1,120 @@
+package regexptable
+
+import (
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// SetTwoPhaseLookup enables or disables two-phase lookups, a different trade-off
+// from the single union that suits huge tables. In the first phase a prefilter
+// selects the candidate patterns, those that can start with one of the bytes of
+// the input (only its first byte when the table is anchored at the start); in the
+// second, each candidate is matched on its own and the winner is chosen as the
+// union would choose it. When inputs contain few distinct bytes and patterns start
+// with distinctive characters, most patterns are never tried.
+//
+// The prefilter is built by Recompile from the patterns in Go's syntax. Patterns
+// that can match the empty string, or that Go cannot parse, are always
+// candidates. The mode applies to the string lookups, Lookup, LookupResult and
+// the methods built on them, and requires compiled regexps that implement
+// IndexMatcher. Consider SetPrecompileIndividuals too, so that candidates are not
+// compiled on first use.
+func (rt *RegexpTable[T]) SetTwoPhaseLookup(enabled bool) {
+	if rt.twoPhase != enabled {
+		rt.twoPhase = enabled
+		rt.needsRecompile = true
+	}
+}
+
+// firstBytePrefilter maps each byte to the entries whose matches can start with it.
+type firstBytePrefilter struct {
+	byByte [256][]int // Positions in the table of the entries that can start with each byte
+	always []int      // Positions of the entries that are candidates for every input
+}
+
+// newFirstBytePrefilter builds the prefilter for the table's entries, in table order.
+func (rt *RegexpTable[T]) newFirstBytePrefilter() *firstBytePrefilter {
+	prefilter := &firstBytePrefilter{}
+	for i, entry := range rt.maplets {
+		re, err := syntax.Parse(rt.effectivePattern(entry), syntax.Perl)
+		if err != nil || nullable(re) {
+			prefilter.always = append(prefilter.always, i)
+			continue
+		}
+		var first [256]bool
+		markFirstBytes(&first, firstRunes(re))
+		for b, ok := range first {
+			if ok {
+				prefilter.byByte[b] = append(prefilter.byByte[b], i)
+			}
+		}
+	}
+	return prefilter
+}
+
+// markFirstBytes marks the bytes that can begin the UTF-8 encoding of a rune in
+// one of the given inclusive rune ranges.
+func markFirstBytes(first *[256]bool, ranges []rune) {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], min(ranges[i+1], utf8.MaxRune)
+		for r := lo; r <= min(hi, utf8.RuneSelf-1); r++ {
+			first[r] = true
+		}
+		if hi < utf8.RuneSelf {
+			continue
+		}
+		if lo <= utf8.RuneError && utf8.RuneError <= hi {
+			// Invalid UTF-8 matches as U+FFFD, so any non-ASCII byte may start it.
+			for b := utf8.RuneSelf; b < 256; b++ {
+				first[b] = true
+			}
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		utf8.EncodeRune(buf[:], max(lo, utf8.RuneSelf))
+		from := buf[0]
+		utf8.EncodeRune(buf[:], hi)
+		for b := int(from); b <= int(buf[0]); b++ {
+			first[b] = true
+		}
+	}
+}
+
+// selected reports which of the n entries in the table the prefilter selects for
+// an input.
+func (p *firstBytePrefilter) selected(input string, n int, anchorStart bool) []bool {
+	selected := make([]bool, n)
+	for _, i := range p.always {
+		selected[i] = true
+	}
+	if anchorStart {
+		input = input[:min(len(input), 1)]
+	}
+	var seen [256]bool
+	for i := 0; i < len(input); i++ {
+		b := input[i]
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		for _, k := range p.byByte[b] {
+			selected[k] = true
+		}
+	}
+	return selected
+}
+
+// locateTwoPhase is locateEntry for a table with two-phase lookups.
+func (rt *RegexpTable[T]) locateTwoPhase(input string) (located[T], error) {
+	var candidates []*ValueAndPattern[T]
+	for i, ok := range rt.prefilter.selected(input, len(rt.maplets), rt.anchorStart) {
+		if ok {
+			candidates = append(candidates, rt.maplets[i])
+		}
+	}
+	entry, loc, err := rt.leftmostIndexes(input, candidates, rt.individualRegexp)
+	if err != nil {
+		return located[T]{}, err
+	}
+	return indexed(entry, input, loc), nil
+}
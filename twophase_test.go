@@ -0,0 +1,79 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegexpTable_TwoPhaseLookup(t *testing.T) {
+	patterns := []string{
+		`error: (\w+)`,
+		`(?i)warn`,
+		`\d+`,
+		`[é-ü]+`,
+		`x*`,
+		`^start`,
+		`(?:foo|bar)baz`,
+		`.end`,
+	}
+	inputs := []string{
+		"", "error: disk", "WARNING", "call 42", "café", "start", "a start",
+		"barbaz", "fooba", "the end", "\xff", "no match here", "xx",
+	}
+	for _, anchoring := range []Anchoring{AnchorNone, AnchorStart, AnchorEnd, AnchorBoth} {
+		builder := NewRegexpTableBuilder[string]()
+		for _, pattern := range patterns {
+			builder.AddPattern(pattern, pattern)
+		}
+		union := builder.MustBuild(anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+		twoPhase := builder.Clone().WithTwoPhaseLookup(true).MustBuild(anchoring.AnchorsStart(), anchoring.AnchorsEnd())
+		if twoPhase.prefilter == nil {
+			t.Fatal("Expected the two-phase table to have a prefilter")
+		}
+
+		for _, input := range inputs {
+			expected := FormatResult(union.LookupResult(input))
+			if got := FormatResult(twoPhase.LookupResult(input)); got != expected {
+				t.Errorf("%v, %q: expected %s, got %s", anchoring, input, expected, got)
+			}
+		}
+	}
+}
+
+func TestRegexpTable_TwoPhaseCandidates(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	table.AddPattern(`abc`, "abc")
+	table.AddPattern(`[0-9]+`, "number")
+	table.AddPattern(`(?i)k`, "k")
+	table.AddPattern(`é`, "e-acute")
+	table.AddPattern(`a?`, "optional")
+	table.SetTwoPhaseLookup(true)
+	if err := table.Recompile(); err != nil {
+		t.Fatalf("Recompile failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []bool
+	}{
+		{"xyz", []bool{false, false, false, false, true}},
+		{"a1", []bool{true, true, false, false, true}},
+		{"K", []bool{false, false, true, false, true}},
+		{"\u212a", []bool{false, false, true, false, true}}, // The Kelvin sign folds to k
+		{"café", []bool{true, false, false, true, true}},
+	}
+	for _, test := range tests {
+		if got := table.prefilter.selected(test.input, len(table.maplets), false); !slices.Equal(got, test.expected) {
+			t.Errorf("selected(%q): expected %v, got %v", test.input, test.expected, got)
+		}
+	}
+	if got := table.prefilter.selected("1a", len(table.maplets), true); !slices.Equal(got, []bool{false, true, false, false, true}) {
+		t.Errorf("Expected only the first byte to count when anchored, got %v", got)
+	}
+
+	table.SetTwoPhaseLookup(false)
+	table.Lookup("abc")
+	if table.prefilter != nil {
+		t.Error("Expected the prefilter to be dropped when the mode is disabled")
+	}
+}
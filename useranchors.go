@@ -0,0 +1,162 @@
+package regexptable
+
+import "fmt"
+
+// AnchorPolicy says what Build does with explicit anchors, such as ^ and $, that
+// users write in their patterns, see WithAnchorPolicy.
+type AnchorPolicy int
+
+const (
+	// AnchorsKeep compiles patterns as written, so a pattern such as ^foo$ in a
+	// table anchored at both ends compiles as ^(?:^foo$)$. This is the default.
+	AnchorsKeep AnchorPolicy = iota
+
+	// AnchorsStrip removes the anchors that repeat the table's anchoring, as
+	// reported by RedundantAnchors, and keeps any others.
+	AnchorsStrip
+
+	// AnchorsReject makes Build report an error for any pattern with an explicit
+	// anchor, leaving anchoring to the table alone.
+	AnchorsReject
+)
+
+// String returns the name of the policy.
+func (p AnchorPolicy) String() string {
+	switch p {
+	case AnchorsKeep:
+		return "keep"
+	case AnchorsStrip:
+		return "strip"
+	case AnchorsReject:
+		return "reject"
+	default:
+		return "unknown"
+	}
+}
+
+// WithAnchorPolicy sets what Build does with explicit anchors in patterns. Anchors
+// that repeat the table's anchoring are harmless with Go's engine, but engines
+// disagree on the finer points, such as whether $ matches before a final newline,
+// so rule sets meant to behave the same everywhere should strip or reject them.
+// The policy applies to each pattern as added, before any folding, and not to
+// the patterns of exclusions. Stripped patterns are reported without their
+// anchors, e.g. by Result.Pattern.
+func (b *RegexpTableBuilder[T]) WithAnchorPolicy(policy AnchorPolicy) *RegexpTableBuilder[T] {
+	b.anchorPolicy = policy
+	return b
+}
+
+// applyAnchorPolicy applies the builder's AnchorPolicy to the entries for a table
+// with the given anchoring, returning the problems found under AnchorsReject.
+func (b *RegexpTableBuilder[T]) applyAnchorPolicy(entries []patternEntry[T], anchoring Anchoring) []error {
+	var errs []error
+	for i, entry := range entries {
+		switch b.anchorPolicy {
+		case AnchorsStrip:
+			entries[i].pattern = stripOffsets(entry.pattern, RedundantAnchors(entry.pattern, anchoring))
+		case AnchorsReject:
+			if offsets := findAnchors(entry.pattern); len(offsets) > 0 {
+				errs = append(errs, fmt.Errorf("pattern '%s'%s contains an explicit anchor at offset %d; anchoring is controlled by the table", entry.pattern, entry.describeName(), offsets[0]))
+			}
+		}
+	}
+	return errs
+}
+
+// RedundantAnchors returns the byte offsets of the explicit anchors in a pattern
+// that repeat the given anchoring, which a table would add anyway: a ^ or \A that
+// starts a top-level branch of a pattern anchored at the start, and a $ that ends
+// one of a pattern anchored at the end. \z and \Z are never redundant, since a
+// table anchors the end with $, which some engines let match before a final
+// newline. Nor is any anchor in a pattern that sets the m flag, which makes ^
+// and $ match at line boundaries. Like findAnchors, the scan is purely textual.
+func RedundantAnchors(pattern string, anchoring Anchoring) []int {
+	if anchoring == AnchorNone || setsMultiline(pattern) {
+		return nil
+	}
+	branchStarts := map[int]bool{0: true}
+	branchEnds := map[int]bool{len(pattern): true}
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				branchEnds[i] = true
+				branchStarts[i+1] = true
+			}
+		}
+	}
+
+	var offsets []int
+	for _, offset := range findAnchors(pattern) {
+		switch pattern[offset] {
+		case '^':
+			if anchoring.AnchorsStart() && branchStarts[offset] {
+				offsets = append(offsets, offset)
+			}
+		case '$':
+			if anchoring.AnchorsEnd() && branchEnds[offset+1] {
+				offsets = append(offsets, offset)
+			}
+		case '\\':
+			if pattern[offset+1] == 'A' && anchoring.AnchorsStart() && branchStarts[offset] {
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+	return offsets
+}
+
+// setsMultiline reports whether a pattern turns on the m flag anywhere, as in
+// (?m) or (?im:x).
+func setsMultiline(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '[':
+			i = skipClass(pattern, i)
+		case '(':
+			if i+1 < len(pattern) && pattern[i+1] == '?' {
+			flags:
+				for j := i + 2; j < len(pattern); j++ {
+					switch c := pattern[j]; {
+					case c == 'm':
+						return true
+					case c == '-' || c == ':' || c == ')':
+						break flags // Flags after - are turned off
+					case !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'):
+						break flags // Not a flag group, e.g. (?P<name>x)
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// stripOffsets removes the anchors at the given offsets, as found by findAnchors,
+// from a pattern.
+func stripOffsets(pattern string, offsets []int) string {
+	if len(offsets) == 0 {
+		return pattern
+	}
+	stripped := make([]byte, 0, len(pattern))
+	last := 0
+	for _, offset := range offsets {
+		stripped = append(stripped, pattern[last:offset]...)
+		last = offset + 1
+		if pattern[offset] == '\\' {
+			last++ // \A is two bytes
+		}
+	}
+	return string(append(stripped, pattern[last:]...))
+}
@@ -0,0 +1,84 @@
+package regexptable
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRedundantAnchors(t *testing.T) {
+	testCases := []struct {
+		pattern   string
+		anchoring Anchoring
+		expected  []int
+	}{
+		{`^foo$`, AnchorBoth, []int{0, 4}},
+		{`^foo$`, AnchorStart, []int{0}},
+		{`^foo$`, AnchorEnd, []int{4}},
+		{`^foo$`, AnchorNone, nil},
+		{`\Afoo\z`, AnchorBoth, []int{0}},
+		{`^a|^b$|c`, AnchorBoth, []int{0, 3, 5}},
+		{`(^a|b)`, AnchorBoth, nil},
+		{`a^b$c`, AnchorBoth, nil},
+		{`(?m)^foo$`, AnchorBoth, nil},
+		{`(?i)^foo$`, AnchorBoth, []int{8}},
+		{`(?P<m>x)$`, AnchorEnd, []int{8}},
+		{`[|]^x`, AnchorStart, nil},
+		{`\|^x`, AnchorStart, nil},
+	}
+	for _, tc := range testCases {
+		if got := RedundantAnchors(tc.pattern, tc.anchoring); !slices.Equal(got, tc.expected) {
+			t.Errorf("RedundantAnchors(%q, %s) = %v, expected %v", tc.pattern, tc.anchoring, got, tc.expected)
+		}
+	}
+}
+
+func TestWithAnchorPolicy_Strip(t *testing.T) {
+	table, err := NewRegexpTableBuilder[string]().
+		WithAnchorPolicy(AnchorsStrip).
+		AddPattern(`^foo$`, "foo").
+		AddPattern(`^a|^b$`, "ab").
+		AddPattern(`x\z`, "x").
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+	for i, expected := range []string{`foo`, `a|b`, `x\z`} {
+		if got := table.PatternAt(i); got != expected {
+			t.Errorf("Expected pattern %d to be %q, got %q", i, expected, got)
+		}
+	}
+	for input, expected := range map[string]string{"foo": "foo", "b": "ab", "x": "x"} {
+		if value, _, err := table.Lookup(input); err != nil || value != expected {
+			t.Errorf("Expected %q for %q, got %q, %v", expected, input, value, err)
+		}
+	}
+
+	unanchored, err := NewRegexpTableBuilder[string]().
+		WithAnchorPolicy(AnchorsStrip).
+		AddPattern(`^foo$`, "foo").
+		Build(false, false)
+	if err != nil {
+		t.Fatalf("Failed to build table: %v", err)
+	}
+	if got := unanchored.PatternAt(0); got != `^foo$` {
+		t.Errorf("Expected anchors kept in an unanchored table, got %q", got)
+	}
+}
+
+func TestWithAnchorPolicy_Reject(t *testing.T) {
+	builder := NewRegexpTableBuilder[string]().
+		WithAnchorPolicy(AnchorsReject).
+		AddPattern(`foo`, "foo").
+		AddPattern(`bar$`, "bar")
+	_, err := builder.Build(false, false)
+	if ErrorCodeOf(err) != CodeCompileFailed || !strings.Contains(err.Error(), "explicit anchor at offset 3") {
+		t.Errorf("Expected the anchor to be rejected, got %v", err)
+	}
+	if errs := builder.Validate(true, true); len(errs) != 1 {
+		t.Errorf("Expected Validate to report 1 error, got %v", errs)
+	}
+	if _, err := builder.Clone().WithAnchorPolicy(AnchorsKeep).Build(true, true); err != nil {
+		t.Errorf("Expected anchors to be kept, got %v", err)
+	}
+}
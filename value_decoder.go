@@ -0,0 +1,78 @@
+package regexptable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValueDecoder turns the raw JSON value of a spec entry into a table value. It is
+// the hook that lets a Loader build typed tables, such as tables of an
+// application's token types, from configuration.
+type ValueDecoder[T any] func(raw json.RawMessage) (T, error)
+
+// JSONValue decodes values with encoding/json. It is the decoder a Loader uses
+// unless told otherwise, and suits any T that encoding/json can unmarshal into.
+func JSONValue[T any](raw json.RawMessage) (T, error) {
+	var value T
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+// StringValue decodes a JSON string.
+func StringValue(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil || isJSONNull(raw) {
+		return "", fmt.Errorf("expected a string, got %s", raw)
+	}
+	return value, nil
+}
+
+// IntValue decodes a JSON number that is a whole number. A JSON string holding a
+// whole number is accepted too, since that is how values read from CSV and TSV
+// rule files arrive.
+func IntValue(raw json.RawMessage) (int, error) {
+	var value int
+	if err := json.Unmarshal(raw, &value); err == nil && !isJSONNull(raw) {
+		return value, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(text)); err == nil {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %s", raw)
+}
+
+// EnumValue returns a decoder that maps JSON strings to values by name, for
+// example {"number": TokenNumber, "word": TokenWord}. Names that are not in the
+// map are rejected.
+func EnumValue[T any](names map[string]T) ValueDecoder[T] {
+	return func(raw json.RawMessage) (T, error) {
+		var zero T
+		name, err := StringValue(raw)
+		if err != nil {
+			return zero, err
+		}
+		value, ok := names[name]
+		if !ok {
+			known := make([]string, 0, len(names))
+			for name := range names {
+				known = append(known, name)
+			}
+			slices.Sort(known)
+			return zero, fmt.Errorf("unknown value %q (expected one of %s)", name, strings.Join(known, ", "))
+		}
+		return value, nil
+	}
+}
+
+// isJSONNull reports whether raw is the JSON null, which encoding/json silently
+// accepts for any type.
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
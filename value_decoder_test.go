@@ -0,0 +1,71 @@
+package regexptable
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testTokenType int
+
+const (
+	testTokenNumber testTokenType = iota + 1
+	testTokenWord
+)
+
+func TestLoader_EnumValueDecoder(t *testing.T) {
+	spec := `{
+		"version": 1,
+		"anchorStart": true,
+		"anchorEnd": true,
+		"entries": [
+			{"pattern": "\\d+", "value": "number"},
+			{"pattern": "[a-z]+", "value": "word"}
+		]
+	}`
+	loader := NewLoader[testTokenType](LoadStrict).WithValueDecoder(EnumValue(map[string]testTokenType{
+		"number": testTokenNumber,
+		"word":   testTokenWord,
+	}))
+	table, err := loader.Load(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if value, _, _ := table.TryLookup("42"); value != testTokenNumber {
+		t.Errorf("Expected testTokenNumber, got %v", value)
+	}
+	if value, _, _ := table.TryLookup("abc"); value != testTokenWord {
+		t.Errorf("Expected testTokenWord, got %v", value)
+	}
+
+	bad := strings.Replace(spec, `"word"`, `"identifier"`, 1)
+	_, err = loader.Load(strings.NewReader(bad))
+	if err == nil || !strings.Contains(err.Error(), `unknown value "identifier" (expected one of number, word)`) {
+		t.Errorf("Expected an unknown value error, got %v", err)
+	}
+}
+
+func TestValueDecoders(t *testing.T) {
+	if value, err := StringValue(json.RawMessage(`"hello"`)); err != nil || value != "hello" {
+		t.Errorf("StringValue: expected hello, got %q, %v", value, err)
+	}
+	for _, raw := range []string{`42`, `null`} {
+		if _, err := StringValue(json.RawMessage(raw)); err == nil {
+			t.Errorf("StringValue(%s): expected an error", raw)
+		}
+	}
+	if value, err := IntValue(json.RawMessage(`42`)); err != nil || value != 42 {
+		t.Errorf("IntValue: expected 42, got %d, %v", value, err)
+	}
+	if value, err := IntValue(json.RawMessage(`"42"`)); err != nil || value != 42 {
+		t.Errorf("IntValue: expected 42 from a string, got %d, %v", value, err)
+	}
+	for _, raw := range []string{`4.5`, `"forty"`, `null`} {
+		if _, err := IntValue(json.RawMessage(raw)); err == nil {
+			t.Errorf("IntValue(%s): expected an error", raw)
+		}
+	}
+	if value, err := JSONValue[[]int](json.RawMessage(`[1, 2]`)); err != nil || len(value) != 2 {
+		t.Errorf("JSONValue: expected [1 2], got %v, %v", value, err)
+	}
+}
@@ -0,0 +1,44 @@
+package regexptable
+
+import (
+	"fmt"
+)
+
+// AddPatternValues adds a pattern that maps to several values, such as the labels
+// a tagging rule assigns. Lookup returns the first value; LookupValues and
+// LookupResult return them all. At least one value is required.
+func (rt *RegexpTable[T]) AddPatternValues(pattern string, values ...T) error {
+	if len(values) == 0 {
+		return fmt.Errorf("pattern '%s' has no values", pattern)
+	}
+	err := rt.AddPattern(pattern, values[0])
+	if err != nil {
+		return err
+	}
+	rt.maplets[len(rt.maplets)-1].moreValues = append([]T(nil), values[1:]...)
+	return nil
+}
+
+// LookupValues is like Lookup but returns every value of the winning pattern. For
+// patterns added with a single value the slice has one element. The slice belongs
+// to the caller.
+func (rt *RegexpTable[T]) LookupValues(input string) ([]T, []string, error) {
+	entry, matches, err := rt.find(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.values(), matches, nil
+}
+
+// TryLookupValues is like LookupValues but reports failure with a boolean.
+func (rt *RegexpTable[T]) TryLookupValues(input string) ([]T, []string, bool) {
+	values, matches, err := rt.LookupValues(input)
+	return values, matches, err == nil
+}
+
+// values returns a fresh slice of all the entry's values.
+func (vp *ValueAndPattern[T]) values() []T {
+	values := make([]T, 0, 1+len(vp.moreValues))
+	values = append(values, vp.Value)
+	return append(values, vp.moreValues...)
+}
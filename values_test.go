@@ -0,0 +1,67 @@
+package regexptable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegexpTable_AddPatternValues(t *testing.T) {
+	table := NewRegexpTable[string](false, false)
+	if err := table.AddPatternValues(`(?i)invoice|receipt`, "finance", "document"); err != nil {
+		t.Fatalf("AddPatternValues failed: %v", err)
+	}
+	if err := table.AddPattern(`(?i)meeting`, "calendar"); err != nil {
+		t.Fatalf("AddPattern failed: %v", err)
+	}
+	if err := table.AddPatternValues(`x`); err == nil {
+		t.Error("Expected an error for a pattern without values")
+	}
+
+	values, matches, err := table.LookupValues("Your invoice is attached")
+	if err != nil {
+		t.Fatalf("LookupValues failed: %v", err)
+	}
+	if !slices.Equal(values, []string{"finance", "document"}) || matches[0] != "invoice" {
+		t.Errorf("Expected [finance document] for invoice, got %v, %v", values, matches)
+	}
+
+	// The first value is the one Lookup returns.
+	if value, _, _ := table.TryLookup("receipt"); value != "finance" {
+		t.Errorf("Expected finance from Lookup, got %q", value)
+	}
+
+	// Single-valued patterns return a one-element slice.
+	if values, _, ok := table.TryLookupValues("Meeting at 3"); !ok || !slices.Equal(values, []string{"calendar"}) {
+		t.Errorf("Expected [calendar], got %v", values)
+	}
+
+	// Results carry all the values and the caller owns them.
+	result, err := table.LookupResult("invoice")
+	if err != nil {
+		t.Fatalf("LookupResult failed: %v", err)
+	}
+	result.Values[1] = "mutated"
+	if values, _, _ := table.TryLookupValues("invoice"); values[1] != "document" {
+		t.Errorf("Expected the table's values to be unaffected, got %v", values)
+	}
+}
+
+func TestRegexpTableBuilder_AddPatternValues(t *testing.T) {
+	table, err := NewRegexpTableBuilder[int]().
+		AddPatternValues(`\d+`, 1, 2, 3).
+		AddPatternExcluding(`[a-z]+`, 4, Exclusion{NotMatching: `no`}).
+		Build(true, true)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if values, _, _ := table.TryLookupValues("42"); !slices.Equal(values, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", values)
+	}
+	if values, _, _ := table.TryLookupValues("yes"); !slices.Equal(values, []int{4}) {
+		t.Errorf("Expected [4], got %v", values)
+	}
+
+	if _, err := NewRegexpTableBuilder[int]().AddPatternValues(`x`).Build(true, true); err == nil {
+		t.Error("Expected Build to report a pattern without values")
+	}
+}
@@ -0,0 +1,60 @@
+package regexptable
+
+import "iter"
+
+// ReadOnlyTable is the read-only side of a RegexpTable, for handing a table to
+// code that should classify inputs with it but not change it. See View.
+type ReadOnlyTable[T any] interface {
+
+	// Lookup matches the input against the table, as RegexpTable.Lookup does.
+	Lookup(input string) (T, []string, error)
+
+	// TryLookup is Lookup reporting success as a boolean.
+	TryLookup(input string) (T, []string, bool)
+
+	// Matches reports whether any pattern of the table matches the input.
+	Matches(input string) bool
+
+	// Entries returns an iterator over the patterns of the table and their
+	// values, as RegexpTable.All does.
+	Entries() iter.Seq2[string, T]
+}
+
+// View returns a ReadOnlyTable backed by the table, so that libraries can accept
+// a table without being able to add or remove patterns, change its options or
+// recompile it. The view is not a snapshot: it sees later changes made through
+// the table itself, and a lookup through it still compiles the table if those
+// changes require it. The view cannot be converted back into the table.
+func (rt *RegexpTable[T]) View() ReadOnlyTable[T] {
+	return tableView[T]{table: rt}
+}
+
+// tableView implements ReadOnlyTable. It holds the table in a field, rather than
+// being a named *RegexpTable type, so that a type assertion cannot recover it.
+type tableView[T any] struct {
+	table *RegexpTable[T]
+}
+
+// Lookup calls the table's Lookup.
+func (v tableView[T]) Lookup(input string) (T, []string, error) {
+	return v.table.Lookup(input)
+}
+
+// TryLookup calls the table's TryLookup.
+func (v tableView[T]) TryLookup(input string) (T, []string, bool) {
+	return v.table.TryLookup(input)
+}
+
+// Matches reports whether the table's Lookup would succeed. It finds the winning
+// entry just as Lookup does, submatches and all, so that exclusions and removed
+// patterns are honoured, and so the match is recorded in the hit statistics and
+// passed to match callbacks like any other.
+func (v tableView[T]) Matches(input string) bool {
+	_, _, err := v.table.find(input)
+	return err == nil
+}
+
+// Entries calls the table's All.
+func (v tableView[T]) Entries() iter.Seq2[string, T] {
+	return v.table.All()
+}
@@ -0,0 +1,35 @@
+package regexptable
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestRegexpTable_View(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	view := table.View()
+
+	if value, matches, err := view.Lookup("42"); err != nil || value != "number" || matches[0] != "42" {
+		t.Errorf("Expected number, got %q %q %v", value, matches, err)
+	}
+	if _, _, ok := view.TryLookup("abc"); ok {
+		t.Error("Expected TryLookup to fail for abc")
+	}
+	if !view.Matches("7") || view.Matches("abc") {
+		t.Error("Expected Matches to report only numbers")
+	}
+
+	table.AddPattern(`[a-z]+`, "word")
+	if !view.Matches("abc") {
+		t.Error("Expected the view to see patterns added to the table")
+	}
+	entries := maps.Collect(view.Entries())
+	if len(entries) != 2 || entries[`[a-z]+`] != "word" {
+		t.Errorf("Expected both entries, got %v", entries)
+	}
+
+	if _, ok := any(view).(*RegexpTable[string]); ok {
+		t.Error("Expected the view not to be the table itself")
+	}
+}
@@ -0,0 +1,88 @@
+package regexptable
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportVRL writes the table's rules as a Vector Remap Language program, so that
+// a vector.dev pipeline can classify with the same rules. The program is a chain
+// of if/else if statements, one per rule in match order, that match the string
+// given by the VRL expression source, e.g. string!(.message), and assign the
+// rule's value to target, e.g. .class. Values are rendered by formatValue, or
+// with %v if it is nil, as VRL strings, and rules with several values assign an
+// array of them.
+//
+// Patterns are rewritten from Go syntax into the syntax of Rust's regex crate,
+// with the table's anchoring and modes applied. For tables anchored at the start,
+// the program picks the same rule as a lookup; otherwise a lookup prefers the
+// rule matching furthest left, which the program does not. Rules that cannot be
+// rewritten, such as those with exclusions or in another engine's syntax, are
+// left out and reported in a *TranslationError after the program has been
+// written.
+func (rt *RegexpTable[T]) ExportVRL(w io.Writer, source, target string, formatValue func(T) string) error {
+	rules, translationErr := rt.translateRules(rustDialect, formatValue)
+	var incomplete *TranslationError
+	if translationErr != nil && !errors.As(translationErr, &incomplete) {
+		return translationErr
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "# Exported from a regexp table: the first rule that matches wins.")
+	for i, rule := range rules {
+		keyword := "if"
+		if i > 0 {
+			keyword = "} else if"
+		}
+		// A quote would end the regex literal; \x27 means the same to the regex.
+		expression := strings.ReplaceAll(rule.expression, "'", `\x27`)
+		fmt.Fprintf(out, "%s match(%s, r'%s') { # rule %d\n", keyword, source, expression, rule.index)
+		fmt.Fprintf(out, "    %s = %s\n", target, vrlValue(rule.values))
+	}
+	if len(rules) > 0 {
+		fmt.Fprintln(out, "}")
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return translationErr
+}
+
+// vrlValue returns a VRL literal for a rule's values: a string for a single
+// value and an array of strings otherwise.
+func vrlValue(values []string) string {
+	if len(values) == 1 {
+		return vrlString(values[0])
+	}
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = vrlString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// vrlString returns s as a VRL string literal.
+func vrlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '{':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package regexptable
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRegexpTable_ExportVRL(t *testing.T) {
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`it's\b`, `quote "{x}"`).
+		AddPatternValues(`(?i)get`, "method", "verb").
+		MustBuild(true, true)
+
+	var out bytes.Buffer
+	if err := table.ExportVRL(&out, "string!(.message)", ".class", nil); err != nil {
+		t.Fatalf("ExportVRL failed: %v", err)
+	}
+	expected := "# Exported from a regexp table: the first rule that matches wins.\n" +
+		`if match(string!(.message), r'\A(?:[0-9]+)\z') { # rule 0` + "\n" +
+		`    .class = "number"` + "\n" +
+		`} else if match(string!(.message), r'\A(?:it\x27s(?-u:\b))\z') { # rule 1` + "\n" +
+		`    .class = "quote \"\{x}\""` + "\n" +
+		`} else if match(string!(.message), r'\A(?:(?i:GET))\z') { # rule 2` + "\n" +
+		`    .class = ["method", "verb"]` + "\n" +
+		"}\n"
+	if out.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestRegexpTable_ExportVRLIncomplete(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotFollowedBy: `\(`})
+	var out bytes.Buffer
+	err := table.ExportVRL(&out, ".message", ".class", nil)
+	var incomplete *TranslationError
+	if !errors.As(err, &incomplete) {
+		t.Errorf("Expected a TranslationError, got %v", err)
+	}
+	if out.String() != "# Exported from a regexp table: the first rule that matches wins.\n" {
+		t.Errorf("Expected an empty program, got\n%s", out.String())
+	}
+}
@@ -0,0 +1,41 @@
+package regexptable
+
+import "errors"
+
+// WarmUp pays the table's start-up costs up front, so that a server can do so
+// before it reports ready rather than on its first request. It compiles the
+// union and the structures lookups use, such as the two-phase prefilter, and the
+// individual pattern of every entry, which lookups otherwise compile on first
+// use to disambiguate matches and check exclusions. With examples > 0 it then
+// looks up that many inputs generated from each pattern (see Examples), which
+// exercises the lookup paths once. These synthetic lookups leave no trace: they
+// bypass the memoization cache, hit statistics and diagnostics.
+//
+// WarmUp returns the error that the first lookup would have reported, such as an
+// invalid pattern, or an error from a synthetic lookup other than ErrNoMatch.
+func (rt *RegexpTable[T]) WarmUp(examples int) error {
+	if err := rt.ensureCompiled(); err != nil {
+		return err
+	}
+	if err := rt.precompileIndividuals(); err != nil {
+		return codeErrorf(CodeCompileFailed, "failed to compile individual pattern: %w", err)
+	}
+	if examples <= 0 {
+		return nil
+	}
+
+	diagnostics := rt.diagnostics
+	rt.diagnostics = nil
+	defer func() { rt.diagnostics = diagnostics }()
+	for _, entry := range rt.maplets {
+		for _, input := range Examples(entry.Pattern, examples) {
+			if rt.normalizers != nil {
+				input, _ = rt.normalize(input)
+			}
+			if _, _, err := rt.matchEntry(input); err != nil && !errors.Is(err, ErrNoMatch) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package regexptable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexpTable_WarmUp(t *testing.T) {
+	var diagnostics []Diagnostic
+	table := NewRegexpTableBuilder[string]().
+		AddPattern(`\d+`, "number").
+		AddPattern(`x*`, "xs").
+		AddPatternExcluding(`[a-z]+`, "word", Exclusion{NotMatching: `if`}).
+		WithHitStats(true, 2).
+		WithMemoization(10, false).
+		WithTwoPhaseLookup(true).
+		WithDiagnostics(func(d Diagnostic) { diagnostics = append(diagnostics, d) }, nil).
+		MustBuild(true, true)
+
+	if err := table.WarmUp(0); err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if table.prefilter == nil {
+		t.Error("Expected the prefilter to be built")
+	}
+	for _, entry := range table.maplets {
+		if entry.compiledPattern == nil {
+			t.Errorf("Expected the individual pattern of %s to be compiled", entry.Pattern)
+		}
+	}
+
+	if err := table.WarmUp(3); err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	for i := range table.Len() {
+		if table.HitCount(i) != 0 {
+			t.Errorf("Expected synthetic lookups not to count as hits, got %d for pattern %d", table.HitCount(i), i)
+		}
+	}
+	if table.memo.order.Len() != 0 || len(diagnostics) != 0 {
+		t.Errorf("Expected synthetic lookups to leave no trace, got %d cached and %v", table.memo.order.Len(), diagnostics)
+	}
+	if value, _, _ := table.Lookup("42"); value != "number" || table.HitCount(0) != 1 {
+		t.Errorf("Expected lookups to work as usual after warming up, got %q", value)
+	}
+}
+
+func TestRegexpTable_WarmUpInvalid(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`(`, "broken")
+	if err := table.WarmUp(1); err == nil || !strings.Contains(err.Error(), "missing closing )") {
+		t.Errorf("Expected the invalid pattern to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package regexptable
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Word is one whitespace-separated word of an input classified by LookupWords.
+type Word[T any] struct {
+	Text   string     // The word itself
+	Start  int        // Byte offset of the word in the input
+	Result *Result[T] // How the word was classified, nil if no pattern matched it
+}
+
+// LookupWords splits the input into words at white space and classifies each
+// word on its own, as LookupResult would, returning one Word per word in input
+// order. It is a quick way to apply rules to a bag of words without setting up a
+// Tokenizer; each word is matched with the table's own anchoring, so an anchored
+// table must match a word in its entirety. The Start and End of each Result, and
+// its group spans, are offsets in the whole input.
+//
+// Words that no pattern matches have a nil Result. Any other lookup error, such as
+// ErrNoPatterns, is returned immediately.
+func (rt *RegexpTable[T]) LookupWords(input string) ([]Word[T], error) {
+	var words []Word[T]
+	start := -1
+	for i := 0; i <= len(input); {
+		r, width := utf8.RuneError, 1
+		if i < len(input) {
+			r, width = utf8.DecodeRuneInString(input[i:])
+		}
+		switch {
+		case i < len(input) && !unicode.IsSpace(r):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0:
+			word, err := rt.lookupWord(input[start:i], start)
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, word)
+			start = -1
+		}
+		i += width
+	}
+	return words, nil
+}
+
+// lookupWord classifies a word that starts at the given offset of the input.
+func (rt *RegexpTable[T]) lookupWord(text string, start int) (Word[T], error) {
+	word := Word[T]{Text: text, Start: start}
+	result, err := rt.LookupResult(text)
+	if errors.Is(err, ErrNoMatch) {
+		return word, nil
+	}
+	if err != nil {
+		return Word[T]{}, err
+	}
+	result.shift(start)
+	word.Result = result
+	return word, nil
+}
@@ -0,0 +1,65 @@
+package regexptable
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegexpTable_LookupWords(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	table.AddPattern(`\d+`, "number")
+	table.AddPattern(`[a-z]+`, "word")
+
+	// Words are separated by any Unicode white space, here a no-break space after ?!.
+	words, err := table.LookupWords("  abc 42\t?!\u00a0x9 end")
+	if err != nil {
+		t.Fatalf("LookupWords failed: %v", err)
+	}
+
+	expected := []struct {
+		text  string
+		start int
+		value string // "" for words that do not match
+	}{
+		{"abc", 2, "word"},
+		{"42", 6, "number"},
+		{"?!", 9, ""},
+		{"x9", 13, ""},
+		{"end", 16, "word"},
+	}
+	if len(words) != len(expected) {
+		t.Fatalf("Expected %d words, got %d: %v", len(expected), len(words), words)
+	}
+	for i, want := range expected {
+		word := words[i]
+		if word.Text != want.text || word.Start != want.start {
+			t.Errorf("Expected word %q at %d, got %q at %d", want.text, want.start, word.Text, word.Start)
+		}
+		switch {
+		case want.value == "" && word.Result != nil:
+			t.Errorf("Expected %q not to match, got %q", want.text, word.Result.Value)
+		case want.value != "" && word.Result == nil:
+			t.Errorf("Expected %q to match %q, got no match", want.text, want.value)
+		case want.value != "":
+			if word.Result.Value != want.value {
+				t.Errorf("Expected %q to match %q, got %q", want.text, want.value, word.Result.Value)
+			}
+			if word.Result.Start != want.start || word.Result.End != want.start+len(want.text) {
+				t.Errorf("Expected span [%d, %d), got [%d, %d)", want.start, want.start+len(want.text), word.Result.Start, word.Result.End)
+			}
+		}
+	}
+}
+
+func TestRegexpTable_LookupWordsEmpty(t *testing.T) {
+	table := NewRegexpTable[string](true, true)
+	if _, err := table.LookupWords("abc"); !errors.Is(err, ErrNoPatterns) {
+		t.Errorf("Expected ErrNoPatterns, got %v", err)
+	}
+
+	table.AddPattern(`\d+`, "number")
+	words, err := table.LookupWords(" \t ")
+	if err != nil || len(words) != 0 {
+		t.Errorf("Expected no words, got %v, %v", words, err)
+	}
+}